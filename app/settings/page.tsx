@@ -8,12 +8,13 @@ import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@
 import { Dialog, DialogContent, DialogHeader, DialogTitle, DialogTrigger } from '@/components/ui/dialog'
 import Link from 'next/link'
 import { useState, useEffect } from 'react'
+import { useAuth } from '@/lib/auth-context'
 
 interface Settings {
   notificationsEnabled: boolean
   team: string
   availableTeams: string[]
-  markdownFile: { name: string; content: string } | null
+  markdownFile: { name: string; content: string; documentId?: string } | null
 }
 
 export default function SettingsPage() {
@@ -41,6 +42,8 @@ export default function SettingsPage() {
   const [teamDialogOpen, setTeamDialogOpen] = useState(false)
   const [teamLoading, setTeamLoading] = useState(false)
   const [teamInfo, setTeamInfo] = useState<string[]>([])
+  const [fileError, setFileError] = useState('')
+  const { tokens } = useAuth()
 
   useEffect(() => {
     const loadDefaults = async () => {
@@ -79,6 +82,29 @@ export default function SettingsPage() {
     }
   }
 
+  // PDF, DOCX and HTML files are converted to markdown by the documents API,
+  // which stores the result; the converted text is kept for the chat page.
+  const handleConvertFile = (file: File) => {
+    if (!tokens) {
+      setFileError('Log in to upload PDF, DOCX or HTML files')
+      return
+    }
+    const reader = new FileReader()
+    reader.onload = async (event) => {
+      try {
+        const { apiClient } = await import('@/lib/api')
+        const dataUrl = event.target?.result as string
+        const doc = await apiClient.uploadFile(file.name, dataUrl.split(',')[1] || '', tokens.id_token)
+        const converted = await apiClient.document(doc.id, tokens.id_token)
+        setSettings(prev => ({ ...prev, markdownFile: { name: file.name, content: converted.content, documentId: doc.id } }))
+      } catch (error) {
+        console.error('Failed to convert file:', error)
+        setFileError(error instanceof Error ? error.message : 'Failed to convert file')
+      }
+    }
+    reader.readAsDataURL(file)
+  }
+
   const handleViewFile = () => {
     if (settings.markdownFile) {
       setFileContent(settings.markdownFile.content)
@@ -130,14 +156,17 @@ export default function SettingsPage() {
             <h2 className="tui-heading-md mb-4">TuiTui Markdown</h2>
             <div className="space-y-4">
               <div>
-                <Label htmlFor="markdown-file" className="text-tui-blue-500">Upload Markdown File</Label>
+                <Label htmlFor="markdown-file" className="text-tui-blue-500">Upload Markdown, PDF, DOCX or HTML File</Label>
                 <Input
                   id="markdown-file"
                   type="file"
-                  accept=".md"
+                  accept=".md,.txt,.pdf,.docx,.html,.htm"
                   onChange={(e) => {
                     const file = e.target.files?.[0]
-                    if (file) {
+                    setFileError('')
+                    if (file && /\.(pdf|docx|html?)$/i.test(file.name)) {
+                      handleConvertFile(file)
+                    } else if (file) {
                       const reader = new FileReader()
                       reader.onload = (event) => {
                         const content = event.target?.result as string
@@ -150,6 +179,7 @@ export default function SettingsPage() {
                   }}
                   className="mt-1"
                 />
+                {fileError && <p className="text-red-500 text-sm mt-1">{fileError}</p>}
               </div>
               <div className="flex items-center justify-between">
                 <div className="flex items-center space-x-2">
@@ -76,11 +76,25 @@ export default function TuiTuiPage() {
         }
       }
       if (settings.markdownFile) {
-        requestData.markdownContent = settings.markdownFile.content
+        // Upload the document once and send its ID; repeat uploads of the same
+        // content return the same document. Fall back to the raw content if the
+        // documents API is unavailable.
+        try {
+          if (!settings.markdownFile.documentId) {
+            const doc = await apiClient.uploadDocument(settings.markdownFile.name, settings.markdownFile.content, tokens!.id_token)
+            settings.markdownFile.documentId = doc.id
+            localStorage.setItem('tuitui-settings', JSON.stringify(settings))
+          }
+          requestData.documentIds = [settings.markdownFile.documentId]
+        } catch (error) {
+          console.error('Failed to upload document:', error)
+          requestData.markdownContent = settings.markdownFile.content
+        }
       }
 
-      // Call the chat API with conversation history and additional data
-      const response = await apiClient.chat(requestData, tokens!.access_token)
+      // Call the chat API with conversation history and additional data.
+      // The API Gateway Cognito authorizer validates ID tokens.
+      const response = await apiClient.chat(requestData, tokens!.id_token)
 
 
       // Add bot response
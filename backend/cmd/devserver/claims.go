@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// errUnauthorized is returned for a missing, malformed or expired token
+var errUnauthorized = errors.New("unauthorized")
+
+// devUser is the identity written into the dev token
+type devUser struct {
+	Sub    string
+	Email  string
+	Name   string
+	Groups []string
+}
+
+// devToken returns an unsigned JWT carrying the claims of a Cognito ID token
+// for user, valid for ttl. It is only accepted by this server.
+func devToken(user devUser, now time.Time, ttl time.Duration) string {
+	claims := map[string]interface{}{
+		"sub":            user.Sub,
+		"email":          user.Email,
+		"email_verified": true,
+		"name":           user.Name,
+		"token_use":      "id",
+		"iss":            "tuitui-devserver",
+		"iat":            now.Unix(),
+		"exp":            now.Add(ttl).Unix(),
+	}
+	if len(user.Groups) > 0 {
+		claims["cognito:groups"] = user.Groups
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+// claimsFromRequest returns the claims the Cognito authorizer would pass on for
+// the request's bearer token. The signature is not checked: any JWT, such as
+// the dev token or a token from a real user pool, is trusted, but an expired
+// one is refused like API Gateway would.
+func claimsFromRequest(r *http.Request, now time.Time) (map[string]interface{}, error) {
+	token := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errUnauthorized
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, errUnauthorized
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.UseNumber()
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, errUnauthorized
+	}
+	if sub, _ := raw["sub"].(string); sub == "" {
+		return nil, errUnauthorized
+	}
+	if exp, ok := raw["exp"].(json.Number); ok {
+		if seconds, err := exp.Int64(); err != nil || now.Unix() >= seconds {
+			return nil, errUnauthorized
+		}
+	}
+
+	// The authorizer passes every claim on as a string, lists as "[a b]"
+	claims := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		claims[key] = claimString(value)
+	}
+	return claims, nil
+}
+
+// claimString formats a claim value the way the Cognito authorizer does
+func claimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = claimString(item)
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
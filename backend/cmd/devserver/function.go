@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda/messages"
+)
+
+// invoker runs a Lambda with a JSON payload and returns its JSON result
+type invoker interface {
+	Invoke(ctx context.Context, requestID string, payload []byte) ([]byte, error)
+}
+
+// errFunction is returned when the handler itself returned an error, which API
+// Gateway turns into a 502
+var errFunction = errors.New("function error")
+
+// function runs one Lambda binary in the RPC mode of aws-lambda-go, the mode
+// the go1.x runtime used: the binary listens on _LAMBDA_SERVER_PORT and serves
+// Function.Invoke. The process is started on the first request.
+type function struct {
+	name string
+	path string   // binary built from cmd/lambda/<name>
+	env  []string // environment of the process, without the port
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	client *rpc.Client
+}
+
+// newFunction returns the Lambda built into dir
+func newFunction(name, dir string, env []string) *function {
+	return &function{name: name, path: filepath.Join(dir, name), env: env}
+}
+
+// Invoke sends the payload to the Lambda, starting it if needed
+func (f *function) Invoke(ctx context.Context, requestID string, payload []byte) ([]byte, error) {
+	client, err := f.start()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(gatewayTimeout)
+	}
+	request := &messages.InvokeRequest{
+		Payload:            payload,
+		RequestId:          requestID,
+		InvokedFunctionArn: "arn:aws:lambda:local:000000000000:function:" + f.name,
+		Deadline: messages.InvokeRequest_Timestamp{
+			Seconds: deadline.Unix(),
+			Nanos:   int64(deadline.Nanosecond()),
+		},
+	}
+
+	var response messages.InvokeResponse
+	call := client.Go("Function.Invoke", request, &response, nil)
+	select {
+	case <-call.Done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if call.Error != nil {
+		// The process died, e.g. it panicked outside the handler; start it again next time
+		f.stop()
+		return nil, fmt.Errorf("invoke %s: %w", f.name, call.Error)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%w: %s: %s", errFunction, response.Error.Type, response.Error.Message)
+	}
+	return response.Payload, nil
+}
+
+// start launches the process and connects to it, unless it is already running
+func (f *function) start() (*rpc.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client != nil {
+		return f.client, nil
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(f.path)
+	cmd.Env = append(append([]string{}, f.env...),
+		"_LAMBDA_SERVER_PORT="+strconv.Itoa(port),
+		"AWS_LAMBDA_FUNCTION_NAME="+f.name,
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", f.name, err)
+	}
+
+	// The binary needs a moment before it listens
+	address := "localhost:" + strconv.Itoa(port)
+	for i := 0; ; i++ {
+		client, err := rpc.Dial("tcp", address)
+		if err == nil {
+			f.cmd, f.client = cmd, client
+			return client, nil
+		}
+		if i == 50 {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return nil, fmt.Errorf("connect to %s: %w", f.name, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// stop ends the process, if it is running
+func (f *function) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client != nil {
+		f.client.Close()
+		f.client = nil
+	}
+	if f.cmd != nil {
+		f.cmd.Process.Kill()
+		f.cmd.Wait()
+		f.cmd = nil
+	}
+}
+
+// freePort asks the OS for a port nothing is listening on
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, fmt.Errorf("find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,180 @@
+// Command devserver serves the API Gateway routes of infrastructure/api_gateway.tf
+// on localhost, so the Next.js app can run against the Lambda handlers without
+// deploying them. Each Lambda in cmd/lambda is built and run unchanged; requests
+// reach its Handler as the same events API Gateway sends, with the claims of
+// the bearer token standing in for the Cognito authorizer.
+//
+// Run it from the backend directory:
+//
+//	go run ./cmd/devserver
+//
+// and point the app at it with NEXT_PUBLIC_API_BASE_URL=http://localhost:3001.
+// Guarded routes need an Authorization header; the server prints a dev token
+// for the user given by the -sub, -email, -name and -groups flags.
+//
+// With -fake-cognito the auth routes talk to an in-process fake user pool
+// instead of AWS, so sign up, verification and sign in work offline; the
+// verification codes are printed to the console.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"tuitui-backend/internal/fakecognito"
+)
+
+// loadEnvFile sets the KEY=VALUE pairs in path that are not already set in the
+// environment. A missing file is not an error.
+func loadEnvFile(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return scanner.Err()
+}
+
+// startFakeCognito serves a fake user pool on a free local port and returns
+// the environment that points the auth Lambdas at it
+func startFakeCognito() ([]string, error) {
+	clientID := os.Getenv("COGNITO_USER_POOL_CLIENT_ID")
+	if clientID == "" {
+		clientID = "local-client"
+	}
+	pool, err := fakecognito.New("local_pool", clientID)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, err
+	}
+	go http.Serve(listener, pool)
+
+	return []string{
+		"COGNITO_ENDPOINT=http://" + listener.Addr().String(),
+		"COGNITO_USER_POOL_CLIENT_ID=" + clientID,
+	}, nil
+}
+
+// buildFunctions compiles every Lambda in cmd/lambda into dir
+func buildFunctions(dir string) error {
+	cmd := exec.Command("go", "build", "-o", dir+string(os.PathSeparator), "./cmd/lambda/...")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:3001", "address to listen on")
+	envFile := flag.String("env", ".env", "file of environment variables for the Lambdas")
+	binDir := flag.String("bin", "", "directory of prebuilt Lambda binaries; built into a temporary directory when empty")
+	stage := flag.String("stage", "local", "API Gateway stage name passed to the handlers")
+	sub := flag.String("sub", "dev-user", "sub claim of the dev token")
+	email := flag.String("email", "dev@example.com", "email claim of the dev token")
+	name := flag.String("name", "Dev User", "name claim of the dev token")
+	groups := flag.String("groups", "admin", "comma separated Cognito groups of the dev token")
+	fakeCognito := flag.Bool("fake-cognito", false, "serve the auth routes from a fake in-memory user pool instead of AWS")
+	flag.Parse()
+
+	if err := loadEnvFile(*envFile); err != nil {
+		log.Fatalf("Failed to load %s: %v", *envFile, err)
+	}
+
+	dir := *binDir
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "tuitui-devserver"); err != nil {
+			log.Fatalf("Failed to create build directory: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		log.Printf("Building Lambda functions into %s...", dir)
+		if err := buildFunctions(dir); err != nil {
+			log.Fatalf("Failed to build Lambda functions: %v", err)
+		}
+	}
+
+	// The chat Lambda answers through API Gateway here, not a streaming Function URL
+	env := append(os.Environ(), "CHAT_RESPONSE_STREAMING=false")
+	if *fakeCognito {
+		cognitoEnv, err := startFakeCognito()
+		if err != nil {
+			log.Fatalf("Failed to start fake Cognito: %v", err)
+		}
+		env = append(env, cognitoEnv...)
+		log.Printf("Auth routes use a fake user pool at %s", strings.TrimPrefix(cognitoEnv[0], "COGNITO_ENDPOINT="))
+	}
+
+	fns := make(map[string]*function)
+	invokers := make(map[string]invoker)
+	for _, fnName := range functions() {
+		fns[fnName] = newFunction(fnName, dir, env)
+		invokers[fnName] = fns[fnName]
+	}
+
+	httpServer := &http.Server{
+		Addr:    *addr,
+		Handler: &server{functions: invokers, stage: *stage},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+
+	var groupList []string
+	for _, group := range strings.Split(*groups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groupList = append(groupList, group)
+		}
+	}
+	token := devToken(devUser{Sub: *sub, Email: *email, Name: *name, Groups: groupList}, time.Now(), 7*24*time.Hour)
+
+	fmt.Printf("API listening on http://%s\n", *addr)
+	fmt.Printf("Dev token for %s (%s), valid for 7 days:\n\n  Authorization: Bearer %s\n\n", *email, *sub, token)
+
+	err := httpServer.ListenAndServe()
+	for _, fn := range fns {
+		fn.stop()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// route is one API Gateway method: the resource path, which may hold {param}
+// segments, the Lambda in cmd/lambda that serves it, and whether the Cognito
+// authorizer guards it
+type route struct {
+	Method   string
+	Resource string
+	Function string
+	Auth     bool
+}
+
+// routes mirrors the methods in infrastructure/api_gateway.tf. OPTIONS methods
+// are mock integrations there, so the server answers them itself.
+var routes = []route{
+	{"GET", "/health", "health", false},
+	{"POST", "/auth/register", "auth-register", false},
+	{"POST", "/auth/login", "auth-login", false},
+	{"POST", "/auth/verify", "auth-verify", false},
+	{"POST", "/auth/resend-code", "auth-resend-code", false},
+	{"POST", "/auth/forgot-password", "auth-forgot-password", false},
+	{"POST", "/auth/reset-password", "auth-reset-password", false},
+	{"POST", "/auth/refresh", "auth-refresh", false},
+	{"POST", "/auth/challenge", "auth-challenge", false},
+	{"POST", "/auth/logout", "auth-logout", true},
+	{"POST", "/auth/logout-all", "auth-logout-all", true},
+	{"POST", "/auth/mfa/setup", "auth-mfa-setup", true},
+	{"POST", "/auth/mfa/verify", "auth-mfa-verify", true},
+	{"POST", "/auth/mfa/preference", "auth-mfa-preference", true},
+	{"POST", "/auth/mfa/disable", "auth-mfa-disable", true},
+	{"POST", "/chat", "chat", true},
+	{"GET", "/conversations", "conversations", true},
+	{"POST", "/conversations", "conversations", true},
+	{"GET", "/conversations/{id}", "conversations", true},
+	{"DELETE", "/conversations/{id}", "conversations", true},
+	{"GET", "/conversations/{id}/messages", "conversation-messages", true},
+	{"GET", "/knowledge", "knowledge", true},
+	{"POST", "/knowledge", "knowledge", true},
+	{"GET", "/knowledge/{id}", "knowledge", true},
+	{"PUT", "/knowledge/{id}", "knowledge", true},
+	{"GET", "/usage", "usage", true},
+	{"GET", "/teams", "teams", true},
+	{"POST", "/teams", "teams", true},
+	{"GET", "/teams/{id}", "teams", true},
+	{"PUT", "/teams/{id}", "teams", true},
+	{"DELETE", "/teams/{id}", "teams", true},
+	{"GET", "/teams/{id}/members", "team-members", true},
+	{"POST", "/teams/{id}/members", "team-members", true},
+	{"PUT", "/teams/{id}/members/{userId}", "team-members", true},
+	{"DELETE", "/teams/{id}/members/{userId}", "team-members", true},
+	{"GET", "/documents", "documents", true},
+	{"POST", "/documents", "documents", true},
+	{"GET", "/documents/{id}", "documents", true},
+	{"PUT", "/documents/{id}", "documents", true},
+	{"DELETE", "/documents/{id}", "documents", true},
+	{"GET", "/documents/{id}/versions", "document-versions", true},
+	{"GET", "/prompts", "prompts", true},
+	{"POST", "/prompts", "prompts", true},
+	{"GET", "/prompts/{name}", "prompts", true},
+	{"PUT", "/prompts/{name}", "prompts", true},
+	{"GET", "/prompts/{name}/versions", "prompt-versions", true},
+	{"POST", "/prompts/{name}/versions", "prompt-versions", true},
+	{"POST", "/prompts/{name}/preview", "prompt-preview", true},
+}
+
+// match reports whether path fits the route's resource, returning the values
+// of its {param} segments
+func (r route) match(path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(r.Resource, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if got[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[strings.Trim(segment, "{}")] = got[i]
+			continue
+		}
+		if segment != got[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// findRoutes returns the routes whose resource matches path, with the path
+// parameters they share
+func findRoutes(path string) ([]route, map[string]string) {
+	var found []route
+	var params map[string]string
+	for _, r := range routes {
+		if p, ok := r.match(path); ok {
+			found = append(found, r)
+			params = p
+		}
+	}
+	return found, params
+}
+
+// functions returns the names of the Lambdas the routes use, sorted
+func functions() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range routes {
+		if !seen[r.Function] {
+			seen[r.Function] = true
+			names = append(names, r.Function)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
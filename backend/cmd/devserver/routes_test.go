@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// terraformRoutes reads the Lambda backed methods of api_gateway.tf as
+// "METHOD /path auth" strings
+func terraformRoutes(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile("../../../infrastructure/api_gateway.tf")
+	if err != nil {
+		t.Skipf("api_gateway.tf not available: %v", err)
+	}
+
+	blocks := regexp.MustCompile(`(?s)resource "(\w+)" "(\w+)" \{(.*?)\n\}`).FindAllStringSubmatch(string(data), -1)
+	field := func(body, name string) string {
+		m := regexp.MustCompile(name + `\s*=\s*"?([^"\s]+)"?`).FindStringSubmatch(body)
+		if m == nil {
+			return ""
+		}
+		return m[1]
+	}
+
+	type resource struct{ parent, part string }
+	resources := make(map[string]resource)
+	for _, b := range blocks {
+		if b[1] == "aws_api_gateway_resource" {
+			resources[b[2]] = resource{field(b[3], "parent_id"), field(b[3], "path_part")}
+		}
+	}
+	var path func(ref string) string
+	path = func(ref string) string {
+		if strings.Contains(ref, "root_resource_id") {
+			return ""
+		}
+		r := resources[strings.Split(ref, ".")[1]]
+		return path(r.parent) + "/" + r.part
+	}
+
+	methods := make(map[string]string)
+	for _, b := range blocks {
+		if b[1] == "aws_api_gateway_method" {
+			auth := field(b[3], "authorization") == "COGNITO_USER_POOLS"
+			methods[b[2]] = field(b[3], "http_method") + " " + path(field(b[3], "resource_id")) + " " + map[bool]string{true: "auth", false: "open"}[auth]
+		}
+	}
+
+	var found []string
+	for _, b := range blocks {
+		if b[1] == "aws_api_gateway_integration" && field(b[3], "type") == "AWS_PROXY" {
+			method := strings.TrimPrefix(field(b[3], "http_method"), "aws_api_gateway_method.")
+			method = strings.TrimSuffix(method, ".http_method")
+			found = append(found, methods[method])
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+func TestRoutes_MatchAPIGateway(t *testing.T) {
+	want := terraformRoutes(t)
+
+	var got []string
+	for _, r := range routes {
+		got = append(got, r.Method+" "+r.Resource+" "+map[bool]string{true: "auth", false: "open"}[r.Auth])
+	}
+	sort.Strings(got)
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Routes differ from api_gateway.tf\nexpected:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestRoutes_FunctionsExist(t *testing.T) {
+	for _, name := range functions() {
+		if _, err := os.Stat("../lambda/" + name + "/main.go"); err != nil {
+			t.Errorf("Expected cmd/lambda/%s to exist: %v", name, err)
+		}
+	}
+}
+
+func TestFindRoutes(t *testing.T) {
+	found, params := findRoutes("/teams/t1/members/u1")
+	if len(found) != 2 || found[0].Function != "team-members" {
+		t.Fatalf("Expected the two member routes, got %v", found)
+	}
+	if params["id"] != "t1" || params["userId"] != "u1" {
+		t.Errorf("Unexpected path parameters %v", params)
+	}
+
+	if found, _ := findRoutes("/teams//members"); len(found) != 0 {
+		t.Errorf("Expected an empty parameter not to match, got %v", found)
+	}
+	if found, _ := findRoutes("/nope"); len(found) != 0 {
+		t.Errorf("Expected no routes, got %v", found)
+	}
+}
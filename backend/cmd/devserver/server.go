@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/pkg/api"
+)
+
+// gatewayTimeout is API Gateway's limit on a Lambda integration
+const gatewayTimeout = 29 * time.Second
+
+// server serves the API Gateway routes, invoking the Lambda behind each one
+type server struct {
+	functions map[string]invoker
+	stage     string
+}
+
+// gatewayError writes an error in the shape API Gateway itself uses, which is
+// {"message": ...} rather than the handlers' {"error": ...}
+func gatewayError(w http.ResponseWriter, status int, message string, headers map[string]string) {
+	for key, value := range headers {
+		w.Header().Set(key, value)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	body, _ := json.Marshal(map[string]string{"message": message})
+	w.Write(body)
+}
+
+// ServeHTTP routes the request like API Gateway: unknown paths and methods are
+// rejected, OPTIONS is answered with the CORS headers, guarded routes need a
+// token, and everything else is passed to the route's Lambda
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	found, params := findRoutes(r.URL.Path)
+	if len(found) == 0 {
+		gatewayError(w, 404, "Missing Authentication Token", nil)
+		return
+	}
+
+	methods := make([]string, 0, len(found)+1)
+	var matched *route
+	for i := range found {
+		methods = append(methods, found[i].Method)
+		if found[i].Method == r.Method {
+			matched = &found[i]
+		}
+	}
+	corsHeaders := api.CORSHeaders(strings.Join(append(methods, "OPTIONS"), ","))
+
+	if r.Method == http.MethodOptions {
+		for key, value := range corsHeaders {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(200)
+		return
+	}
+	if matched == nil {
+		gatewayError(w, 403, "Missing Authentication Token", corsHeaders)
+		return
+	}
+
+	var claims map[string]interface{}
+	if matched.Auth {
+		var err error
+		if claims, err = claimsFromRequest(r, time.Now()); err != nil {
+			gatewayError(w, 401, "Unauthorized", corsHeaders)
+			return
+		}
+	}
+
+	request, err := newProxyRequest(r, *matched, params, claims, s.stage)
+	if err != nil {
+		gatewayError(w, 400, err.Error(), corsHeaders)
+		return
+	}
+
+	fn, ok := s.functions[matched.Function]
+	if !ok {
+		gatewayError(w, 500, "No function "+matched.Function, corsHeaders)
+		return
+	}
+
+	payload, _ := json.Marshal(request)
+	ctx, cancel := context.WithTimeout(r.Context(), gatewayTimeout)
+	defer cancel()
+
+	result, err := fn.Invoke(ctx, newRequestID(), payload)
+	if err != nil {
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			gatewayError(w, 504, "Endpoint request timed out", corsHeaders)
+		default:
+			gatewayError(w, 502, "Internal server error", corsHeaders)
+		}
+		return
+	}
+
+	var response events.APIGatewayProxyResponse
+	if err := json.Unmarshal(result, &response); err != nil {
+		log.Printf("%s %s: malformed Lambda response: %v", r.Method, r.URL.Path, err)
+		gatewayError(w, 502, "Internal server error", corsHeaders)
+		return
+	}
+	writeProxyResponse(w, response)
+}
+
+// newProxyRequest translates an HTTP request into the event API Gateway sends
+// a Lambda proxy integration
+func newProxyRequest(r *http.Request, rt route, params map[string]string, claims map[string]interface{}, stage string) (events.APIGatewayProxyRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, fmt.Errorf("Failed to read request body: %v", err)
+	}
+
+	request := events.APIGatewayProxyRequest{
+		Resource:                        rt.Resource,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         make(map[string]string),
+		MultiValueHeaders:               make(map[string][]string),
+		QueryStringParameters:           make(map[string]string),
+		MultiValueQueryStringParameters: make(map[string][]string),
+		PathParameters:                  params,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:    "000000000000",
+			RequestID:    newRequestID(),
+			Stage:        stage,
+			ResourcePath: rt.Resource,
+			HTTPMethod:   r.Method,
+			Path:         "/" + stage + r.URL.Path,
+			RequestTime:  time.Now().UTC().Format("02/Jan/2006:15:04:05 -0700"),
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  sourceIP(r),
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}
+
+	for key, values := range r.Header {
+		request.Headers[key] = values[len(values)-1]
+		request.MultiValueHeaders[key] = values
+	}
+	for key, values := range r.URL.Query() {
+		request.QueryStringParameters[key] = values[len(values)-1]
+		request.MultiValueQueryStringParameters[key] = values
+	}
+	if claims != nil {
+		request.RequestContext.Authorizer = map[string]interface{}{"claims": claims}
+	}
+
+	// API Gateway base64 encodes bodies that are not text
+	if utf8.Valid(body) {
+		request.Body = string(body)
+	} else {
+		request.Body = base64.StdEncoding.EncodeToString(body)
+		request.IsBase64Encoded = true
+	}
+	return request, nil
+}
+
+// writeProxyResponse writes a Lambda proxy integration response
+func writeProxyResponse(w http.ResponseWriter, response events.APIGatewayProxyResponse) {
+	for key, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(response.Body)
+		if err == nil {
+			body = decoded
+		}
+	}
+
+	status := response.StatusCode
+	if status == 0 {
+		status = 200
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// newRequestID returns a random ID in the UUID format API Gateway uses
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// sourceIP returns the client address without its port
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
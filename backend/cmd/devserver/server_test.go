@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/rpc"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/pkg/api"
+)
+
+// handlerInvoker runs a handler in process, as the RPC mode would
+type handlerInvoker func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+func (h handlerInvoker) Invoke(ctx context.Context, requestID string, payload []byte) ([]byte, error) {
+	var request events.APIGatewayProxyRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return nil, err
+	}
+	response, err := h(ctx, request)
+	if err != nil {
+		return nil, errFunction
+	}
+	return json.Marshal(response)
+}
+
+// testServer serves every function with handler
+func testServer(handler handlerInvoker) *server {
+	invokers := make(map[string]invoker)
+	for _, name := range functions() {
+		invokers[name] = handler
+	}
+	return &server{functions: invokers, stage: "local"}
+}
+
+func TestServer_PassesRequestToHandler(t *testing.T) {
+	var got events.APIGatewayProxyRequest
+	s := testServer(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		got = request
+		return api.JSON(201, map[string]string{"id": "m1"}, api.CORSHeaders("POST,OPTIONS")), nil
+	})
+
+	token := devToken(devUser{Sub: "user-1", Email: "a@example.com", Groups: []string{"admin", "editors"}}, time.Now(), time.Hour)
+	req := httptest.NewRequest("POST", "/teams/t1/members?role=admin", strings.NewReader(`{"userId":"u2"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if rec.Code != 201 || rec.Body.String() != `{"id":"m1"}` {
+		t.Fatalf("Unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("Expected the handler's headers to be passed on")
+	}
+
+	if got.Resource != "/teams/{id}/members" || got.Path != "/teams/t1/members" || got.HTTPMethod != "POST" {
+		t.Errorf("Unexpected route %s %s %s", got.HTTPMethod, got.Resource, got.Path)
+	}
+	if got.PathParameters["id"] != "t1" || got.QueryStringParameters["role"] != "admin" || got.Body != `{"userId":"u2"}` {
+		t.Errorf("Unexpected request %+v", got)
+	}
+	if got.RequestContext.RequestID == "" || got.RequestContext.Stage != "local" {
+		t.Errorf("Expected a request context, got %+v", got.RequestContext)
+	}
+
+	user, ok := auth.UserFromRequest(got)
+	if !ok || user.Sub != "user-1" || user.Email != "a@example.com" || !user.InGroup("editors") {
+		t.Errorf("Expected the token's user, got %+v", user)
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	s := testServer(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		t.Fatal("Handler should not be called")
+		return events.APIGatewayProxyResponse{}, nil
+	})
+
+	expired := devToken(devUser{Sub: "user-1"}, time.Now().Add(-2*time.Hour), time.Hour)
+	for _, header := range []string{"", "Bearer not-a-jwt", "Bearer " + expired} {
+		req := httptest.NewRequest("GET", "/conversations", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != 401 {
+			t.Errorf("%q: expected status 401, got %d", header, rec.Code)
+		}
+	}
+}
+
+func TestServer_OpenRouteNeedsNoToken(t *testing.T) {
+	s := testServer(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		if request.RequestContext.Authorizer != nil {
+			t.Error("Expected no authorizer context")
+		}
+		return events.APIGatewayProxyResponse{StatusCode: 200, Body: "ok"}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != 200 || rec.Body.String() != "ok" {
+		t.Errorf("Unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServer_GatewayResponses(t *testing.T) {
+	s := testServer(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{}, errors.New("boom")
+	})
+	token := "Bearer " + devToken(devUser{Sub: "user-1"}, time.Now(), time.Hour)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{"OPTIONS", "/teams/t1", 200},
+		{"GET", "/nope", 404},
+		{"PATCH", "/teams/t1", 403},
+		{"GET", "/teams/t1", 502},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", token)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.want, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("OPTIONS", "/teams/t1", nil))
+	if methods := rec.Header().Get("Access-Control-Allow-Methods"); methods != "GET,PUT,DELETE,OPTIONS" {
+		t.Errorf("Expected the resource's methods, got %q", methods)
+	}
+}
+
+func TestServer_BinaryBody(t *testing.T) {
+	var got events.APIGatewayProxyRequest
+	s := testServer(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		got = request
+		return events.APIGatewayProxyResponse{StatusCode: 200, Body: "aGk=", IsBase64Encoded: true}, nil
+	})
+
+	req := httptest.NewRequest("POST", "/documents", strings.NewReader("\xff\xfe"))
+	req.Header.Set("Authorization", "Bearer "+devToken(devUser{Sub: "user-1"}, time.Now(), time.Hour))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if !got.IsBase64Encoded || got.Body != "//4=" {
+		t.Errorf("Expected a base64 body, got %q", got.Body)
+	}
+	if rec.Body.String() != "hi" {
+		t.Errorf("Expected the response body decoded, got %q", rec.Body.String())
+	}
+}
+
+func TestFunction_InvokesOverRPC(t *testing.T) {
+	handler := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		if request.Path == "/fail" {
+			return events.APIGatewayProxyResponse{}, errors.New("boom")
+		}
+		return events.APIGatewayProxyResponse{StatusCode: 200, Body: request.Path}, nil
+	}
+
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.Register(lambda.NewFunction(lambda.NewHandler(handler))); err != nil {
+		t.Fatalf("Failed to register function: %v", err)
+	}
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go rpcServer.Accept(listener)
+
+	client, err := rpc.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	fn := &function{name: "test", client: client}
+	defer fn.stop()
+
+	payload, _ := json.Marshal(events.APIGatewayProxyRequest{Path: "/ok"})
+	result, err := fn.Invoke(context.Background(), "req-1", payload)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var response events.APIGatewayProxyResponse
+	json.Unmarshal(result, &response)
+	if response.Body != "/ok" {
+		t.Errorf("Expected body /ok, got %q", response.Body)
+	}
+
+	payload, _ = json.Marshal(events.APIGatewayProxyRequest{Path: "/fail"})
+	if _, err := fn.Invoke(context.Background(), "req-2", payload); !errors.Is(err, errFunction) {
+		t.Errorf("Expected a function error, got %v", err)
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := t.TempDir() + "/.env"
+	content := "# comment\nDEVSERVER_TEST_A=one\nexport DEVSERVER_TEST_B=\"two\"\nDEVSERVER_TEST_C=three\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// t.Setenv restores the variables afterwards; A and B are then unset for the file to fill in
+	t.Setenv("DEVSERVER_TEST_A", "")
+	t.Setenv("DEVSERVER_TEST_B", "")
+	t.Setenv("DEVSERVER_TEST_C", "kept")
+	os.Unsetenv("DEVSERVER_TEST_A")
+	os.Unsetenv("DEVSERVER_TEST_B")
+
+	if err := loadEnvFile(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for key, want := range map[string]string{"DEVSERVER_TEST_A": "one", "DEVSERVER_TEST_B": "two", "DEVSERVER_TEST_C": "kept"} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("Expected %s=%q, got %q", key, want, got)
+		}
+	}
+
+	if err := loadEnvFile(t.TempDir() + "/missing"); err != nil {
+		t.Errorf("Expected a missing file to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Command fakecognito serves a fake Cognito user pool for offline development.
+// Point the auth Lambdas, or the dev server, at it with
+//
+//	COGNITO_ENDPOINT=http://localhost:9229
+//	COGNITO_USER_POOL_CLIENT_ID=local-client
+//
+// Sign up, verification, password resets, sign in, token refresh, sign out and
+// authenticator app MFA then work without AWS. Codes are printed here instead
+// of emailed. -users adds confirmed accounts at start up, e.g.
+//
+//	go run ./cmd/fakecognito -users jane@tui.co.uk:Passw0rd! -admins jane@tui.co.uk
+//
+// and -temporary-users adds accounts as an admin would create them, which must
+// set a new password at their first sign in.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"tuitui-backend/internal/fakecognito"
+)
+
+// seedUser is an account given with -users
+type seedUser struct {
+	Email    string
+	Password string
+}
+
+// parseUsers reads a comma separated list of email:password pairs
+func parseUsers(list string) ([]seedUser, error) {
+	var users []seedUser
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		email, password, ok := strings.Cut(entry, ":")
+		if !ok || email == "" || password == "" {
+			return nil, fmt.Errorf("invalid user %q, expected email:password", entry)
+		}
+		users = append(users, seedUser{Email: email, Password: password})
+	}
+	return users, nil
+}
+
+// logRequests logs the action and status of each request
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: 200}
+		next.ServeHTTP(rec, r)
+		action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AWSCognitoIdentityProviderService.")
+		if action == "" {
+			action = r.Method + " " + r.URL.Path
+		}
+		log.Printf("%s %d %s", action, rec.status, rec.Header().Get("X-Amzn-ErrorType"))
+	})
+}
+
+// statusRecorder remembers the status code written
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:9229", "address to listen on")
+	poolID := flag.String("pool-id", "local_pool", "user pool ID used in token issuers")
+	clientID := flag.String("client-id", "local-client", "app client ID to accept")
+	users := flag.String("users", "", "comma separated email:password accounts to create, already verified")
+	temporaryUsers := flag.String("temporary-users", "", "comma separated email:password accounts that must set a new password at first sign in")
+	admins := flag.String("admins", "", "comma separated emails to add to the admin group")
+	adminGroup := flag.String("admin-group", "admin", "Cognito group given to -admins")
+	flag.Parse()
+
+	pool, err := fakecognito.New(*poolID, *clientID)
+	if err != nil {
+		log.Fatalf("Failed to create user pool: %v", err)
+	}
+	for _, email := range strings.Split(*admins, ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			pool.Groups[email] = []string{*adminGroup}
+		}
+	}
+	seeds, err := parseUsers(*users)
+	if err != nil {
+		log.Fatalf("Failed to parse -users: %v", err)
+	}
+	for _, u := range seeds {
+		pool.AddUser(u.Email, u.Password)
+		log.Printf("Added user %s", u.Email)
+	}
+	temporary, err := parseUsers(*temporaryUsers)
+	if err != nil {
+		log.Fatalf("Failed to parse -temporary-users: %v", err)
+	}
+	for _, u := range temporary {
+		pool.AddTemporaryUser(u.Email, u.Password)
+		log.Printf("Added user %s with a temporary password", u.Email)
+	}
+
+	fmt.Printf("Fake Cognito listening; set COGNITO_ENDPOINT=http://%s COGNITO_USER_POOL_CLIENT_ID=%s\n", *addr, *clientID)
+	if err := http.ListenAndServe(*addr, logRequests(pool)); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
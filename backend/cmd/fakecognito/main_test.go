@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseUsers(t *testing.T) {
+	users, err := parseUsers(" jane@tui.co.uk:Passw0rd!, ,bob@tui.co.uk:Pa:ss0rd! ")
+	if err != nil {
+		t.Fatalf("parseUsers returned error: %v", err)
+	}
+	if len(users) != 2 || users[0] != (seedUser{"jane@tui.co.uk", "Passw0rd!"}) || users[1] != (seedUser{"bob@tui.co.uk", "Pa:ss0rd!"}) {
+		t.Errorf("Unexpected users %+v", users)
+	}
+
+	for _, list := range []string{"jane@tui.co.uk", "jane@tui.co.uk:", ":Passw0rd!"} {
+		if _, err := parseUsers(list); err == nil {
+			t.Errorf("%q: expected an error", list)
+		}
+	}
+}
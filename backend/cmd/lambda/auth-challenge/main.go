@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// ChallengeRequest represents the request body for answering a sign in
+// challenge returned by /auth/login. NewPassword answers NEW_PASSWORD_REQUIRED
+// and Code answers SOFTWARE_TOKEN_MFA.
+type ChallengeRequest struct {
+	Email         string `json:"email"`
+	ChallengeName string `json:"challengeName"`
+	Session       string `json:"session"`
+	NewPassword   string `json:"newPassword,omitempty"`
+	Code          string `json:"code,omitempty"`
+}
+
+// LoginResponse represents the response when the challenge completes sign in,
+// in the same shape as auth-login so clients can store it the same way
+type LoginResponse struct {
+	Message      string `json:"message"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// ChallengeResponse represents the response when Cognito needs a further step,
+// such as an MFA code after a new password
+type ChallengeResponse struct {
+	Message             string            `json:"message"`
+	ChallengeName       string            `json:"challenge_name"`
+	Session             string            `json:"session"`
+	ChallengeParameters map[string]string `json:"challenge_parameters,omitempty"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves sign in challenges. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// challengeAnswers returns the ChallengeResponses of RespondToAuthChallenge for
+// the request, or an error message when its answer is missing
+func challengeAnswers(req ChallengeRequest) (map[string]*string, string) {
+	answers := map[string]*string{
+		"USERNAME": aws.String(req.Email),
+	}
+	switch req.ChallengeName {
+	case auth.ChallengeNewPassword:
+		if req.NewPassword == "" {
+			return nil, "New password is required"
+		}
+		answers["NEW_PASSWORD"] = aws.String(req.NewPassword)
+	case auth.ChallengeSoftwareTokenMFA:
+		if req.Code == "" {
+			return nil, "Code is required"
+		}
+		answers["SOFTWARE_TOKEN_MFA_CODE"] = aws.String(req.Code)
+	}
+	return answers, ""
+}
+
+// Handle is the Lambda function handler for answering sign in challenges
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var challengeReq ChallengeRequest
+	if err := json.Unmarshal([]byte(request.Body), &challengeReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if challengeReq.Email == "" || challengeReq.ChallengeName == "" || challengeReq.Session == "" {
+		errorResponse := ErrorResponse{
+			Error: "Email, challenge name and session are required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	if !auth.ChallengeSupported(challengeReq.ChallengeName) {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Unsupported challenge: %s", challengeReq.ChallengeName),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	answers, missing := challengeAnswers(challengeReq)
+	if missing != "" {
+		errorResponse := ErrorResponse{
+			Error: missing,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Answer the challenge within the sign in session
+	respondInput := &cognitoidentityprovider.RespondToAuthChallengeInput{
+		ClientId:           aws.String(h.cfg.CognitoUserPoolClientID),
+		ChallengeName:      aws.String(challengeReq.ChallengeName),
+		Session:            aws.String(challengeReq.Session),
+		ChallengeResponses: answers,
+	}
+
+	authResult, err := h.cognito.RespondToAuthChallengeWithContext(ctx, respondInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("challenge failed", "email_hash", logging.HashEmail(challengeReq.Email), "challenge", challengeReq.ChallengeName, "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors
+		errorMsg := err.Error()
+		statusCode := 401
+
+		if strings.Contains(errorMsg, "CodeMismatchException") {
+			errorMsg = "Invalid code. Please check and try again."
+		} else if strings.Contains(errorMsg, "ExpiredCodeException") {
+			errorMsg = "Code has expired. Please enter a new code."
+		} else if strings.Contains(errorMsg, "InvalidPasswordException") {
+			errorMsg = "Password does not meet requirements. Please use at least 8 characters with uppercase, lowercase, numbers, and special characters."
+			statusCode = 400
+		} else if strings.Contains(errorMsg, "NotAuthorizedException") {
+			// Cognito reports an expired or reused session this way
+			errorMsg = "Your sign in session has expired. Please sign in again."
+		} else if strings.Contains(errorMsg, "TooManyFailedAttemptsException") || strings.Contains(errorMsg, "LimitExceededException") {
+			errorMsg = "Too many attempts. Please wait a few minutes and try again."
+		} else {
+			errorMsg = fmt.Sprintf("Authentication failed: %v", err)
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Cognito may ask for another step, such as an MFA code after a new password
+	var response interface{}
+	if result := authResult.AuthenticationResult; result != nil {
+		logging.FromContext(ctx).Info("challenge completed", "email_hash", logging.HashEmail(challengeReq.Email), "challenge", challengeReq.ChallengeName)
+
+		response = LoginResponse{
+			Message:      "Login successful",
+			AccessToken:  aws.StringValue(result.AccessToken),
+			RefreshToken: aws.StringValue(result.RefreshToken),
+			IDToken:      aws.StringValue(result.IdToken),
+			TokenType:    aws.StringValue(result.TokenType),
+			ExpiresIn:    int(aws.Int64Value(result.ExpiresIn)),
+		}
+	} else {
+		challenge := aws.StringValue(authResult.ChallengeName)
+		if !auth.ChallengeSupported(challenge) {
+			logging.FromContext(ctx).Warn("challenge not supported", "email_hash", logging.HashEmail(challengeReq.Email), "challenge", challenge)
+
+			errorResponse := ErrorResponse{
+				Error: fmt.Sprintf("Sign in requires a step that is not supported: %s", challenge),
+			}
+			errorBody, _ := json.Marshal(errorResponse)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 401,
+				Body:       string(errorBody),
+				Headers:    corsHeaders,
+			}, nil
+		}
+
+		response = ChallengeResponse{
+			Message:             "Additional verification required",
+			ChallengeName:       challenge,
+			Session:             aws.StringValue(authResult.Session),
+			ChallengeParameters: aws.StringValueMap(authResult.ChallengeParameters),
+		}
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client that answers RespondToAuthChallenge
+// with output or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.RespondToAuthChallengeInput
+	output *cognitoidentityprovider.RespondToAuthChallengeOutput
+	err    error
+}
+
+func (f *fakeCognito) RespondToAuthChallengeWithContext(ctx aws.Context, input *cognitoidentityprovider.RespondToAuthChallengeInput, opts ...request.Option) (*cognitoidentityprovider.RespondToAuthChallengeOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.RespondToAuthChallengeOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// post sends body to h and decodes the error, if any
+func post(t *testing.T, h *handler, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+// tokens is a RespondToAuthChallenge output that completes sign in
+var tokens = &cognitoidentityprovider.RespondToAuthChallengeOutput{
+	AuthenticationResult: &cognitoidentityprovider.AuthenticationResultType{
+		AccessToken:  aws.String("access"),
+		RefreshToken: aws.String("refresh"),
+		IdToken:      aws.String("id"),
+		TokenType:    aws.String("Bearer"),
+		ExpiresIn:    aws.Int64(3600),
+	},
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_InvalidJSON(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), `{invalid json}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid request body" {
+		t.Errorf("Expected 400 'Invalid request body', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_Validation(t *testing.T) {
+	tests := []struct {
+		body    string
+		message string
+	}{
+		{`{"challengeName": "NEW_PASSWORD_REQUIRED", "session": "s", "newPassword": "N3wPassw0rd!"}`, "Email, challenge name and session are required"},
+		{`{"email": "test@example.com", "session": "s"}`, "Email, challenge name and session are required"},
+		{`{"email": "test@example.com", "challengeName": "NEW_PASSWORD_REQUIRED"}`, "Email, challenge name and session are required"},
+		{`{"email": "test@example.com", "challengeName": "CUSTOM_CHALLENGE", "session": "s"}`, "Unsupported challenge: CUSTOM_CHALLENGE"},
+		{`{"email": "test@example.com", "challengeName": "NEW_PASSWORD_REQUIRED", "session": "s"}`, "New password is required"},
+		{`{"email": "test@example.com", "challengeName": "SOFTWARE_TOKEN_MFA", "session": "s"}`, "Code is required"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{}
+		response, errorResp := post(t, newTestHandler(cognito), tt.body)
+		if response.StatusCode != 400 || errorResp.Error != tt.message {
+			t.Errorf("%s: expected 400 %q, got %d %q", tt.body, tt.message, response.StatusCode, errorResp.Error)
+		}
+		if cognito.input != nil {
+			t.Errorf("%s: expected Cognito not to be called", tt.body)
+		}
+	}
+}
+
+func TestHandler_NewPasswordReturnsTokens(t *testing.T) {
+	cognito := &fakeCognito{output: tokens}
+
+	response, _ := post(t, newTestHandler(cognito), `{"email": "test@example.com", "challengeName": "NEW_PASSWORD_REQUIRED", "session": "s", "newPassword": "N3wPassw0rd!"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var loginResp LoginResponse
+	if err := json.Unmarshal([]byte(response.Body), &loginResp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if loginResp.AccessToken != "access" || loginResp.RefreshToken != "refresh" || loginResp.IDToken != "id" || loginResp.ExpiresIn != 3600 {
+		t.Errorf("Unexpected login response %+v", loginResp)
+	}
+
+	input := cognito.input
+	answers := aws.StringValueMap(input.ChallengeResponses)
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.ChallengeName) != "NEW_PASSWORD_REQUIRED" || aws.StringValue(input.Session) != "s" {
+		t.Errorf("Unexpected RespondToAuthChallenge input %v", input)
+	}
+	if answers["USERNAME"] != "test@example.com" || answers["NEW_PASSWORD"] != "N3wPassw0rd!" {
+		t.Errorf("Unexpected challenge responses %v", answers)
+	}
+}
+
+func TestHandler_MFACodeAnswer(t *testing.T) {
+	cognito := &fakeCognito{output: tokens}
+
+	response, _ := post(t, newTestHandler(cognito), `{"email": "test@example.com", "challengeName": "SOFTWARE_TOKEN_MFA", "session": "s", "code": "123456"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if code := aws.StringValue(cognito.input.ChallengeResponses["SOFTWARE_TOKEN_MFA_CODE"]); code != "123456" {
+		t.Errorf("Expected the code to be sent as SOFTWARE_TOKEN_MFA_CODE, got %q", code)
+	}
+}
+
+func TestHandler_ReturnsNextChallenge(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.RespondToAuthChallengeOutput{
+		ChallengeName: aws.String("SOFTWARE_TOKEN_MFA"),
+		Session:       aws.String("next"),
+	}}
+
+	response, _ := post(t, newTestHandler(cognito), `{"email": "test@example.com", "challengeName": "NEW_PASSWORD_REQUIRED", "session": "s", "newPassword": "N3wPassw0rd!"}`)
+	var challengeResp ChallengeResponse
+	json.Unmarshal([]byte(response.Body), &challengeResp)
+	if response.StatusCode != 200 || challengeResp.ChallengeName != "SOFTWARE_TOKEN_MFA" || challengeResp.Session != "next" {
+		t.Errorf("Expected the next challenge, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_ChallengeErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		status  int
+		message string
+	}{
+		{cognitoidentityprovider.ErrCodeCodeMismatchException, 401, "Invalid code. Please check and try again."},
+		{cognitoidentityprovider.ErrCodeExpiredCodeException, 401, "Code has expired. Please enter a new code."},
+		{cognitoidentityprovider.ErrCodeInvalidPasswordException, 400, "Password does not meet requirements. Please use at least 8 characters with uppercase, lowercase, numbers, and special characters."},
+		{cognitoidentityprovider.ErrCodeNotAuthorizedException, 401, "Your sign in session has expired. Please sign in again."},
+		{cognitoidentityprovider.ErrCodeTooManyFailedAttemptsException, 401, "Too many attempts. Please wait a few minutes and try again."},
+		{cognitoidentityprovider.ErrCodeInternalErrorException, 401, "Authentication failed: InternalErrorException: failed"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{err: awserr.New(tt.code, "failed", nil)}
+		response, errorResp := post(t, newTestHandler(cognito), `{"email": "test@example.com", "challengeName": "SOFTWARE_TOKEN_MFA", "session": "s", "code": "123456"}`)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.code, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_NewPasswordWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddTemporaryUser("jane@example.com", "Temp0rary!")
+
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Temp0rary!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	session := aws.StringValue(authOut.Session)
+
+	answer := func(password string) string {
+		return `{"email": "jane@example.com", "challengeName": "NEW_PASSWORD_REQUIRED", "session": "` + session + `", "newPassword": "` + password + `"}`
+	}
+
+	response, errorResp := post(t, h, answer("weak"))
+	if response.StatusCode != 400 || errorResp.Error == "" {
+		t.Errorf("Expected 400 for a weak password, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = post(t, h, answer("N3wPassw0rd!"))
+	var loginResp LoginResponse
+	json.Unmarshal([]byte(response.Body), &loginResp)
+	if response.StatusCode != 200 || loginResp.IDToken == "" || loginResp.RefreshToken == "" {
+		t.Errorf("Expected tokens after setting a new password, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, errorResp = post(t, h, answer("N3wPassw0rd!"))
+	if response.StatusCode != 401 || errorResp.Error != "Your sign in session has expired. Please sign in again." {
+		t.Errorf("Expected a used session to be refused, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_MFACodeWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("jane@example.com", "Passw0rd!")
+	secret := pool.EnableMFA("jane@example.com")
+
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+
+	answer := func(code string) string {
+		return `{"email": "jane@example.com", "challengeName": "SOFTWARE_TOKEN_MFA", "session": "` + aws.StringValue(authOut.Session) + `", "code": "` + code + `"}`
+	}
+
+	response, errorResp := post(t, h, answer("000000"))
+	if response.StatusCode != 401 || errorResp.Error != "Invalid code. Please check and try again." {
+		t.Errorf("Expected a wrong code to be refused, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = post(t, h, answer(fakecognito.TOTP(secret, time.Now())))
+	var loginResp LoginResponse
+	json.Unmarshal([]byte(response.Body), &loginResp)
+	if response.StatusCode != 200 || loginResp.IDToken == "" {
+		t.Errorf("Expected tokens for the right code, got %d: %s", response.StatusCode, response.Body)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// sentMessage is returned whether or not the email belongs to an account, so
+// the endpoint cannot be used to find out who is registered
+const sentMessage = "If an account exists for this email, a password reset code has been sent."
+
+// ForgotPasswordRequest represents the request body for starting a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ForgotPasswordResponse represents the response for a started password reset
+type ForgotPasswordResponse struct {
+	Message string `json:"message"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves password reset requests. It is created once at cold start, so
+// warm invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for sending a password reset code
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var forgotReq ForgotPasswordRequest
+	if err := json.Unmarshal([]byte(request.Body), &forgotReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if forgotReq.Email == "" {
+		errorResponse := ErrorResponse{
+			Error: "Email is required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Send a reset code to the account's verified email
+	forgotInput := &cognitoidentityprovider.ForgotPasswordInput{
+		ClientId: aws.String(h.cfg.CognitoUserPoolClientID),
+		Username: aws.String(forgotReq.Email),
+	}
+
+	_, err := h.cognito.ForgotPasswordWithContext(ctx, forgotInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("password reset request failed", "email_hash", logging.HashEmail(forgotReq.Email), "error", err)
+
+		errorMsg := err.Error()
+
+		// Unknown, unconfirmed and disabled accounts get the same answer as real
+		// ones below; only failures that any email could hit are reported
+		hidden := strings.Contains(errorMsg, "UserNotFoundException") ||
+			strings.Contains(errorMsg, "InvalidParameterException") ||
+			strings.Contains(errorMsg, "NotAuthorizedException")
+		if !hidden {
+			if strings.Contains(errorMsg, "LimitExceededException") || strings.Contains(errorMsg, "TooManyRequestsException") {
+				errorMsg = "Too many requests. Please wait a few minutes and try again."
+			} else {
+				errorMsg = "Failed to send reset code. Please try again."
+			}
+
+			errorResponse := ErrorResponse{
+				Error: errorMsg,
+			}
+			errorBody, _ := json.Marshal(errorResponse)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 400,
+				Body:       string(errorBody),
+				Headers:    corsHeaders,
+			}, nil
+		}
+	} else {
+		logging.FromContext(ctx).Info("password reset code sent", "email_hash", logging.HashEmail(forgotReq.Email))
+	}
+
+	// Create response
+	response := ForgotPasswordResponse{
+		Message: sentMessage,
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client that answers ForgotPassword with output
+// or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.ForgotPasswordInput
+	output *cognitoidentityprovider.ForgotPasswordOutput
+	err    error
+}
+
+func (f *fakeCognito) ForgotPasswordWithContext(ctx aws.Context, input *cognitoidentityprovider.ForgotPasswordInput, opts ...request.Option) (*cognitoidentityprovider.ForgotPasswordOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.ForgotPasswordOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// post sends body to h and decodes the error, if any
+func post(t *testing.T, h *handler, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_InvalidJSON(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), `{invalid json}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid request body" {
+		t.Errorf("Expected 400 'Invalid request body', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_MissingEmail(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), `{"email": ""}`)
+	if response.StatusCode != 400 || errorResp.Error != "Email is required" {
+		t.Errorf("Expected 400 'Email is required', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_SendsResetCode(t *testing.T) {
+	cognito := &fakeCognito{}
+
+	response, _ := post(t, newTestHandler(cognito), `{"email": "test@example.com"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var forgotResp ForgotPasswordResponse
+	if err := json.Unmarshal([]byte(response.Body), &forgotResp); err != nil || forgotResp.Message != sentMessage {
+		t.Errorf("Expected the generic message, got %s", response.Body)
+	}
+
+	input := cognito.input
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.Username) != "test@example.com" {
+		t.Errorf("Unexpected ForgotPassword input %v", input)
+	}
+}
+
+func TestHandler_HidesWhetherAccountExists(t *testing.T) {
+	sent, _ := post(t, newTestHandler(&fakeCognito{}), `{"email": "test@example.com"}`)
+
+	for _, code := range []string{
+		cognitoidentityprovider.ErrCodeUserNotFoundException,
+		cognitoidentityprovider.ErrCodeInvalidParameterException,
+		cognitoidentityprovider.ErrCodeNotAuthorizedException,
+	} {
+		cognito := &fakeCognito{err: awserr.New(code, "failed", nil)}
+		response, _ := post(t, newTestHandler(cognito), `{"email": "test@example.com"}`)
+		if response.StatusCode != sent.StatusCode || response.Body != sent.Body {
+			t.Errorf("%s: expected the same response as a real account, got %d: %s", code, response.StatusCode, response.Body)
+		}
+	}
+}
+
+func TestHandler_ForgotPasswordErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+	}{
+		{cognitoidentityprovider.ErrCodeLimitExceededException, "Too many requests. Please wait a few minutes and try again."},
+		{cognitoidentityprovider.ErrCodeTooManyRequestsException, "Too many requests. Please wait a few minutes and try again."},
+		{cognitoidentityprovider.ErrCodeCodeDeliveryFailureException, "Failed to send reset code. Please try again."},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{err: awserr.New(tt.code, "failed", nil)}
+		response, errorResp := post(t, newTestHandler(cognito), `{"email": "test@example.com"}`)
+		if response.StatusCode != 400 || errorResp.Error != tt.message {
+			t.Errorf("%s: expected 400 %q, got %d %q", tt.code, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_ForgotPasswordWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("jane@example.com", "Passw0rd!")
+
+	known, _ := post(t, h, `{"email": "jane@example.com"}`)
+	unknown, _ := post(t, h, `{"email": "nobody@example.com"}`)
+	if known.StatusCode != 200 || unknown.StatusCode != 200 || known.Body != unknown.Body {
+		t.Errorf("Expected the same answer for known and unknown emails, got %d %s and %d %s", known.StatusCode, known.Body, unknown.StatusCode, unknown.Body)
+	}
+	if pool.ResetCode("jane@example.com") == "" {
+		t.Error("Expected a reset code to be sent")
+	}
+}
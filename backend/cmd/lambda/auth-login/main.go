@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
 	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
 	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
 )
 
 // LoginRequest represents the request body for user login
@@ -30,13 +34,40 @@ type LoginResponse struct {
 	ExpiresIn    int    `json:"expires_in"`
 }
 
+// ChallengeResponse represents the response when Cognito needs another step,
+// such as a new password or an MFA code, before it issues tokens. The client
+// answers it at /auth/challenge with the session.
+type ChallengeResponse struct {
+	Message             string            `json:"message"`
+	ChallengeName       string            `json:"challenge_name"`
+	Session             string            `json:"session"`
+	ChallengeParameters map[string]string `json:"challenge_parameters,omitempty"`
+}
+
 // ErrorResponse represents an error response structure
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-// Handler is the Lambda function handler for user login
-func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// handler serves user login. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for user login
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// CORS headers for all responses
 	corsHeaders := map[string]string{
 		"Content-Type":                 "application/json",
@@ -53,20 +84,6 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		}, nil
 	}
 
-	// Load configuration from environment variables
-	cfg, err := config.Load()
-	if err != nil {
-		errorResponse := ErrorResponse{
-			Error: fmt.Sprintf("Failed to load configuration: %v", err),
-		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
-	}
-
 	// Parse request body
 	var loginReq LoginRequest
 	if err := json.Unmarshal([]byte(request.Body), &loginReq); err != nil {
@@ -94,28 +111,9 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		}, nil
 	}
 
-	// Create AWS session
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(cfg.AWSRegion),
-	})
-	if err != nil {
-		errorResponse := ErrorResponse{
-			Error: fmt.Sprintf("Failed to create AWS session: %v", err),
-		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
-	}
-
-	// Create Cognito client
-	cognitoClient := cognitoidentityprovider.New(sess)
-
 	// Authenticate user
 	authInput := &cognitoidentityprovider.InitiateAuthInput{
-		ClientId: aws.String(cfg.CognitoUserPoolClientID),
+		ClientId: aws.String(h.cfg.CognitoUserPoolClientID),
 		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
 		AuthParameters: map[string]*string{
 			"USERNAME": aws.String(loginReq.Email),
@@ -123,8 +121,10 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		},
 	}
 
-	authResult, err := cognitoClient.InitiateAuth(authInput)
+	authResult, err := h.cognito.InitiateAuthWithContext(ctx, authInput)
 	if err != nil {
+		logging.FromContext(ctx).Warn("login failed", "email_hash", logging.HashEmail(loginReq.Email), "error", err)
+
 		// Extract more user-friendly error messages from Cognito errors
 		errorMsg := err.Error()
 
@@ -150,14 +150,43 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		}, nil
 	}
 
-	// Create response
-	response := LoginResponse{
-		Message:      "Login successful",
-		AccessToken:  *authResult.AuthenticationResult.AccessToken,
-		RefreshToken: *authResult.AuthenticationResult.RefreshToken,
-		IDToken:      *authResult.AuthenticationResult.IdToken,
-		TokenType:    *authResult.AuthenticationResult.TokenType,
-		ExpiresIn:    int(*authResult.AuthenticationResult.ExpiresIn),
+	// Cognito returns a challenge instead of tokens when sign in needs another step
+	var response interface{}
+	if result := authResult.AuthenticationResult; result != nil {
+		logging.FromContext(ctx).Info("login succeeded", "email_hash", logging.HashEmail(loginReq.Email))
+
+		response = LoginResponse{
+			Message:      "Login successful",
+			AccessToken:  aws.StringValue(result.AccessToken),
+			RefreshToken: aws.StringValue(result.RefreshToken),
+			IDToken:      aws.StringValue(result.IdToken),
+			TokenType:    aws.StringValue(result.TokenType),
+			ExpiresIn:    int(aws.Int64Value(result.ExpiresIn)),
+		}
+	} else {
+		challenge := aws.StringValue(authResult.ChallengeName)
+		if !auth.ChallengeSupported(challenge) {
+			logging.FromContext(ctx).Warn("login challenge not supported", "email_hash", logging.HashEmail(loginReq.Email), "challenge", challenge)
+
+			errorResponse := ErrorResponse{
+				Error: fmt.Sprintf("Sign in requires a step that is not supported: %s", challenge),
+			}
+			errorBody, _ := json.Marshal(errorResponse)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 401,
+				Body:       string(errorBody),
+				Headers:    corsHeaders,
+			}, nil
+		}
+
+		logging.FromContext(ctx).Info("login challenged", "email_hash", logging.HashEmail(loginReq.Email), "challenge", challenge)
+
+		response = ChallengeResponse{
+			Message:             "Additional verification required",
+			ChallengeName:       challenge,
+			Session:             aws.StringValue(authResult.Session),
+			ChallengeParameters: aws.StringValueMap(authResult.ChallengeParameters),
+		}
 	}
 
 	// Marshal response to JSON
@@ -183,6 +212,17 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 }
 
 func main() {
-	// Start Lambda handler
-	lambda.Start(Handler)
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
 }
@@ -3,17 +3,54 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
 )
 
+// fakeCognito is an in-memory Cognito client that answers InitiateAuth with output
+// or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.InitiateAuthInput
+	output *cognitoidentityprovider.InitiateAuthOutput
+	err    error
+}
+
+func (f *fakeCognito) InitiateAuthWithContext(ctx aws.Context, input *cognitoidentityprovider.InitiateAuthInput, opts ...request.Option) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.InitiateAuthOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
 func TestHandler_OptionsRequest(t *testing.T) {
 	request := events.APIGatewayProxyRequest{
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -29,7 +66,7 @@ func TestHandler_InvalidJSON(t *testing.T) {
 		Body:       `{invalid json}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -54,7 +91,7 @@ func TestHandler_MissingEmail(t *testing.T) {
 		Body:       `{"email": "", "password": "test123"}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -79,7 +116,7 @@ func TestHandler_MissingPassword(t *testing.T) {
 		Body:       `{"email": "test@example.com", "password": ""}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -94,7 +131,7 @@ func TestHandler_CORSHeaders(t *testing.T) {
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -122,3 +159,226 @@ func TestLoginRequest_Struct(t *testing.T) {
 		t.Errorf("Expected password 'password123', got '%s'", req.Password)
 	}
 }
+
+func TestNewHandler_UsesHTTPClient(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	// The AWS SDK needs an *http.Transport, so requests are counted as it picks their proxy
+	requests := 0
+	transport := &http.Transport{Proxy: func(*http.Request) (*url.URL, error) {
+		requests++
+		return nil, nil
+	}}
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, &http.Client{Transport: transport})
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("jane@example.com", "Passw0rd!")
+
+	h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "jane@example.com", "password": "Passw0rd!"}`,
+	})
+	if requests != 1 {
+		t.Errorf("Expected Cognito to be called through the given client, got %d requests", requests)
+	}
+}
+
+func TestHandler_LogsInWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("jane@example.com", "Passw0rd!")
+
+	login := func(email, password string) events.APIGatewayProxyResponse {
+		response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Body:       `{"email": "` + email + `", "password": "` + password + `"}`,
+		})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		return response
+	}
+
+	response := login("jane@example.com", "Passw0rd!")
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	var loginResp LoginResponse
+	if err := json.Unmarshal([]byte(response.Body), &loginResp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if loginResp.IDToken == "" || loginResp.RefreshToken == "" || loginResp.TokenType != "Bearer" || loginResp.ExpiresIn != 3600 {
+		t.Errorf("Unexpected login response %+v", loginResp)
+	}
+
+	// Wrong passwords and unknown emails get the same answer
+	for _, response := range []events.APIGatewayProxyResponse{login("jane@example.com", "Wrong0ne!"), login("nobody@example.com", "Passw0rd!")} {
+		var errorResp ErrorResponse
+		json.Unmarshal([]byte(response.Body), &errorResp)
+		if response.StatusCode != 401 || errorResp.Error != "Invalid email or password." {
+			t.Errorf("Expected 401 with the generic error, got %d: %s", response.StatusCode, response.Body)
+		}
+	}
+}
+
+func TestHandler_LoginReturnsTokens(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.InitiateAuthOutput{
+		AuthenticationResult: &cognitoidentityprovider.AuthenticationResultType{
+			AccessToken:  aws.String("access"),
+			RefreshToken: aws.String("refresh"),
+			IdToken:      aws.String("id"),
+			TokenType:    aws.String("Bearer"),
+			ExpiresIn:    aws.Int64(3600),
+		},
+	}}
+
+	response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "test@example.com", "password": "Passw0rd!"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var loginResp LoginResponse
+	if err := json.Unmarshal([]byte(response.Body), &loginResp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if loginResp.AccessToken != "access" || loginResp.RefreshToken != "refresh" || loginResp.IDToken != "id" || loginResp.ExpiresIn != 3600 {
+		t.Errorf("Unexpected login response %+v", loginResp)
+	}
+
+	input := cognito.input
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.AuthFlow) != "USER_PASSWORD_AUTH" || aws.StringValue(input.AuthParameters["USERNAME"]) != "test@example.com" {
+		t.Errorf("Unexpected InitiateAuth input %v", input)
+	}
+}
+
+func TestHandler_LoginErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+	}{
+		{cognitoidentityprovider.ErrCodeNotAuthorizedException, "Invalid email or password."},
+		{cognitoidentityprovider.ErrCodeUserNotConfirmedException, "Please verify your email address before signing in."},
+		{cognitoidentityprovider.ErrCodeTooManyRequestsException, "Authentication failed: TooManyRequestsException: slow down"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{err: awserr.New(tt.code, "slow down", nil)}
+		response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Body:       `{"email": "test@example.com", "password": "Passw0rd!"}`,
+		})
+		if err != nil {
+			t.Fatalf("%s: Handler returned error: %v", tt.code, err)
+		}
+
+		var errorResp ErrorResponse
+		json.Unmarshal([]byte(response.Body), &errorResp)
+		if response.StatusCode != 401 || errorResp.Error != tt.message {
+			t.Errorf("%s: expected 401 %q, got %d %q", tt.code, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_LoginReturnsChallenge(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.InitiateAuthOutput{
+		ChallengeName:       aws.String("NEW_PASSWORD_REQUIRED"),
+		Session:             aws.String("session"),
+		ChallengeParameters: map[string]*string{"requiredAttributes": aws.String("[]")},
+	}}
+
+	response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "test@example.com", "password": "Temp0rary!"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var challengeResp ChallengeResponse
+	if err := json.Unmarshal([]byte(response.Body), &challengeResp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if challengeResp.ChallengeName != "NEW_PASSWORD_REQUIRED" || challengeResp.Session != "session" || challengeResp.ChallengeParameters["requiredAttributes"] != "[]" {
+		t.Errorf("Unexpected challenge response %+v", challengeResp)
+	}
+}
+
+func TestHandler_LoginUnsupportedChallenge(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.InitiateAuthOutput{
+		ChallengeName: aws.String("CUSTOM_CHALLENGE"),
+		Session:       aws.String("session"),
+	}}
+
+	response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "test@example.com", "password": "Passw0rd!"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	if response.StatusCode != 401 || errorResp.Error != "Sign in requires a step that is not supported: CUSTOM_CHALLENGE" {
+		t.Errorf("Expected 401 for an unsupported challenge, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_TemporaryPasswordWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddTemporaryUser("jane@example.com", "Temp0rary!")
+
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "jane@example.com", "password": "Temp0rary!"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	var challengeResp ChallengeResponse
+	json.Unmarshal([]byte(response.Body), &challengeResp)
+	if response.StatusCode != 200 || challengeResp.ChallengeName != "NEW_PASSWORD_REQUIRED" || challengeResp.Session == "" {
+		t.Errorf("Expected a new password challenge, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_MFAWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("jane@example.com", "Passw0rd!")
+	pool.EnableMFA("jane@example.com")
+
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "jane@example.com", "password": "Passw0rd!"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	var challengeResp ChallengeResponse
+	json.Unmarshal([]byte(response.Body), &challengeResp)
+	if response.StatusCode != 200 || challengeResp.ChallengeName != "SOFTWARE_TOKEN_MFA" || challengeResp.Session == "" {
+		t.Errorf("Expected an MFA challenge, got %d: %s", response.StatusCode, response.Body)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+	"tuitui-backend/internal/revocation"
+)
+
+// fakeCognito is an in-memory Cognito client that answers GlobalSignOut with
+// err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input *cognitoidentityprovider.GlobalSignOutInput
+	err   error
+}
+
+func (f *fakeCognito) GlobalSignOutWithContext(ctx aws.Context, input *cognitoidentityprovider.GlobalSignOutInput, opts ...request.Option) (*cognitoidentityprovider.GlobalSignOutOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.GlobalSignOutOutput{}, nil
+}
+
+// newTestHandler returns a handler using cognito and an in-memory revocation list
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:         &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito:     cognito,
+		revocations: revocation.NewList(revocation.NewMemoryStore()),
+	}
+}
+
+// testToken returns an unsigned JWT for sub, which is all the handler reads
+func testToken(sub string) string {
+	payload, _ := json.Marshal(map[string]string{"sub": sub, "token_use": "access"})
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// claimsIssuedAt are the authorizer claims of an ID token for user-1 issued at t
+func claimsIssuedAt(t time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":        "user-1",
+		"origin_jti": "origin-1",
+		"iat":        t.UTC().Format("Mon Jan 02 15:04:05 MST 2006"),
+	}
+}
+
+// post sends body to h as the user with claims and decodes the error, if any
+func post(t *testing.T, h *handler, claims map[string]interface{}, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+// revoked reports whether h refuses the token with claims
+func revoked(t *testing.T, h *handler, claims map[string]interface{}) bool {
+	t.Helper()
+	session, _ := revocation.SessionFromRequest(events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	ok, err := h.revocations.Revoked(context.Background(), session)
+	if err != nil {
+		t.Fatalf("Revoked returned error: %v", err)
+	}
+	return ok
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"accessToken": "` + testToken("user-1") + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_InvalidJSON(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), claimsIssuedAt(time.Now()), `{invalid json}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid request body" {
+		t.Errorf("Expected 400 'Invalid request body', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_MissingAccessToken(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), claimsIssuedAt(time.Now()), `{"accessToken": ""}`)
+	if response.StatusCode != 400 || errorResp.Error != "Access token is required" {
+		t.Errorf("Expected 400 'Access token is required', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_AccessTokenOfAnotherUser(t *testing.T) {
+	cognito := &fakeCognito{}
+	h := newTestHandler(cognito)
+
+	for _, token := range []string{testToken("user-2"), "not-a-token"} {
+		response, errorResp := post(t, h, claimsIssuedAt(time.Now()), `{"accessToken": "`+token+`"}`)
+		if response.StatusCode != 403 || errorResp.Error != "Access token does not belong to the signed in user" {
+			t.Errorf("Expected 403 for %q, got %d '%s'", token, response.StatusCode, errorResp.Error)
+		}
+	}
+	if cognito.input != nil {
+		t.Error("Expected GlobalSignOut not to be called")
+	}
+}
+
+func TestHandler_LogoutAllRevokesEarlierTokens(t *testing.T) {
+	cognito := &fakeCognito{}
+	h := newTestHandler(cognito)
+	earlier := claimsIssuedAt(time.Now().Add(-time.Minute))
+	token := testToken("user-1")
+
+	response, _ := post(t, h, earlier, `{"accessToken": "`+token+`"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var logoutResp LogoutResponse
+	json.Unmarshal([]byte(response.Body), &logoutResp)
+	if logoutResp.Message != "Signed out of all devices" {
+		t.Errorf("Unexpected message %q", logoutResp.Message)
+	}
+	if aws.StringValue(cognito.input.AccessToken) != token {
+		t.Errorf("Unexpected GlobalSignOut input %v", cognito.input)
+	}
+
+	if !revoked(t, h, earlier) {
+		t.Error("Expected tokens issued before the sign out to be refused")
+	}
+	if revoked(t, h, claimsIssuedAt(time.Now().Add(time.Minute))) {
+		t.Error("Expected tokens from a later sign in to be accepted")
+	}
+}
+
+func TestHandler_LogoutAllWithoutRevocationTable(t *testing.T) {
+	h := newTestHandler(&fakeCognito{})
+	h.revocations = nil
+
+	response, _ := post(t, h, claimsIssuedAt(time.Now()), `{"accessToken": "`+testToken("user-1")+`"}`)
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_LogoutAllErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Access Token has been revoked", nil), 401, "Invalid access token. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "slow down", nil), 400, "Too many requests. Please wait a few minutes and try again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil), 400, "Failed to sign out. Please try again."},
+	}
+
+	for _, tt := range tests {
+		response, errorResp := post(t, newTestHandler(&fakeCognito{err: tt.err}), claimsIssuedAt(time.Now()), `{"accessToken": "`+testToken("user-1")+`"}`)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%v: expected %d %q, got %d %q", tt.err, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_LogoutAllWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	h.revocations = revocation.NewList(revocation.NewMemoryStore())
+	sub := pool.AddUser("jane@example.com", "Passw0rd!")
+
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	result := authOut.AuthenticationResult
+	claims := map[string]interface{}{"sub": sub, "iat": "0"}
+
+	response, _ := post(t, h, claims, `{"accessToken": "`+aws.StringValue(result.AccessToken)+`"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if !revoked(t, h, claims) {
+		t.Error("Expected earlier ID tokens to be refused after signing out everywhere")
+	}
+
+	_, err = h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId:       aws.String("local-client"),
+		AuthFlow:       aws.String("REFRESH_TOKEN_AUTH"),
+		AuthParameters: map[string]*string{"REFRESH_TOKEN": result.RefreshToken},
+	})
+	if err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Errorf("Expected the refresh token to be revoked, got %v", err)
+	}
+}
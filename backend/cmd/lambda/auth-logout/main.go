@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/revocation"
+)
+
+// LogoutRequest represents the request body for signing out
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// LogoutResponse represents the response for a successful sign out
+type LogoutResponse struct {
+	Message string `json:"message"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves sign outs. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+
+	// revocations is nil when no revoked sessions table is configured
+	revocations *revocation.List
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	revocations, err := revocation.New(cfg)
+	if err != nil && !errors.Is(err, revocation.ErrNotConfigured) {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito, revocations: revocations}, nil
+}
+
+// Handle is the Lambda function handler for signing out of the current session.
+// The refresh token is revoked in Cognito, and the session is recorded so its
+// ID tokens, which the API Gateway authorizer accepts until they expire, are
+// refused by the guarded Lambdas.
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	session, ok := revocation.SessionFromRequest(request)
+	if !ok {
+		errorResponse := ErrorResponse{
+			Error: "No authorization context found",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var logoutReq LogoutRequest
+	if err := json.Unmarshal([]byte(request.Body), &logoutReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if logoutReq.RefreshToken == "" {
+		errorResponse := ErrorResponse{
+			Error: "Refresh token is required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// The session is recorded first, so it is refused even if Cognito fails below
+	if h.revocations != nil {
+		err := h.revocations.RevokeSession(ctx, session)
+		if errors.Is(err, revocation.ErrNoSession) {
+			logging.FromContext(ctx).Warn("token has no origin_jti; its ID tokens stay valid until they expire")
+		} else if err != nil {
+			logging.FromContext(ctx).Error("failed to record revoked session", "error", err)
+
+			errorResponse := ErrorResponse{
+				Error: "Failed to sign out. Please try again.",
+			}
+			errorBody, _ := json.Marshal(errorResponse)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 500,
+				Body:       string(errorBody),
+				Headers:    corsHeaders,
+			}, nil
+		}
+	}
+
+	// Revoke the refresh token and the access tokens issued from it
+	revokeInput := &cognitoidentityprovider.RevokeTokenInput{
+		ClientId: aws.String(h.cfg.CognitoUserPoolClientID),
+		Token:    aws.String(logoutReq.RefreshToken),
+	}
+
+	_, err := h.cognito.RevokeTokenWithContext(ctx, revokeInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("token revocation failed", "error", err)
+
+		errorMsg := err.Error()
+
+		// A token that is already revoked, expired or unknown cannot be used
+		// again, so the session is signed out all the same
+		signedOut := strings.Contains(errorMsg, "NotAuthorizedException") ||
+			strings.Contains(errorMsg, "UnsupportedTokenTypeException")
+		if !signedOut {
+			if strings.Contains(errorMsg, "TooManyRequestsException") {
+				errorMsg = "Too many requests. Please wait a few minutes and try again."
+			} else {
+				errorMsg = "Failed to sign out. Please try again."
+			}
+
+			errorResponse := ErrorResponse{
+				Error: errorMsg,
+			}
+			errorBody, _ := json.Marshal(errorResponse)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 400,
+				Body:       string(errorBody),
+				Headers:    corsHeaders,
+			}, nil
+		}
+	}
+
+	logging.FromContext(ctx).Info("signed out")
+
+	// Create response
+	response := LogoutResponse{
+		Message: "Signed out",
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
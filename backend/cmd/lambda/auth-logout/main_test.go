@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+	"tuitui-backend/internal/revocation"
+)
+
+// fakeCognito is an in-memory Cognito client that answers RevokeToken with err,
+// and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input *cognitoidentityprovider.RevokeTokenInput
+	err   error
+}
+
+func (f *fakeCognito) RevokeTokenWithContext(ctx aws.Context, input *cognitoidentityprovider.RevokeTokenInput, opts ...request.Option) (*cognitoidentityprovider.RevokeTokenOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.RevokeTokenOutput{}, nil
+}
+
+// newTestHandler returns a handler using cognito and an in-memory revocation list
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:         &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito:     cognito,
+		revocations: revocation.NewList(revocation.NewMemoryStore()),
+	}
+}
+
+// testClaims are the authorizer claims of the caller's ID token
+var testClaims = map[string]interface{}{
+	"sub":        "user-1",
+	"origin_jti": "origin-1",
+	"iat":        "1743595200",
+}
+
+// post sends body to h as the user with claims and decodes the error, if any
+func post(t *testing.T, h *handler, claims map[string]interface{}, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+// revoked reports whether h has recorded the session of claims as signed out
+func revoked(t *testing.T, h *handler, claims map[string]interface{}) bool {
+	t.Helper()
+	session, _ := revocation.SessionFromRequest(events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	ok, err := h.revocations.Revoked(context.Background(), session)
+	if err != nil {
+		t.Fatalf("Revoked returned error: %v", err)
+	}
+	return ok
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"refreshToken": "refresh"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_InvalidJSON(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), testClaims, `{invalid json}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid request body" {
+		t.Errorf("Expected 400 'Invalid request body', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_MissingRefreshToken(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), testClaims, `{"refreshToken": ""}`)
+	if response.StatusCode != 400 || errorResp.Error != "Refresh token is required" {
+		t.Errorf("Expected 400 'Refresh token is required', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_LogoutRevokesSession(t *testing.T) {
+	cognito := &fakeCognito{}
+	h := newTestHandler(cognito)
+
+	response, _ := post(t, h, testClaims, `{"refreshToken": "refresh"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var logoutResp LogoutResponse
+	json.Unmarshal([]byte(response.Body), &logoutResp)
+	if logoutResp.Message != "Signed out" {
+		t.Errorf("Unexpected message %q", logoutResp.Message)
+	}
+
+	if aws.StringValue(cognito.input.ClientId) != "test-client" || aws.StringValue(cognito.input.Token) != "refresh" {
+		t.Errorf("Unexpected RevokeToken input %v", cognito.input)
+	}
+	if !revoked(t, h, testClaims) {
+		t.Error("Expected the session to be recorded as revoked")
+	}
+
+	other := map[string]interface{}{"sub": "user-1", "origin_jti": "origin-2", "iat": "1743595200"}
+	if revoked(t, h, other) {
+		t.Error("Expected the user's other sessions to stay signed in")
+	}
+}
+
+func TestHandler_LogoutWithoutRevocationTable(t *testing.T) {
+	h := newTestHandler(&fakeCognito{})
+	h.revocations = nil
+
+	response, _ := post(t, h, testClaims, `{"refreshToken": "refresh"}`)
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_LogoutWithoutOriginJTI(t *testing.T) {
+	claims := map[string]interface{}{"sub": "user-1", "iat": "1743595200"}
+
+	response, _ := post(t, newTestHandler(&fakeCognito{}), claims, `{"refreshToken": "refresh"}`)
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_LogoutErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Refresh Token has been revoked", nil), 200, ""},
+		{awserr.New(cognitoidentityprovider.ErrCodeUnsupportedTokenTypeException, "Unsupported token type", nil), 200, ""},
+		{awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "slow down", nil), 400, "Too many requests. Please wait a few minutes and try again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil), 400, "Failed to sign out. Please try again."},
+	}
+
+	for _, tt := range tests {
+		h := newTestHandler(&fakeCognito{err: tt.err})
+		response, errorResp := post(t, h, testClaims, `{"refreshToken": "refresh"}`)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%v: expected %d %q, got %d %q", tt.err, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+		// The session is refused whether or not Cognito revoked the token
+		if !revoked(t, h, testClaims) {
+			t.Errorf("%v: expected the session to be recorded as revoked", tt.err)
+		}
+	}
+}
+
+// idTokenClaims returns the claims of a JWT the way the Cognito authorizer passes them on
+func idTokenClaims(t *testing.T, token string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+	var raw map[string]interface{}
+	json.Unmarshal(payload, &raw)
+	return map[string]interface{}{
+		"sub":        raw["sub"],
+		"origin_jti": raw["origin_jti"],
+		"iat":        time.Unix(int64(raw["iat"].(float64)), 0).UTC().Format("Mon Jan 02 15:04:05 MST 2006"),
+	}
+}
+
+func TestHandler_LogoutWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	h.revocations = revocation.NewList(revocation.NewMemoryStore())
+	pool.AddUser("jane@example.com", "Passw0rd!")
+
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	result := authOut.AuthenticationResult
+	claims := idTokenClaims(t, aws.StringValue(result.IdToken))
+
+	response, _ := post(t, h, claims, `{"refreshToken": "`+aws.StringValue(result.RefreshToken)+`"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if !revoked(t, h, claims) {
+		t.Error("Expected the ID token to be refused after sign out")
+	}
+
+	_, err = h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId:       aws.String("local-client"),
+		AuthFlow:       aws.String("REFRESH_TOKEN_AUTH"),
+		AuthParameters: map[string]*string{"REFRESH_TOKEN": result.RefreshToken},
+	})
+	if err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Errorf("Expected the refresh token to be revoked, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// MFADisableRequest represents the request body for turning MFA off. The
+// password and a current code from the authenticator app are checked with a
+// sign in, so a stolen session alone cannot turn MFA off.
+type MFADisableRequest struct {
+	AccessToken string `json:"accessToken"`
+	Password    string `json:"password"`
+	Code        string `json:"code"`
+}
+
+// MFAResponse represents the response for a successful MFA change
+type MFAResponse struct {
+	Message string `json:"message"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves turning MFA off. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for disabling MFA once the password and
+// a code from the authenticator app are checked. Sign in asks for the password alone afterwards;
+// enrolling again starts at /auth/mfa/setup.
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		errorResponse := ErrorResponse{
+			Error: "No authorization context found",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var disableReq MFADisableRequest
+	if err := json.Unmarshal([]byte(request.Body), &disableReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if disableReq.AccessToken == "" || disableReq.Password == "" || disableReq.Code == "" {
+		errorResponse := ErrorResponse{
+			Error: "Access token, password and code are required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// The MFA turned off is the owner's, who must be the caller
+	if auth.TokenSubject(disableReq.AccessToken) != user.Sub {
+		errorResponse := ErrorResponse{
+			Error: "Access token does not belong to the signed in user",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 403,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Prove the caller holds the enrolled authenticator app
+	if statusCode, errorMsg := h.checkCode(ctx, user.Email, disableReq.Password, disableReq.Code); statusCode != 0 {
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Turn software token MFA off
+	preferenceInput := &cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken: aws.String(disableReq.AccessToken),
+		SoftwareTokenMfaSettings: &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+			Enabled:      aws.Bool(false),
+			PreferredMfa: aws.Bool(false),
+		},
+	}
+
+	_, err := h.cognito.SetUserMFAPreferenceWithContext(ctx, preferenceInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("disabling mfa failed", "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors
+		errorMsg := err.Error()
+		statusCode := 400
+
+		if strings.Contains(errorMsg, "NotAuthorizedException") {
+			errorMsg = "Invalid access token. Please sign in again."
+			statusCode = 401
+		} else if strings.Contains(errorMsg, "TooManyRequestsException") {
+			errorMsg = "Too many requests. Please wait a few minutes and try again."
+		} else {
+			errorMsg = "Failed to disable two-factor authentication. Please try again."
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	logging.FromContext(ctx).Info("mfa disabled", "email_hash", logging.HashEmail(user.Email))
+
+	// Create response
+	response := MFAResponse{
+		Message: "Two-factor authentication disabled",
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+// checkCode signs the user in with their password and answers the
+// SOFTWARE_TOKEN_MFA challenge with code, which only succeeds for the
+// authenticator app Cognito has enrolled. VerifySoftwareToken is not used as it
+// belongs to enrolling an app, not to checking one. The session the sign in
+// creates is revoked straight away. It returns the status code and message of
+// the error response, or 0 when the code is right.
+func (h *handler) checkCode(ctx context.Context, email, password, code string) (int, string) {
+	logger := logging.FromContext(ctx)
+
+	authResult, err := h.cognito.InitiateAuthWithContext(ctx, &cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String(h.cfg.CognitoUserPoolClientID),
+		AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeUserPasswordAuth),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String(email),
+			"PASSWORD": aws.String(password),
+		},
+	})
+	if err != nil {
+		logger.Warn("password check failed", "error", err)
+
+		errorMsg := err.Error()
+		if strings.Contains(errorMsg, "NotAuthorizedException") {
+			return 403, "Incorrect password."
+		}
+		if strings.Contains(errorMsg, "TooManyRequestsException") {
+			return 400, "Too many attempts. Please wait a few minutes and try again."
+		}
+		return 400, "Failed to verify code. Please try again."
+	}
+
+	if aws.StringValue(authResult.ChallengeName) != cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa {
+		h.revoke(ctx, authResult.AuthenticationResult)
+		return 400, "Two-factor authentication is not set up."
+	}
+
+	challengeResult, err := h.cognito.RespondToAuthChallengeWithContext(ctx, &cognitoidentityprovider.RespondToAuthChallengeInput{
+		ClientId:      aws.String(h.cfg.CognitoUserPoolClientID),
+		ChallengeName: aws.String(cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa),
+		Session:       authResult.Session,
+		ChallengeResponses: map[string]*string{
+			"USERNAME":                aws.String(email),
+			"SOFTWARE_TOKEN_MFA_CODE": aws.String(code),
+		},
+	})
+	if err != nil {
+		logger.Warn("software token check failed", "error", err)
+
+		errorMsg := err.Error()
+		if strings.Contains(errorMsg, "CodeMismatchException") || strings.Contains(errorMsg, "ExpiredCodeException") {
+			return 403, "Invalid code. Please check and try again."
+		}
+		if strings.Contains(errorMsg, "TooManyRequestsException") || strings.Contains(errorMsg, "TooManyFailedAttemptsException") {
+			return 400, "Too many attempts. Please wait a few minutes and try again."
+		}
+		return 400, "Failed to verify code. Please try again."
+	}
+
+	h.revoke(ctx, challengeResult.AuthenticationResult)
+	return 0, ""
+}
+
+// revoke signs out the session a code check created. Failing to is logged
+// rather than refused, as the session is the user's own.
+func (h *handler) revoke(ctx context.Context, result *cognitoidentityprovider.AuthenticationResultType) {
+	if result == nil || result.RefreshToken == nil {
+		return
+	}
+	_, err := h.cognito.RevokeTokenWithContext(ctx, &cognitoidentityprovider.RevokeTokenInput{
+		ClientId: aws.String(h.cfg.CognitoUserPoolClientID),
+		Token:    result.RefreshToken,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to revoke code check session", "error", err)
+	}
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
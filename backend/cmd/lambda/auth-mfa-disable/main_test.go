@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client for a user with MFA on. It answers
+// InitiateAuth with authOutput or a SOFTWARE_TOKEN_MFA challenge, or authErr;
+// RespondToAuthChallenge with challengeErr; and SetUserMFAPreference with err.
+// It records their inputs and the refresh tokens revoked. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	authInput      *cognitoidentityprovider.InitiateAuthInput
+	authOutput     *cognitoidentityprovider.InitiateAuthOutput
+	authErr        error
+	challengeInput *cognitoidentityprovider.RespondToAuthChallengeInput
+	challengeErr   error
+	revoked        []string
+	input          *cognitoidentityprovider.SetUserMFAPreferenceInput
+	err            error
+}
+
+func (f *fakeCognito) InitiateAuthWithContext(ctx aws.Context, input *cognitoidentityprovider.InitiateAuthInput, opts ...request.Option) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	f.authInput = input
+	if f.authErr != nil {
+		return nil, f.authErr
+	}
+	if f.authOutput != nil {
+		return f.authOutput, nil
+	}
+	return &cognitoidentityprovider.InitiateAuthOutput{
+		ChallengeName: aws.String(cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa),
+		Session:       aws.String("session-1"),
+	}, nil
+}
+
+func (f *fakeCognito) RespondToAuthChallengeWithContext(ctx aws.Context, input *cognitoidentityprovider.RespondToAuthChallengeInput, opts ...request.Option) (*cognitoidentityprovider.RespondToAuthChallengeOutput, error) {
+	f.challengeInput = input
+	if f.challengeErr != nil {
+		return nil, f.challengeErr
+	}
+	return &cognitoidentityprovider.RespondToAuthChallengeOutput{
+		AuthenticationResult: &cognitoidentityprovider.AuthenticationResultType{RefreshToken: aws.String("refresh-1")},
+	}, nil
+}
+
+func (f *fakeCognito) RevokeTokenWithContext(ctx aws.Context, input *cognitoidentityprovider.RevokeTokenInput, opts ...request.Option) (*cognitoidentityprovider.RevokeTokenOutput, error) {
+	f.revoked = append(f.revoked, aws.StringValue(input.Token))
+	return &cognitoidentityprovider.RevokeTokenOutput{}, nil
+}
+
+func (f *fakeCognito) SetUserMFAPreferenceWithContext(ctx aws.Context, input *cognitoidentityprovider.SetUserMFAPreferenceInput, opts ...request.Option) (*cognitoidentityprovider.SetUserMFAPreferenceOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.SetUserMFAPreferenceOutput{}, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// testToken returns an unsigned JWT for sub, which is all the handler reads
+func testToken(sub string) string {
+	payload, _ := json.Marshal(map[string]string{"sub": sub, "token_use": "access"})
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// testClaims are the authorizer claims of user-1
+var testClaims = map[string]interface{}{"sub": "user-1", "email": "jane@example.com"}
+
+// post sends body to h as the user with claims and decodes the error, if any
+func post(t *testing.T, h *handler, claims map[string]interface{}, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+// login signs jane@example.com in through the Cognito client of h
+func login(t *testing.T, h *handler) *cognitoidentityprovider.InitiateAuthOutput {
+	t.Helper()
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	return authOut
+}
+
+func TestHandler_Validation(t *testing.T) {
+	tests := []struct {
+		claims  map[string]interface{}
+		body    string
+		status  int
+		message string
+	}{
+		{nil, `{"accessToken": "` + testToken("user-1") + `", "password": "Passw0rd!", "code": "123456"}`, 401, "No authorization context found"},
+		{testClaims, `{invalid json}`, 400, "Invalid request body"},
+		{testClaims, `{"password": "Passw0rd!", "code": "123456"}`, 400, "Access token, password and code are required"},
+		{testClaims, `{"accessToken": "` + testToken("user-1") + `", "code": "123456"}`, 400, "Access token, password and code are required"},
+		{testClaims, `{"accessToken": "` + testToken("user-1") + `", "password": "Passw0rd!"}`, 400, "Access token, password and code are required"},
+		{testClaims, `{"accessToken": "` + testToken("user-2") + `", "password": "Passw0rd!", "code": "123456"}`, 403, "Access token does not belong to the signed in user"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{}
+		response, errorResp := post(t, newTestHandler(cognito), tt.claims, tt.body)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.body, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+		if cognito.authInput != nil || cognito.input != nil {
+			t.Errorf("%s: expected Cognito not to be called", tt.body)
+		}
+	}
+}
+
+// disableBody is a request turning user-1's MFA off
+var disableBody = `{"accessToken": "` + testToken("user-1") + `", "password": "Passw0rd!", "code": "123456"}`
+
+func TestHandler_DisablesMFA(t *testing.T) {
+	cognito := &fakeCognito{}
+
+	response, _ := post(t, newTestHandler(cognito), testClaims, disableBody)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	params := cognito.authInput.AuthParameters
+	if aws.StringValue(cognito.authInput.AuthFlow) != "USER_PASSWORD_AUTH" || aws.StringValue(params["USERNAME"]) != "jane@example.com" || aws.StringValue(params["PASSWORD"]) != "Passw0rd!" {
+		t.Errorf("Expected a sign in with the password, got %v", cognito.authInput)
+	}
+	responses := cognito.challengeInput.ChallengeResponses
+	if aws.StringValue(cognito.challengeInput.Session) != "session-1" || aws.StringValue(responses["SOFTWARE_TOKEN_MFA_CODE"]) != "123456" {
+		t.Errorf("Expected the code to answer the MFA challenge, got %v", cognito.challengeInput)
+	}
+	if len(cognito.revoked) != 1 || cognito.revoked[0] != "refresh-1" {
+		t.Errorf("Expected the code check's session to be revoked, got %v", cognito.revoked)
+	}
+
+	settings := cognito.input.SoftwareTokenMfaSettings
+	if aws.StringValue(cognito.input.AccessToken) != testToken("user-1") || aws.BoolValue(settings.Enabled) || aws.BoolValue(settings.PreferredMfa) {
+		t.Errorf("Expected MFA to be turned off, got %v", cognito.input)
+	}
+}
+
+func TestHandler_MFANotSetUp(t *testing.T) {
+	cognito := &fakeCognito{authOutput: &cognitoidentityprovider.InitiateAuthOutput{
+		AuthenticationResult: &cognitoidentityprovider.AuthenticationResultType{RefreshToken: aws.String("refresh-2")},
+	}}
+
+	response, errorResp := post(t, newTestHandler(cognito), testClaims, disableBody)
+	if response.StatusCode != 400 || errorResp.Error != "Two-factor authentication is not set up." {
+		t.Errorf("Expected 400 for a user without MFA, got %d: %s", response.StatusCode, response.Body)
+	}
+	if len(cognito.revoked) != 1 || cognito.revoked[0] != "refresh-2" || cognito.input != nil {
+		t.Errorf("Expected the sign in revoked and MFA left alone, got %v and %v", cognito.revoked, cognito.input)
+	}
+}
+
+func TestHandler_CodeErrors(t *testing.T) {
+	tests := []struct {
+		cognito *fakeCognito
+		status  int
+		message string
+	}{
+		{&fakeCognito{authErr: awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Incorrect username or password.", nil)}, 403, "Incorrect password."},
+		{&fakeCognito{authErr: awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "slow down", nil)}, 400, "Too many attempts. Please wait a few minutes and try again."},
+		{&fakeCognito{authErr: awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil)}, 400, "Failed to verify code. Please try again."},
+		{&fakeCognito{challengeErr: awserr.New(cognitoidentityprovider.ErrCodeCodeMismatchException, "Invalid code", nil)}, 403, "Invalid code. Please check and try again."},
+		{&fakeCognito{challengeErr: awserr.New(cognitoidentityprovider.ErrCodeExpiredCodeException, "expired", nil)}, 403, "Invalid code. Please check and try again."},
+		{&fakeCognito{challengeErr: awserr.New(cognitoidentityprovider.ErrCodeTooManyFailedAttemptsException, "slow down", nil)}, 400, "Too many attempts. Please wait a few minutes and try again."},
+		{&fakeCognito{challengeErr: awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil)}, 400, "Failed to verify code. Please try again."},
+	}
+
+	for _, tt := range tests {
+		response, errorResp := post(t, newTestHandler(tt.cognito), testClaims, disableBody)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%v %v: expected %d %q, got %d %q", tt.cognito.authErr, tt.cognito.challengeErr, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+		if tt.cognito.input != nil {
+			t.Errorf("%v %v: expected MFA to stay on", tt.cognito.authErr, tt.cognito.challengeErr)
+		}
+	}
+}
+
+func TestHandler_DisableErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Access Token has been revoked", nil), 401, "Invalid access token. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "slow down", nil), 400, "Too many requests. Please wait a few minutes and try again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil), 400, "Failed to disable two-factor authentication. Please try again."},
+	}
+
+	for _, tt := range tests {
+		response, errorResp := post(t, newTestHandler(&fakeCognito{err: tt.err}), testClaims, disableBody)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%v: expected %d %q, got %d %q", tt.err, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_DisableWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	sub := pool.AddUser("jane@example.com", "Passw0rd!")
+	accessToken := aws.StringValue(login(t, h).AuthenticationResult.AccessToken)
+	secret := pool.EnableMFA("jane@example.com")
+	claims := map[string]interface{}{"sub": sub, "email": "jane@example.com"}
+
+	body := func(password, code string) string {
+		return `{"accessToken": "` + accessToken + `", "password": "` + password + `", "code": "` + code + `"}`
+	}
+
+	code := fakecognito.TOTP(secret, time.Now())
+	for _, tt := range []struct{ password, code string }{{"Wrong0ne!", code}, {"Passw0rd!", "000000"}} {
+		response, _ := post(t, h, claims, body(tt.password, tt.code))
+		if response.StatusCode != 403 {
+			t.Fatalf("Expected password %s and code %s to be refused, got %d: %s", tt.password, tt.code, response.StatusCode, response.Body)
+		}
+	}
+	if authOut := login(t, h); authOut.AuthenticationResult != nil {
+		t.Fatalf("Expected MFA to stay on after a refused request, got %v", authOut)
+	}
+
+	response, _ := post(t, h, claims, body("Passw0rd!", code))
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if authOut := login(t, h); authOut.AuthenticationResult == nil {
+		t.Errorf("Expected sign in without a code once MFA is disabled, got %v", authOut)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// MFAPreferenceRequest represents the request body for changing the caller's
+// authenticator app MFA settings. Preferred needs Enabled.
+type MFAPreferenceRequest struct {
+	AccessToken string `json:"accessToken"`
+	Enabled     bool   `json:"enabled"`
+	Preferred   bool   `json:"preferred"`
+}
+
+// MFAPreferenceResponse represents the response with the settings now in force
+type MFAPreferenceResponse struct {
+	Message   string `json:"message"`
+	Enabled   bool   `json:"enabled"`
+	Preferred bool   `json:"preferred"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves MFA preference changes. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for setting MFA preferences. Only
+// authenticator app MFA is configured in the pool, so the settings are whether
+// it is enabled, and whether it is preferred at sign in. Turning it off needs a
+// code from the app, so that goes through /auth/mfa/disable instead.
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		errorResponse := ErrorResponse{
+			Error: "No authorization context found",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var preferenceReq MFAPreferenceRequest
+	if err := json.Unmarshal([]byte(request.Body), &preferenceReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if preferenceReq.AccessToken == "" {
+		errorResponse := ErrorResponse{
+			Error: "Access token is required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	if preferenceReq.Preferred && !preferenceReq.Enabled {
+		errorResponse := ErrorResponse{
+			Error: "Two-factor authentication must be enabled to be preferred",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	if !preferenceReq.Enabled {
+		errorResponse := ErrorResponse{
+			Error: "Turning off two-factor authentication needs your password and a code from your authenticator app",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// The settings changed are the owner's, who must be the caller
+	if auth.TokenSubject(preferenceReq.AccessToken) != user.Sub {
+		errorResponse := ErrorResponse{
+			Error: "Access token does not belong to the signed in user",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 403,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Update the software token MFA settings
+	preferenceInput := &cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken: aws.String(preferenceReq.AccessToken),
+		SoftwareTokenMfaSettings: &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+			Enabled:      aws.Bool(preferenceReq.Enabled),
+			PreferredMfa: aws.Bool(preferenceReq.Preferred),
+		},
+	}
+
+	_, err := h.cognito.SetUserMFAPreferenceWithContext(ctx, preferenceInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("setting mfa preference failed", "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors
+		errorMsg := err.Error()
+		statusCode := 400
+
+		if strings.Contains(errorMsg, "NotAuthorizedException") {
+			errorMsg = "Invalid access token. Please sign in again."
+			statusCode = 401
+		} else if strings.Contains(errorMsg, "InvalidParameterException") {
+			// Cognito refuses to enable a software token that was never verified
+			errorMsg = "Set up an authenticator app before turning on two-factor authentication."
+		} else if strings.Contains(errorMsg, "TooManyRequestsException") {
+			errorMsg = "Too many requests. Please wait a few minutes and try again."
+		} else {
+			errorMsg = "Failed to update two-factor settings. Please try again."
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	logging.FromContext(ctx).Info("mfa preference updated", "email_hash", logging.HashEmail(user.Email), "enabled", preferenceReq.Enabled, "preferred", preferenceReq.Preferred)
+
+	// Create response
+	response := MFAPreferenceResponse{
+		Message:   "Two-factor settings updated",
+		Enabled:   preferenceReq.Enabled,
+		Preferred: preferenceReq.Preferred,
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
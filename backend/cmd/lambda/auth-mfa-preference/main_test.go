@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client that answers SetUserMFAPreference
+// with err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input *cognitoidentityprovider.SetUserMFAPreferenceInput
+	err   error
+}
+
+func (f *fakeCognito) SetUserMFAPreferenceWithContext(ctx aws.Context, input *cognitoidentityprovider.SetUserMFAPreferenceInput, opts ...request.Option) (*cognitoidentityprovider.SetUserMFAPreferenceOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.SetUserMFAPreferenceOutput{}, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// testToken returns an unsigned JWT for sub, which is all the handler reads
+func testToken(sub string) string {
+	payload, _ := json.Marshal(map[string]string{"sub": sub, "token_use": "access"})
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// testClaims are the authorizer claims of user-1
+var testClaims = map[string]interface{}{"sub": "user-1", "email": "jane@example.com"}
+
+// post sends body to h as the user with claims and decodes the error, if any
+func post(t *testing.T, h *handler, claims map[string]interface{}, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+// login signs jane@example.com in through the Cognito client of h
+func login(t *testing.T, h *handler) *cognitoidentityprovider.InitiateAuthOutput {
+	t.Helper()
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	return authOut
+}
+
+func TestHandler_Validation(t *testing.T) {
+	tests := []struct {
+		claims  map[string]interface{}
+		body    string
+		status  int
+		message string
+	}{
+		{nil, `{"accessToken": "` + testToken("user-1") + `", "enabled": true}`, 401, "No authorization context found"},
+		{testClaims, `{invalid json}`, 400, "Invalid request body"},
+		{testClaims, `{"enabled": true}`, 400, "Access token is required"},
+		{testClaims, `{"accessToken": "` + testToken("user-1") + `", "preferred": true}`, 400, "Two-factor authentication must be enabled to be preferred"},
+		{testClaims, `{"accessToken": "` + testToken("user-1") + `", "enabled": false}`, 400, "Turning off two-factor authentication needs your password and a code from your authenticator app"},
+		{testClaims, `{"accessToken": "` + testToken("user-2") + `", "enabled": true}`, 403, "Access token does not belong to the signed in user"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{}
+		response, errorResp := post(t, newTestHandler(cognito), tt.claims, tt.body)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.body, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+		if cognito.input != nil {
+			t.Errorf("%s: expected Cognito not to be called", tt.body)
+		}
+	}
+}
+
+func TestHandler_SetsPreference(t *testing.T) {
+	tests := []struct {
+		body      string
+		enabled   bool
+		preferred bool
+	}{
+		{`"enabled": true, "preferred": true`, true, true},
+		{`"enabled": true, "preferred": false`, true, false},
+	}
+
+	for _, tt := range tests {
+		token := testToken("user-1")
+		cognito := &fakeCognito{}
+		response, _ := post(t, newTestHandler(cognito), testClaims, `{"accessToken": "`+token+`", `+tt.body+`}`)
+		if response.StatusCode != 200 {
+			t.Fatalf("%s: expected status 200, got %d: %s", tt.body, response.StatusCode, response.Body)
+		}
+
+		var preferenceResp MFAPreferenceResponse
+		json.Unmarshal([]byte(response.Body), &preferenceResp)
+		if preferenceResp.Enabled != tt.enabled || preferenceResp.Preferred != tt.preferred {
+			t.Errorf("%s: unexpected response %+v", tt.body, preferenceResp)
+		}
+
+		settings := cognito.input.SoftwareTokenMfaSettings
+		if aws.StringValue(cognito.input.AccessToken) != token || aws.BoolValue(settings.Enabled) != tt.enabled || aws.BoolValue(settings.PreferredMfa) != tt.preferred {
+			t.Errorf("%s: unexpected SetUserMFAPreference input %v", tt.body, cognito.input)
+		}
+	}
+}
+
+func TestHandler_PreferenceErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Access Token has been revoked", nil), 401, "Invalid access token. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeInvalidParameterException, "User has not verified software token mfa", nil), 400, "Set up an authenticator app before turning on two-factor authentication."},
+		{awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "slow down", nil), 400, "Too many requests. Please wait a few minutes and try again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil), 400, "Failed to update two-factor settings. Please try again."},
+	}
+
+	for _, tt := range tests {
+		response, errorResp := post(t, newTestHandler(&fakeCognito{err: tt.err}), testClaims, `{"accessToken": "`+testToken("user-1")+`", "enabled": true}`)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%v: expected %d %q, got %d %q", tt.err, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_PreferenceWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	sub := pool.AddUser("jane@example.com", "Passw0rd!")
+	accessToken := aws.StringValue(login(t, h).AuthenticationResult.AccessToken)
+	claims := map[string]interface{}{"sub": sub, "email": "jane@example.com"}
+
+	response, errorResp := post(t, h, claims, `{"accessToken": "`+accessToken+`", "enabled": true}`)
+	if response.StatusCode != 400 || errorResp.Error != "Set up an authenticator app before turning on two-factor authentication." {
+		t.Errorf("Expected MFA to need an authenticator app, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	pool.EnableMFA("jane@example.com")
+	response, _ = post(t, h, claims, `{"accessToken": "`+accessToken+`", "enabled": true, "preferred": true}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if authOut := login(t, h); aws.StringValue(authOut.ChallengeName) != cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa {
+		t.Errorf("Expected sign in to ask for a code once MFA is preferred, got %v", authOut)
+	}
+}
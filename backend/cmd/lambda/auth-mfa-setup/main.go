@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// MFASetupRequest represents the request body for starting authenticator app
+// enrollment
+type MFASetupRequest struct {
+	AccessToken string `json:"accessToken"`
+}
+
+// MFASetupResponse represents the response with the secret to add to an
+// authenticator app, as text and as an otpauth:// URI for a QR code
+type MFASetupResponse struct {
+	Message    string `json:"message"`
+	SecretCode string `json:"secret_code"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves MFA enrollment. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for starting TOTP enrollment. It
+// returns a new secret, which is not used at sign in until a code for it is
+// checked at /auth/mfa/verify.
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		errorResponse := ErrorResponse{
+			Error: "No authorization context found",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var setupReq MFASetupRequest
+	if err := json.Unmarshal([]byte(request.Body), &setupReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if setupReq.AccessToken == "" {
+		errorResponse := ErrorResponse{
+			Error: "Access token is required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// The secret is added to the token's owner, who must be the caller
+	if auth.TokenSubject(setupReq.AccessToken) != user.Sub {
+		errorResponse := ErrorResponse{
+			Error: "Access token does not belong to the signed in user",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 403,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Generate a new secret, replacing any earlier one that was not verified
+	associateInput := &cognitoidentityprovider.AssociateSoftwareTokenInput{
+		AccessToken: aws.String(setupReq.AccessToken),
+	}
+
+	associateResult, err := h.cognito.AssociateSoftwareTokenWithContext(ctx, associateInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("software token association failed", "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors
+		errorMsg := err.Error()
+		statusCode := 400
+
+		if strings.Contains(errorMsg, "NotAuthorizedException") {
+			errorMsg = "Invalid access token. Please sign in again."
+			statusCode = 401
+		} else if strings.Contains(errorMsg, "TooManyRequestsException") {
+			errorMsg = "Too many requests. Please wait a few minutes and try again."
+		} else {
+			errorMsg = "Failed to start two-factor setup. Please try again."
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	logging.FromContext(ctx).Info("mfa setup started", "email_hash", logging.HashEmail(user.Email))
+
+	// Create response
+	secret := aws.StringValue(associateResult.SecretCode)
+	response := MFASetupResponse{
+		Message:    "Add this key to your authenticator app, then enter a code from it to finish",
+		SecretCode: secret,
+		OTPAuthURI: auth.OTPAuthURI(auth.MFAIssuer, user.Email, secret),
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client that answers AssociateSoftwareToken
+// with secret or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.AssociateSoftwareTokenInput
+	secret string
+	err    error
+}
+
+func (f *fakeCognito) AssociateSoftwareTokenWithContext(ctx aws.Context, input *cognitoidentityprovider.AssociateSoftwareTokenInput, opts ...request.Option) (*cognitoidentityprovider.AssociateSoftwareTokenOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.AssociateSoftwareTokenOutput{SecretCode: aws.String(f.secret)}, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// testToken returns an unsigned JWT for sub, which is all the handler reads
+func testToken(sub string) string {
+	payload, _ := json.Marshal(map[string]string{"sub": sub, "token_use": "access"})
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// testClaims are the authorizer claims of user-1
+var testClaims = map[string]interface{}{"sub": "user-1", "email": "jane@example.com"}
+
+// post sends body to h as the user with claims and decodes the error, if any
+func post(t *testing.T, h *handler, claims map[string]interface{}, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_Validation(t *testing.T) {
+	tests := []struct {
+		claims  map[string]interface{}
+		body    string
+		status  int
+		message string
+	}{
+		{nil, `{"accessToken": "` + testToken("user-1") + `"}`, 401, "No authorization context found"},
+		{testClaims, `{invalid json}`, 400, "Invalid request body"},
+		{testClaims, `{}`, 400, "Access token is required"},
+		{testClaims, `{"accessToken": "` + testToken("user-2") + `"}`, 403, "Access token does not belong to the signed in user"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{}
+		response, errorResp := post(t, newTestHandler(cognito), tt.claims, tt.body)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.body, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+		if cognito.input != nil {
+			t.Errorf("%s: expected Cognito not to be called", tt.body)
+		}
+	}
+}
+
+func TestHandler_SetupReturnsSecret(t *testing.T) {
+	token := testToken("user-1")
+	cognito := &fakeCognito{secret: "JBSWY3DPEHPK3PXP"}
+
+	response, _ := post(t, newTestHandler(cognito), testClaims, `{"accessToken": "`+token+`"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var setupResp MFASetupResponse
+	if err := json.Unmarshal([]byte(response.Body), &setupResp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if setupResp.SecretCode != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Expected the secret, got %q", setupResp.SecretCode)
+	}
+	if setupResp.OTPAuthURI != "otpauth://totp/TuiTui:jane@example.com?issuer=TuiTui&secret=JBSWY3DPEHPK3PXP" {
+		t.Errorf("Unexpected otpauth URI %q", setupResp.OTPAuthURI)
+	}
+	if aws.StringValue(cognito.input.AccessToken) != token {
+		t.Errorf("Expected the access token to be sent to Cognito, got %v", cognito.input)
+	}
+}
+
+func TestHandler_SetupErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Access Token has been revoked", nil), 401, "Invalid access token. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "slow down", nil), 400, "Too many requests. Please wait a few minutes and try again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeSoftwareTokenMFANotFoundException, "not enabled", nil), 400, "Failed to start two-factor setup. Please try again."},
+	}
+
+	for _, tt := range tests {
+		response, errorResp := post(t, newTestHandler(&fakeCognito{err: tt.err}), testClaims, `{"accessToken": "`+testToken("user-1")+`"}`)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%v: expected %d %q, got %d %q", tt.err, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_SetupWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	sub := pool.AddUser("jane@example.com", "Passw0rd!")
+
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	accessToken := authOut.AuthenticationResult.AccessToken
+	claims := map[string]interface{}{"sub": sub, "email": "jane@example.com"}
+
+	response, _ := post(t, h, claims, `{"accessToken": "`+aws.StringValue(accessToken)+`"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	var setupResp MFASetupResponse
+	json.Unmarshal([]byte(response.Body), &setupResp)
+	if setupResp.SecretCode == "" || !strings.Contains(setupResp.OTPAuthURI, "secret="+setupResp.SecretCode) {
+		t.Fatalf("Expected a secret in the otpauth URI, got %+v", setupResp)
+	}
+
+	// The secret makes codes the pool accepts
+	_, err = h.cognito.VerifySoftwareToken(&cognitoidentityprovider.VerifySoftwareTokenInput{
+		AccessToken: accessToken,
+		UserCode:    aws.String(fakecognito.TOTP(setupResp.SecretCode, time.Now())),
+	})
+	if err != nil {
+		t.Errorf("Expected a code from the secret to verify, got %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// MFAVerifyRequest represents the request body for finishing authenticator app
+// enrollment with a code from the app
+type MFAVerifyRequest struct {
+	AccessToken string `json:"accessToken"`
+	Code        string `json:"code"`
+	DeviceName  string `json:"deviceName,omitempty"`
+}
+
+// MFAResponse represents the response for a successful MFA change
+type MFAResponse struct {
+	Message string `json:"message"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves MFA verification. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for verifying the first code of a new
+// authenticator app. Once the code is accepted, MFA is enabled and preferred,
+// so the next sign in asks for a SOFTWARE_TOKEN_MFA code.
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		errorResponse := ErrorResponse{
+			Error: "No authorization context found",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var verifyReq MFAVerifyRequest
+	if err := json.Unmarshal([]byte(request.Body), &verifyReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if verifyReq.AccessToken == "" || verifyReq.Code == "" {
+		errorResponse := ErrorResponse{
+			Error: "Access token and code are required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// The token verified is the owner's, who must be the caller
+	if auth.TokenSubject(verifyReq.AccessToken) != user.Sub {
+		errorResponse := ErrorResponse{
+			Error: "Access token does not belong to the signed in user",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 403,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Check the code against the secret from /auth/mfa/setup
+	verifyInput := &cognitoidentityprovider.VerifySoftwareTokenInput{
+		AccessToken: aws.String(verifyReq.AccessToken),
+		UserCode:    aws.String(verifyReq.Code),
+	}
+	if verifyReq.DeviceName != "" {
+		verifyInput.FriendlyDeviceName = aws.String(verifyReq.DeviceName)
+	}
+
+	verifyResult, err := h.cognito.VerifySoftwareTokenWithContext(ctx, verifyInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("software token verification failed", "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors
+		errorMsg := err.Error()
+		statusCode := 400
+
+		if strings.Contains(errorMsg, "EnableSoftwareTokenMFAException") || strings.Contains(errorMsg, "CodeMismatchException") {
+			errorMsg = "Invalid code. Please check and try again."
+		} else if strings.Contains(errorMsg, "NotAuthorizedException") {
+			errorMsg = "Invalid access token. Please sign in again."
+			statusCode = 401
+		} else if strings.Contains(errorMsg, "InvalidParameterException") || strings.Contains(errorMsg, "SoftwareTokenMFANotFoundException") {
+			errorMsg = "Set up an authenticator app before turning on two-factor authentication."
+		} else if strings.Contains(errorMsg, "TooManyRequestsException") || strings.Contains(errorMsg, "TooManyFailedAttemptsException") {
+			errorMsg = "Too many attempts. Please wait a few minutes and try again."
+		} else {
+			errorMsg = "Failed to verify code. Please try again."
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	if aws.StringValue(verifyResult.Status) != cognitoidentityprovider.VerifySoftwareTokenResponseTypeSuccess {
+		errorResponse := ErrorResponse{
+			Error: "Invalid code. Please check and try again.",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Turn MFA on now the app is known to produce the right codes
+	preferenceInput := &cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken: aws.String(verifyReq.AccessToken),
+		SoftwareTokenMfaSettings: &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+			Enabled:      aws.Bool(true),
+			PreferredMfa: aws.Bool(true),
+		},
+	}
+
+	_, err = h.cognito.SetUserMFAPreferenceWithContext(ctx, preferenceInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("enabling mfa failed", "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors
+		errorMsg := err.Error()
+		statusCode := 400
+
+		if strings.Contains(errorMsg, "NotAuthorizedException") {
+			errorMsg = "Invalid access token. Please sign in again."
+			statusCode = 401
+		} else if strings.Contains(errorMsg, "InvalidParameterException") {
+			// Cognito refuses to enable a software token that was never verified
+			errorMsg = "Set up an authenticator app before turning on two-factor authentication."
+		} else if strings.Contains(errorMsg, "TooManyRequestsException") {
+			errorMsg = "Too many requests. Please wait a few minutes and try again."
+		} else {
+			errorMsg = "Failed to enable two-factor authentication. Please try again."
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	logging.FromContext(ctx).Info("mfa enabled", "email_hash", logging.HashEmail(user.Email))
+
+	// Create response
+	response := MFAResponse{
+		Message: "Two-factor authentication enabled",
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client that answers VerifySoftwareToken
+// with status or verifyErr, and SetUserMFAPreference with preferenceErr. It
+// records their inputs; other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	verifyInput     *cognitoidentityprovider.VerifySoftwareTokenInput
+	preferenceInput *cognitoidentityprovider.SetUserMFAPreferenceInput
+	status          string
+	verifyErr       error
+	preferenceErr   error
+}
+
+func (f *fakeCognito) VerifySoftwareTokenWithContext(ctx aws.Context, input *cognitoidentityprovider.VerifySoftwareTokenInput, opts ...request.Option) (*cognitoidentityprovider.VerifySoftwareTokenOutput, error) {
+	f.verifyInput = input
+	if f.verifyErr != nil {
+		return nil, f.verifyErr
+	}
+	status := f.status
+	if status == "" {
+		status = cognitoidentityprovider.VerifySoftwareTokenResponseTypeSuccess
+	}
+	return &cognitoidentityprovider.VerifySoftwareTokenOutput{Status: aws.String(status)}, nil
+}
+
+func (f *fakeCognito) SetUserMFAPreferenceWithContext(ctx aws.Context, input *cognitoidentityprovider.SetUserMFAPreferenceInput, opts ...request.Option) (*cognitoidentityprovider.SetUserMFAPreferenceOutput, error) {
+	f.preferenceInput = input
+	if f.preferenceErr != nil {
+		return nil, f.preferenceErr
+	}
+	return &cognitoidentityprovider.SetUserMFAPreferenceOutput{}, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// testToken returns an unsigned JWT for sub, which is all the handler reads
+func testToken(sub string) string {
+	payload, _ := json.Marshal(map[string]string{"sub": sub, "token_use": "access"})
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// testClaims are the authorizer claims of user-1
+var testClaims = map[string]interface{}{"sub": "user-1", "email": "jane@example.com"}
+
+// post sends body to h as the user with claims and decodes the error, if any
+func post(t *testing.T, h *handler, claims map[string]interface{}, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_Validation(t *testing.T) {
+	tests := []struct {
+		claims  map[string]interface{}
+		body    string
+		status  int
+		message string
+	}{
+		{nil, `{"accessToken": "` + testToken("user-1") + `", "code": "123456"}`, 401, "No authorization context found"},
+		{testClaims, `{invalid json}`, 400, "Invalid request body"},
+		{testClaims, `{"code": "123456"}`, 400, "Access token and code are required"},
+		{testClaims, `{"accessToken": "` + testToken("user-1") + `"}`, 400, "Access token and code are required"},
+		{testClaims, `{"accessToken": "` + testToken("user-2") + `", "code": "123456"}`, 403, "Access token does not belong to the signed in user"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{}
+		response, errorResp := post(t, newTestHandler(cognito), tt.claims, tt.body)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.body, tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+		if cognito.verifyInput != nil {
+			t.Errorf("%s: expected Cognito not to be called", tt.body)
+		}
+	}
+}
+
+func TestHandler_VerifyEnablesMFA(t *testing.T) {
+	token := testToken("user-1")
+	cognito := &fakeCognito{}
+
+	response, _ := post(t, newTestHandler(cognito), testClaims, `{"accessToken": "`+token+`", "code": "123456", "deviceName": "Work phone"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	verify := cognito.verifyInput
+	if aws.StringValue(verify.AccessToken) != token || aws.StringValue(verify.UserCode) != "123456" || aws.StringValue(verify.FriendlyDeviceName) != "Work phone" {
+		t.Errorf("Unexpected VerifySoftwareToken input %v", verify)
+	}
+	settings := cognito.preferenceInput.SoftwareTokenMfaSettings
+	if !aws.BoolValue(settings.Enabled) || !aws.BoolValue(settings.PreferredMfa) {
+		t.Errorf("Expected MFA to be enabled and preferred, got %v", settings)
+	}
+}
+
+func TestHandler_VerifyRejectedStatus(t *testing.T) {
+	cognito := &fakeCognito{status: cognitoidentityprovider.VerifySoftwareTokenResponseTypeError}
+
+	response, errorResp := post(t, newTestHandler(cognito), testClaims, `{"accessToken": "`+testToken("user-1")+`", "code": "123456"}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid code. Please check and try again." {
+		t.Errorf("Expected 400 for a rejected code, got %d %q", response.StatusCode, errorResp.Error)
+	}
+	if cognito.preferenceInput != nil {
+		t.Error("Expected MFA not to be enabled for a rejected code")
+	}
+}
+
+func TestHandler_VerifyErrors(t *testing.T) {
+	tests := []struct {
+		cognito *fakeCognito
+		status  int
+		message string
+	}{
+		{&fakeCognito{verifyErr: awserr.New(cognitoidentityprovider.ErrCodeEnableSoftwareTokenMFAException, "Code mismatch", nil)}, 400, "Invalid code. Please check and try again."},
+		{&fakeCognito{verifyErr: awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Access Token has expired", nil)}, 401, "Invalid access token. Please sign in again."},
+		{&fakeCognito{verifyErr: awserr.New(cognitoidentityprovider.ErrCodeInvalidParameterException, "not set up", nil)}, 400, "Set up an authenticator app before turning on two-factor authentication."},
+		{&fakeCognito{verifyErr: awserr.New(cognitoidentityprovider.ErrCodeTooManyFailedAttemptsException, "slow down", nil)}, 400, "Too many attempts. Please wait a few minutes and try again."},
+		{&fakeCognito{verifyErr: awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil)}, 400, "Failed to verify code. Please try again."},
+		{&fakeCognito{preferenceErr: awserr.New(cognitoidentityprovider.ErrCodeInternalErrorException, "boom", nil)}, 400, "Failed to enable two-factor authentication. Please try again."},
+	}
+
+	for _, tt := range tests {
+		response, errorResp := post(t, newTestHandler(tt.cognito), testClaims, `{"accessToken": "`+testToken("user-1")+`", "code": "123456"}`)
+		if response.StatusCode != tt.status || errorResp.Error != tt.message {
+			t.Errorf("expected %d %q, got %d %q", tt.status, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_VerifyWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	sub := pool.AddUser("jane@example.com", "Passw0rd!")
+
+	login := func() *cognitoidentityprovider.InitiateAuthOutput {
+		t.Helper()
+		authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+			ClientId: aws.String("local-client"),
+			AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+			AuthParameters: map[string]*string{
+				"USERNAME": aws.String("jane@example.com"),
+				"PASSWORD": aws.String("Passw0rd!"),
+			},
+		})
+		if err != nil {
+			t.Fatalf("InitiateAuth returned error: %v", err)
+		}
+		return authOut
+	}
+
+	accessToken := aws.StringValue(login().AuthenticationResult.AccessToken)
+	associateOut, err := h.cognito.AssociateSoftwareToken(&cognitoidentityprovider.AssociateSoftwareTokenInput{AccessToken: aws.String(accessToken)})
+	if err != nil {
+		t.Fatalf("AssociateSoftwareToken returned error: %v", err)
+	}
+	claims := map[string]interface{}{"sub": sub, "email": "jane@example.com"}
+
+	response, errorResp := post(t, h, claims, `{"accessToken": "`+accessToken+`", "code": "000000"}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid code. Please check and try again." {
+		t.Errorf("Expected a wrong code to be refused, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	code := fakecognito.TOTP(aws.StringValue(associateOut.SecretCode), time.Now())
+	response, _ = post(t, h, claims, `{"accessToken": "`+accessToken+`", "code": "`+code+`"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if authOut := login(); aws.StringValue(authOut.ChallengeName) != "SOFTWARE_TOKEN_MFA" {
+		t.Errorf("Expected sign in to ask for a code once MFA is enabled, got %v", authOut)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// RefreshRequest represents the request body for refreshing tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// LoginResponse represents the response for a successful refresh, in the same
+// shape as auth-login so clients can store it the same way
+type LoginResponse struct {
+	Message      string `json:"message"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves token refreshes. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for refreshing tokens
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var refreshReq RefreshRequest
+	if err := json.Unmarshal([]byte(request.Body), &refreshReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if refreshReq.RefreshToken == "" {
+		errorResponse := ErrorResponse{
+			Error: "Refresh token is required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Exchange the refresh token for new tokens
+	authInput := &cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String(h.cfg.CognitoUserPoolClientID),
+		AuthFlow: aws.String("REFRESH_TOKEN_AUTH"),
+		AuthParameters: map[string]*string{
+			"REFRESH_TOKEN": aws.String(refreshReq.RefreshToken),
+		},
+	}
+
+	authResult, err := h.cognito.InitiateAuthWithContext(ctx, authInput)
+	if err == nil && authResult.AuthenticationResult == nil {
+		err = fmt.Errorf("no tokens returned for challenge %s", aws.StringValue(authResult.ChallengeName))
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("token refresh failed", "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors. Cognito
+		// reports revoked and expired tokens as NotAuthorizedException, told
+		// apart by the message.
+		errorMsg := err.Error()
+
+		if strings.Contains(errorMsg, "NotAuthorizedException") && strings.Contains(errorMsg, "revoked") {
+			errorMsg = "Your session has been signed out. Please sign in again."
+		} else if strings.Contains(errorMsg, "NotAuthorizedException") && strings.Contains(errorMsg, "expired") {
+			errorMsg = "Your session has expired. Please sign in again."
+		} else if strings.Contains(errorMsg, "NotAuthorizedException") || strings.Contains(errorMsg, "UserNotFoundException") {
+			errorMsg = "Invalid refresh token. Please sign in again."
+		} else {
+			errorMsg = fmt.Sprintf("Token refresh failed: %v", err)
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	logging.FromContext(ctx).Info("tokens refreshed")
+
+	// Cognito only returns a refresh token when it rotates them; otherwise the
+	// caller keeps using the one it sent
+	result := authResult.AuthenticationResult
+	refreshToken := aws.StringValue(result.RefreshToken)
+	if refreshToken == "" {
+		refreshToken = refreshReq.RefreshToken
+	}
+
+	// Create response
+	response := LoginResponse{
+		Message:      "Tokens refreshed",
+		AccessToken:  aws.StringValue(result.AccessToken),
+		RefreshToken: refreshToken,
+		IDToken:      aws.StringValue(result.IdToken),
+		TokenType:    aws.StringValue(result.TokenType),
+		ExpiresIn:    int(aws.Int64Value(result.ExpiresIn)),
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client that answers InitiateAuth with output
+// or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.InitiateAuthInput
+	output *cognitoidentityprovider.InitiateAuthOutput
+	err    error
+}
+
+func (f *fakeCognito) InitiateAuthWithContext(ctx aws.Context, input *cognitoidentityprovider.InitiateAuthInput, opts ...request.Option) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.InitiateAuthOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// post sends body to h and decodes the error, if any
+func post(t *testing.T, h *handler, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_InvalidJSON(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), `{invalid json}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid request body" {
+		t.Errorf("Expected 400 'Invalid request body', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_MissingRefreshToken(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), `{"refreshToken": ""}`)
+	if response.StatusCode != 400 || errorResp.Error != "Refresh token is required" {
+		t.Errorf("Expected 400 'Refresh token is required', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_RefreshReturnsTokens(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.InitiateAuthOutput{
+		AuthenticationResult: &cognitoidentityprovider.AuthenticationResultType{
+			AccessToken: aws.String("new-access"),
+			IdToken:     aws.String("new-id"),
+			TokenType:   aws.String("Bearer"),
+			ExpiresIn:   aws.Int64(3600),
+		},
+	}}
+
+	response, _ := post(t, newTestHandler(cognito), `{"refreshToken": "refresh"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var loginResp LoginResponse
+	if err := json.Unmarshal([]byte(response.Body), &loginResp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	// Without rotation the caller keeps its refresh token
+	if loginResp.AccessToken != "new-access" || loginResp.IDToken != "new-id" || loginResp.RefreshToken != "refresh" || loginResp.ExpiresIn != 3600 {
+		t.Errorf("Unexpected refresh response %+v", loginResp)
+	}
+
+	input := cognito.input
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.AuthFlow) != "REFRESH_TOKEN_AUTH" || aws.StringValue(input.AuthParameters["REFRESH_TOKEN"]) != "refresh" {
+		t.Errorf("Unexpected InitiateAuth input %v", input)
+	}
+}
+
+func TestHandler_RefreshReturnsRotatedToken(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.InitiateAuthOutput{
+		AuthenticationResult: &cognitoidentityprovider.AuthenticationResultType{
+			AccessToken:  aws.String("new-access"),
+			IdToken:      aws.String("new-id"),
+			RefreshToken: aws.String("new-refresh"),
+		},
+	}}
+
+	response, _ := post(t, newTestHandler(cognito), `{"refreshToken": "refresh"}`)
+	var loginResp LoginResponse
+	json.Unmarshal([]byte(response.Body), &loginResp)
+	if loginResp.RefreshToken != "new-refresh" {
+		t.Errorf("Expected the rotated refresh token, got %+v", loginResp)
+	}
+}
+
+func TestHandler_RefreshErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		message string
+	}{
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Refresh Token has been revoked", nil), "Your session has been signed out. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Refresh Token has expired", nil), "Your session has expired. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Invalid Refresh Token", nil), "Invalid refresh token. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeUserNotFoundException, "User does not exist.", nil), "Invalid refresh token. Please sign in again."},
+		{awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "slow down", nil), "Token refresh failed: TooManyRequestsException: slow down"},
+	}
+
+	for _, tt := range tests {
+		response, errorResp := post(t, newTestHandler(&fakeCognito{err: tt.err}), `{"refreshToken": "refresh"}`)
+		if response.StatusCode != 401 || errorResp.Error != tt.message {
+			t.Errorf("%v: expected 401 %q, got %d %q", tt.err, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_RefreshWithoutTokens(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.InitiateAuthOutput{ChallengeName: aws.String("SOFTWARE_TOKEN_MFA")}}
+
+	response, _ := post(t, newTestHandler(cognito), `{"refreshToken": "refresh"}`)
+	if response.StatusCode != 401 {
+		t.Errorf("Expected 401 when Cognito returns no tokens, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_RefreshesWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("jane@example.com", "Passw0rd!")
+
+	authOut, err := h.cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("local-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	refreshToken := aws.StringValue(authOut.AuthenticationResult.RefreshToken)
+
+	response, _ := post(t, h, `{"refreshToken": "`+refreshToken+`"}`)
+	var loginResp LoginResponse
+	json.Unmarshal([]byte(response.Body), &loginResp)
+	if response.StatusCode != 200 || loginResp.IDToken == "" || loginResp.RefreshToken != refreshToken {
+		t.Errorf("Expected new tokens, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, errorResp := post(t, h, `{"refreshToken": "not-a-token"}`)
+	if response.StatusCode != 401 || errorResp.Error != "Invalid refresh token. Please sign in again." {
+		t.Errorf("Expected 401 for an unknown token, got %d: %s", response.StatusCode, response.Body)
+	}
+}
@@ -3,17 +3,54 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
 )
 
+// fakeCognito is an in-memory Cognito client that answers SignUp with output
+// or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.SignUpInput
+	output *cognitoidentityprovider.SignUpOutput
+	err    error
+}
+
+func (f *fakeCognito) SignUpWithContext(ctx aws.Context, input *cognitoidentityprovider.SignUpInput, opts ...request.Option) (*cognitoidentityprovider.SignUpOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.SignUpOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
 func TestHandler_OptionsRequest(t *testing.T) {
 	request := events.APIGatewayProxyRequest{
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -29,7 +66,7 @@ func TestHandler_InvalidJSON(t *testing.T) {
 		Body:       `{invalid json}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -54,7 +91,7 @@ func TestHandler_MissingEmail(t *testing.T) {
 		Body:       `{"email": "", "password": "test123", "name": "Test"}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -79,7 +116,7 @@ func TestHandler_MissingPassword(t *testing.T) {
 		Body:       `{"email": "test@example.com", "password": "", "name": "Test"}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -95,7 +132,7 @@ func TestHandler_MissingName(t *testing.T) {
 		Body:       `{"email": "test@example.com", "password": "test123", "name": ""}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -110,7 +147,7 @@ func TestHandler_CORSHeaders(t *testing.T) {
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -143,3 +180,112 @@ func TestRegisterRequest_Struct(t *testing.T) {
 		t.Errorf("Expected name 'Test User', got '%s'", req.Name)
 	}
 }
+
+func TestHandler_RegistersWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "jane@example.com", "password": "Passw0rd!", "name": "Jane"}`,
+	}
+	response, err := h.Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	var registerResp RegisterResponse
+	if err := json.Unmarshal([]byte(response.Body), &registerResp); err != nil || registerResp.UserSub == "" {
+		t.Errorf("Expected a user sub, got %s", response.Body)
+	}
+	if pool.Code("jane@example.com") == "" {
+		t.Error("Expected a verification code to be sent")
+	}
+
+	// Registering again is refused
+	response, _ = h.Handle(context.Background(), request)
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	if response.StatusCode != 400 || errorResp.Error != "An account with this email already exists." {
+		t.Errorf("Expected the existing account error, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_WeakPasswordWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "jane@example.com", "password": "password", "name": "Jane"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	if response.StatusCode != 400 || !strings.HasPrefix(errorResp.Error, "Password does not meet requirements") {
+		t.Errorf("Expected the password policy error, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_RegisterReturnsUserSub(t *testing.T) {
+	cognito := &fakeCognito{output: &cognitoidentityprovider.SignUpOutput{UserSub: aws.String("user-123")}}
+
+	response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "test@example.com", "password": "Passw0rd!", "name": "Test User"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var registerResp RegisterResponse
+	if err := json.Unmarshal([]byte(response.Body), &registerResp); err != nil || registerResp.UserSub != "user-123" {
+		t.Errorf("Expected user sub 'user-123', got %s", response.Body)
+	}
+
+	input := cognito.input
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.Username) != "test@example.com" || len(input.UserAttributes) != 2 {
+		t.Errorf("Unexpected SignUp input %v", input)
+	}
+}
+
+func TestHandler_RegisterErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+	}{
+		{cognitoidentityprovider.ErrCodeUsernameExistsException, "An account with this email already exists."},
+		{cognitoidentityprovider.ErrCodeInvalidParameterException, "Invalid input. Please check your email and password."},
+		{cognitoidentityprovider.ErrCodeCodeDeliveryFailureException, "Registration failed: CodeDeliveryFailureException: failed"},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{err: awserr.New(tt.code, "failed", nil)}
+		response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Body:       `{"email": "test@example.com", "password": "Passw0rd!", "name": "Test User"}`,
+		})
+		if err != nil {
+			t.Fatalf("%s: Handler returned error: %v", tt.code, err)
+		}
+
+		var errorResp ErrorResponse
+		json.Unmarshal([]byte(response.Body), &errorResp)
+		if response.StatusCode != 400 || errorResp.Error != tt.message {
+			t.Errorf("%s: expected 400 %q, got %d %q", tt.code, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
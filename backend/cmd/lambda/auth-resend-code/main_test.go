@@ -3,17 +3,53 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
 )
 
+// fakeCognito is an in-memory Cognito client that answers ResendConfirmationCode with output
+// or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.ResendConfirmationCodeInput
+	output *cognitoidentityprovider.ResendConfirmationCodeOutput
+	err    error
+}
+
+func (f *fakeCognito) ResendConfirmationCodeWithContext(ctx aws.Context, input *cognitoidentityprovider.ResendConfirmationCodeInput, opts ...request.Option) (*cognitoidentityprovider.ResendConfirmationCodeOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.ResendConfirmationCodeOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
 func TestHandler_OptionsRequest(t *testing.T) {
 	request := events.APIGatewayProxyRequest{
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -29,7 +65,7 @@ func TestHandler_InvalidJSON(t *testing.T) {
 		Body:       `{invalid json}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -54,7 +90,7 @@ func TestHandler_MissingEmail(t *testing.T) {
 		Body:       `{"email": ""}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -78,7 +114,7 @@ func TestHandler_CORSHeaders(t *testing.T) {
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -97,3 +133,80 @@ func TestResendCodeRequest_Struct(t *testing.T) {
 		t.Errorf("Expected email 'test@example.com', got '%s'", req.Email)
 	}
 }
+
+func TestHandler_ResendsWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("verified@example.com", "Passw0rd!")
+
+	resend := func(email string) (events.APIGatewayProxyResponse, ErrorResponse) {
+		response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Body:       `{"email": "` + email + `"}`,
+		})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		var errorResp ErrorResponse
+		json.Unmarshal([]byte(response.Body), &errorResp)
+		return response, errorResp
+	}
+
+	if response, errorResp := resend("nobody@example.com"); response.StatusCode != 400 || errorResp.Error != "No account found with this email." {
+		t.Errorf("Expected the unknown account error, got %d: %s", response.StatusCode, response.Body)
+	}
+	if response, errorResp := resend("verified@example.com"); response.StatusCode != 400 || errorResp.Error != "User is already verified." {
+		t.Errorf("Expected the already verified error, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_ResendSucceeds(t *testing.T) {
+	cognito := &fakeCognito{}
+
+	response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "test@example.com"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	input := cognito.input
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.Username) != "test@example.com" {
+		t.Errorf("Unexpected ResendConfirmationCode input %v", input)
+	}
+}
+
+func TestHandler_ResendErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+	}{
+		{cognitoidentityprovider.ErrCodeUserNotFoundException, "No account found with this email."},
+		{cognitoidentityprovider.ErrCodeInvalidParameterException, "User is already verified."},
+		{cognitoidentityprovider.ErrCodeLimitExceededException, "Too many requests. Please wait a few minutes and try again."},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{err: awserr.New(tt.code, "failed", nil)}
+		response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Body:       `{"email": "test@example.com"}`,
+		})
+		if err != nil {
+			t.Fatalf("%s: Handler returned error: %v", tt.code, err)
+		}
+
+		var errorResp ErrorResponse
+		json.Unmarshal([]byte(response.Body), &errorResp)
+		if response.StatusCode != 400 || errorResp.Error != tt.message {
+			t.Errorf("%s: expected 400 %q, got %d %q", tt.code, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+)
+
+// ResetPasswordRequest represents the request body for setting a new password
+type ResetPasswordRequest struct {
+	Email       string `json:"email"`
+	Code        string `json:"code"`
+	NewPassword string `json:"newPassword"`
+}
+
+// ResetPasswordResponse represents the response for a successful password reset
+type ResetPasswordResponse struct {
+	Message string `json:"message"`
+}
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler serves password resets. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for resetting a password with a code
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": "POST,OPTIONS",
+	}
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Parse request body
+	var resetReq ResetPasswordRequest
+	if err := json.Unmarshal([]byte(request.Body), &resetReq); err != nil {
+		errorResponse := ErrorResponse{
+			Error: "Invalid request body",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Validate required fields
+	if resetReq.Email == "" || resetReq.Code == "" || resetReq.NewPassword == "" {
+		errorResponse := ErrorResponse{
+			Error: "Email, code and new password are required",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Set the new password
+	confirmInput := &cognitoidentityprovider.ConfirmForgotPasswordInput{
+		ClientId:         aws.String(h.cfg.CognitoUserPoolClientID),
+		Username:         aws.String(resetReq.Email),
+		ConfirmationCode: aws.String(resetReq.Code),
+		Password:         aws.String(resetReq.NewPassword),
+	}
+
+	_, err := h.cognito.ConfirmForgotPasswordWithContext(ctx, confirmInput)
+	if err != nil {
+		logging.FromContext(ctx).Warn("password reset failed", "email_hash", logging.HashEmail(resetReq.Email), "error", err)
+
+		// Extract more user-friendly error messages from Cognito errors
+		errorMsg := err.Error()
+
+		// Unknown and disabled accounts look like a wrong code, so they cannot be discovered
+		if strings.Contains(errorMsg, "CodeMismatchException") ||
+			strings.Contains(errorMsg, "UserNotFoundException") ||
+			strings.Contains(errorMsg, "NotAuthorizedException") {
+			errorMsg = "Invalid reset code. Please check and try again."
+		} else if strings.Contains(errorMsg, "ExpiredCodeException") {
+			errorMsg = "Reset code has expired. Please request a new code."
+		} else if strings.Contains(errorMsg, "InvalidPasswordException") {
+			errorMsg = "Password does not meet requirements. Please use at least 8 characters with uppercase, lowercase, numbers, and special characters."
+		} else if strings.Contains(errorMsg, "LimitExceededException") || strings.Contains(errorMsg, "TooManyFailedAttemptsException") {
+			errorMsg = "Too many attempts. Please wait a few minutes and try again."
+		} else {
+			errorMsg = "Failed to reset password. Please try again."
+		}
+
+		errorResponse := ErrorResponse{
+			Error: errorMsg,
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	logging.FromContext(ctx).Info("password reset", "email_hash", logging.HashEmail(resetReq.Email))
+
+	// Create response
+	response := ResetPasswordResponse{
+		Message: "Password reset successfully. You can now sign in with your new password.",
+	}
+
+	// Marshal response to JSON
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := ErrorResponse{
+			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Return successful response
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseBody),
+		Headers:    corsHeaders,
+	}, nil
+}
+
+func main() {
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
+}
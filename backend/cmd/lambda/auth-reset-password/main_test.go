@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// fakeCognito is an in-memory Cognito client that answers ConfirmForgotPassword with output
+// or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.ConfirmForgotPasswordInput
+	output *cognitoidentityprovider.ConfirmForgotPasswordOutput
+	err    error
+}
+
+func (f *fakeCognito) ConfirmForgotPasswordWithContext(ctx aws.Context, input *cognitoidentityprovider.ConfirmForgotPasswordInput, opts ...request.Option) (*cognitoidentityprovider.ConfirmForgotPasswordOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.ConfirmForgotPasswordOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
+// post sends body to h and decodes the error, if any
+func post(t *testing.T, h *handler, body string) (events.APIGatewayProxyResponse, ErrorResponse) {
+	t.Helper()
+	response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	return response, errorResp
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "OPTIONS",
+	}
+
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+	if response.Headers["Access-Control-Allow-Methods"] != "POST,OPTIONS" {
+		t.Error("Expected CORS methods header")
+	}
+}
+
+func TestHandler_InvalidJSON(t *testing.T) {
+	response, errorResp := post(t, newTestHandler(&fakeCognito{}), `{invalid json}`)
+	if response.StatusCode != 400 || errorResp.Error != "Invalid request body" {
+		t.Errorf("Expected 400 'Invalid request body', got %d '%s'", response.StatusCode, errorResp.Error)
+	}
+}
+
+func TestHandler_MissingFields(t *testing.T) {
+	for _, body := range []string{
+		`{"code": "123456", "newPassword": "N3wPassw0rd!"}`,
+		`{"email": "test@example.com", "newPassword": "N3wPassw0rd!"}`,
+		`{"email": "test@example.com", "code": "123456"}`,
+	} {
+		response, errorResp := post(t, newTestHandler(&fakeCognito{}), body)
+		if response.StatusCode != 400 || errorResp.Error != "Email, code and new password are required" {
+			t.Errorf("%s: expected 400 for missing fields, got %d '%s'", body, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_ResetsPassword(t *testing.T) {
+	cognito := &fakeCognito{}
+
+	response, _ := post(t, newTestHandler(cognito), `{"email": "test@example.com", "code": "123456", "newPassword": "N3wPassw0rd!"}`)
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	input := cognito.input
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.Username) != "test@example.com" ||
+		aws.StringValue(input.ConfirmationCode) != "123456" || aws.StringValue(input.Password) != "N3wPassw0rd!" {
+		t.Errorf("Unexpected ConfirmForgotPassword input %v", input)
+	}
+}
+
+func TestHandler_ResetPasswordErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+	}{
+		{cognitoidentityprovider.ErrCodeCodeMismatchException, "Invalid reset code. Please check and try again."},
+		{cognitoidentityprovider.ErrCodeUserNotFoundException, "Invalid reset code. Please check and try again."},
+		{cognitoidentityprovider.ErrCodeNotAuthorizedException, "Invalid reset code. Please check and try again."},
+		{cognitoidentityprovider.ErrCodeExpiredCodeException, "Reset code has expired. Please request a new code."},
+		{cognitoidentityprovider.ErrCodeInvalidPasswordException, "Password does not meet requirements. Please use at least 8 characters with uppercase, lowercase, numbers, and special characters."},
+		{cognitoidentityprovider.ErrCodeLimitExceededException, "Too many attempts. Please wait a few minutes and try again."},
+		{cognitoidentityprovider.ErrCodeInternalErrorException, "Failed to reset password. Please try again."},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{err: awserr.New(tt.code, "failed", nil)}
+		response, errorResp := post(t, newTestHandler(cognito), `{"email": "test@example.com", "code": "123456", "newPassword": "N3wPassw0rd!"}`)
+		if response.StatusCode != 400 || errorResp.Error != tt.message {
+			t.Errorf("%s: expected 400 %q, got %d %q", tt.code, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
+
+func TestHandler_ResetsWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	pool.AddUser("jane@example.com", "Passw0rd!")
+
+	// Unknown emails look like a wrong code
+	wrong, wrongErr := post(t, h, `{"email": "jane@example.com", "code": "000000", "newPassword": "N3wPassw0rd!"}`)
+	unknown, unknownErr := post(t, h, `{"email": "nobody@example.com", "code": "000000", "newPassword": "N3wPassw0rd!"}`)
+	if wrong.StatusCode != 400 || unknown.StatusCode != 400 || wrongErr != unknownErr {
+		t.Errorf("Expected the same error for a wrong code and an unknown email, got %s and %s", wrong.Body, unknown.Body)
+	}
+
+	// Request a code, then use it
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("eu-west-2"), Endpoint: aws.String(pool.URL)}))
+	_, err = cognitoidentityprovider.New(sess).ForgotPassword(&cognitoidentityprovider.ForgotPasswordInput{
+		ClientId: aws.String("local-client"),
+		Username: aws.String("jane@example.com"),
+	})
+	if err != nil {
+		t.Fatalf("ForgotPassword returned error: %v", err)
+	}
+	response, _ := post(t, h, `{"email": "jane@example.com", "code": "`+pool.ResetCode("jane@example.com")+`", "newPassword": "N3wPassw0rd!"}`)
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+}
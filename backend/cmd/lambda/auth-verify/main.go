@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
 	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
 	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
 )
 
 // VerifyRequest represents the request body for email verification
@@ -30,8 +34,25 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-// Handler is the Lambda function handler for email verification
-func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// handler serves email verification. It is created once at cold start, so warm
+// invocations reuse its Cognito client and connections.
+type handler struct {
+	cfg     *config.Config
+	cognito cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+// newHandler creates the handler and its clients for cfg, sending their
+// requests through httpClient
+func newHandler(cfg *config.Config, httpClient *http.Client) (*handler, error) {
+	cognito, err := auth.NewCognitoClient(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{cfg: cfg, cognito: cognito}, nil
+}
+
+// Handle is the Lambda function handler for email verification
+func (h *handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// CORS headers for all responses
 	corsHeaders := map[string]string{
 		"Content-Type":                 "application/json",
@@ -48,20 +69,6 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		}, nil
 	}
 
-	// Load configuration from environment variables
-	cfg, err := config.Load()
-	if err != nil {
-		errorResponse := ErrorResponse{
-			Error: fmt.Sprintf("Failed to load configuration: %v", err),
-		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
-	}
-
 	// Parse request body
 	var verifyReq VerifyRequest
 	if err := json.Unmarshal([]byte(request.Body), &verifyReq); err != nil {
@@ -89,34 +96,17 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		}, nil
 	}
 
-	// Create AWS session
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(cfg.AWSRegion),
-	})
-	if err != nil {
-		errorResponse := ErrorResponse{
-			Error: fmt.Sprintf("Failed to create AWS session: %v", err),
-		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
-	}
-
-	// Create Cognito client
-	cognitoClient := cognitoidentityprovider.New(sess)
-
 	// Confirm sign up
 	confirmInput := &cognitoidentityprovider.ConfirmSignUpInput{
-		ClientId:         aws.String(cfg.CognitoUserPoolClientID),
+		ClientId:         aws.String(h.cfg.CognitoUserPoolClientID),
 		Username:         aws.String(verifyReq.Email),
 		ConfirmationCode: aws.String(verifyReq.Code),
 	}
 
-	_, err = cognitoClient.ConfirmSignUp(confirmInput)
+	_, err := h.cognito.ConfirmSignUpWithContext(ctx, confirmInput)
 	if err != nil {
+		logging.FromContext(ctx).Warn("verification failed", "email_hash", logging.HashEmail(verifyReq.Email), "error", err)
+
 		// Extract more user-friendly error messages from Cognito errors
 		errorMsg := err.Error()
 
@@ -141,6 +131,8 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		}, nil
 	}
 
+	logging.FromContext(ctx).Info("email verified", "email_hash", logging.HashEmail(verifyReq.Email))
+
 	// Create response
 	response := VerifyResponse{
 		Message: "Email verified successfully. You can now sign in.",
@@ -169,6 +161,17 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 }
 
 func main() {
-	// Start Lambda handler
-	lambda.Start(Handler)
+	// Create the handler once per cold start, logging at the configured LOG_LEVEL
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err != nil {
+		logger.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	h, err := newHandler(cfg, auth.NewHTTPClient())
+	if err != nil {
+		logger.Error("failed to create handler", "error", err)
+		os.Exit(1)
+	}
+	lambda.Start(logging.Wrap(logger, h.Handle))
 }
@@ -3,17 +3,55 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
 )
 
+// fakeCognito is an in-memory Cognito client that answers ConfirmSignUp with output
+// or err, and records its input. Other methods panic.
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	input  *cognitoidentityprovider.ConfirmSignUpInput
+	output *cognitoidentityprovider.ConfirmSignUpOutput
+	err    error
+}
+
+func (f *fakeCognito) ConfirmSignUpWithContext(ctx aws.Context, input *cognitoidentityprovider.ConfirmSignUpInput, opts ...request.Option) (*cognitoidentityprovider.ConfirmSignUpOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.output == nil {
+		return &cognitoidentityprovider.ConfirmSignUpOutput{}, nil
+	}
+	return f.output, nil
+}
+
+// newTestHandler returns a handler using cognito
+func newTestHandler(cognito *fakeCognito) *handler {
+	return &handler{
+		cfg:     &config.Config{CognitoUserPoolClientID: "test-client"},
+		cognito: cognito,
+	}
+}
+
 func TestHandler_OptionsRequest(t *testing.T) {
 	request := events.APIGatewayProxyRequest{
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -29,7 +67,7 @@ func TestHandler_InvalidJSON(t *testing.T) {
 		Body:       `{invalid json}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -54,7 +92,7 @@ func TestHandler_MissingEmail(t *testing.T) {
 		Body:       `{"email": "", "code": "123456"}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -70,7 +108,7 @@ func TestHandler_MissingCode(t *testing.T) {
 		Body:       `{"email": "test@example.com", "code": ""}`,
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -85,7 +123,7 @@ func TestHandler_CORSHeaders(t *testing.T) {
 		HTTPMethod: "OPTIONS",
 	}
 
-	response, err := Handler(context.Background(), request)
+	response, err := newTestHandler(&fakeCognito{}).Handle(context.Background(), request)
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -109,3 +147,97 @@ func TestVerifyRequest_Struct(t *testing.T) {
 		t.Errorf("Expected code '123456', got '%s'", req.Code)
 	}
 }
+
+func TestHandler_VerifiesWithFakeCognito(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	h, err := newHandler(&config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolClientID: "local-client"}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("newHandler returned error: %v", err)
+	}
+	signUp(t, pool.URL, "jane@example.com")
+
+	verify := func(code string) (events.APIGatewayProxyResponse, ErrorResponse) {
+		response, err := h.Handle(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Body:       `{"email": "jane@example.com", "code": "` + code + `"}`,
+		})
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		var errorResp ErrorResponse
+		json.Unmarshal([]byte(response.Body), &errorResp)
+		return response, errorResp
+	}
+
+	if response, errorResp := verify("bad-code"); response.StatusCode != 400 || !strings.HasPrefix(errorResp.Error, "Invalid verification code") {
+		t.Errorf("Expected the wrong code error, got %d: %s", response.StatusCode, response.Body)
+	}
+	if response, _ := verify(pool.Code("jane@example.com")); response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if response, errorResp := verify("123456"); response.StatusCode != 400 || !strings.HasPrefix(errorResp.Error, "User is already verified") {
+		t.Errorf("Expected the already verified error, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+// signUp registers an unconfirmed user in the fake pool
+func signUp(t *testing.T, endpoint, email string) {
+	t.Helper()
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("eu-west-2"), Endpoint: aws.String(endpoint)}))
+	_, err := cognitoidentityprovider.New(sess).SignUp(&cognitoidentityprovider.SignUpInput{
+		ClientId: aws.String("local-client"),
+		Username: aws.String(email),
+		Password: aws.String("Passw0rd!"),
+	})
+	if err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+}
+
+func TestHandler_VerifySucceeds(t *testing.T) {
+	cognito := &fakeCognito{}
+
+	response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"email": "test@example.com", "code": "123456"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	input := cognito.input
+	if aws.StringValue(input.ClientId) != "test-client" || aws.StringValue(input.Username) != "test@example.com" || aws.StringValue(input.ConfirmationCode) != "123456" {
+		t.Errorf("Unexpected ConfirmSignUp input %v", input)
+	}
+}
+
+func TestHandler_VerifyErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+	}{
+		{cognitoidentityprovider.ErrCodeCodeMismatchException, "Invalid verification code. Please check and try again."},
+		{cognitoidentityprovider.ErrCodeExpiredCodeException, "Verification code has expired. Please request a new code."},
+		{cognitoidentityprovider.ErrCodeNotAuthorizedException, "User is already verified or the code is invalid."},
+	}
+
+	for _, tt := range tests {
+		cognito := &fakeCognito{err: awserr.New(tt.code, "failed", nil)}
+		response, err := newTestHandler(cognito).Handle(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Body:       `{"email": "test@example.com", "code": "123456"}`,
+		})
+		if err != nil {
+			t.Fatalf("%s: Handler returned error: %v", tt.code, err)
+		}
+
+		var errorResp ErrorResponse
+		json.Unmarshal([]byte(response.Body), &errorResp)
+		if response.StatusCode != 400 || errorResp.Error != tt.message {
+			t.Errorf("%s: expected 400 %q, got %d %q", tt.code, tt.message, response.StatusCode, errorResp.Error)
+		}
+	}
+}
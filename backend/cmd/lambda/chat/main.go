@@ -3,16 +3,33 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
-	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/agent"
+	"tuitui-backend/internal/auth"
 	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/conversation"
+	"tuitui-backend/internal/document"
+	"tuitui-backend/internal/knowledge"
+	"tuitui-backend/internal/llm"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/prompt"
+	"tuitui-backend/internal/ratelimit"
+	"tuitui-backend/internal/redact"
+	"tuitui-backend/internal/retrieval"
+	"tuitui-backend/internal/revocation"
+	"tuitui-backend/internal/team"
+	"tuitui-backend/internal/usage"
+	"tuitui-backend/pkg/api"
 )
 
 type Response struct {
@@ -21,6 +38,19 @@ type Response struct {
 	AWSRegion   string `json:"aws_region"`
 	APIVersion  string `json:"api_version"`
 	Status      string `json:"status"`
+
+	ConversationID string `json:"conversation_id,omitempty"`
+
+	// ToolCalls records every tool the model called while answering
+	ToolCalls []agent.Call `json:"tool_calls,omitempty"`
+
+	// Usage is the tokens consumed across every model call of the turn
+	Usage *llm.Usage `json:"usage,omitempty"`
+
+	// StopReason says why the model stopped; Truncated is set when the answer was
+	// cut off at the token limit, so the UI can offer to continue it
+	StopReason string `json:"stop_reason,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -32,229 +62,944 @@ type ChatMessage struct {
 	Content string `json:"content"`
 }
 
-func callAmazonQ(messages []ChatMessage, systemPrompt string, apiKey string, modelName string, apiEndpoint string) (string, error) {
-	if apiKey == "" {
-		return "", fmt.Errorf("Amazon AI API key not configured")
+// ChatRequest represents the request body for the chat endpoint
+type ChatRequest struct {
+	Message             string        `json:"message"`
+	ConversationHistory []ChatMessage `json:"conversationHistory,omitempty"`
+	Team                string        `json:"team,omitempty"`
+	TeamInfo            []string      `json:"teamInfo,omitempty"`
+	MarkdownContent     string        `json:"markdownContent,omitempty"`
+	Stream              bool          `json:"stream,omitempty"`
+	ConversationID      string        `json:"conversationId,omitempty"`
+
+	// DocumentIDs name uploaded documents to read instead of sending markdownContent
+	DocumentIDs []string `json:"documentIds,omitempty"`
+}
+
+// chatTurn holds everything needed to answer one chat request
+type chatTurn struct {
+	chatReq       *ChatRequest
+	user          *auth.User
+	provider      llm.Provider
+	modelReq      llm.Request
+	conversations conversation.Store
+	usage         usage.Store
+	limiter       *ratelimit.Limiter
+	tools         *agent.Registry
+	agentOpts     agent.Options
+
+	// redactor holds the values replaced by placeholders in the model request,
+	// or is nil when redaction is off
+	redactor *redact.Redactor
+}
+
+// chatError is a failed chat request with the status code it should be reported with
+type chatError struct {
+	StatusCode int
+	Message    string
+}
+
+// newConversationStore creates the conversation store; tests replace it
+var newConversationStore = conversation.NewStore
+
+// newKnowledgeStore creates the knowledge base store; tests replace it
+var newKnowledgeStore = knowledge.NewStore
+
+// newUsageStore creates the token usage store; tests replace it
+var newUsageStore = usage.NewStore
+
+// newLimiter creates the per-user rate limiter; tests replace it
+var newLimiter = ratelimit.New
+
+// newRevocations opens the list of signed out sessions; tests replace it
+var newRevocations = revocation.New
+
+// newTeamStore creates the team store used to resolve the caller's team; tests replace it
+var newTeamStore = team.NewStore
+
+// newPromptStore creates the prompt template store; tests replace it
+var newPromptStore = prompt.NewStore
+
+// newDocumentLibrary creates the uploaded document library; tests replace it
+var newDocumentLibrary = document.New
+
+// newEmbedder creates the embedder used to rank document sections; tests replace it
+var newEmbedder = retrieval.NewEmbedder
+
+// httpClient fetches the user pool's signing keys for StreamHandler; it is
+// shared so warm invocations reuse its connections
+var httpClient = auth.NewHTTPClient()
+
+// maxKnowledgeEntries caps how many knowledge base entries go into one prompt
+const maxKnowledgeEntries = 5
+
+// maxDocuments caps how many uploaded documents one chat request can name
+const maxDocuments = 10
+
+// redactionNote tells the model how to treat placeholders left by redaction
+const redactionNote = "\n\nSome personal details and secrets in this conversation have been replaced with placeholders such as [EMAIL_1]. Repeat a placeholder exactly when you need to refer to its value."
+
+var (
+	errInvalidRequestBody = errors.New("Invalid request body")
+	errMessageRequired    = errors.New("Message is required")
+)
+
+// deltaEvent is the payload of a content_block_delta event sent to streaming clients.
+// It mirrors the Anthropic Messages event so clients see the same shape whichever
+// provider generated the text.
+type deltaEvent struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+}
+
+// writeDeltaEvent writes a text fragment to w as a content_block_delta event
+func writeDeltaEvent(w io.Writer, text string) error {
+	payload := deltaEvent{Type: "content_block_delta"}
+	payload.Delta.Type = "text_delta"
+	payload.Delta.Text = text
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
 	}
+	return writeSSEEvent(w, "content_block_delta", string(data))
+}
 
-	url := apiEndpoint
+// writeSSEEvent writes a single Server-Sent Event to w
+func writeSSEEvent(w io.Writer, event string, data string) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
 
-	amazonQMessages := make([]map[string]string, len(messages))
-	for i, msg := range messages {
-		amazonQMessages[i] = map[string]string{
-			"role":    msg.Role,
-			"content": msg.Content,
+// wantsEventStream reports whether the client asked for a Server-Sent Events response
+func wantsEventStream(chatReq *ChatRequest, headers map[string]string) bool {
+	if chatReq.Stream {
+		return true
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, "Accept") && strings.Contains(value, "text/event-stream") {
+			return true
 		}
 	}
+	return false
+}
+
+// parseChatRequest decodes and validates the chat request body
+func parseChatRequest(body string) (*ChatRequest, error) {
+	var chatReq ChatRequest
+	if err := json.Unmarshal([]byte(body), &chatReq); err != nil {
+		return nil, errInvalidRequestBody
+	}
+
+	if chatReq.Message == "" {
+		return nil, errMessageRequired
+	}
 
-	requestBody := map[string]interface{}{
-		"model":      modelName,
-		"max_tokens": 1000,
-		"messages":   amazonQMessages,
+	return &chatReq, nil
+}
+
+// buildSystemPrompt renders the prompt template body with relevant knowledge and
+// additional context. teamConfig is the caller's server-side team, or nil when
+// teams are not in use. A template that fails to render is logged and the
+// built-in default is used instead, so a bad template never stops a chat.
+func buildSystemPrompt(ctx context.Context, body string, chatReq *ChatRequest, user *auth.User, teamConfig *team.Team, entries []knowledge.Entry, sections []retrieval.Chunk) string {
+	data := prompt.Data{
+		Team:      chatReq.Team,
+		TeamInfo:  chatReq.TeamInfo,
+		Date:      time.Now().UTC().Format("2006-01-02"),
+		Knowledge: knowledge.FormatPrompt(entries),
+	}
+	if teamConfig != nil {
+		data.Team = teamConfig.Name
+		data.TeamInstructions = teamConfig.SystemPrompt
+	}
+	if user != nil {
+		data.UserName = user.Name
+		data.UserEmail = user.Email
+	}
+	if len(sections) > 0 {
+		data.Documents = formatSections(sections)
 	}
 
-	if systemPrompt != "" {
-		requestBody["system"] = systemPrompt
+	systemPrompt, err := prompt.Render(body, data)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to render prompt template", "error", err)
+		systemPrompt, _ = prompt.Render(prompt.DefaultBody, data)
 	}
 
-	jsonData, err := json.Marshal(requestBody)
+	return systemPrompt
+}
+
+// loadPromptTemplate returns the body of the prompt template selected by the
+// caller's team. The built-in default covers a missing table quietly and other
+// failures are only logged.
+func loadPromptTemplate(ctx context.Context, cfg *config.Config, teamConfig *team.Team) string {
+	name := ""
+	if teamConfig != nil {
+		name = teamConfig.PromptTemplate
+	}
+
+	store, err := newPromptStore(cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %v", err)
+		if !errors.Is(err, prompt.ErrNotConfigured) {
+			logging.FromContext(ctx).Error("failed to open prompt store", "error", err)
+		}
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	body, err := prompt.Load(ctx, store, name)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
+		logging.FromContext(ctx).Error("failed to load prompt template", "template", name, "error", err)
 	}
+	return body
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+// formatSections renders document chunks under their heading paths
+func formatSections(sections []retrieval.Chunk) string {
+	parts := make([]string, len(sections))
+	for i, section := range sections {
+		if section.Heading != "" {
+			parts[i] = "## " + section.Heading + "\n" + section.Text
+		} else {
+			parts[i] = section.Text
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// retrieveSections returns the chunks of the uploaded document most relevant to the message.
+// If the embedder fails the first chunks are used, so the document is never dropped entirely.
+func retrieveSections(ctx context.Context, cfg *config.Config, chatReq *ChatRequest) []retrieval.Chunk {
+	if chatReq.MarkdownContent == "" {
+		return nil
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	embedder, err := newEmbedder(cfg)
+	if err == nil {
+		var sections []retrieval.Chunk
+		sections, err = retrieval.Retrieve(ctx, embedder, chatReq.MarkdownContent, chatReq.Message, cfg.RetrievalTopK)
+		if err == nil {
+			return sections
+		}
+	}
+
+	logging.FromContext(ctx).Warn("failed to retrieve document sections", "error", err)
+	sections := retrieval.ChunkMarkdown(chatReq.MarkdownContent, retrieval.DefaultChunkSize)
+	if len(sections) > cfg.RetrievalTopK {
+		sections = sections[:cfg.RetrievalTopK]
+	}
+	return sections
+}
+
+// loadKnowledge returns the knowledge base entries relevant to the message.
+// The knowledge base only enriches the prompt, so failures are logged and skipped.
+func loadKnowledge(ctx context.Context, store knowledge.Store, message string) []knowledge.Entry {
+	if store == nil {
+		return nil
+	}
+
+	entries, err := store.List(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to call Amazon API: %v", err)
+		logging.FromContext(ctx).Error("failed to load knowledge base", "error", err)
+		return nil
+	}
+
+	return knowledge.Relevant(entries, message, maxKnowledgeEntries)
+}
+
+func loadHistory(ctx context.Context, cfg *config.Config, chatReq *ChatRequest, user *auth.User) ([]ChatMessage, conversation.Store, *chatError) {
+	if chatReq.ConversationID == "" {
+		return chatReq.ConversationHistory, nil, nil
+	}
+
+	if user == nil {
+		return nil, nil, &chatError{401, "Authentication required to use conversations"}
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	store, err := newConversationStore(cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %v", err)
+		return nil, nil, &chatError{500, fmt.Sprintf("Failed to create conversation store: %v", err)}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("AmazonQ API error: %s", string(body))
+	stored, err := store.ListMessages(ctx, user.Sub, chatReq.ConversationID)
+	if errors.Is(err, conversation.ErrNotFound) {
+		return nil, nil, &chatError{404, "Conversation not found"}
+	}
+	if err != nil {
+		return nil, nil, &chatError{500, fmt.Sprintf("Failed to load conversation: %v", err)}
 	}
 
-	var response map[string]interface{}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+	history := make([]ChatMessage, len(stored))
+	for i, msg := range stored {
+		history[i] = ChatMessage{Role: msg.Role, Content: msg.Content}
 	}
 
-	if content, ok := response["content"].([]interface{}); ok && len(content) > 0 {
-		if textBlock, ok := content[0].(map[string]interface{}); ok {
-			if text, ok := textBlock["text"].(string); ok {
-				return text, nil
-			}
+	return history, store, nil
+}
+
+// resolveTeam looks up the team the caller's chat runs as and applies its runbook
+// links and default documents to chatReq. It returns nil when the caller has no
+// team, or when no teams table is configured and the client's team is used as sent.
+func resolveTeam(ctx context.Context, cfg *config.Config, chatReq *ChatRequest, user *auth.User) (*team.Team, *chatError) {
+	if user == nil {
+		return nil, nil
+	}
+
+	store, err := newTeamStore(cfg)
+	if errors.Is(err, team.ErrNotConfigured) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &chatError{500, fmt.Sprintf("Failed to create team store: %v", err)}
+	}
+
+	resolved, err := team.Resolve(ctx, store, user.Sub, chatReq.Team)
+	if errors.Is(err, team.ErrNotMember) {
+		return nil, &chatError{403, "You are not a member of the requested team"}
+	}
+	if err != nil {
+		return nil, &chatError{500, fmt.Sprintf("Failed to resolve team: %v", err)}
+	}
+
+	// The stored team replaces whatever team details the client sent
+	chatReq.Team = ""
+	chatReq.TeamInfo = nil
+	if resolved == nil {
+		return nil, nil
+	}
+	chatReq.Team = resolved.ID
+	chatReq.TeamInfo = resolved.Links
+	if chatReq.MarkdownContent == "" && len(chatReq.DocumentIDs) == 0 {
+		chatReq.MarkdownContent = teamDocuments(resolved.Documents)
+	}
+	return resolved, nil
+}
+
+// teamDocuments joins a team's default documents into one markdown document
+func teamDocuments(documents []team.Document) string {
+	parts := make([]string, 0, len(documents))
+	for _, doc := range documents {
+		parts = append(parts, formatDocument(doc.Name, doc.Content))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// formatDocument renders a document under its name as a top-level heading
+func formatDocument(name, content string) string {
+	if name == "" {
+		return content
+	}
+	return "# " + name + "\n\n" + content
+}
+
+// loadDocuments adds the caller's uploaded documents named in chatReq.DocumentIDs
+// to the markdown the answer is drawn from
+func loadDocuments(ctx context.Context, cfg *config.Config, chatReq *ChatRequest, user *auth.User) *chatError {
+	if len(chatReq.DocumentIDs) == 0 {
+		return nil
+	}
+	if len(chatReq.DocumentIDs) > maxDocuments {
+		return &chatError{400, fmt.Sprintf("At most %d documents can be used at once", maxDocuments)}
+	}
+
+	if user == nil {
+		return &chatError{401, "Authentication required to use documents"}
+	}
+
+	library, err := newDocumentLibrary(cfg)
+	if err != nil {
+		return &chatError{500, fmt.Sprintf("Failed to create document library: %v", err)}
+	}
+
+	var parts []string
+	if chatReq.MarkdownContent != "" {
+		parts = append(parts, chatReq.MarkdownContent)
+	}
+	for _, id := range chatReq.DocumentIDs {
+		version, content, err := library.Content(ctx, user.Sub, id, 0)
+		if errors.Is(err, document.ErrNotFound) {
+			return &chatError{404, fmt.Sprintf("Document %s not found", id)}
+		}
+		if err != nil {
+			return &chatError{500, fmt.Sprintf("Failed to load document: %v", err)}
 		}
+		parts = append(parts, formatDocument(version.Name, content))
 	}
 
-	return "No response from AmazonQ", nil
+	chatReq.MarkdownContent = strings.Join(parts, "\n\n")
+	return nil
 }
 
-// Handler is the Lambda function handler
-func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	// CORS headers for all responses
-	corsHeaders := map[string]string{
-		"Content-Type":                 "application/json",
-		"Access-Control-Allow-Origin":  "*",
-		"Access-Control-Allow-Headers": "Content-Type,Authorization",
-		"Access-Control-Allow-Methods": "POST,OPTIONS",
+// prepareChat loads history and builds the model request for a validated chat request.
+// teamConfig's model settings override the configured model.
+func prepareChat(ctx context.Context, cfg *config.Config, chatReq *ChatRequest, user *auth.User, teamConfig *team.Team) (*chatTurn, *chatError) {
+	history, store, chatErr := loadHistory(ctx, cfg, chatReq, user)
+	if chatErr != nil {
+		return nil, chatErr
 	}
 
-	// Handle OPTIONS preflight request
-	if request.HTTPMethod == "OPTIONS" {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 200,
-			Headers:    corsHeaders,
-		}, nil
+	if chatErr := loadDocuments(ctx, cfg, chatReq, user); chatErr != nil {
+		return nil, chatErr
 	}
 
-	// Load configuration from environment variables
-	cfg, err := config.Load()
+	maxTokens := cfg.AIMaxTokens
+	if teamConfig != nil {
+		if teamConfig.Model.Model != "" {
+			teamCfg := *cfg
+			teamCfg.AIModelName = teamConfig.Model.Model
+			cfg = &teamCfg
+		}
+		if teamConfig.Model.MaxTokens > 0 {
+			maxTokens = teamConfig.Model.MaxTokens
+		}
+	}
+
+	// Create the model provider selected in configuration
+	provider, err := llm.New(cfg)
+	if err != nil {
+		return nil, &chatError{500, fmt.Sprintf("Failed to create AI provider: %v", err)}
+	}
+
+	kb, err := newKnowledgeStore(cfg)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to open knowledge base", "error", err)
+	}
+
+	// Personal data and secrets are replaced before anything is logged or sent
+	// to the model; run restores them in the reply
+	var redactor *redact.Redactor
+	if cfg.RedactPII {
+		redactor = redact.New()
+	}
+	chatReq.MarkdownContent = redactor.Redact(chatReq.MarkdownContent)
+	messages := buildMessages(ctx, history, chatReq.Message, redactor)
+
+	// The rendered prompt holds the caller's email, team instructions and knowledge
+	system := buildSystemPrompt(ctx, loadPromptTemplate(ctx, cfg, teamConfig), chatReq, user, teamConfig, loadKnowledge(ctx, kb, chatReq.Message), retrieveSections(ctx, cfg, chatReq))
+	system = redactor.Redact(system)
+	if redactor.Len() > 0 {
+		system += redactionNote
+	}
+
+	return &chatTurn{
+		chatReq:  chatReq,
+		user:     user,
+		provider: provider,
+		modelReq: llm.Request{
+			System:    system,
+			Messages:  messages,
+			MaxTokens: maxTokens,
+		},
+		conversations: store,
+		usage:         openUsage(ctx, cfg),
+		tools:         buildTools(chatReq, kb),
+		agentOpts: agent.Options{
+			MaxIterations:    cfg.AgentMaxIterations,
+			MaxContinuations: cfg.AgentMaxContinuations,
+		},
+		redactor: redactor,
+	}, nil
+}
+
+// openUsage opens the usage store. Chat still works without one, so a missing
+// table is skipped quietly and other failures are only logged.
+func openUsage(ctx context.Context, cfg *config.Config) usage.Store {
+	store, err := newUsageStore(cfg)
 	if err != nil {
-		errorResponse := ErrorResponse{
-			Error: fmt.Sprintf("Failed to load configuration: %v", err),
+		if !errors.Is(err, usage.ErrNotConfigured) {
+			logging.FromContext(ctx).Error("failed to open usage store", "error", err)
 		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
+		return nil
+	}
+	return store
+}
+
+// checkSession refuses callers whose session was signed out, as verifying a token
+// only proves it has not expired. Unlike the rate limiter, a failed
+// check refuses the request, since the token may have been revoked.
+func checkSession(ctx context.Context, cfg *config.Config, request events.APIGatewayProxyRequest) *chatError {
+	session, ok := revocation.SessionFromRequest(request)
+	if !ok {
+		return nil
 	}
 
-	// Parse request body
-	var chatReq struct {
-		Message             string        `json:"message"`
-		ConversationHistory []ChatMessage `json:"conversationHistory,omitempty"`
-		Team                string        `json:"team,omitempty"`
-		TeamInfo            []string      `json:"teamInfo,omitempty"`
-		MarkdownContent     string        `json:"markdownContent,omitempty"`
+	revocations, err := newRevocations(cfg)
+	if errors.Is(err, revocation.ErrNotConfigured) {
+		return nil
 	}
-	if err := json.Unmarshal([]byte(request.Body), &chatReq); err != nil {
-		errorResponse := ErrorResponse{
-			Error: "Invalid request body",
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to open revoked sessions", "error", err)
+		return &chatError{StatusCode: 500, Message: "Failed to check session"}
+	}
+
+	revoked, err := revocations.Revoked(ctx, session)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to check session", "error", err)
+		return &chatError{StatusCode: 500, Message: "Failed to check session"}
+	}
+	if revoked {
+		return &chatError{StatusCode: 401, Message: "Session has been signed out. Please sign in again."}
+	}
+	return nil
+}
+
+// openLimiter opens the rate limiter, or returns nil when rate limiting is off
+func openLimiter(ctx context.Context, cfg *config.Config) *ratelimit.Limiter {
+	limiter, err := newLimiter(cfg)
+	if err != nil {
+		if !errors.Is(err, ratelimit.ErrNotConfigured) {
+			logging.FromContext(ctx).Error("failed to open rate limiter", "error", err)
 		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 400,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
+		return nil
 	}
+	return limiter
+}
 
-	// Validate required fields
-	if chatReq.Message == "" {
-		errorResponse := ErrorResponse{
-			Error: "Message is required",
+// checkRateLimit counts the request against the caller's limits and adds the
+// rate limit headers to headers. It returns false when the caller must wait.
+// Team overrides only apply to teamConfig, the team resolveTeam checked the
+// caller belongs to; the team named by the client is never trusted.
+// Requests are let through if the limiter fails, so its outage does not stop chat.
+func checkRateLimit(ctx context.Context, limiter *ratelimit.Limiter, teamConfig *team.Team, user *auth.User, headers map[string]string) bool {
+	if limiter == nil || user == nil {
+		return true
+	}
+
+	var teamID string
+	if teamConfig != nil {
+		teamID = teamConfig.ID
+	}
+	decision, err := limiter.Allow(ctx, user.Sub, teamID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to check rate limit", "error", err)
+		return true
+	}
+
+	var names []string
+	for name, value := range decision.Headers() {
+		headers[name] = value
+		names = append(names, name)
+	}
+	if len(names) > 0 {
+		// Browsers only show cross-origin scripts the headers listed here
+		sort.Strings(names)
+		headers["Access-Control-Expose-Headers"] = strings.Join(names, ",")
+	}
+
+	return decision.Allowed
+}
+
+// startChat runs the checks every chat entry point applies before the model is
+// called: signed out sessions are refused, the caller's team is resolved from
+// their memberships and their rate limits are checked, adding the rate limit
+// headers to headers. It then builds the turn. request carries the caller's
+// claims, from the Cognito authorizer or streamRequest.
+func startChat(ctx context.Context, cfg *config.Config, request events.APIGatewayProxyRequest, chatReq *ChatRequest, headers map[string]string) (*chatTurn, *chatError) {
+	user, _ := auth.UserFromRequest(request)
+	if chatErr := checkSession(ctx, cfg, request); chatErr != nil {
+		return nil, chatErr
+	}
+
+	// Teams are resolved from the caller's memberships, not trusted from the client
+	teamConfig, chatErr := resolveTeam(ctx, cfg, chatReq, user)
+	if chatErr != nil {
+		return nil, chatErr
+	}
+
+	// Refuse callers over their limits before any work is done for them
+	limiter := openLimiter(ctx, cfg)
+	if !checkRateLimit(ctx, limiter, teamConfig, user, headers) {
+		return nil, &chatError{429, "Rate limit exceeded, please try again later"}
+	}
+
+	turn, chatErr := prepareChat(ctx, cfg, chatReq, user, teamConfig)
+	if chatErr != nil {
+		return nil, chatErr
+	}
+	turn.limiter = limiter
+	return turn, nil
+}
+
+// streamRequest verifies the ID token in the Authorization header of a
+// Function URL request, which has no Cognito authorizer, and returns a request
+// carrying its claims as the authorizer would
+func streamRequest(ctx context.Context, cfg *config.Config, headers map[string]string) (events.APIGatewayProxyRequest, *chatError) {
+	var token string
+	for key, value := range headers {
+		if strings.EqualFold(key, "Authorization") {
+			token = value
 		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 400,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
+	}
+	if token == "" {
+		return events.APIGatewayProxyRequest{}, &chatError{401, "Authentication required"}
 	}
 
-	// Build system prompt with additional context
-	var systemParts []string
+	claims, err := auth.NewVerifier(cfg, httpClient).Verify(ctx, token)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return events.APIGatewayProxyRequest{}, &chatError{401, "Invalid or expired token. Please sign in again."}
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to verify token", "error", err)
+		return events.APIGatewayProxyRequest{}, &chatError{500, "Failed to verify token"}
+	}
 
-	if chatReq.Team != "" {
-		systemParts = append(systemParts, "Team: "+chatReq.Team)
+	return events.APIGatewayProxyRequest{
+		Headers: headers,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"claims": claims},
+		},
+	}, nil
+}
+
+// buildTools registers the tools the model may call for this request
+func buildTools(chatReq *ChatRequest, kb knowledge.Store) *agent.Registry {
+	tools := agent.NewRegistry()
+	if kb != nil {
+		tools.Register(agent.NewKnowledgeSearch(kb))
 	}
 	if len(chatReq.TeamInfo) > 0 {
-		systemParts = append(systemParts, "Team Information: "+strings.Join(chatReq.TeamInfo, ", "))
+		tools.Register(agent.NewRunbookLinks(chatReq.TeamInfo))
 	}
-	if chatReq.MarkdownContent != "" {
-		systemParts = append(systemParts, "Additional context from uploaded document:\n"+chatReq.MarkdownContent)
+	return tools
+}
+
+// run answers the turn, executing any tools the model calls and continuing a
+// reply cut off at the token limit. When onDelta is set the text of every model
+// call is streamed through it. Redacted values are restored in the streamed
+// text and the final reply.
+func (t *chatTurn) run(ctx context.Context, onDelta func(text string) error) (*agent.Result, error) {
+	opts := t.agentOpts
+	opts.OnDelta = onDelta
+
+	var restorer *redact.Restorer
+	if onDelta != nil && t.redactor != nil {
+		restorer = t.redactor.Restorer(onDelta)
+		opts.OnDelta = restorer.Write
+	}
+
+	result, err := agent.Run(ctx, t.provider, t.modelReq, t.tools, opts)
+	if err != nil {
+		return nil, err
+	}
+	if restorer != nil {
+		if err := restorer.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Response.Text = t.redactor.Restore(result.Response.Text)
+	return result, nil
+}
+
+// save appends the user's message and the reply to the stored conversation. A
+// failure is logged rather than returned so the user still gets their answer.
+func (t *chatTurn) save(ctx context.Context, reply *llm.Response) {
+	if t.conversations == nil {
+		return
+	}
+
+	err := t.conversations.AppendMessages(ctx, t.user.Sub, t.chatReq.ConversationID,
+		conversation.Message{Role: "user", Content: t.chatReq.Message},
+		conversation.Message{Role: "assistant", Content: newResponse(reply).Message},
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to save conversation", "conversation_id", t.chatReq.ConversationID, "error", err)
+	}
+}
+
+// record attributes the tokens the turn used to the caller and their team, and
+// counts them against the caller's daily allowance. A failure is logged rather
+// than returned so the user still gets their answer.
+func (t *chatTurn) record(ctx context.Context, result *agent.Result) {
+	if t.limiter != nil && t.user != nil {
+		if err := t.limiter.Consume(ctx, t.user.Sub, result.Usage.InputTokens+result.Usage.OutputTokens); err != nil {
+			logging.FromContext(ctx).Error("failed to count tokens against rate limit", "error", err)
+		}
+	}
+
+	if t.usage == nil {
+		return
 	}
 
-	// Build the complete system prompt with base knowledge
-	systemPrompt := "You are a helpful assistant for the TuiTui team.\n\n"
-	systemPrompt += "KNOWLEDGE BASE - Use this information when answering questions:\n"
-	systemPrompt += "• For questions about USL 502 errors: Contact 'Rhydian Downing' who wrote the USL section of the documentation. Documentation available at: https://runway.devops.tui/docs/default/component/flightsearchresults/#mfe-search-results\n\n"
+	event := usage.Event{
+		Team:         t.chatReq.Team,
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+		Time:         time.Now(),
+	}
+	if t.user != nil {
+		event.UserID = t.user.Sub
+	}
 
-	if len(systemParts) > 0 {
-		systemPrompt += "ADDITIONAL CONTEXT:\n" + strings.Join(systemParts, "\n\n")
+	if err := t.usage.Record(ctx, event); err != nil {
+		logging.FromContext(ctx).Error("failed to record usage", "error", err)
 	}
+}
 
-	// Build messages array with conversation history and new message
-	var messages []ChatMessage
-	if len(chatReq.ConversationHistory) > 0 {
-		messages = append(messages, chatReq.ConversationHistory...)
+// response wraps the result in the chat response envelope
+func (t *chatTurn) response(result *agent.Result) Response {
+	response := newResponse(result.Response)
+	response.ConversationID = t.chatReq.ConversationID
+	response.ToolCalls = result.Calls
+	response.Usage = &result.Usage
+	response.StopReason = result.Response.StopReason
+	response.Truncated = result.Truncated
+	return response
+}
+
+// buildMessages builds the messages array with conversation history and the new
+// message, redacted by redactor so neither the logs nor the model see the values
+func buildMessages(ctx context.Context, history []ChatMessage, message string, redactor *redact.Redactor) []llm.Message {
+	var messages []llm.Message
+	for _, msg := range history {
+		messages = append(messages, llm.Message{
+			Role:    msg.Role,
+			Content: redactor.Redact(msg.Content),
+		})
 	}
-	messages = append(messages, ChatMessage{
+	messages = append(messages, llm.Message{
 		Role:    "user",
-		Content: chatReq.Message,
+		Content: redactor.Redact(message),
 	})
 
-	// Log for debugging
-	fmt.Printf("Conversation history received: %d messages\n", len(chatReq.ConversationHistory))
-	fmt.Printf("Total messages being sent to AmazonQ: %d\n", len(messages))
+	logger := logging.FromContext(ctx)
+	logger.Debug("built model messages", "history", len(history), "messages", len(messages))
 	for i, msg := range messages {
-		fmt.Printf("Message %d [%s]: %s\n", i, msg.Role, msg.Content[:min(50, len(msg.Content))])
+		logger.Debug("model message", "index", i, "role", msg.Role, "preview", msg.Content[:min(50, len(msg.Content))])
 	}
 
-	// Get API key from environment
-	apiKey := os.Getenv("AMAZON_AI_API_KEY")
+	return messages
+}
 
-	amazonQResponse, err := callAmazonQ(messages, systemPrompt, apiKey, cfg.AIModelName, cfg.AIAPIEndpoint)
-	if err != nil {
-		errorResponse := ErrorResponse{
-			Error: fmt.Sprintf("Failed to get response from AmazonQ: %v", err),
-		}
-		errorBody, _ := json.Marshal(errorResponse)
-		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       string(errorBody),
-			Headers:    corsHeaders,
-		}, nil
+// modelError returns the status code and message for a failed model call.
+// Outages that retries could not ride out are reported as 503 so clients can retry later.
+func modelError(err error) (int, string) {
+	if llm.IsUnavailable(err) {
+		return 503, "The AI service is temporarily unavailable, please try again shortly"
 	}
+	return 500, fmt.Sprintf("Failed to get response from AmazonQ: %v", err)
+}
 
-	// Create response
-	response := Response{
-		Message:     amazonQResponse,
+// newResponse wraps the assistant reply in the chat response envelope
+func newResponse(reply *llm.Response) Response {
+	message := reply.Text
+	if message == "" {
+		message = "No response from AmazonQ"
+	}
+
+	return Response{
+		Message:     message,
 		Environment: "development",
 		AWSRegion:   "eu-west-2",
 		APIVersion:  "v1",
 		Status:      "OK",
 	}
+}
+
+// Handler is the Lambda function handler
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("POST,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
 
-	// Marshal response to JSON
-	responseBody, err := json.Marshal(response)
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	// Parse and validate request body
+	chatReq, err := parseChatRequest(request.Body)
 	if err != nil {
-		errorResponse := ErrorResponse{
-			Error: fmt.Sprintf("Failed to marshal response: %v", err),
+		return api.Error(400, err.Error(), corsHeaders), nil
+	}
+
+	// The Cognito authorizer identifies the caller when the route requires auth
+	turn, chatErr := startChat(ctx, cfg, request, chatReq, corsHeaders)
+	if chatErr != nil {
+		return api.Error(chatErr.StatusCode, chatErr.Message, corsHeaders), nil
+	}
+
+	// Streaming clients get the deltas as Server-Sent Events. API Gateway buffers the
+	// proxy response, so the events arrive together; use StreamHandler behind a
+	// Function URL for true incremental delivery.
+	if wantsEventStream(chatReq, request.Headers) {
+		var sseBody bytes.Buffer
+		result, err := turn.run(ctx, func(text string) error {
+			return writeDeltaEvent(&sseBody, text)
+		})
+		if err != nil {
+			status, message := modelError(err)
+			return api.Error(status, message, corsHeaders), nil
 		}
-		errorBody, _ := json.Marshal(errorResponse)
+
+		turn.save(ctx, result.Response)
+		turn.record(ctx, result)
+
+		doneBody, _ := json.Marshal(turn.response(result))
+		writeSSEEvent(&sseBody, "done", string(doneBody))
+
+		corsHeaders["Content-Type"] = "text/event-stream"
+		corsHeaders["Cache-Control"] = "no-cache"
+
 		return events.APIGatewayProxyResponse{
-			StatusCode: 500,
-			Body:       string(errorBody),
+			StatusCode: 200,
+			Body:       sseBody.String(),
 			Headers:    corsHeaders,
 		}, nil
 	}
 
+	result, err := turn.run(ctx, nil)
+	if err != nil {
+		status, message := modelError(err)
+		return api.Error(status, message, corsHeaders), nil
+	}
+
+	turn.save(ctx, result.Response)
+	turn.record(ctx, result)
+
 	// Return successful response
-	return events.APIGatewayProxyResponse{
+	return api.JSON(200, turn.response(result), corsHeaders), nil
+}
+
+// StreamHandler is the Lambda function handler for Function URLs configured with
+// RESPONSE_STREAM. Deltas are written to the client as Server-Sent Events while the
+// model is still generating; clients that did not ask for a stream get the JSON body.
+// Callers send their ID token as they would to API Gateway, and get the same
+// team, rate limit and conversation handling as Handler.
+func StreamHandler(ctx context.Context, request events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("POST,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.RequestContext.HTTP.Method == "OPTIONS" {
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return streamError(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	body := request.Body
+	if request.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return streamError(400, errInvalidRequestBody.Error(), corsHeaders), nil
+		}
+		body = string(decoded)
+	}
+
+	// Parse and validate request body
+	chatReq, err := parseChatRequest(body)
+	if err != nil {
+		return streamError(400, err.Error(), corsHeaders), nil
+	}
+
+	// The Function URL is public, so the caller's ID token is checked here
+	authorized, chatErr := streamRequest(ctx, cfg, request.Headers)
+	if chatErr != nil {
+		return streamError(chatErr.StatusCode, chatErr.Message, corsHeaders), nil
+	}
+	if user, ok := auth.UserFromRequest(authorized); ok {
+		// Only now is the user known, so their sub joins the request's log lines
+		ctx = logging.NewContext(ctx, logging.FromContext(ctx).With("sub", user.Sub))
+	}
+
+	turn, chatErr := startChat(ctx, cfg, authorized, chatReq, corsHeaders)
+	if chatErr != nil {
+		return streamError(chatErr.StatusCode, chatErr.Message, corsHeaders), nil
+	}
+
+	// Non-streaming clients keep getting the JSON body
+	if !wantsEventStream(chatReq, request.Headers) {
+		result, err := turn.run(ctx, nil)
+		if err != nil {
+			status, message := modelError(err)
+			return streamError(status, message, corsHeaders), nil
+		}
+
+		turn.save(ctx, result.Response)
+		turn.record(ctx, result)
+
+		responseBody, _ := json.Marshal(turn.response(result))
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: 200,
+			Body:       bytes.NewReader(responseBody),
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	corsHeaders["Content-Type"] = "text/event-stream"
+	corsHeaders["Cache-Control"] = "no-cache"
+
+	// The status code has been sent by the time the model fails, so errors are
+	// reported to the client as an "error" event at the end of the stream.
+	reader, writer := io.Pipe()
+	go func() {
+		defer writer.Close()
+
+		result, err := turn.run(ctx, func(text string) error {
+			return writeDeltaEvent(writer, text)
+		})
+		if err != nil {
+			_, message := modelError(err)
+			errorBody, _ := json.Marshal(ErrorResponse{
+				Error: message,
+			})
+			writeSSEEvent(writer, "error", string(errorBody))
+			return
+		}
+
+		turn.save(ctx, result.Response)
+		turn.record(ctx, result)
+
+		doneBody, _ := json.Marshal(turn.response(result))
+		writeSSEEvent(writer, "done", string(doneBody))
+	}()
+
+	return &events.LambdaFunctionURLStreamingResponse{
 		StatusCode: 200,
-		Body:       string(responseBody),
+		Body:       reader,
 		Headers:    corsHeaders,
 	}, nil
 }
 
+// streamError returns an ErrorResponse body from the streaming handler
+func streamError(statusCode int, message string, headers map[string]string) *events.LambdaFunctionURLStreamingResponse {
+	errorBody, _ := json.Marshal(ErrorResponse{
+		Error: message,
+	})
+
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: statusCode,
+		Body:       bytes.NewReader(errorBody),
+		Headers:    headers,
+	}
+}
+
 func main() {
-	// Start Lambda handler
-	lambda.Start(Handler)
+	// Start Lambda handler. The Function URL deployment sets CHAT_RESPONSE_STREAMING
+	// so responses are streamed instead of buffered by API Gateway.
+	cfg, err := config.Load()
+	logger := logging.New(cfg)
+	if err == nil && cfg.ChatResponseStreaming {
+		lambda.Start(logging.WrapStream(logger, StreamHandler))
+		return
+	}
+	lambda.Start(logging.Wrap(logger, Handler))
 }
@@ -1,11 +1,35 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/conversation"
+	"tuitui-backend/internal/document"
+	"tuitui-backend/internal/fakecognito"
+	"tuitui-backend/internal/knowledge"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/mockllm"
+	"tuitui-backend/internal/prompt"
+	"tuitui-backend/internal/ratelimit"
+	"tuitui-backend/internal/retrieval"
+	"tuitui-backend/internal/revocation"
+	"tuitui-backend/internal/team"
+	"tuitui-backend/internal/usage"
 )
 
 func TestHandler_OptionsRequest(t *testing.T) {
@@ -141,3 +165,1242 @@ func TestChatMessage_Struct(t *testing.T) {
 		t.Errorf("Expected content 'Hello', got '%s'", msg.Content)
 	}
 }
+
+// newStreamingServer returns a stand-in model API that streams the given text deltas
+func newStreamingServer(t *testing.T, deltas []string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode model request: %v", err)
+		}
+		if body["stream"] != true {
+			t.Errorf("Expected stream to be requested, got %v", body["stream"])
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+		for _, delta := range deltas {
+			data, _ := json.Marshal(map[string]interface{}{
+				"type":  "content_block_delta",
+				"index": 0,
+				"delta": map[string]string{"type": "text_delta", "text": delta},
+			})
+			fmt.Fprintf(w, "event: content_block_delta\ndata: %s\n\n", data)
+		}
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHandler_StreamingResponse(t *testing.T) {
+	server := newStreamingServer(t, []string{"Hello", ", world"})
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Hi", "stream": true}`,
+	}
+
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if response.Headers["Content-Type"] != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, got '%s'", response.Headers["Content-Type"])
+	}
+
+	var deltas int
+	var done Response
+	for _, block := range strings.Split(strings.TrimSpace(response.Body), "\n\n") {
+		lines := strings.SplitN(block, "\n", 2)
+		if len(lines) != 2 {
+			t.Fatalf("Malformed event: %q", block)
+		}
+		data := strings.TrimPrefix(lines[1], "data: ")
+		switch lines[0] {
+		case "event: content_block_delta":
+			deltas++
+		case "event: done":
+			if err := json.Unmarshal([]byte(data), &done); err != nil {
+				t.Fatalf("Failed to parse done event: %v", err)
+			}
+		}
+	}
+
+	if deltas != 2 {
+		t.Errorf("Expected 2 delta events, got %d", deltas)
+	}
+
+	if done.Message != "Hello, world" || done.Status != "OK" {
+		t.Errorf("Expected final event with full message, got %+v", done)
+	}
+}
+
+func TestHandler_AcceptEventStreamHeader(t *testing.T) {
+	server := newStreamingServer(t, []string{"Hi"})
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Headers:    map[string]string{"accept": "text/event-stream"},
+		Body:       `{"message": "Hi"}`,
+	}
+
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.Headers["Content-Type"] != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, got '%s'", response.Headers["Content-Type"])
+	}
+}
+
+// streamToken points the Cognito settings at a fake pool and returns the sub
+// and ID token of a user signed in to it, for calling StreamHandler
+func streamToken(t *testing.T) (string, string) {
+	t.Helper()
+	pool := fakecognito.NewTestServer(t, "eu-west-2_chat", "chat-client")
+	t.Setenv("COGNITO_ENDPOINT", pool.URL)
+	t.Setenv("COGNITO_USER_POOL_ID", "eu-west-2_chat")
+	t.Setenv("COGNITO_USER_POOL_CLIENT_ID", "chat-client")
+
+	sub := pool.AddUser("jane@example.com", "Passw0rd!")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	cognito, err := auth.NewCognitoClient(cfg, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewCognitoClient returned error: %v", err)
+	}
+	out, err := cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String("chat-client"),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	return sub, aws.StringValue(out.AuthenticationResult.IdToken)
+}
+
+// streamRequestWith returns a Function URL POST request sending token
+func streamRequestWith(token, body string) events.LambdaFunctionURLRequest {
+	request := events.LambdaFunctionURLRequest{
+		Headers: map[string]string{"authorization": "Bearer " + token},
+		Body:    body,
+	}
+	request.RequestContext.HTTP.Method = "POST"
+	return request
+}
+
+func TestStreamHandler_StreamsDeltas(t *testing.T) {
+	server := newStreamingServer(t, []string{"Streaming", " works"})
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	sub, token := streamToken(t)
+
+	var logs bytes.Buffer
+	ctx := logging.NewContext(context.Background(), slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	response, err := StreamHandler(ctx, streamRequestWith(token, `{"message": "Hi", "stream": true}`))
+	if err != nil {
+		t.Fatalf("StreamHandler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+
+	if strings.Count(string(body), "event: content_block_delta") != 2 {
+		t.Errorf("Expected 2 delta events, got body: %s", body)
+	}
+
+	if !strings.Contains(string(body), `"message":"Streaming works"`) {
+		t.Errorf("Expected done event with full message, got body: %s", body)
+	}
+
+	// Lines logged once the token is verified name the user
+	if !strings.Contains(logs.String(), `"msg":"built model messages","sub":"`+sub+`"`) {
+		t.Errorf("Expected the user's sub in the request's log lines, got:\n%s", logs.String())
+	}
+}
+
+func TestStreamHandler_EmptyMessage(t *testing.T) {
+	_, token := streamToken(t)
+
+	response, err := StreamHandler(context.Background(), streamRequestWith(token, `{"message": ""}`))
+	if err != nil {
+		t.Fatalf("StreamHandler returned error: %v", err)
+	}
+
+	if response.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", response.StatusCode)
+	}
+}
+
+func TestStreamHandler_RequiresValidToken(t *testing.T) {
+	server := newStreamingServer(t, []string{"Never sent"})
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	_, token := streamToken(t)
+
+	missing := streamRequestWith("", `{"message": "Hi", "stream": true}`)
+	missing.Headers = nil
+
+	tests := []struct {
+		name    string
+		request events.LambdaFunctionURLRequest
+	}{
+		{"missing", missing},
+		{"forged", streamRequestWith(token[:strings.LastIndex(token, ".")]+".forged", `{"message": "Hi", "stream": true}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := StreamHandler(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("StreamHandler returned error: %v", err)
+			}
+			if response.StatusCode != 401 {
+				t.Errorf("Expected status 401, got %d", response.StatusCode)
+			}
+		})
+	}
+}
+
+func TestStreamHandler_SavesConversation(t *testing.T) {
+	server := newStreamingServer(t, []string{"Streaming", " works"})
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	sub, token := streamToken(t)
+
+	store := conversation.NewMemoryStore()
+	useConversationStore(t, store)
+	conv, _ := store.CreateConversation(context.Background(), sub, "")
+
+	response, err := StreamHandler(context.Background(), streamRequestWith(token, fmt.Sprintf(`{"message": "Hi", "stream": true, "conversationId": %q}`, conv.ID)))
+	if err != nil {
+		t.Fatalf("StreamHandler returned error: %v", err)
+	}
+	if _, err := io.ReadAll(response.Body); err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+
+	messages, _ := store.ListMessages(context.Background(), sub, conv.ID)
+	if len(messages) != 2 || messages[0].Content != "Hi" || messages[1].Content != "Streaming works" {
+		t.Errorf("Expected the turn to be saved, got %+v", messages)
+	}
+}
+
+func TestHandler_UnknownProvider(t *testing.T) {
+	t.Setenv("AI_PROVIDER", "carrier-pigeon")
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Hello"}`,
+	}
+
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 500 {
+		t.Errorf("Expected status 500, got %d", response.StatusCode)
+	}
+}
+
+// modelRequest is the part of the model API request body the tests inspect
+type modelRequest struct {
+	System   string        `json:"system"`
+	Messages []ChatMessage `json:"messages"`
+}
+
+// newModelServer returns a stand-in model API that replies with text and passes
+// every decoded request to inspect
+func newModelServer(t *testing.T, text string, inspect func(modelRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body modelRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode model request: %v", err)
+		}
+		if inspect != nil {
+			inspect(body)
+		}
+
+		reply, _ := json.Marshal(map[string]interface{}{
+			"content":     []map[string]string{{"type": "text", "text": text}},
+			"stop_reason": "end_turn",
+			"usage":       map[string]int{"input_tokens": 10, "output_tokens": 5},
+		})
+		w.Write(reply)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// useConversationStore makes the handler use store for the duration of the test
+func useConversationStore(t *testing.T, store conversation.Store) {
+	t.Helper()
+	original := newConversationStore
+	newConversationStore = func(cfg *config.Config) (conversation.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newConversationStore = original })
+}
+
+// authorizedRequest returns a POST request carrying Cognito claims for sub
+func authorizedRequest(sub string, body string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{"sub": sub},
+			},
+		},
+	}
+}
+
+func TestHandler_ConversationRequiresAuth(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Hi", "conversationId": "abc"}`,
+	}
+
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ConversationNotFound(t *testing.T) {
+	useConversationStore(t, conversation.NewMemoryStore())
+
+	response, err := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hi", "conversationId": "missing"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ConversationHistoryFromStore(t *testing.T) {
+	store := conversation.NewMemoryStore()
+	useConversationStore(t, store)
+
+	ctx := context.Background()
+	conv, _ := store.CreateConversation(ctx, "user-1", "")
+	store.AppendMessages(ctx, "user-1", conv.ID,
+		conversation.Message{Role: "user", Content: "First question"},
+		conversation.Message{Role: "assistant", Content: "First answer"},
+	)
+
+	var sent modelRequest
+	server := newModelServer(t, "Second answer", func(body modelRequest) { sent = body })
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	// The client-supplied history must be ignored in favour of the stored one
+	body := fmt.Sprintf(`{"message": "Second question", "conversationId": "%s", "conversationHistory": [{"role": "user", "content": "forged"}]}`, conv.ID)
+	response, err := Handler(ctx, authorizedRequest("user-1", body))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if len(sent.Messages) != 3 || sent.Messages[0].Content != "First question" {
+		t.Errorf("Expected stored history to be sent, got %+v", sent.Messages)
+	}
+
+	var chatResp Response
+	json.Unmarshal([]byte(response.Body), &chatResp)
+	if chatResp.ConversationID != conv.ID {
+		t.Errorf("Expected conversation_id '%s', got '%s'", conv.ID, chatResp.ConversationID)
+	}
+
+	stored, _ := store.ListMessages(ctx, "user-1", conv.ID)
+	if len(stored) != 4 || stored[3].Content != "Second answer" {
+		t.Errorf("Expected the new turn to be saved, got %+v", stored)
+	}
+}
+
+// useKnowledgeStore makes the handler use store for the duration of the test
+func useKnowledgeStore(t *testing.T, store knowledge.Store) {
+	t.Helper()
+	original := newKnowledgeStore
+	newKnowledgeStore = func(cfg *config.Config) (knowledge.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newKnowledgeStore = original })
+}
+
+func TestHandler_RelevantKnowledgeInSystemPrompt(t *testing.T) {
+	useKnowledgeStore(t, knowledge.NewMemoryStore(
+		knowledge.Entry{Title: "USL 502 errors", Body: "Contact Rhydian Downing.", Tags: []string{"usl", "502"}},
+		knowledge.Entry{Title: "Deploy freeze", Body: "No deploys on Fridays.", Tags: []string{"deploy"}},
+	))
+
+	var sent modelRequest
+	server := newModelServer(t, "Ask Rhydian", func(body modelRequest) { sent = body })
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Who owns USL 502s?"}`,
+	}
+
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if !strings.Contains(sent.System, "USL 502 errors: Contact Rhydian Downing.") {
+		t.Errorf("Expected the USL entry in the system prompt, got:\n%s", sent.System)
+	}
+	if strings.Contains(sent.System, "Deploy freeze") {
+		t.Errorf("Expected unrelated entries to be left out, got:\n%s", sent.System)
+	}
+}
+
+func TestHandler_DocumentSectionsRetrieved(t *testing.T) {
+	var sent modelRequest
+	server := newModelServer(t, "Use terraform apply", func(body modelRequest) { sent = body })
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	t.Setenv("RETRIEVAL_TOP_K", "1")
+
+	doc := "# Runbook\n\n## Deployments\n\nRun terraform apply after make build.\n\n## Holidays\n\nBook annual leave in the HR portal.\n"
+	body, _ := json.Marshal(ChatRequest{Message: "How do I deploy with terraform?", MarkdownContent: doc})
+
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "POST", Body: string(body)})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if !strings.Contains(sent.System, "## Runbook > Deployments\nRun terraform apply") {
+		t.Errorf("Expected the deployments section in the system prompt, got:\n%s", sent.System)
+	}
+	if strings.Contains(sent.System, "annual leave") {
+		t.Errorf("Expected unrelated sections to be left out, got:\n%s", sent.System)
+	}
+}
+
+func TestRetrieveSections_EmbedderFailureFallsBack(t *testing.T) {
+	original := newEmbedder
+	newEmbedder = func(cfg *config.Config) (retrieval.Embedder, error) {
+		return nil, fmt.Errorf("embedder unavailable")
+	}
+	t.Cleanup(func() { newEmbedder = original })
+
+	doc := "# One\n\nFirst.\n\n# Two\n\nSecond.\n\n# Three\n\nThird.\n"
+	sections := retrieveSections(context.Background(), &config.Config{RetrievalTopK: 2}, &ChatRequest{Message: "third", MarkdownContent: doc})
+
+	if len(sections) != 2 || sections[0].Heading != "One" || sections[1].Heading != "Two" {
+		t.Errorf("Expected the first two sections as a fallback, got %+v", sections)
+	}
+}
+
+func TestHandler_ToolUseLoop(t *testing.T) {
+	useKnowledgeStore(t, knowledge.NewMemoryStore(
+		knowledge.Entry{Title: "Deploy freeze", Body: "No deploys on Fridays.", Tags: []string{"deploy"}},
+	))
+
+	replies := []string{
+		`{"content":[{"type":"tool_use","id":"tu_1","name":"search_knowledge_base","input":{"query":"deploy"}}],"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":4}}`,
+		`{"content":[{"type":"text","text":"Not on Fridays"}],"stop_reason":"end_turn","usage":{"input_tokens":30,"output_tokens":4}}`,
+	}
+	var requests []map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		fmt.Fprint(w, replies[len(requests)-1])
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Can I release today?", "teamInfo": ["https://example.com/runbooks/release"]}`,
+	}
+
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 model calls, got %d", len(requests))
+	}
+
+	var tools []struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(requests[0]["tools"], &tools)
+	if len(tools) != 2 || tools[0].Name != "search_knowledge_base" || tools[1].Name != "find_runbook_link" {
+		t.Errorf("Expected knowledge and runbook tools, got %+v", tools)
+	}
+	if !strings.Contains(string(requests[1]["messages"]), `"type":"tool_result","tool_use_id":"tu_1","content":"KNOWLEDGE BASE`) {
+		t.Errorf("Expected the tool result in the second request, got %s", requests[1]["messages"])
+	}
+
+	var chatResp Response
+	json.Unmarshal([]byte(response.Body), &chatResp)
+	if chatResp.Message != "Not on Fridays" {
+		t.Errorf("Expected final answer, got '%s'", chatResp.Message)
+	}
+	if len(chatResp.ToolCalls) != 1 || chatResp.ToolCalls[0].Name != "search_knowledge_base" || !strings.Contains(chatResp.ToolCalls[0].Output, "No deploys on Fridays.") {
+		t.Errorf("Expected the tool call in the response metadata, got %+v", chatResp.ToolCalls)
+	}
+}
+
+func TestHandler_ModelOverloadedReturns503(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"overloaded_error"}}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	t.Setenv("AI_MAX_RETRIES", "0")
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Hello"}`,
+	}
+
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 503 {
+		t.Errorf("Expected status 503, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func useUsageStore(t *testing.T, store usage.Store) {
+	t.Helper()
+	original := newUsageStore
+	newUsageStore = func(cfg *config.Config) (usage.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newUsageStore = original })
+}
+
+func TestHandler_RecordsUsage(t *testing.T) {
+	store := usage.NewMemoryStore()
+	useUsageStore(t, store)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	response, err := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello", "team": "Search"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var chatResp Response
+	json.Unmarshal([]byte(response.Body), &chatResp)
+	if chatResp.Usage == nil || chatResp.Usage.InputTokens != 12 || chatResp.Usage.OutputTokens != 3 {
+		t.Errorf("Expected usage in the response, got %+v", chatResp.Usage)
+	}
+
+	today := usage.PeriodKey(usage.PeriodDay, time.Now())
+	for _, query := range []usage.Query{
+		{Subject: usage.SubjectUser, ID: "user-1", Period: usage.PeriodDay, From: today, To: today},
+		{Subject: usage.SubjectTeam, ID: "search", Period: usage.PeriodDay, From: today, To: today},
+	} {
+		aggregates, _ := store.Aggregates(context.Background(), query)
+		if len(aggregates) != 1 || aggregates[0].TotalTokens != 15 || aggregates[0].Requests != 1 {
+			t.Errorf("Expected 15 tokens recorded for %s %s, got %+v", query.Subject, query.ID, aggregates)
+		}
+	}
+}
+
+func useLimiter(t *testing.T, policy ratelimit.Policy) {
+	t.Helper()
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), policy)
+	original := newLimiter
+	newLimiter = func(cfg *config.Config) (*ratelimit.Limiter, error) {
+		return limiter, nil
+	}
+	t.Cleanup(func() { newLimiter = original })
+}
+
+func TestHandler_RateLimited(t *testing.T) {
+	useLimiter(t, ratelimit.Policy{
+		Default: ratelimit.Limits{RequestsPerMinute: 1},
+		Teams:   map[string]ratelimit.Limits{"search": {RequestsPerMinute: 5}},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	first, _ := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello"}`))
+	if first.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", first.StatusCode, first.Body)
+	}
+	if first.Headers["X-RateLimit-Remaining-Requests"] != "0" || first.Headers["Access-Control-Expose-Headers"] == "" {
+		t.Errorf("Expected rate limit headers on the allowed response, got %v", first.Headers)
+	}
+
+	second, _ := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello again"}`))
+	if second.StatusCode != 429 {
+		t.Fatalf("Expected status 429, got %d", second.StatusCode)
+	}
+	if second.Headers["Retry-After"] == "" || second.Headers["X-RateLimit-Limit-Requests"] != "1" {
+		t.Errorf("Expected Retry-After and X-RateLimit headers, got %v", second.Headers)
+	}
+
+	useTeamStore(t, seededTeams(t))
+	team, _ := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello", "team": "search"}`))
+	if team.StatusCode != 200 {
+		t.Errorf("Expected the team override to allow the request, got %d", team.StatusCode)
+	}
+}
+
+func TestHandler_RateLimitIgnoresUnresolvedTeams(t *testing.T) {
+	useLimiter(t, ratelimit.Policy{
+		Default: ratelimit.Limits{RequestsPerMinute: 1},
+		Teams:   map[string]ratelimit.Limits{"search": {RequestsPerMinute: 5}},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	// Without a team store the client's team is not checked, so it gets the defaults
+	first, _ := Handler(context.Background(), authorizedRequest("user-2", `{"message": "Hello", "team": "search"}`))
+	if first.StatusCode != 200 || first.Headers["X-RateLimit-Limit-Requests"] != "1" {
+		t.Fatalf("Expected the default limits, got %d %v", first.StatusCode, first.Headers)
+	}
+	second, _ := Handler(context.Background(), authorizedRequest("user-2", `{"message": "Hello", "team": "search"}`))
+	if second.StatusCode != 429 {
+		t.Errorf("Expected an unchecked team not to lift the limit, got %d", second.StatusCode)
+	}
+
+	// With one, a user without memberships naming the team chats without it
+	useTeamStore(t, seededTeams(t))
+	other, _ := Handler(context.Background(), authorizedRequest("user-3", `{"message": "Hello", "team": "search"}`))
+	if other.StatusCode != 200 || other.Headers["X-RateLimit-Limit-Requests"] != "1" {
+		t.Errorf("Expected a user without teams to get the default limits, got %d %v", other.StatusCode, other.Headers)
+	}
+}
+
+func TestHandler_TokenQuotaExhausted(t *testing.T) {
+	useLimiter(t, ratelimit.Policy{Default: ratelimit.Limits{TokensPerDay: 10}})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	first, _ := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello"}`))
+	if first.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", first.StatusCode, first.Body)
+	}
+
+	second, _ := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello again"}`))
+	if second.StatusCode != 429 || second.Headers["X-RateLimit-Remaining-Tokens"] != "0" {
+		t.Errorf("Expected the spent token quota to return 429, got %d %v", second.StatusCode, second.Headers)
+	}
+}
+
+func TestHandler_TruncatedReply(t *testing.T) {
+	var maxTokens []float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		maxTokens = append(maxTokens, body["max_tokens"].(float64))
+		if len(maxTokens) == 1 {
+			fmt.Fprint(w, `{"content":[{"type":"text","text":"Deploys are "}],"stop_reason":"max_tokens"}`)
+			return
+		}
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"frozen on Fridays."}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	t.Setenv("AI_MAX_TOKENS", "64")
+
+	request := events.APIGatewayProxyRequest{HTTPMethod: "POST", Body: `{"message": "Can I deploy?"}`}
+
+	response, _ := Handler(context.Background(), request)
+	var chatResp Response
+	json.Unmarshal([]byte(response.Body), &chatResp)
+	if chatResp.Message != "Deploys are frozen on Fridays." || chatResp.Truncated || chatResp.StopReason != "end_turn" {
+		t.Errorf("Expected the reply to be continued, got %+v", chatResp)
+	}
+	if len(maxTokens) != 2 || maxTokens[0] != 64 {
+		t.Errorf("Expected two calls limited to 64 tokens, got %v", maxTokens)
+	}
+
+	// Without continuations the cut-off reply is flagged for the UI
+	maxTokens = nil
+	t.Setenv("AGENT_MAX_CONTINUATIONS", "0")
+	response, _ = Handler(context.Background(), request)
+	chatResp = Response{}
+	json.Unmarshal([]byte(response.Body), &chatResp)
+	if chatResp.Message != "Deploys are " || !chatResp.Truncated || chatResp.StopReason != "max_tokens" {
+		t.Errorf("Expected a truncated reply, got %+v", chatResp)
+	}
+}
+
+// useTeamStore makes the handler resolve teams from store for the duration of the test
+func useTeamStore(t *testing.T, store team.Store) {
+	t.Helper()
+	original := newTeamStore
+	newTeamStore = func(cfg *config.Config) (team.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newTeamStore = original })
+}
+
+// seededTeams returns a store with the search team, which user-1 belongs to, and the payments team
+func seededTeams(t *testing.T) team.Store {
+	store := team.NewMemoryStore(
+		team.Team{
+			ID:           "search",
+			Name:         "Search",
+			SystemPrompt: "Quote the search SLOs when asked about latency.",
+			Links:        []string{"https://runbooks.example.com/search"},
+			Documents:    []team.Document{{Name: "SLOs", Content: "p99 latency must stay under 300ms."}},
+			Model:        team.ModelSettings{Model: "claude-team-model", MaxTokens: 512},
+		},
+		team.Team{ID: "payments", Name: "Payments"},
+	)
+	if _, err := store.PutMember(context.Background(), team.Member{TeamID: "search", UserID: "user-1", Role: team.RoleMember}); err != nil {
+		t.Fatalf("PutMember returned error: %v", err)
+	}
+	return store
+}
+
+func TestHandler_TeamResolvedServerSide(t *testing.T) {
+	useTeamStore(t, seededTeams(t))
+	store := usage.NewMemoryStore()
+	useUsageStore(t, store)
+
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Under 300ms"}],"stop_reason":"end_turn","usage":{"input_tokens":4,"output_tokens":2}}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	// The client's team details are ignored in favour of the caller's membership
+	body := `{"message": "What is our latency target?", "teamInfo": ["https://elsewhere.example.com"]}`
+	response, err := Handler(context.Background(), authorizedRequest("user-1", body))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	system, _ := sent["system"].(string)
+	for _, want := range []string{"Team: Search", "Quote the search SLOs", "p99 latency must stay under 300ms."} {
+		if !strings.Contains(system, want) {
+			t.Errorf("Expected %q in the system prompt, got:\n%s", want, system)
+		}
+	}
+	if strings.Contains(system, "elsewhere.example.com") {
+		t.Errorf("Expected the client's team info to be dropped, got:\n%s", system)
+	}
+	if sent["model"] != "claude-team-model" || sent["max_tokens"] != float64(512) {
+		t.Errorf("Expected the team's model settings, got %v and %v", sent["model"], sent["max_tokens"])
+	}
+
+	today := usage.PeriodKey(usage.PeriodDay, time.Now())
+	aggregates, _ := store.Aggregates(context.Background(), usage.Query{Subject: usage.SubjectTeam, ID: "search", Period: usage.PeriodDay, From: today, To: today})
+	if len(aggregates) != 1 || aggregates[0].TotalTokens != 6 {
+		t.Errorf("Expected usage recorded against the resolved team, got %+v", aggregates)
+	}
+}
+
+func TestHandler_NotTeamMember(t *testing.T) {
+	useTeamStore(t, seededTeams(t))
+
+	response, err := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello", "team": "Payments"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 403 {
+		t.Errorf("Expected status 403, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_TeamLabelWithoutMemberships(t *testing.T) {
+	useTeamStore(t, seededTeams(t))
+
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	// Older clients send the team name saved in the browser
+	response, err := Handler(context.Background(), authorizedRequest("user-3", `{"message": "Hello", "team": "Syntax Swing"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	system, _ := sent["system"].(string)
+	if strings.Contains(system, "Syntax Swing") {
+		t.Errorf("Expected the unchecked team name to be dropped, got:\n%s", system)
+	}
+}
+
+// usePromptStore makes the handler load prompt templates from store for the duration of the test
+func usePromptStore(t *testing.T, store prompt.Store) {
+	t.Helper()
+	original := newPromptStore
+	newPromptStore = func(cfg *config.Config) (prompt.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newPromptStore = original })
+}
+
+func TestHandler_TeamPromptTemplate(t *testing.T) {
+	teams := seededTeams(t)
+	search, _ := teams.Get(context.Background(), "search")
+	search.PromptTemplate = "search"
+	teams.Update(context.Background(), *search)
+	useTeamStore(t, teams)
+
+	prompts := prompt.NewMemoryStore()
+	if _, err := prompt.Create(context.Background(), prompts, "search", "", "Helping {{.UserName}} of {{.Team}} on {{.Date}}. {{.TeamInstructions}}", "admin"); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	usePromptStore(t, prompts)
+
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	request := authorizedRequest("user-1", `{"message": "Hello"}`)
+	request.RequestContext.Authorizer["claims"].(map[string]interface{})["name"] = "Ada"
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	want := "Helping Ada of Search on " + time.Now().UTC().Format("2006-01-02") + ". Quote the search SLOs when asked about latency."
+	if sent["system"] != want {
+		t.Errorf("Expected the team's template, got:\n%v", sent["system"])
+	}
+}
+
+func TestBuildSystemPrompt_FallsBackOnRenderError(t *testing.T) {
+	chatReq := &ChatRequest{Message: "Hi", Team: "Payments"}
+
+	// Index out of range only fails when the template is executed
+	got := buildSystemPrompt(context.Background(), `{{index .TeamInfo 5}}`, chatReq, nil, nil, nil, nil)
+
+	want := "You are a helpful assistant for the TuiTui team.\n\nADDITIONAL CONTEXT:\nTeam: Payments"
+	if got != want {
+		t.Errorf("Expected the built-in default, got %q", got)
+	}
+}
+
+// useDocumentLibrary makes the handler read documents from library for the duration of the test
+func useDocumentLibrary(t *testing.T, library *document.Library) {
+	t.Helper()
+	original := newDocumentLibrary
+	newDocumentLibrary = func(cfg *config.Config) (*document.Library, error) {
+		return library, nil
+	}
+	t.Cleanup(func() { newDocumentLibrary = original })
+}
+
+func TestHandler_DocumentIDs(t *testing.T) {
+	library := document.NewLibrary(document.NewMemoryStore(), document.NewFileBlobs(t.TempDir()))
+	useDocumentLibrary(t, library)
+	doc, _, _ := library.Upload(context.Background(), "user-1", "Release notes", "## Deployments\n\nDeploys need a change ticket.")
+
+	var sent modelRequest
+	server := newModelServer(t, "Raise a ticket", func(body modelRequest) { sent = body })
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	body, _ := json.Marshal(ChatRequest{Message: "What do deploys need?", DocumentIDs: []string{doc.ID}})
+	response, err := Handler(context.Background(), authorizedRequest("user-1", string(body)))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if !strings.Contains(sent.System, "Deploys need a change ticket.") {
+		t.Errorf("Expected the document in the system prompt, got:\n%s", sent.System)
+	}
+
+	// Documents are private to their owner
+	response, _ = Handler(context.Background(), authorizedRequest("user-2", string(body)))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 for another user's document, got %d", response.StatusCode)
+	}
+
+	response, _ = Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "POST", Body: string(body)})
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401 without a user, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_RedactsBeforeModel(t *testing.T) {
+	store := conversation.NewMemoryStore()
+	useConversationStore(t, store)
+	ctx := context.Background()
+	conv, _ := store.CreateConversation(ctx, "user-1", "")
+
+	var sent modelRequest
+	server := newModelServer(t, "I have emailed [EMAIL_2] about [BOOKING_REF_1].", func(body modelRequest) { sent = body })
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	message := "Booking ref 12345678 for jane@example.com is stuck"
+	body := fmt.Sprintf(`{"message": %q, "conversationId": %q, "markdownContent": "Escalate to ops@example.com"}`, message, conv.ID)
+	response, err := Handler(ctx, authorizedRequest("user-1", body))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if got := sent.Messages[len(sent.Messages)-1].Content; got != "Booking ref [BOOKING_REF_1] for [EMAIL_2] is stuck" {
+		t.Errorf("Expected the message to be redacted, got %q", got)
+	}
+	if strings.Contains(sent.System, "ops@example.com") || !strings.Contains(sent.System, "Escalate to [EMAIL_1]") || !strings.Contains(sent.System, "placeholders") {
+		t.Errorf("Expected the document to be redacted and the placeholders explained, got:\n%s", sent.System)
+	}
+
+	var chatResp Response
+	json.Unmarshal([]byte(response.Body), &chatResp)
+	if chatResp.Message != "I have emailed jane@example.com about 12345678." {
+		t.Errorf("Expected the reply to be restored, got %q", chatResp.Message)
+	}
+
+	// The user's own conversation keeps the real values
+	stored, _ := store.ListMessages(ctx, "user-1", conv.ID)
+	if len(stored) != 2 || stored[0].Content != message || stored[1].Content != chatResp.Message {
+		t.Errorf("Expected the original values to be saved, got %+v", stored)
+	}
+}
+
+func TestHandler_RedactsSystemPrompt(t *testing.T) {
+	teams := seededTeams(t)
+	search, _ := teams.Get(context.Background(), "search")
+	search.PromptTemplate = "search"
+	search.SystemPrompt = "Page oncall@example.com for outages."
+	teams.Update(context.Background(), *search)
+	useTeamStore(t, teams)
+
+	prompts := prompt.NewMemoryStore()
+	if _, err := prompt.Create(context.Background(), prompts, "search", "", "Helping {{.UserName}} <{{.UserEmail}}>. {{.TeamInstructions}}", "admin"); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	usePromptStore(t, prompts)
+
+	var sent modelRequest
+	server := newModelServer(t, "OK", func(body modelRequest) { sent = body })
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	request := authorizedRequest("user-1", `{"message": "Hello"}`)
+	claims := request.RequestContext.Authorizer["claims"].(map[string]interface{})
+	claims["name"] = "Ada"
+	claims["email"] = "ada@example.com"
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if strings.Contains(sent.System, "@example.com") || !strings.Contains(sent.System, "<[EMAIL_1]>") || !strings.Contains(sent.System, "placeholders") {
+		t.Errorf("Expected the emails in the system prompt to be redacted, got:\n%s", sent.System)
+	}
+}
+
+func TestHandler_RedactionDisabled(t *testing.T) {
+	var sent modelRequest
+	server := newModelServer(t, "OK", func(body modelRequest) { sent = body })
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	t.Setenv("REDACT_PII", "false")
+
+	response, err := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Mail jane@example.com"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+	if got := sent.Messages[0].Content; got != "Mail jane@example.com" {
+		t.Errorf("Expected the message unchanged, got %q", got)
+	}
+}
+
+func TestHandler_StreamRestoresSplitPlaceholders(t *testing.T) {
+	server := newStreamingServer(t, []string{"Card [CA", "RD_1] was ", "refunded"})
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Refund 4111 1111 1111 1111", "stream": true}`,
+	}
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	var streamed strings.Builder
+	var done Response
+	for _, block := range strings.Split(strings.TrimSpace(response.Body), "\n\n") {
+		lines := strings.SplitN(block, "\n", 2)
+		data := strings.TrimPrefix(lines[1], "data: ")
+		switch lines[0] {
+		case "event: content_block_delta":
+			var delta struct {
+				Delta struct {
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			json.Unmarshal([]byte(data), &delta)
+			streamed.WriteString(delta.Delta.Text)
+		case "event: done":
+			json.Unmarshal([]byte(data), &done)
+		}
+	}
+
+	want := "Card 4111 1111 1111 1111 was refunded"
+	if streamed.String() != want || done.Message != want {
+		t.Errorf("Expected %q streamed and in the done event, got %q and %q", want, streamed.String(), done.Message)
+	}
+}
+
+func TestHandler_MockModelStreamsToolUseTurn(t *testing.T) {
+	useKnowledgeStore(t, knowledge.NewMemoryStore(
+		knowledge.Entry{Title: "Deploy freeze", Body: "No deploys on Fridays.", Tags: []string{"deploy"}},
+	))
+	model := mockllm.NewTestServer(t,
+		mockllm.ToolUse("tu_1", "search_knowledge_base", map[string]string{"query": "deploy"}),
+		mockllm.Reply{Text: "Not on Fridays.", Chunks: []string{"Not on ", "Fridays."}},
+	)
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", model.URL)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Can I deploy today?", "stream": true}`,
+	}
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	requests := model.Requests()
+	if len(requests) != 2 || !requests[0].Stream {
+		t.Fatalf("Expected 2 streamed model calls, got %+v", requests)
+	}
+	last := requests[1].Messages[len(requests[1].Messages)-1].Blocks()
+	if len(last) != 1 || last[0].Type != "tool_result" || !strings.Contains(last[0].Content, "No deploys on Fridays.") {
+		t.Errorf("Expected the tool result in the second call, got %+v", last)
+	}
+
+	var done Response
+	for _, block := range strings.Split(strings.TrimSpace(response.Body), "\n\n") {
+		if strings.HasPrefix(block, "event: done\n") {
+			json.Unmarshal([]byte(strings.TrimPrefix(strings.SplitN(block, "\n", 2)[1], "data: ")), &done)
+		}
+	}
+	if done.Message != "Not on Fridays." || len(done.ToolCalls) != 1 {
+		t.Errorf("Expected the final answer and the tool call, got %+v", done)
+	}
+	if done.Usage == nil || done.Usage.InputTokens == 0 || done.Usage.OutputTokens == 0 {
+		t.Errorf("Expected usage from both calls, got %+v", done.Usage)
+	}
+}
+
+func TestHandler_MockModelRetriesTransientFailures(t *testing.T) {
+	model := mockllm.NewTestServer(t,
+		mockllm.Failure(429),
+		mockllm.Failure(529),
+		mockllm.Text("Recovered"),
+	)
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", model.URL)
+	t.Setenv("AI_MAX_RETRIES", "2")
+
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Body:       `{"message": "Hello"}`,
+	})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 || !strings.Contains(response.Body, "Recovered") {
+		t.Errorf("Expected the reply after two retries, got %d: %s", response.StatusCode, response.Body)
+	}
+	if len(model.Requests()) != 3 {
+		t.Errorf("Expected 3 model calls, got %d", len(model.Requests()))
+	}
+}
+
+func useRevocations(t *testing.T, revocations *revocation.List) {
+	t.Helper()
+	original := newRevocations
+	newRevocations = func(cfg *config.Config) (*revocation.List, error) {
+		return revocations, nil
+	}
+	t.Cleanup(func() { newRevocations = original })
+}
+
+// failingRevocationStore is a revocation store whose reads fail
+type failingRevocationStore struct {
+	revocation.Store
+}
+
+func (failingRevocationStore) Get(ctx context.Context, key string) (time.Time, error) {
+	return time.Time{}, fmt.Errorf("table unavailable")
+}
+
+func TestHandler_RevokedSession(t *testing.T) {
+	revocations := revocation.NewList(revocation.NewMemoryStore())
+	revocations.RevokeUser(context.Background(), "user-1")
+	useRevocations(t, revocations)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+
+	response, _ := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello"}`))
+	if response.StatusCode != 401 {
+		t.Fatalf("Expected status 401 for a signed out session, got %d: %s", response.StatusCode, response.Body)
+	}
+	var errorResp ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	if errorResp.Error != "Session has been signed out. Please sign in again." {
+		t.Errorf("Unexpected error %q", errorResp.Error)
+	}
+
+	other, _ := Handler(context.Background(), authorizedRequest("user-2", `{"message": "Hello"}`))
+	if other.StatusCode != 200 {
+		t.Errorf("Expected other users to keep chatting, got %d: %s", other.StatusCode, other.Body)
+	}
+}
+
+func TestStreamHandler_RevokedSession(t *testing.T) {
+	server := newStreamingServer(t, []string{"Never sent"})
+	t.Setenv("AMAZON_AI_API_KEY", "test-key")
+	t.Setenv("AI_API_ENDPOINT", server.URL)
+	_, token := streamToken(t)
+
+	cfg, _ := config.Load()
+	claims, err := auth.NewVerifier(cfg, http.DefaultClient).Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	revocations := revocation.NewList(revocation.NewMemoryStore())
+	revocations.RevokeSession(context.Background(), revocation.Session{OriginJTI: claims["origin_jti"].(string)})
+	useRevocations(t, revocations)
+
+	response, err := StreamHandler(context.Background(), streamRequestWith(token, `{"message": "Hi", "stream": true}`))
+	if err != nil {
+		t.Fatalf("StreamHandler returned error: %v", err)
+	}
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401 for a signed out session, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_SessionCheckFails(t *testing.T) {
+	useRevocations(t, revocation.NewList(failingRevocationStore{}))
+
+	response, _ := Handler(context.Background(), authorizedRequest("user-1", `{"message": "Hello"}`))
+	if response.StatusCode != 500 {
+		t.Errorf("Expected status 500 when sessions cannot be checked, got %d: %s", response.StatusCode, response.Body)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/conversation"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/pkg/api"
+)
+
+// Response represents the response for listing a conversation's messages
+type Response struct {
+	ConversationID string                 `json:"conversation_id"`
+	Messages       []conversation.Message `json:"messages"`
+}
+
+// newStore creates the conversation store; tests replace it with an in-memory store
+var newStore = conversation.NewStore
+
+// Handler is the Lambda function handler for GET /conversations/{id}/messages
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Conversations belong to the authenticated Cognito user
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	id := request.PathParameters["id"]
+	if id == "" {
+		return api.Error(400, "Conversation ID is required", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create conversation store: %v", err), corsHeaders), nil
+	}
+
+	messages, err := store.ListMessages(ctx, user.Sub, id)
+	if errors.Is(err, conversation.ErrNotFound) {
+		return api.Error(404, "Conversation not found", corsHeaders), nil
+	}
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to list messages: %v", err), corsHeaders), nil
+	}
+
+	// Return successful response
+	return api.JSON(200, Response{ConversationID: id, Messages: messages}, corsHeaders), nil
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/conversation"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store conversation.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (conversation.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// newRequest returns a GET request for the conversation id from the authenticated user sub
+func newRequest(sub, id string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": id},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{"sub": sub},
+			},
+		},
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ListMessages(t *testing.T) {
+	store := conversation.NewMemoryStore()
+	useStore(t, store)
+
+	ctx := context.Background()
+	conv, _ := store.CreateConversation(ctx, "user-1", "")
+	store.AppendMessages(ctx, "user-1", conv.ID,
+		conversation.Message{Role: "user", Content: "Hello"},
+		conversation.Message{Role: "assistant", Content: "Hi there"},
+	)
+
+	response, err := Handler(ctx, newRequest("user-1", conv.ID))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var resp Response
+	if err := json.Unmarshal([]byte(response.Body), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if resp.ConversationID != conv.ID || len(resp.Messages) != 2 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestHandler_NotFound(t *testing.T) {
+	useStore(t, conversation.NewMemoryStore())
+
+	response, _ := Handler(context.Background(), newRequest("user-1", "missing"))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/conversation"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/pkg/api"
+)
+
+// CreateConversationRequest represents the request body for creating a conversation
+type CreateConversationRequest struct {
+	Title string `json:"title"`
+}
+
+// ListResponse represents the response for listing conversations
+type ListResponse struct {
+	Conversations []conversation.Conversation `json:"conversations"`
+}
+
+// MessageResponse represents a response that only carries a message
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// newStore creates the conversation store; tests replace it with an in-memory store
+var newStore = conversation.NewStore
+
+// Handler is the Lambda function handler for /conversations and /conversations/{id}
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,POST,DELETE,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Conversations belong to the authenticated Cognito user
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create conversation store: %v", err), corsHeaders), nil
+	}
+
+	id := request.PathParameters["id"]
+
+	switch {
+	case id == "" && request.HTTPMethod == "GET":
+		conversations, err := store.ListConversations(ctx, user.Sub)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list conversations: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, ListResponse{Conversations: conversations}, corsHeaders), nil
+
+	case id == "" && request.HTTPMethod == "POST":
+		var createReq CreateConversationRequest
+		if request.Body != "" {
+			if err := json.Unmarshal([]byte(request.Body), &createReq); err != nil {
+				return api.Error(400, "Invalid request body", corsHeaders), nil
+			}
+		}
+
+		conv, err := store.CreateConversation(ctx, user.Sub, createReq.Title)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to create conversation: %v", err), corsHeaders), nil
+		}
+		return api.JSON(201, conv, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "GET":
+		conv, err := store.GetConversation(ctx, user.Sub, id)
+		if errors.Is(err, conversation.ErrNotFound) {
+			return api.Error(404, "Conversation not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to get conversation: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, conv, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "DELETE":
+		err := store.DeleteConversation(ctx, user.Sub, id)
+		if errors.Is(err, conversation.ErrNotFound) {
+			return api.Error(404, "Conversation not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to delete conversation: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, MessageResponse{Message: "Conversation deleted"}, corsHeaders), nil
+	}
+
+	return api.Error(405, "Method not allowed", corsHeaders), nil
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/conversation"
+	"tuitui-backend/pkg/api"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store conversation.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (conversation.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// newRequest returns a request from the authenticated user sub
+func newRequest(method, sub, id, body string) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{"sub": sub},
+			},
+		},
+	}
+	if id != "" {
+		request.PathParameters = map[string]string{"id": id}
+	}
+	return request
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	if response.Headers["Access-Control-Allow-Methods"] != "GET,POST,DELETE,OPTIONS" {
+		t.Errorf("Unexpected CORS methods header: %s", response.Headers["Access-Control-Allow-Methods"])
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_StoreNotConfigured(t *testing.T) {
+	response, err := Handler(context.Background(), newRequest("GET", "user-1", "", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 500 {
+		t.Errorf("Expected status 500, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_CreateListGetDelete(t *testing.T) {
+	useStore(t, conversation.NewMemoryStore())
+	ctx := context.Background()
+
+	response, _ := Handler(ctx, newRequest("POST", "user-1", "", `{"title": "USL outage"}`))
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var created conversation.Conversation
+	if err := json.Unmarshal([]byte(response.Body), &created); err != nil {
+		t.Fatalf("Failed to parse conversation: %v", err)
+	}
+	if created.Title != "USL outage" {
+		t.Errorf("Expected title 'USL outage', got '%s'", created.Title)
+	}
+
+	response, _ = Handler(ctx, newRequest("GET", "user-1", "", ""))
+	var list ListResponse
+	json.Unmarshal([]byte(response.Body), &list)
+	if len(list.Conversations) != 1 {
+		t.Errorf("Expected 1 conversation, got %d", len(list.Conversations))
+	}
+
+	response, _ = Handler(ctx, newRequest("GET", "user-1", created.ID, ""))
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	// Another user cannot see or delete the conversation
+	response, _ = Handler(ctx, newRequest("GET", "user-2", created.ID, ""))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 for another user, got %d", response.StatusCode)
+	}
+
+	response, _ = Handler(ctx, newRequest("DELETE", "user-1", created.ID, ""))
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	response, _ = Handler(ctx, newRequest("DELETE", "user-1", created.ID, ""))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 after delete, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_InvalidJSON(t *testing.T) {
+	useStore(t, conversation.NewMemoryStore())
+
+	response, _ := Handler(context.Background(), newRequest("POST", "user-1", "", `{invalid json}`))
+	if response.StatusCode != 400 {
+		t.Errorf("Expected status 400, got %d", response.StatusCode)
+	}
+
+	var errorResp api.ErrorResponse
+	json.Unmarshal([]byte(response.Body), &errorResp)
+	if errorResp.Error != "Invalid request body" {
+		t.Errorf("Expected 'Invalid request body', got '%s'", errorResp.Error)
+	}
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	useStore(t, conversation.NewMemoryStore())
+
+	response, _ := Handler(context.Background(), newRequest("DELETE", "user-1", "", ""))
+	if response.StatusCode != 405 {
+		t.Errorf("Expected status 405, got %d", response.StatusCode)
+	}
+}
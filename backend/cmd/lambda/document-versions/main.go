@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/document"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/pkg/api"
+)
+
+// Response represents the response for listing a document's versions
+type Response struct {
+	DocumentID string             `json:"document_id"`
+	Versions   []document.Version `json:"versions"`
+}
+
+// newStore creates the document store; tests replace it with an in-memory store
+var newStore = document.NewStore
+
+// Handler is the Lambda function handler for GET /documents/{id}/versions
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Documents belong to the authenticated Cognito user
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	id := request.PathParameters["id"]
+	if id == "" {
+		return api.Error(400, "Document ID is required", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create document store: %v", err), corsHeaders), nil
+	}
+
+	versions, err := store.ListVersions(ctx, user.Sub, id)
+	if errors.Is(err, document.ErrNotFound) {
+		return api.Error(404, "Document not found", corsHeaders), nil
+	}
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to list versions: %v", err), corsHeaders), nil
+	}
+
+	return api.JSON(200, Response{DocumentID: id, Versions: versions}, corsHeaders), nil
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
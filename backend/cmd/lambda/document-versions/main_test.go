@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/document"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store document.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (document.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// newRequest returns a GET request for the document id from the authenticated user sub
+func newRequest(sub, id string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": id},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{"sub": sub},
+			},
+		},
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ListVersions(t *testing.T) {
+	store := document.NewMemoryStore()
+	useStore(t, store)
+
+	ctx := context.Background()
+	doc, _ := store.CreateDocument(ctx, "user-1", document.Version{Name: "Runbook", Hash: document.Hash([]byte("first"))})
+	store.AddVersion(ctx, "user-1", doc.ID, document.Version{Name: "Runbook", Hash: document.Hash([]byte("second"))})
+
+	response, err := Handler(ctx, newRequest("user-1", doc.ID))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var resp Response
+	if err := json.Unmarshal([]byte(response.Body), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if resp.DocumentID != doc.ID || len(resp.Versions) != 2 || resp.Versions[1].Version != 2 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestHandler_NotFound(t *testing.T) {
+	store := document.NewMemoryStore()
+	useStore(t, store)
+
+	doc, _ := store.CreateDocument(context.Background(), "user-1", document.Version{Name: "Runbook"})
+
+	response, _ := Handler(context.Background(), newRequest("user-2", doc.ID))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 for another user's document, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/convert"
+	"tuitui-backend/internal/document"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/pkg/api"
+)
+
+// DocumentRequest represents the request body for uploading or updating a document.
+// File is a base64 encoded PDF, DOCX or HTML file that is converted to markdown
+// and stored instead of Content.
+type DocumentRequest struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	File    string `json:"file,omitempty"`
+}
+
+// ListResponse represents the response for listing documents
+type ListResponse struct {
+	Documents []document.Document `json:"documents"`
+}
+
+// UploadResponse represents the response for an upload. Deduplicated is set when
+// the caller already had a document with the same content and it was returned instead.
+type UploadResponse struct {
+	document.Document
+	Deduplicated bool `json:"deduplicated"`
+}
+
+// ContentResponse represents a version of a document with its content
+type ContentResponse struct {
+	ID string `json:"id"`
+	document.Version
+	Content string `json:"content"`
+}
+
+// MessageResponse represents a response that only carries a message
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// newLibrary creates the document library; tests replace it with an in-memory one
+var newLibrary = document.New
+
+// Handler is the Lambda function handler for /documents and /documents/{id}.
+// GET /documents/{id} returns the latest content, or an earlier version with ?version=.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,POST,PUT,DELETE,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	// Documents belong to the authenticated Cognito user
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	library, err := newLibrary(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create document library: %v", err), corsHeaders), nil
+	}
+
+	id := request.PathParameters["id"]
+
+	switch {
+	case id == "" && request.HTTPMethod == "GET":
+		documents, err := library.ListDocuments(ctx, user.Sub)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list documents: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, ListResponse{Documents: documents}, corsHeaders), nil
+
+	case id == "" && request.HTTPMethod == "POST":
+		var docReq DocumentRequest
+		if err := json.Unmarshal([]byte(request.Body), &docReq); err != nil {
+			return api.Error(400, "Invalid request body", corsHeaders), nil
+		}
+
+		content, err := requestContent(docReq)
+		if status, message, failed := conversionError(err); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+
+		doc, created, err := library.Upload(ctx, user.Sub, docReq.Name, content)
+		if status, message, failed := documentError(err, "upload"); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+
+		status := 201
+		if !created {
+			status = 200
+		}
+		return api.JSON(status, UploadResponse{Document: *doc, Deduplicated: !created}, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "GET":
+		version := 0
+		if param := request.QueryStringParameters["version"]; param != "" {
+			version, err = strconv.Atoi(param)
+			if err != nil || version < 1 {
+				return api.Error(400, "version must be a positive number", corsHeaders), nil
+			}
+		}
+
+		selected, content, err := library.Content(ctx, user.Sub, id, version)
+		if status, message, failed := documentError(err, "get"); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+		return api.JSON(200, ContentResponse{ID: id, Version: *selected, Content: content}, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "PUT":
+		var docReq DocumentRequest
+		if err := json.Unmarshal([]byte(request.Body), &docReq); err != nil {
+			return api.Error(400, "Invalid request body", corsHeaders), nil
+		}
+
+		content, err := requestContent(docReq)
+		if status, message, failed := conversionError(err); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+
+		doc, err := library.Update(ctx, user.Sub, id, docReq.Name, content)
+		if status, message, failed := documentError(err, "update"); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+		return api.JSON(200, doc, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "DELETE":
+		err := library.Delete(ctx, user.Sub, id)
+		if status, message, failed := documentError(err, "delete"); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+		return api.JSON(200, MessageResponse{Message: "Document deleted"}, corsHeaders), nil
+	}
+
+	return api.Error(405, "Method not allowed", corsHeaders), nil
+}
+
+// documentError returns the status and message for a failed library call, and
+// whether err was a failure at all
+func documentError(err error, action string) (int, string, bool) {
+	switch {
+	case err == nil:
+		return 0, "", false
+	case errors.Is(err, document.ErrNotFound):
+		return 404, "Document not found", true
+	case errors.Is(err, document.ErrEmpty):
+		return 400, "Content is required", true
+	case errors.Is(err, document.ErrTooLarge):
+		return 413, fmt.Sprintf("Documents must not exceed %d bytes", document.MaxSize), true
+	}
+	return 500, fmt.Sprintf("Failed to %s document: %v", action, err), true
+}
+
+// errInvalidFile is returned for a file that is not valid base64
+var errInvalidFile = errors.New("file must be base64 encoded")
+
+// requestContent returns the markdown to store for a request: its content, or
+// its file converted to markdown
+func requestContent(docReq DocumentRequest) (string, error) {
+	if docReq.File == "" {
+		return docReq.Content, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(docReq.File)
+	if err != nil {
+		return "", errInvalidFile
+	}
+
+	markdown, _, err := convert.ToMarkdown(docReq.Name, data)
+	return markdown, err
+}
+
+// conversionError returns the status and message for a file that could not be
+// converted, and whether err was a failure at all
+func conversionError(err error) (int, string, bool) {
+	switch {
+	case err == nil:
+		return 0, "", false
+	case errors.Is(err, errInvalidFile):
+		return 400, "File must be base64 encoded", true
+	case errors.Is(err, convert.ErrUnsupported):
+		return 415, "Unsupported file type; upload a PDF, DOCX, HTML or markdown file", true
+	case errors.Is(err, convert.ErrTooLarge):
+		return 413, fmt.Sprintf("Files must not exceed %d bytes", convert.MaxFileSize), true
+	case errors.Is(err, convert.ErrNoText):
+		return 422, "No text found in file", true
+	}
+	return 422, fmt.Sprintf("Failed to convert file: %v", err), true
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
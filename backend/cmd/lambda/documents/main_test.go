@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/convert"
+	"tuitui-backend/internal/document"
+)
+
+// useLibrary makes the handler use an in-memory library for the duration of the test
+func useLibrary(t *testing.T) *document.Library {
+	t.Helper()
+	library := document.NewLibrary(document.NewMemoryStore(), document.NewFileBlobs(t.TempDir()))
+	original := newLibrary
+	newLibrary = func(cfg *config.Config) (*document.Library, error) {
+		return library, nil
+	}
+	t.Cleanup(func() { newLibrary = original })
+	return library
+}
+
+// newRequest returns a request from the authenticated user sub
+func newRequest(sub, method, id, body string) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{"sub": sub},
+			},
+		},
+	}
+	if id != "" {
+		request.PathParameters = map[string]string{"id": id}
+	}
+	return request
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_UploadDeduplicates(t *testing.T) {
+	useLibrary(t)
+	body := `{"name": "Runbook", "content": "# Deploys\n\nRun make deploy."}`
+
+	response, err := Handler(context.Background(), newRequest("user-1", "POST", "", body))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var first UploadResponse
+	json.Unmarshal([]byte(response.Body), &first)
+	if first.ID == "" || first.Version != 1 || first.Deduplicated {
+		t.Errorf("Unexpected upload: %+v", first)
+	}
+
+	response, _ = Handler(context.Background(), newRequest("user-1", "POST", "", body))
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200 for a repeat upload, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var second UploadResponse
+	json.Unmarshal([]byte(response.Body), &second)
+	if second.ID != first.ID || !second.Deduplicated {
+		t.Errorf("Expected the first document back, got %+v", second)
+	}
+
+	response, _ = Handler(context.Background(), newRequest("user-1", "GET", "", ""))
+	var list ListResponse
+	json.Unmarshal([]byte(response.Body), &list)
+	if len(list.Documents) != 1 {
+		t.Errorf("Expected one document, got %+v", list.Documents)
+	}
+}
+
+func TestHandler_UploadInvalid(t *testing.T) {
+	useLibrary(t)
+
+	tests := map[string]int{
+		"not json":      400,
+		`{"name": "x"}`: 400,
+		`{"content": "` + strings.Repeat("x", document.MaxSize+1) + `"}`: 413,
+	}
+	for body, want := range tests {
+		response, err := Handler(context.Background(), newRequest("user-1", "POST", "", body))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != want {
+			t.Errorf("Expected status %d, got %d", want, response.StatusCode)
+		}
+	}
+}
+
+func TestHandler_UploadConvertsFile(t *testing.T) {
+	useLibrary(t)
+	page := `<html><body><h1>Deploys</h1><ul><li>Run make deploy</li></ul></body></html>`
+	body := `{"name": "deploys.html", "file": "` + base64.StdEncoding.EncodeToString([]byte(page)) + `"}`
+
+	response, err := Handler(context.Background(), newRequest("user-1", "POST", "", body))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var uploaded UploadResponse
+	json.Unmarshal([]byte(response.Body), &uploaded)
+
+	response, _ = Handler(context.Background(), newRequest("user-1", "GET", uploaded.ID, ""))
+	var stored ContentResponse
+	json.Unmarshal([]byte(response.Body), &stored)
+	if stored.Content != "# Deploys\n\n- Run make deploy\n" || stored.Name != "deploys.html" {
+		t.Errorf("Expected the file to be stored as markdown, got %+v", stored)
+	}
+}
+
+func TestHandler_UploadFileInvalid(t *testing.T) {
+	useLibrary(t)
+	encode := func(data string) string { return base64.StdEncoding.EncodeToString([]byte(data)) }
+
+	tests := map[string]int{
+		`{"name": "a.pdf", "file": "not base64!"}`:                                   400,
+		`{"name": "a.png", "file": "` + encode("\x89PNG\xff\xfe") + `"}`:             415,
+		`{"name": "a.html", "file": "` + encode("<html><body></body></html>") + `"}`: 422,
+		`{"name": "a.pdf", "file": "` + encode("%PDF-1.4\ngarbage") + `"}`:           422,
+	}
+	for body, want := range tests {
+		response, err := Handler(context.Background(), newRequest("user-1", "POST", "", body))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != want {
+			t.Errorf("Expected status %d for %s, got %d: %s", want, body, response.StatusCode, response.Body)
+		}
+	}
+}
+
+func TestHandler_UploadFileTooLarge(t *testing.T) {
+	useLibrary(t)
+
+	file := base64.StdEncoding.EncodeToString(make([]byte, convert.MaxFileSize+1))
+	response, err := Handler(context.Background(), newRequest("user-1", "POST", "", `{"name": "big.pdf", "file": "`+file+`"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 413 || !strings.Contains(response.Body, "Files must not exceed 4194304 bytes") {
+		t.Errorf("Expected status 413 naming the limit, got %d: %s", response.StatusCode, response.Body)
+	}
+}
+
+func TestHandler_UpdateAndGetVersions(t *testing.T) {
+	library := useLibrary(t)
+	ctx := context.Background()
+	doc, _, _ := library.Upload(ctx, "user-1", "Runbook", "first")
+
+	response, _ := Handler(ctx, newRequest("user-1", "PUT", doc.ID, `{"content": "second"}`))
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var updated document.Document
+	json.Unmarshal([]byte(response.Body), &updated)
+	if updated.Version != 2 || updated.Name != "Runbook" {
+		t.Errorf("Unexpected update: %+v", updated)
+	}
+
+	response, _ = Handler(ctx, newRequest("user-1", "GET", doc.ID, ""))
+	var latest ContentResponse
+	json.Unmarshal([]byte(response.Body), &latest)
+	if latest.ID != doc.ID || latest.Version.Version != 2 || latest.Content != "second" {
+		t.Errorf("Expected the latest content, got %+v", latest)
+	}
+
+	request := newRequest("user-1", "GET", doc.ID, "")
+	request.QueryStringParameters = map[string]string{"version": "1"}
+	response, _ = Handler(ctx, request)
+	var first ContentResponse
+	json.Unmarshal([]byte(response.Body), &first)
+	if first.Version.Version != 1 || first.Content != "first" {
+		t.Errorf("Expected the first version, got %+v", first)
+	}
+
+	request.QueryStringParameters = map[string]string{"version": "latest"}
+	response, _ = Handler(ctx, request)
+	if response.StatusCode != 400 {
+		t.Errorf("Expected status 400 for an invalid version, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_OtherUsersDocument(t *testing.T) {
+	library := useLibrary(t)
+	doc, _, _ := library.Upload(context.Background(), "user-1", "Runbook", "private")
+
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		response, _ := Handler(context.Background(), newRequest("user-2", method, doc.ID, `{"content": "mine"}`))
+		if response.StatusCode != 404 {
+			t.Errorf("Expected status 404 for %s as another user, got %d", method, response.StatusCode)
+		}
+	}
+}
+
+func TestHandler_DeleteDocument(t *testing.T) {
+	library := useLibrary(t)
+	doc, _, _ := library.Upload(context.Background(), "user-1", "Runbook", "text")
+
+	response, _ := Handler(context.Background(), newRequest("user-1", "DELETE", doc.ID, ""))
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = Handler(context.Background(), newRequest("user-1", "GET", doc.ID, ""))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 after delete, got %d", response.StatusCode)
+	}
+}
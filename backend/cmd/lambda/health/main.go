@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
 )
 
 // Response represents the Lambda response structure
@@ -78,6 +79,7 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 }
 
 func main() {
-	// Start Lambda handler
-	lambda.Start(Handler)
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	lambda.Start(logging.Wrap(logging.New(cfg), Handler))
 }
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/knowledge"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/pkg/api"
+)
+
+// EntryRequest represents the request body for creating or editing an entry
+type EntryRequest struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Tags  []string `json:"tags"`
+	Owner string   `json:"owner"`
+	Links []string `json:"links"`
+}
+
+// ListResponse represents the response for listing knowledge base entries
+type ListResponse struct {
+	Entries []knowledge.Entry `json:"entries"`
+}
+
+// newStore creates the knowledge store; tests replace it with an in-memory store
+var newStore = knowledge.NewStore
+
+// Handler is the Lambda function handler for /knowledge and /knowledge/{id}.
+// Any signed-in user can read entries; only members of the admin group can write.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,POST,PUT,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	if request.HTTPMethod != "GET" && !user.InGroup(cfg.AdminGroup) {
+		return api.Error(403, "Admin access required", corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create knowledge store: %v", err), corsHeaders), nil
+	}
+
+	id := request.PathParameters["id"]
+
+	switch {
+	case id == "" && request.HTTPMethod == "GET":
+		entries, err := store.List(ctx)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list knowledge entries: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, ListResponse{Entries: entries}, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "GET":
+		entry, err := store.Get(ctx, id)
+		if errors.Is(err, knowledge.ErrNotFound) {
+			return api.Error(404, "Knowledge entry not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to get knowledge entry: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, entry, corsHeaders), nil
+
+	case id == "" && request.HTTPMethod == "POST":
+		entry, errResp := parseEntry(request.Body, user, corsHeaders)
+		if errResp != nil {
+			return *errResp, nil
+		}
+
+		created, err := store.Create(ctx, entry)
+		if errors.Is(err, knowledge.ErrExists) {
+			return api.Error(409, "Knowledge entry already exists", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to create knowledge entry: %v", err), corsHeaders), nil
+		}
+		return api.JSON(201, created, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "PUT":
+		entry, errResp := parseEntry(request.Body, user, corsHeaders)
+		if errResp != nil {
+			return *errResp, nil
+		}
+		entry.ID = id
+
+		updated, err := store.Update(ctx, entry)
+		if errors.Is(err, knowledge.ErrNotFound) {
+			return api.Error(404, "Knowledge entry not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to update knowledge entry: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, updated, corsHeaders), nil
+	}
+
+	return api.Error(405, "Method not allowed", corsHeaders), nil
+}
+
+// parseEntry decodes and validates an entry from the request body
+func parseEntry(body string, user *auth.User, headers map[string]string) (knowledge.Entry, *events.APIGatewayProxyResponse) {
+	var entryReq EntryRequest
+	if err := json.Unmarshal([]byte(body), &entryReq); err != nil {
+		resp := api.Error(400, "Invalid request body", headers)
+		return knowledge.Entry{}, &resp
+	}
+
+	entry := knowledge.Entry{
+		ID:        entryReq.ID,
+		Title:     entryReq.Title,
+		Body:      entryReq.Body,
+		Tags:      entryReq.Tags,
+		Owner:     entryReq.Owner,
+		Links:     entryReq.Links,
+		UpdatedBy: user.Email,
+	}
+	if entry.UpdatedBy == "" {
+		entry.UpdatedBy = user.Sub
+	}
+
+	if err := entry.Validate(); err != nil {
+		resp := api.Error(400, "Title and body are required", headers)
+		return knowledge.Entry{}, &resp
+	}
+
+	return entry, nil
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
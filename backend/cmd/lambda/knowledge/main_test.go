@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/knowledge"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store knowledge.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (knowledge.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// newRequest returns a request from the authenticated user sub in groups
+func newRequest(method, id, body string, groups ...interface{}) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "user-1",
+					"email":          "user@tui.co.uk",
+					"cognito:groups": groups,
+				},
+			},
+		},
+	}
+	if id != "" {
+		request.PathParameters = map[string]string{"id": id}
+	}
+	return request
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	if response.Headers["Access-Control-Allow-Methods"] != "GET,POST,PUT,OPTIONS" {
+		t.Errorf("Unexpected CORS methods header: %s", response.Headers["Access-Control-Allow-Methods"])
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_WriteRequiresAdmin(t *testing.T) {
+	useStore(t, knowledge.NewMemoryStore())
+
+	body := `{"title": "Deploy freeze", "body": "No deploys on Fridays."}`
+	for _, request := range []events.APIGatewayProxyRequest{
+		newRequest("POST", "", body),
+		newRequest("PUT", "deploy-freeze", body, "developers"),
+	} {
+		response, err := Handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 403 {
+			t.Errorf("Expected status 403 for %s, got %d", request.HTTPMethod, response.StatusCode)
+		}
+	}
+}
+
+func TestHandler_CreateEditList(t *testing.T) {
+	useStore(t, knowledge.NewMemoryStore())
+	ctx := context.Background()
+
+	response, _ := Handler(ctx, newRequest("POST", "", `{"title": "Deploy freeze", "body": "No deploys on Fridays.", "tags": ["deploy"]}`, "admin"))
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var created knowledge.Entry
+	json.Unmarshal([]byte(response.Body), &created)
+	if created.ID != "deploy-freeze" || created.UpdatedBy != "user@tui.co.uk" {
+		t.Errorf("Unexpected created entry: %+v", created)
+	}
+
+	response, _ = Handler(ctx, newRequest("POST", "", `{"title": "Deploy freeze", "body": "Again"}`, "admin"))
+	if response.StatusCode != 409 {
+		t.Errorf("Expected status 409 for duplicate, got %d", response.StatusCode)
+	}
+
+	response, _ = Handler(ctx, newRequest("PUT", created.ID, `{"title": "Deploy freeze", "body": "No deploys after Thursday noon."}`, "admin"))
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = Handler(ctx, newRequest("PUT", "missing", `{"title": "Missing", "body": "Missing"}`, "admin"))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404, got %d", response.StatusCode)
+	}
+
+	response, _ = Handler(ctx, newRequest("GET", created.ID, ""))
+	var got knowledge.Entry
+	json.Unmarshal([]byte(response.Body), &got)
+	if got.Body != "No deploys after Thursday noon." {
+		t.Errorf("Expected edited body, got %+v", got)
+	}
+
+	response, _ = Handler(ctx, newRequest("GET", "", ""))
+	var list ListResponse
+	json.Unmarshal([]byte(response.Body), &list)
+	if len(list.Entries) != 1 {
+		t.Errorf("Expected 1 entry, got %d", len(list.Entries))
+	}
+}
+
+func TestHandler_InvalidEntry(t *testing.T) {
+	useStore(t, knowledge.NewMemoryStore())
+
+	tests := []string{`{invalid`, `{"title": "No body"}`}
+	for _, body := range tests {
+		response, err := Handler(context.Background(), newRequest("POST", "", body, "admin"))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 400 {
+			t.Errorf("Expected status 400 for %s, got %d", body, response.StatusCode)
+		}
+	}
+}
@@ -3,10 +3,14 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/revocation"
 )
 
 // Response represents the /me endpoint response
@@ -20,6 +24,31 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// newRevocations opens the list of signed out sessions; tests replace it
+var newRevocations = revocation.New
+
+// sessionRevoked reports whether the caller's session was signed out. Without
+// a revoked sessions table nothing is recorded, so every session is accepted.
+func sessionRevoked(ctx context.Context, request events.APIGatewayProxyRequest) (bool, error) {
+	session, ok := revocation.SessionFromRequest(request)
+	if !ok {
+		return false, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	revocations, err := newRevocations(cfg)
+	if errors.Is(err, revocation.ErrNotConfigured) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return revocations.Revoked(ctx, session)
+}
+
 // Handler is the Lambda function handler for /me endpoint
 func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Extract user information from Cognito authorizer claims
@@ -39,6 +68,36 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 		}, nil
 	}
 
+	// The authorizer accepts tokens of signed out sessions until they expire
+	revoked, err := sessionRevoked(ctx, request)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to check session", "error", err)
+		errorResponse := ErrorResponse{
+			Error: "Failed to check session",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       string(errorBody),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+		}, nil
+	}
+	if revoked {
+		errorResponse := ErrorResponse{
+			Error: "Session has been signed out. Please sign in again.",
+		}
+		errorBody, _ := json.Marshal(errorResponse)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       string(errorBody),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+		}, nil
+	}
+
 	// Extract user details from claims
 	userInfo := make(map[string]interface{})
 
@@ -95,6 +154,7 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events
 }
 
 func main() {
-	// Start Lambda handler
-	lambda.Start(Handler)
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	lambda.Start(logging.Wrap(logging.New(cfg), Handler))
 }
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/revocation"
 )
 
 func TestHandler_NoAuthorizer(t *testing.T) {
@@ -89,3 +92,56 @@ func TestResponse_Struct(t *testing.T) {
 		t.Error("Expected user id '123'")
 	}
 }
+
+func useRevocations(t *testing.T, revocations *revocation.List) {
+	t.Helper()
+	original := newRevocations
+	newRevocations = func(cfg *config.Config) (*revocation.List, error) {
+		return revocations, nil
+	}
+	t.Cleanup(func() { newRevocations = original })
+}
+
+func TestHandler_RevokedSession(t *testing.T) {
+	revocations := revocation.NewList(revocation.NewMemoryStore())
+	useRevocations(t, revocations)
+
+	request := func(originJTI string, issued time.Time) events.APIGatewayProxyRequest {
+		return events.APIGatewayProxyRequest{
+			HTTPMethod: "GET",
+			RequestContext: events.APIGatewayProxyRequestContext{
+				Authorizer: map[string]interface{}{
+					"claims": map[string]interface{}{
+						"sub":        "user-123",
+						"origin_jti": originJTI,
+						"iat":        issued.UTC().Format("Mon Jan 02 15:04:05 MST 2006"),
+					},
+				},
+			},
+		}
+	}
+	session := revocation.Session{Sub: "user-123", OriginJTI: "origin-1"}
+	if err := revocations.RevokeSession(context.Background(), session); err != nil {
+		t.Fatalf("RevokeSession returned error: %v", err)
+	}
+
+	response, err := Handler(context.Background(), request("origin-1", time.Now()))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401 for a signed out session, got %d", response.StatusCode)
+	}
+
+	response, _ = Handler(context.Background(), request("origin-2", time.Now()))
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200 for another session, got %d", response.StatusCode)
+	}
+
+	// Signing out everywhere refuses every token issued so far
+	revocations.RevokeUser(context.Background(), "user-123")
+	response, _ = Handler(context.Background(), request("origin-2", time.Now().Add(-time.Minute)))
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401 after signing out everywhere, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/prompt"
+	"tuitui-backend/internal/team"
+	"tuitui-backend/pkg/api"
+)
+
+// PreviewRequest represents the request body for previewing a template. Every
+// field is optional: Body previews a draft instead of the saved template,
+// Version previews an earlier version, and TeamID fills in a team's details.
+type PreviewRequest struct {
+	Body    string `json:"body"`
+	Version int    `json:"version"`
+	TeamID  string `json:"teamId"`
+}
+
+// Response represents a rendered preview. Version is 0 for a draft body or the
+// built-in default.
+type Response struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Prompt  string `json:"prompt"`
+}
+
+// newStore creates the prompt store; tests replace it with an in-memory store
+var newStore = prompt.NewStore
+
+// newTeamStore creates the team store used to fill in a team's details; tests replace it
+var newTeamStore = team.NewStore
+
+// Handler is the Lambda function handler for POST /prompts/{name}/preview. It
+// renders the template as a chat by the caller would see it, with sample
+// knowledge and document sections. Only members of the admin group can use it.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("POST,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	name := request.PathParameters["name"]
+	if name == "" {
+		return api.Error(400, "Template name is required", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	if !user.InGroup(cfg.AdminGroup) {
+		return api.Error(403, "Admin access required", corsHeaders), nil
+	}
+
+	if request.HTTPMethod != "POST" {
+		return api.Error(405, "Method not allowed", corsHeaders), nil
+	}
+
+	var previewReq PreviewRequest
+	if strings.TrimSpace(request.Body) != "" {
+		if err := json.Unmarshal([]byte(request.Body), &previewReq); err != nil {
+			return api.Error(400, "Invalid request body", corsHeaders), nil
+		}
+	}
+
+	preview := Response{Name: name}
+	if previewReq.Body != "" {
+		if err := prompt.Validate(previewReq.Body); err != nil {
+			reason := strings.TrimPrefix(err.Error(), prompt.ErrInvalid.Error()+": ")
+			return api.Error(400, fmt.Sprintf("Invalid prompt template: %s", reason), corsHeaders), nil
+		}
+	} else {
+		store, err := newStore(cfg)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to create prompt store: %v", err), corsHeaders), nil
+		}
+
+		preview.Version, previewReq.Body, err = templateBody(ctx, store, name, previewReq.Version)
+		if errors.Is(err, prompt.ErrNotFound) {
+			return api.Error(404, "Prompt template or version not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to get prompt template: %v", err), corsHeaders), nil
+		}
+	}
+
+	data := prompt.SampleData()
+	data.UserName = user.Name
+	data.UserEmail = user.Email
+
+	if previewReq.TeamID != "" {
+		teams, err := newTeamStore(cfg)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to create team store: %v", err), corsHeaders), nil
+		}
+
+		t, err := teams.Get(ctx, previewReq.TeamID)
+		if errors.Is(err, team.ErrNotFound) {
+			return api.Error(404, "Team not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to get team: %v", err), corsHeaders), nil
+		}
+		data.Team = t.Name
+		data.TeamInstructions = t.SystemPrompt
+		data.TeamInfo = t.Links
+	}
+
+	preview.Prompt, err = prompt.Render(previewReq.Body, data)
+	if err != nil {
+		return api.Error(422, fmt.Sprintf("Failed to render prompt template: %v", err), corsHeaders), nil
+	}
+
+	return api.JSON(200, preview, corsHeaders), nil
+}
+
+// templateBody returns the saved body of a template, or of one of its versions
+// when version is set
+func templateBody(ctx context.Context, store prompt.Store, name string, version int) (int, string, error) {
+	if version == 0 {
+		tmpl, err := prompt.Get(ctx, store, name)
+		if err != nil {
+			return 0, "", err
+		}
+		return tmpl.Version, tmpl.Body, nil
+	}
+
+	versions, err := store.ListVersions(ctx, name)
+	if err != nil {
+		return 0, "", err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v.Version, v.Body, nil
+		}
+	}
+	return 0, "", prompt.ErrNotFound
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/prompt"
+	"tuitui-backend/internal/team"
+)
+
+// useStores makes the handler use the prompt and team stores for the duration of the test
+func useStores(t *testing.T, prompts prompt.Store, teams team.Store) {
+	t.Helper()
+	originalPrompts, originalTeams := newStore, newTeamStore
+	newStore = func(cfg *config.Config) (prompt.Store, error) {
+		return prompts, nil
+	}
+	newTeamStore = func(cfg *config.Config) (team.Store, error) {
+		return teams, nil
+	}
+	t.Cleanup(func() {
+		newStore, newTeamStore = originalPrompts, originalTeams
+	})
+}
+
+// newRequest returns a preview request for the template name from a user in groups
+func newRequest(name, body string, groups ...interface{}) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Body:           body,
+		PathParameters: map[string]string{"name": name},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "user-1",
+					"email":          "ada@tui.co.uk",
+					"name":           "Ada",
+					"cognito:groups": groups,
+				},
+			},
+		},
+	}
+}
+
+// preview sends request and decodes a successful response
+func preview(t *testing.T, request events.APIGatewayProxyRequest) Response {
+	t.Helper()
+	response, err := Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var result Response
+	json.Unmarshal([]byte(response.Body), &result)
+	return result
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "POST"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_RequiresAdmin(t *testing.T) {
+	useStores(t, prompt.NewMemoryStore(), team.NewMemoryStore())
+
+	response, err := Handler(context.Background(), newRequest("default", "", "developers"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 403 {
+		t.Errorf("Expected status 403, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_PreviewBuiltinDefault(t *testing.T) {
+	useStores(t, prompt.NewMemoryStore(), team.NewMemoryStore())
+
+	result := preview(t, newRequest("default", "", "admin"))
+	if result.Name != "default" || result.Version != 0 {
+		t.Errorf("Expected the built-in default, got %+v", result)
+	}
+	for _, want := range []string{"You are a helpful assistant", "Team: Example Team", "Relevant sections from uploaded document:"} {
+		if !strings.Contains(result.Prompt, want) {
+			t.Errorf("Expected %q in the preview, got:\n%s", want, result.Prompt)
+		}
+	}
+}
+
+func TestHandler_PreviewVersionAndTeam(t *testing.T) {
+	prompts := prompt.NewMemoryStore()
+	ctx := context.Background()
+	prompt.Create(ctx, prompts, "support", "", "Old: {{.Team}}", "admin")
+	prompt.Update(ctx, prompts, "support", "", "New: {{.UserName}} of {{.Team}} on {{.Date}} ({{join .TeamInfo \" \"}})", "admin")
+	teams := team.NewMemoryStore(team.Team{ID: "search", Name: "Search", Links: []string{"https://runbooks.example.com/search"}})
+	useStores(t, prompts, teams)
+
+	result := preview(t, newRequest("support", `{"teamId": "search"}`, "admin"))
+	want := "New: Ada of Search on " + time.Now().UTC().Format("2006-01-02") + " (https://runbooks.example.com/search)"
+	if result.Version != 2 || result.Prompt != want {
+		t.Errorf("Expected version 2 rendered for the team, got %+v", result)
+	}
+
+	result = preview(t, newRequest("support", `{"version": 1}`, "admin"))
+	if result.Version != 1 || result.Prompt != "Old: Example Team" {
+		t.Errorf("Expected version 1 with sample data, got %+v", result)
+	}
+
+	result = preview(t, newRequest("support", `{"body": "Draft for {{upper .UserName}}"}`, "admin"))
+	if result.Version != 0 || result.Prompt != "Draft for ADA" {
+		t.Errorf("Expected the draft body, got %+v", result)
+	}
+}
+
+func TestHandler_PreviewErrors(t *testing.T) {
+	prompts := prompt.NewMemoryStore()
+	prompt.Create(context.Background(), prompts, "support", "", "Hi", "admin")
+	useStores(t, prompts, team.NewMemoryStore())
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"missing", "", 404},
+		{"support", `{"version": 4}`, 404},
+		{"support", `{"teamId": "nobody"}`, 404},
+		{"support", `{"body": "{{.Nope}}"}`, 400},
+		{"support", "not json", 400},
+	}
+	for _, tt := range tests {
+		response, err := Handler(context.Background(), newRequest(tt.name, tt.body, "admin"))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != tt.status {
+			t.Errorf("Expected status %d for %s %q, got %d", tt.status, tt.name, tt.body, response.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/prompt"
+	"tuitui-backend/pkg/api"
+)
+
+// RollbackRequest represents the request body for rolling a template back
+type RollbackRequest struct {
+	RollbackTo int `json:"rollbackTo"`
+}
+
+// Response represents the response for listing a template's versions
+type Response struct {
+	Name     string           `json:"name"`
+	Versions []prompt.Version `json:"versions"`
+}
+
+// newStore creates the prompt store; tests replace it with an in-memory store
+var newStore = prompt.NewStore
+
+// Handler is the Lambda function handler for /prompts/{name}/versions. GET lists
+// a template's versions; POST rolls back by saving an earlier version again as
+// the newest one. Only members of the admin group can use either.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,POST,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	name := request.PathParameters["name"]
+	if name == "" {
+		return api.Error(400, "Template name is required", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	if !user.InGroup(cfg.AdminGroup) {
+		return api.Error(403, "Admin access required", corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create prompt store: %v", err), corsHeaders), nil
+	}
+
+	switch request.HTTPMethod {
+	case "GET":
+		versions, err := store.ListVersions(ctx, name)
+		if errors.Is(err, prompt.ErrNotFound) && name == prompt.DefaultName {
+			// The built-in default has no history until it is first saved
+			versions, err = []prompt.Version{}, nil
+		}
+		if errors.Is(err, prompt.ErrNotFound) {
+			return api.Error(404, "Prompt template not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list versions: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, Response{Name: name, Versions: versions}, corsHeaders), nil
+
+	case "POST":
+		var rollbackReq RollbackRequest
+		if err := json.Unmarshal([]byte(request.Body), &rollbackReq); err != nil || rollbackReq.RollbackTo < 1 {
+			return api.Error(400, "rollbackTo must be a version number", corsHeaders), nil
+		}
+
+		by := user.Email
+		if by == "" {
+			by = user.Sub
+		}
+
+		tmpl, err := prompt.Rollback(ctx, store, name, rollbackReq.RollbackTo, by)
+		if errors.Is(err, prompt.ErrNotFound) {
+			return api.Error(404, "Prompt template or version not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to roll back prompt template: %v", err), corsHeaders), nil
+		}
+		return api.JSON(201, tmpl, corsHeaders), nil
+	}
+
+	return api.Error(405, "Method not allowed", corsHeaders), nil
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/prompt"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store prompt.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (prompt.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// newRequest returns a request for the template name from a user in groups
+func newRequest(method, name, body string, groups ...interface{}) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:     method,
+		Body:           body,
+		PathParameters: map[string]string{"name": name},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "user-1",
+					"email":          "user@tui.co.uk",
+					"cognito:groups": groups,
+				},
+			},
+		},
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_RequiresAdmin(t *testing.T) {
+	useStore(t, prompt.NewMemoryStore())
+
+	response, err := Handler(context.Background(), newRequest("GET", "support", "", "developers"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 403 {
+		t.Errorf("Expected status 403, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ListAndRollback(t *testing.T) {
+	store := prompt.NewMemoryStore()
+	useStore(t, store)
+	ctx := context.Background()
+
+	prompt.Create(ctx, store, "support", "", "First", "admin@tui.co.uk")
+	prompt.Update(ctx, store, "support", "", "Second", "admin@tui.co.uk")
+
+	response, err := Handler(ctx, newRequest("POST", "support", `{"rollbackTo": 1}`, "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+	var tmpl prompt.Template
+	json.Unmarshal([]byte(response.Body), &tmpl)
+	if tmpl.Version != 3 || tmpl.Body != "First" || tmpl.UpdatedBy != "user@tui.co.uk" {
+		t.Errorf("Expected version 3 restoring the first body, got %+v", tmpl)
+	}
+
+	response, _ = Handler(ctx, newRequest("GET", "support", "", "admin"))
+	var list Response
+	json.Unmarshal([]byte(response.Body), &list)
+	if list.Name != "support" || len(list.Versions) != 3 || list.Versions[2].Note != "Rolled back to version 1" {
+		t.Errorf("Unexpected versions: %+v", list)
+	}
+}
+
+func TestHandler_NotFound(t *testing.T) {
+	store := prompt.NewMemoryStore()
+	useStore(t, store)
+	ctx := context.Background()
+	prompt.Create(ctx, store, "support", "", "First", "admin")
+
+	for _, request := range []events.APIGatewayProxyRequest{
+		newRequest("GET", "missing", "", "admin"),
+		newRequest("POST", "missing", `{"rollbackTo": 1}`, "admin"),
+		newRequest("POST", "support", `{"rollbackTo": 7}`, "admin"),
+	} {
+		response, err := Handler(ctx, request)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 404 {
+			t.Errorf("Expected status 404 for %s %s, got %d", request.HTTPMethod, request.Body, response.StatusCode)
+		}
+	}
+
+	response, _ := Handler(ctx, newRequest("POST", "support", `{}`, "admin"))
+	if response.StatusCode != 400 {
+		t.Errorf("Expected status 400 without a version, got %d", response.StatusCode)
+	}
+
+	// The built-in default has an empty history rather than not existing
+	response, _ = Handler(ctx, newRequest("GET", prompt.DefaultName, "", "admin"))
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200 for the built-in default, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/prompt"
+	"tuitui-backend/pkg/api"
+)
+
+// TemplateRequest represents the request body for creating or editing a template
+type TemplateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Body        string `json:"body"`
+}
+
+// ListResponse represents the response for listing prompt templates
+type ListResponse struct {
+	Templates []prompt.Template `json:"templates"`
+}
+
+// newStore creates the prompt store; tests replace it with an in-memory store
+var newStore = prompt.NewStore
+
+// Handler is the Lambda function handler for /prompts and /prompts/{name}.
+// Templates shape every chat's system prompt, so only members of the admin
+// group can read or change them. Saving a template adds a new version.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,POST,PUT,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	if !user.InGroup(cfg.AdminGroup) {
+		return api.Error(403, "Admin access required", corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create prompt store: %v", err), corsHeaders), nil
+	}
+
+	name := request.PathParameters["name"]
+
+	switch {
+	case name == "" && request.HTTPMethod == "GET":
+		templates, err := listTemplates(ctx, store)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list prompt templates: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, ListResponse{Templates: templates}, corsHeaders), nil
+
+	case name != "" && request.HTTPMethod == "GET":
+		tmpl, err := prompt.Get(ctx, store, name)
+		if status, message, failed := templateError(err, "get prompt template"); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+		return api.JSON(200, tmpl, corsHeaders), nil
+
+	case name == "" && request.HTTPMethod == "POST":
+		var templateReq TemplateRequest
+		if err := json.Unmarshal([]byte(request.Body), &templateReq); err != nil {
+			return api.Error(400, "Invalid request body", corsHeaders), nil
+		}
+
+		created, err := prompt.Create(ctx, store, templateReq.Name, templateReq.Description, templateReq.Body, editor(user))
+		if status, message, failed := templateError(err, "create prompt template"); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+		return api.JSON(201, created, corsHeaders), nil
+
+	case name != "" && request.HTTPMethod == "PUT":
+		var templateReq TemplateRequest
+		if err := json.Unmarshal([]byte(request.Body), &templateReq); err != nil {
+			return api.Error(400, "Invalid request body", corsHeaders), nil
+		}
+
+		updated, err := prompt.Update(ctx, store, name, templateReq.Description, templateReq.Body, editor(user))
+		if status, message, failed := templateError(err, "update prompt template"); failed {
+			return api.Error(status, message, corsHeaders), nil
+		}
+		return api.JSON(200, updated, corsHeaders), nil
+	}
+
+	return api.Error(405, "Method not allowed", corsHeaders), nil
+}
+
+// listTemplates returns the saved templates, with the built-in default in
+// place of the default template until one has been saved
+func listTemplates(ctx context.Context, store prompt.Store) ([]prompt.Template, error) {
+	templates, err := store.ListTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tmpl := range templates {
+		if tmpl.Name == prompt.DefaultName {
+			return templates, nil
+		}
+	}
+
+	templates = append(templates, prompt.Builtin())
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// editor identifies the user saving a template in its history
+func editor(user *auth.User) string {
+	if user.Email != "" {
+		return user.Email
+	}
+	return user.Sub
+}
+
+// templateError maps a prompt package error to a status code and message
+func templateError(err error, action string) (int, string, bool) {
+	switch {
+	case err == nil:
+		return 0, "", false
+	case errors.Is(err, prompt.ErrInvalid):
+		reason := strings.TrimPrefix(err.Error(), prompt.ErrInvalid.Error()+": ")
+		return 400, fmt.Sprintf("Invalid prompt template: %s", reason), true
+	case errors.Is(err, prompt.ErrExists):
+		return 409, "Prompt template already exists", true
+	case errors.Is(err, prompt.ErrNotFound):
+		return 404, "Prompt template not found", true
+	}
+	return 500, fmt.Sprintf("Failed to %s: %v", action, err), true
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
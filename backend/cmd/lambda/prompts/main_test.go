@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/prompt"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store prompt.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (prompt.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// newRequest returns a request from the authenticated user sub in groups
+func newRequest(method, name, body string, groups ...interface{}) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "user-1",
+					"email":          "user@tui.co.uk",
+					"cognito:groups": groups,
+				},
+			},
+		},
+	}
+	if name != "" {
+		request.PathParameters = map[string]string{"name": name}
+	}
+	return request
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	if response.Headers["Access-Control-Allow-Methods"] != "GET,POST,PUT,OPTIONS" {
+		t.Errorf("Unexpected CORS methods header: %s", response.Headers["Access-Control-Allow-Methods"])
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_RequiresAdmin(t *testing.T) {
+	useStore(t, prompt.NewMemoryStore())
+
+	for _, request := range []events.APIGatewayProxyRequest{
+		newRequest("GET", "", ""),
+		newRequest("PUT", "default", `{"body": "Hi"}`, "developers"),
+	} {
+		response, err := Handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 403 {
+			t.Errorf("Expected status 403 for %s, got %d", request.HTTPMethod, response.StatusCode)
+		}
+	}
+}
+
+func TestHandler_CreateEditList(t *testing.T) {
+	store := prompt.NewMemoryStore()
+	useStore(t, store)
+	ctx := context.Background()
+
+	// The built-in default is listed before anything is saved
+	response, err := Handler(ctx, newRequest("GET", "", "", "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var list ListResponse
+	json.Unmarshal([]byte(response.Body), &list)
+	if len(list.Templates) != 1 || list.Templates[0].Name != prompt.DefaultName || list.Templates[0].Version != 0 {
+		t.Errorf("Expected only the built-in default, got %+v", list.Templates)
+	}
+
+	body := `{"name": "support", "description": "Support desk", "body": "Help {{.UserName}}."}`
+	response, err = Handler(ctx, newRequest("POST", "", body, "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = Handler(ctx, newRequest("POST", "", body, "admin"))
+	if response.StatusCode != 409 {
+		t.Errorf("Expected status 409 for a duplicate template, got %d", response.StatusCode)
+	}
+
+	response, err = Handler(ctx, newRequest("PUT", "support", `{"body": "Help {{.UserName}} kindly."}`, "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var updated prompt.Template
+	json.Unmarshal([]byte(response.Body), &updated)
+	if response.StatusCode != 200 || updated.Version != 2 || updated.Description != "Support desk" || updated.UpdatedBy != "user@tui.co.uk" {
+		t.Errorf("Expected version 2 by the editor, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = Handler(ctx, newRequest("GET", "support", "", "admin"))
+	var got prompt.Template
+	json.Unmarshal([]byte(response.Body), &got)
+	if got.Body != "Help {{.UserName}} kindly." {
+		t.Errorf("Expected the latest body, got %+v", got)
+	}
+
+	list = ListResponse{}
+	response, _ = Handler(ctx, newRequest("GET", "", "", "admin"))
+	json.Unmarshal([]byte(response.Body), &list)
+	if len(list.Templates) != 2 || list.Templates[0].Name != prompt.DefaultName || list.Templates[1].Name != "support" {
+		t.Errorf("Expected the default and support templates, got %+v", list.Templates)
+	}
+}
+
+func TestHandler_InvalidTemplate(t *testing.T) {
+	useStore(t, prompt.NewMemoryStore())
+
+	for _, body := range []string{"not json", `{"name": "Bad Name", "body": "Hi"}`, `{"name": "ok", "body": "{{.Missing}}"}`} {
+		response, err := Handler(context.Background(), newRequest("POST", "", body, "admin"))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 400 {
+			t.Errorf("Expected status 400 for %q, got %d", body, response.StatusCode)
+		}
+	}
+
+	response, _ := Handler(context.Background(), newRequest("PUT", "default", `{"body": "{{if}}"}`, "admin"))
+	if response.StatusCode != 400 || !strings.Contains(response.Body, "Invalid prompt template: ") {
+		t.Errorf("Expected a 400 explaining the template error, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, _ = Handler(context.Background(), newRequest("PUT", "missing", `{"body": "Hi"}`, "admin"))
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 for a missing template, got %d", response.StatusCode)
+	}
+}
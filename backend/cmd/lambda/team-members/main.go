@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/team"
+	"tuitui-backend/pkg/api"
+)
+
+// MemberRequest represents the request body for adding a member or changing their role.
+// New members are identified by their Cognito user ID or their email address.
+type MemberRequest struct {
+	UserID string `json:"userId"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// ListResponse represents the response for listing a team's members
+type ListResponse struct {
+	TeamID  string        `json:"team_id"`
+	Members []team.Member `json:"members"`
+}
+
+// MessageResponse represents a response carrying only a message
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+var (
+	// newStore creates the team store; tests replace it with an in-memory store
+	newStore = team.NewStore
+
+	// newDirectory creates the Cognito user lookup used to add members by email
+	newDirectory = team.NewDirectory
+)
+
+// Handler is the Lambda function handler for /teams/{id}/members and
+// /teams/{id}/members/{userId}. Members can see who else is in their team;
+// team admins and members of the admin group add, change and remove members,
+// and any member can leave a team.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,POST,PUT,DELETE,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	teamID := request.PathParameters["id"]
+	if teamID == "" {
+		return api.Error(400, "Team ID is required", corsHeaders), nil
+	}
+	userID := request.PathParameters["userId"]
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create team store: %v", err), corsHeaders), nil
+	}
+
+	if _, err := store.Get(ctx, teamID); err != nil {
+		if errors.Is(err, team.ErrNotFound) {
+			return api.Error(404, "Team not found", corsHeaders), nil
+		}
+		return api.Error(500, fmt.Sprintf("Failed to get team: %v", err), corsHeaders), nil
+	}
+
+	canManage, err := team.CanManage(ctx, store, user.Sub, user.InGroup(cfg.AdminGroup), teamID)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to check team access: %v", err), corsHeaders), nil
+	}
+
+	switch {
+	case userID == "" && request.HTTPMethod == "GET":
+		if !canManage {
+			if _, err := store.GetMember(ctx, teamID, user.Sub); errors.Is(err, team.ErrMemberNotFound) {
+				return api.Error(403, "Team membership required", corsHeaders), nil
+			} else if err != nil {
+				return api.Error(500, fmt.Sprintf("Failed to check team access: %v", err), corsHeaders), nil
+			}
+		}
+
+		members, err := store.ListMembers(ctx, teamID)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list members: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, ListResponse{TeamID: teamID, Members: members}, corsHeaders), nil
+
+	case userID == "" && request.HTTPMethod == "POST":
+		if !canManage {
+			return api.Error(403, "Team admin access required", corsHeaders), nil
+		}
+
+		var memberReq MemberRequest
+		if err := json.Unmarshal([]byte(request.Body), &memberReq); err != nil {
+			return api.Error(400, "Invalid request body", corsHeaders), nil
+		}
+
+		memberReq.Email = strings.TrimSpace(memberReq.Email)
+		if memberReq.UserID == "" {
+			if memberReq.Email == "" {
+				return api.Error(400, "userId or email is required", corsHeaders), nil
+			}
+
+			directory, err := newDirectory(cfg)
+			if err != nil {
+				return api.Error(500, fmt.Sprintf("Failed to create user directory: %v", err), corsHeaders), nil
+			}
+			memberReq.UserID, err = directory.LookupEmail(ctx, memberReq.Email)
+			if errors.Is(err, team.ErrUserNotFound) {
+				return api.Error(404, "No user with that email address", corsHeaders), nil
+			}
+			if err != nil {
+				return api.Error(500, fmt.Sprintf("Failed to look up user: %v", err), corsHeaders), nil
+			}
+		}
+
+		return putMember(ctx, store, team.Member{
+			TeamID: teamID,
+			UserID: memberReq.UserID,
+			Email:  memberReq.Email,
+			Role:   defaultRole(memberReq.Role),
+		}, 201, corsHeaders), nil
+
+	case userID != "" && request.HTTPMethod == "PUT":
+		if !canManage {
+			return api.Error(403, "Team admin access required", corsHeaders), nil
+		}
+
+		var memberReq MemberRequest
+		if err := json.Unmarshal([]byte(request.Body), &memberReq); err != nil {
+			return api.Error(400, "Invalid request body", corsHeaders), nil
+		}
+
+		existing, err := store.GetMember(ctx, teamID, userID)
+		if errors.Is(err, team.ErrMemberNotFound) {
+			return api.Error(404, "Member not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to get member: %v", err), corsHeaders), nil
+		}
+
+		existing.Role = defaultRole(memberReq.Role)
+		return putMember(ctx, store, *existing, 200, corsHeaders), nil
+
+	case userID != "" && request.HTTPMethod == "DELETE":
+		// Members may always leave a team themselves
+		if !canManage && userID != user.Sub {
+			return api.Error(403, "Team admin access required", corsHeaders), nil
+		}
+
+		err := store.RemoveMember(ctx, teamID, userID)
+		if errors.Is(err, team.ErrMemberNotFound) {
+			return api.Error(404, "Member not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to remove member: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, MessageResponse{Message: "Member removed"}, corsHeaders), nil
+	}
+
+	return api.Error(405, "Method not allowed", corsHeaders), nil
+}
+
+// putMember validates and stores a membership, responding with status on success
+func putMember(ctx context.Context, store team.Store, member team.Member, status int, headers map[string]string) events.APIGatewayProxyResponse {
+	if err := member.Validate(); err != nil {
+		return api.Error(400, fmt.Sprintf("Invalid member: %v", err), headers)
+	}
+
+	saved, err := store.PutMember(ctx, member)
+	if errors.Is(err, team.ErrNotFound) {
+		return api.Error(404, "Team not found", headers)
+	}
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to save member: %v", err), headers)
+	}
+	return api.JSON(status, saved, headers)
+}
+
+// defaultRole returns the requested role, or a plain member role when none is given
+func defaultRole(role string) string {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		return team.RoleMember
+	}
+	return role
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/team"
+)
+
+// fakeDirectory resolves the email addresses it holds
+type fakeDirectory map[string]string
+
+func (d fakeDirectory) LookupEmail(ctx context.Context, email string) (string, error) {
+	if sub, ok := d[email]; ok {
+		return sub, nil
+	}
+	return "", team.ErrUserNotFound
+}
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store team.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (team.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// useDirectory makes the handler look users up in directory for the duration of the test
+func useDirectory(t *testing.T, directory team.Directory) {
+	t.Helper()
+	original := newDirectory
+	newDirectory = func(cfg *config.Config) (team.Directory, error) {
+		return directory, nil
+	}
+	t.Cleanup(func() { newDirectory = original })
+}
+
+// newRequest returns a request from user-1 for the search team's members
+func newRequest(method, userID, body string, groups ...interface{}) events.APIGatewayProxyRequest {
+	params := map[string]string{"id": "search"}
+	if userID != "" {
+		params["userId"] = userID
+	}
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:     method,
+		Body:           body,
+		PathParameters: params,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "user-1",
+					"cognito:groups": groups,
+				},
+			},
+		},
+	}
+}
+
+// seededStore returns the search team with user-1 in role and user-2 as a member
+func seededStore(t *testing.T, role string) team.Store {
+	store := team.NewMemoryStore(team.Team{ID: "search", Name: "Search"})
+	members := []team.Member{{TeamID: "search", UserID: "user-2", Role: team.RoleMember}}
+	if role != "" {
+		members = append(members, team.Member{TeamID: "search", UserID: "user-1", Role: role})
+	}
+	for _, member := range members {
+		if _, err := store.PutMember(context.Background(), member); err != nil {
+			t.Fatalf("PutMember returned error: %v", err)
+		}
+	}
+	return store
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_UnknownTeam(t *testing.T) {
+	useStore(t, team.NewMemoryStore())
+
+	response, err := Handler(context.Background(), newRequest("GET", "", "", "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ListMembers(t *testing.T) {
+	useStore(t, seededStore(t, ""))
+
+	response, err := Handler(context.Background(), newRequest("GET", "", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 403 {
+		t.Errorf("Expected outsiders to be refused, got %d", response.StatusCode)
+	}
+
+	useStore(t, seededStore(t, team.RoleMember))
+
+	response, err = Handler(context.Background(), newRequest("GET", "", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var list ListResponse
+	json.Unmarshal([]byte(response.Body), &list)
+
+	if list.TeamID != "search" || len(list.Members) != 2 || list.Members[0].UserID != "user-1" {
+		t.Errorf("Unexpected members: %+v", list)
+	}
+}
+
+func TestHandler_AddMemberByEmail(t *testing.T) {
+	store := seededStore(t, team.RoleAdmin)
+	useStore(t, store)
+	useDirectory(t, fakeDirectory{"user-3@example.com": "user-3"})
+
+	response, err := Handler(context.Background(), newRequest("POST", "", `{"email":"user-3@example.com"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	member, err := store.GetMember(context.Background(), "search", "user-3")
+	if err != nil {
+		t.Fatalf("GetMember returned error: %v", err)
+	}
+	if member.Email != "user-3@example.com" || member.Role != team.RoleMember {
+		t.Errorf("Unexpected member: %+v", member)
+	}
+
+	response, err = Handler(context.Background(), newRequest("POST", "", `{"email":"nobody@example.com"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 for an unknown email, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_AddMemberRequiresManager(t *testing.T) {
+	useStore(t, seededStore(t, team.RoleMember))
+
+	response, err := Handler(context.Background(), newRequest("POST", "", `{"userId":"user-3"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 403 {
+		t.Errorf("Expected status 403, got %d", response.StatusCode)
+	}
+
+	useStore(t, seededStore(t, ""))
+
+	response, err = Handler(context.Background(), newRequest("POST", "", `{"userId":"user-3","role":"owner"}`, "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 400 {
+		t.Errorf("Expected status 400 for an unknown role, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ChangeRole(t *testing.T) {
+	store := seededStore(t, team.RoleAdmin)
+	useStore(t, store)
+
+	response, err := Handler(context.Background(), newRequest("PUT", "user-2", `{"role":"admin"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	member, _ := store.GetMember(context.Background(), "search", "user-2")
+	if !member.IsAdmin() {
+		t.Errorf("Expected user-2 to be promoted, got %+v", member)
+	}
+
+	response, err = Handler(context.Background(), newRequest("PUT", "user-9", `{"role":"admin"}`))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 for a non-member, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_RemoveMember(t *testing.T) {
+	store := seededStore(t, team.RoleMember)
+	useStore(t, store)
+
+	response, err := Handler(context.Background(), newRequest("DELETE", "user-2", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 403 {
+		t.Errorf("Expected members to be unable to remove others, got %d", response.StatusCode)
+	}
+
+	response, err = Handler(context.Background(), newRequest("DELETE", "user-1", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected members to be able to leave, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	if _, err := store.GetMember(context.Background(), "search", "user-1"); err != team.ErrMemberNotFound {
+		t.Errorf("Expected user-1 to have left, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/team"
+	"tuitui-backend/pkg/api"
+)
+
+// TeamRequest represents the request body for creating or editing a team
+type TeamRequest struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	SystemPrompt   string          `json:"systemPrompt"`
+	PromptTemplate string          `json:"promptTemplate"`
+	Links          []string        `json:"links"`
+	Documents      []team.Document `json:"documents"`
+	Model          struct {
+		Model     string `json:"model"`
+		MaxTokens int    `json:"maxTokens"`
+	} `json:"model"`
+}
+
+// ListResponse represents the response for listing teams
+type ListResponse struct {
+	Teams []team.Team `json:"teams"`
+
+	// Memberships are the caller's own teams and roles
+	Memberships []team.Member `json:"memberships"`
+}
+
+// MessageResponse represents a response carrying only a message
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// newStore creates the team store; tests replace it with an in-memory store
+var newStore = team.NewStore
+
+// Handler is the Lambda function handler for /teams and /teams/{id}. Any
+// signed-in user can read teams; members of the admin group create and delete
+// them, and team admins can also edit their own team.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,POST,PUT,DELETE,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create team store: %v", err), corsHeaders), nil
+	}
+
+	id := request.PathParameters["id"]
+	globalAdmin := user.InGroup(cfg.AdminGroup)
+
+	switch {
+	case id == "" && request.HTTPMethod == "GET":
+		teams, err := store.List(ctx)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list teams: %v", err), corsHeaders), nil
+		}
+		memberships, err := store.Memberships(ctx, user.Sub)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to list memberships: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, ListResponse{Teams: teams, Memberships: memberships}, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "GET":
+		t, err := store.Get(ctx, id)
+		if errors.Is(err, team.ErrNotFound) {
+			return api.Error(404, "Team not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to get team: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, t, corsHeaders), nil
+
+	case id == "" && request.HTTPMethod == "POST":
+		if !globalAdmin {
+			return api.Error(403, "Admin access required", corsHeaders), nil
+		}
+
+		t, errResp := parseTeam(request.Body, user, corsHeaders)
+		if errResp != nil {
+			return *errResp, nil
+		}
+
+		created, err := store.Create(ctx, t)
+		if errors.Is(err, team.ErrExists) {
+			return api.Error(409, "Team already exists", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to create team: %v", err), corsHeaders), nil
+		}
+		return api.JSON(201, created, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "PUT":
+		allowed, err := team.CanManage(ctx, store, user.Sub, globalAdmin, id)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to check team access: %v", err), corsHeaders), nil
+		}
+		if !allowed {
+			return api.Error(403, "Team admin access required", corsHeaders), nil
+		}
+
+		t, errResp := parseTeam(request.Body, user, corsHeaders)
+		if errResp != nil {
+			return *errResp, nil
+		}
+		t.ID = id
+
+		updated, err := store.Update(ctx, t)
+		if errors.Is(err, team.ErrNotFound) {
+			return api.Error(404, "Team not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to update team: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, updated, corsHeaders), nil
+
+	case id != "" && request.HTTPMethod == "DELETE":
+		if !globalAdmin {
+			return api.Error(403, "Admin access required", corsHeaders), nil
+		}
+
+		err := store.Delete(ctx, id)
+		if errors.Is(err, team.ErrNotFound) {
+			return api.Error(404, "Team not found", corsHeaders), nil
+		}
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to delete team: %v", err), corsHeaders), nil
+		}
+		return api.JSON(200, MessageResponse{Message: "Team deleted"}, corsHeaders), nil
+	}
+
+	return api.Error(405, "Method not allowed", corsHeaders), nil
+}
+
+// parseTeam decodes and validates a team from the request body
+func parseTeam(body string, user *auth.User, headers map[string]string) (team.Team, *events.APIGatewayProxyResponse) {
+	var teamReq TeamRequest
+	if err := json.Unmarshal([]byte(body), &teamReq); err != nil {
+		resp := api.Error(400, "Invalid request body", headers)
+		return team.Team{}, &resp
+	}
+
+	t := team.Team{
+		ID:             teamReq.ID,
+		Name:           teamReq.Name,
+		Description:    teamReq.Description,
+		SystemPrompt:   teamReq.SystemPrompt,
+		PromptTemplate: teamReq.PromptTemplate,
+		Links:          teamReq.Links,
+		Documents:      teamReq.Documents,
+		Model: team.ModelSettings{
+			Model:     teamReq.Model.Model,
+			MaxTokens: teamReq.Model.MaxTokens,
+		},
+		UpdatedBy: user.Email,
+	}
+	if t.UpdatedBy == "" {
+		t.UpdatedBy = user.Sub
+	}
+
+	if err := t.Validate(); err != nil {
+		resp := api.Error(400, fmt.Sprintf("Invalid team: %v", err), headers)
+		return team.Team{}, &resp
+	}
+
+	return t, nil
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
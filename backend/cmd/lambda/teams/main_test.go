@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/team"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store team.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (team.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// newRequest returns a request from user-1 in groups
+func newRequest(method, id, body string, groups ...interface{}) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "user-1",
+					"email":          "user-1@example.com",
+					"cognito:groups": groups,
+				},
+			},
+		},
+	}
+	if id != "" {
+		request.PathParameters = map[string]string{"id": id}
+	}
+	return request
+}
+
+// seededStore returns a store with the search team, where user-1 has role
+func seededStore(t *testing.T, role string) team.Store {
+	store := team.NewMemoryStore(team.Team{ID: "search", Name: "Search"}, team.Team{ID: "payments", Name: "Payments"})
+	if role != "" {
+		if _, err := store.PutMember(context.Background(), team.Member{TeamID: "search", UserID: "user-1", Role: role}); err != nil {
+			t.Fatalf("PutMember returned error: %v", err)
+		}
+	}
+	return store
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	if response.Headers["Access-Control-Allow-Methods"] != "GET,POST,PUT,DELETE,OPTIONS" {
+		t.Errorf("Unexpected CORS methods header: %s", response.Headers["Access-Control-Allow-Methods"])
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_ListTeams(t *testing.T) {
+	useStore(t, seededStore(t, team.RoleMember))
+
+	response, err := Handler(context.Background(), newRequest("GET", "", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var list ListResponse
+	json.Unmarshal([]byte(response.Body), &list)
+
+	if len(list.Teams) != 2 || list.Teams[0].ID != "payments" {
+		t.Errorf("Unexpected teams: %+v", list.Teams)
+	}
+	if len(list.Memberships) != 1 || list.Memberships[0].TeamID != "search" {
+		t.Errorf("Unexpected memberships: %+v", list.Memberships)
+	}
+}
+
+func TestHandler_GetTeamNotFound(t *testing.T) {
+	useStore(t, seededStore(t, ""))
+
+	response, err := Handler(context.Background(), newRequest("GET", "missing", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_CreateTeam(t *testing.T) {
+	store := seededStore(t, "")
+	useStore(t, store)
+
+	body := `{"name":"Data Platform","systemPrompt":"Answer for data engineers.","promptTemplate":"data","links":["https://runbooks/data"],"model":{"maxTokens":2048}}`
+
+	response, err := Handler(context.Background(), newRequest("POST", "", body, "developers"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 403 {
+		t.Errorf("Expected status 403 for a non-admin, got %d", response.StatusCode)
+	}
+
+	response, err = Handler(context.Background(), newRequest("POST", "", body, "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 201 {
+		t.Fatalf("Expected status 201, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var created team.Team
+	json.Unmarshal([]byte(response.Body), &created)
+
+	if created.ID != "data-platform" || created.SystemPrompt != "Answer for data engineers." || created.Model.MaxTokens != 2048 || created.PromptTemplate != "data" {
+		t.Errorf("Unexpected team: %+v", created)
+	}
+	if created.UpdatedBy != "user-1@example.com" {
+		t.Errorf("Expected the editor to be recorded, got %q", created.UpdatedBy)
+	}
+
+	response, err = Handler(context.Background(), newRequest("POST", "", body, "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 409 {
+		t.Errorf("Expected status 409 for a duplicate team, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_CreateTeamInvalid(t *testing.T) {
+	useStore(t, seededStore(t, ""))
+
+	for _, body := range []string{"not json", `{"name":" "}`, `{"name":"Ops","model":{"maxTokens":-1}}`, `{"name":"Ops","promptTemplate":"Not A Name"}`} {
+		response, err := Handler(context.Background(), newRequest("POST", "", body, "admin"))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 400 {
+			t.Errorf("Expected status 400 for %q, got %d", body, response.StatusCode)
+		}
+	}
+}
+
+func TestHandler_UpdateTeam(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		groups []interface{}
+		want   int
+	}{
+		{name: "team admin", role: team.RoleAdmin, want: 200},
+		{name: "global admin", groups: []interface{}{"admin"}, want: 200},
+		{name: "team member", role: team.RoleMember, want: 403},
+		{name: "outsider", want: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useStore(t, seededStore(t, tt.role))
+
+			body := `{"name":"Search","systemPrompt":"Mention the search SLOs."}`
+			response, err := Handler(context.Background(), newRequest("PUT", "search", body, tt.groups...))
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if response.StatusCode != tt.want {
+				t.Fatalf("Expected status %d, got %d: %s", tt.want, response.StatusCode, response.Body)
+			}
+
+			if tt.want == 200 {
+				var updated team.Team
+				json.Unmarshal([]byte(response.Body), &updated)
+				if updated.ID != "search" || updated.SystemPrompt != "Mention the search SLOs." {
+					t.Errorf("Unexpected team: %+v", updated)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteTeam(t *testing.T) {
+	useStore(t, seededStore(t, team.RoleAdmin))
+
+	response, err := Handler(context.Background(), newRequest("DELETE", "search", ""))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 403 {
+		t.Errorf("Expected team admins to be refused, got %d", response.StatusCode)
+	}
+
+	response, err = Handler(context.Background(), newRequest("DELETE", "search", "", "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	response, err = Handler(context.Background(), newRequest("DELETE", "search", "", "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404 for a deleted team, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/logging"
+	"tuitui-backend/internal/team"
+	"tuitui-backend/internal/usage"
+	"tuitui-backend/pkg/api"
+)
+
+// UsageResponse represents the response for a usage report
+type UsageResponse struct {
+	Subject string            `json:"subject"`
+	ID      string            `json:"id"`
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Daily   []usage.Aggregate `json:"daily"`
+	Monthly []usage.Aggregate `json:"monthly"`
+}
+
+// defaultDays is how far back a report goes when no start date is given
+const defaultDays = 30
+
+// maxDays caps the range of a single report
+const maxDays = 366
+
+// newStore creates the usage store; tests replace it with an in-memory store
+var newStore = usage.NewStore
+
+// newTeamStore creates the team store used to recognise team admins; tests replace it
+var newTeamStore = team.NewStore
+
+// now returns the current time; tests replace it
+var now = time.Now
+
+// Handler is the Lambda function handler for GET /usage. Users see their own
+// usage and team admins their team's; reports for another user (?user=) or any
+// other team (?team=) require the admin group.
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// CORS headers for all responses
+	corsHeaders := api.CORSHeaders("GET,OPTIONS")
+
+	// Handle OPTIONS preflight request
+	if request.HTTPMethod == "OPTIONS" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    corsHeaders,
+		}, nil
+	}
+
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return api.Error(401, "No authorization context found", corsHeaders), nil
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load configuration: %v", err), corsHeaders), nil
+	}
+
+	params := request.QueryStringParameters
+	subject, id := usage.SubjectUser, user.Sub
+	switch {
+	case params["team"] != "" && params["user"] != "":
+		return api.Error(400, "Specify either team or user, not both", corsHeaders), nil
+	case params["team"] != "":
+		subject, id = usage.SubjectTeam, usage.NormalizeTeam(params["team"])
+	case params["user"] != "":
+		id = params["user"]
+	}
+
+	allowed := user.InGroup(cfg.AdminGroup) || (subject == usage.SubjectUser && id == user.Sub)
+	if !allowed && subject == usage.SubjectTeam {
+		allowed, err = isTeamAdmin(ctx, cfg, user.Sub, id)
+		if err != nil {
+			return api.Error(500, fmt.Sprintf("Failed to check team access: %v", err), corsHeaders), nil
+		}
+	}
+	if !allowed {
+		return api.Error(403, "Admin access required", corsHeaders), nil
+	}
+
+	from, to, err := parseRange(params["from"], params["to"])
+	if err != nil {
+		return api.Error(400, err.Error(), corsHeaders), nil
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to create usage store: %v", err), corsHeaders), nil
+	}
+
+	report := UsageResponse{
+		Subject: subject,
+		ID:      id,
+		From:    from.Format(usage.DayLayout),
+		To:      to.Format(usage.DayLayout),
+	}
+
+	report.Daily, err = store.Aggregates(ctx, usage.Query{
+		Subject: subject,
+		ID:      id,
+		Period:  usage.PeriodDay,
+		From:    report.From,
+		To:      report.To,
+	})
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load usage: %v", err), corsHeaders), nil
+	}
+
+	report.Monthly, err = store.Aggregates(ctx, usage.Query{
+		Subject: subject,
+		ID:      id,
+		Period:  usage.PeriodMonth,
+		From:    from.Format(usage.MonthLayout),
+		To:      to.Format(usage.MonthLayout),
+	})
+	if err != nil {
+		return api.Error(500, fmt.Sprintf("Failed to load usage: %v", err), corsHeaders), nil
+	}
+
+	return api.JSON(200, report, corsHeaders), nil
+}
+
+// isTeamAdmin reports whether the user administers the team. Without a teams
+// table there are no team admins.
+func isTeamAdmin(ctx context.Context, cfg *config.Config, userID, teamID string) (bool, error) {
+	store, err := newTeamStore(cfg)
+	if errors.Is(err, team.ErrNotConfigured) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return team.CanManage(ctx, store, userID, false, teamID)
+}
+
+// parseRange parses the from and to dates (YYYY-MM-DD), defaulting to the
+// last defaultDays days up to today
+func parseRange(fromParam, toParam string) (time.Time, time.Time, error) {
+	to := now().UTC().Truncate(24 * time.Hour)
+	if toParam != "" {
+		parsed, err := time.Parse(usage.DayLayout, toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid to date, expected YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, 1-defaultDays)
+	if fromParam != "" {
+		parsed, err := time.Parse(usage.DayLayout, fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid from date, expected YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) >= maxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("Date range must not exceed %d days", maxDays)
+	}
+
+	return from, to, nil
+}
+
+func main() {
+	// Start Lambda handler, logging at the configured LOG_LEVEL
+	cfg, _ := config.Load()
+	logger := logging.New(cfg)
+	lambda.Start(logging.Wrap(logger, Handler))
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/team"
+	"tuitui-backend/internal/usage"
+)
+
+// useStore makes the handler use store for the duration of the test
+func useStore(t *testing.T, store usage.Store) {
+	t.Helper()
+	original := newStore
+	newStore = func(cfg *config.Config) (usage.Store, error) {
+		return store, nil
+	}
+	t.Cleanup(func() { newStore = original })
+}
+
+// useNow fixes the handler's clock for the duration of the test
+func useNow(t *testing.T, at time.Time) {
+	t.Helper()
+	original := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = original })
+}
+
+// newRequest returns a GET request from user-1 in groups with the given query
+func newRequest(params map[string]string, groups ...interface{}) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		QueryStringParameters: params,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "user-1",
+					"cognito:groups": groups,
+				},
+			},
+		},
+	}
+}
+
+// seededStore returns a store holding usage for user-1 and user-2 in the search team
+func seededStore(t *testing.T) usage.Store {
+	store := usage.NewMemoryStore()
+	for _, event := range []usage.Event{
+		{UserID: "user-1", Team: "Search", InputTokens: 100, OutputTokens: 10, Time: time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)},
+		{UserID: "user-1", Team: "Search", InputTokens: 200, OutputTokens: 20, Time: time.Date(2025, 4, 2, 12, 0, 0, 0, time.UTC)},
+		{UserID: "user-2", Team: "Search", InputTokens: 50, OutputTokens: 5, Time: time.Date(2025, 4, 2, 13, 0, 0, 0, time.UTC)},
+	} {
+		if err := store.Record(context.Background(), event); err != nil {
+			t.Fatalf("Record returned error: %v", err)
+		}
+	}
+	return store
+}
+
+func TestHandler_OptionsRequest(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	if response.Headers["Access-Control-Allow-Methods"] != "GET,OPTIONS" {
+		t.Errorf("Unexpected CORS methods header: %s", response.Headers["Access-Control-Allow-Methods"])
+	}
+}
+
+func TestHandler_NoAuthorizer(t *testing.T) {
+	response, err := Handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET"})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if response.StatusCode != 401 {
+		t.Errorf("Expected status 401, got %d", response.StatusCode)
+	}
+}
+
+func TestHandler_OwnUsageDefaultsToLast30Days(t *testing.T) {
+	useStore(t, seededStore(t))
+	useNow(t, time.Date(2025, 4, 10, 8, 0, 0, 0, time.UTC))
+
+	response, err := Handler(context.Background(), newRequest(nil))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var report UsageResponse
+	json.Unmarshal([]byte(response.Body), &report)
+
+	if report.Subject != "user" || report.ID != "user-1" || report.From != "2025-03-12" || report.To != "2025-04-10" {
+		t.Errorf("Unexpected report range: %+v", report)
+	}
+	if len(report.Daily) != 2 || report.Daily[1].Period != "2025-04-02" || report.Daily[1].TotalTokens != 220 {
+		t.Errorf("Unexpected daily usage: %+v", report.Daily)
+	}
+	if len(report.Monthly) != 2 || report.Monthly[0].Period != "2025-03" || report.Monthly[1].InputTokens != 200 {
+		t.Errorf("Unexpected monthly usage: %+v", report.Monthly)
+	}
+}
+
+func TestHandler_TeamUsageRequiresAdmin(t *testing.T) {
+	useStore(t, seededStore(t))
+
+	for _, params := range []map[string]string{{"team": "Search"}, {"user": "user-2"}} {
+		response, err := Handler(context.Background(), newRequest(params, "developers"))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 403 {
+			t.Errorf("Expected status 403 for %v, got %d", params, response.StatusCode)
+		}
+	}
+}
+
+func TestHandler_AdminTeamUsage(t *testing.T) {
+	useStore(t, seededStore(t))
+
+	params := map[string]string{"team": " Search ", "from": "2025-04-01", "to": "2025-04-30"}
+	response, err := Handler(context.Background(), newRequest(params, "admin"))
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d: %s", response.StatusCode, response.Body)
+	}
+
+	var report UsageResponse
+	json.Unmarshal([]byte(response.Body), &report)
+
+	if report.Subject != "team" || report.ID != "search" {
+		t.Errorf("Expected the normalized team, got %s %s", report.Subject, report.ID)
+	}
+	if len(report.Daily) != 1 || report.Daily[0].InputTokens != 250 || report.Daily[0].Requests != 2 {
+		t.Errorf("Unexpected daily team usage: %+v", report.Daily)
+	}
+}
+
+func TestHandler_TeamAdminUsage(t *testing.T) {
+	useStore(t, seededStore(t))
+
+	teams := team.NewMemoryStore(team.Team{ID: "search", Name: "Search"}, team.Team{ID: "payments", Name: "Payments"})
+	teams.PutMember(context.Background(), team.Member{TeamID: "search", UserID: "user-1", Role: team.RoleAdmin})
+	teams.PutMember(context.Background(), team.Member{TeamID: "payments", UserID: "user-1", Role: team.RoleMember})
+	original := newTeamStore
+	newTeamStore = func(cfg *config.Config) (team.Store, error) {
+		return teams, nil
+	}
+	t.Cleanup(func() { newTeamStore = original })
+
+	tests := map[string]int{"search": 200, "payments": 403}
+	for teamID, want := range tests {
+		response, err := Handler(context.Background(), newRequest(map[string]string{"team": teamID}))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != want {
+			t.Errorf("Expected status %d for %s, got %d", want, teamID, response.StatusCode)
+		}
+	}
+}
+
+func TestHandler_InvalidRange(t *testing.T) {
+	useStore(t, seededStore(t))
+
+	for _, params := range []map[string]string{
+		{"from": "April"},
+		{"from": "2025-04-10", "to": "2025-04-01"},
+		{"from": "2023-01-01", "to": "2025-01-01"},
+		{"team": "search", "user": "user-2"},
+	} {
+		response, err := Handler(context.Background(), newRequest(params, "admin"))
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if response.StatusCode != 400 {
+			t.Errorf("Expected status 400 for %v, got %d", params, response.StatusCode)
+		}
+	}
+}
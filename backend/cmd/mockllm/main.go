@@ -0,0 +1,93 @@
+// Command mockllm serves a mock Anthropic Messages API for offline development.
+// Point the chat Lambda, or the dev server, at it with
+//
+//	AI_PROVIDER=anthropic
+//	AI_API_ENDPOINT=http://localhost:4010/v1/messages
+//	AMAZON_AI_API_KEY=mock
+//
+// Without a script every message gets an echo of the last user message.
+// -script names a JSON file with a list of replies to answer with first, e.g.
+//
+//	[
+//	  {"text": "Deploys are frozen on Fridays."},
+//	  {"content": [{"type": "tool_use", "id": "tu_1", "name": "search_knowledge_base", "input": {"query": "deploy"}}]},
+//	  {"status": 529},
+//	  {"status": 429, "retry_after": 1}
+//	]
+//
+// Replies can also be added while it runs by POSTing them to /_mock/replies;
+// GET /_mock/requests lists what the server received.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"tuitui-backend/internal/mockllm"
+)
+
+// loadScript reads a JSON list of replies
+func loadScript(path string) ([]mockllm.Reply, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var replies []mockllm.Reply
+	if err := json.Unmarshal(data, &replies); err != nil {
+		return nil, fmt.Errorf("invalid script %s: %v", path, err)
+	}
+	return replies, nil
+}
+
+// logRequests logs the method, path and status of each request
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: 200}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %d", r.Method, r.URL.Path, rec.status)
+	})
+}
+
+// statusRecorder remembers the status code written, and still flushes streams
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:4010", "address to listen on")
+	script := flag.String("script", "", "JSON file of replies to answer with, in order")
+	apiKey := flag.String("api-key", "", "x-api-key to require; any key is accepted when empty")
+	flag.Parse()
+
+	server := mockllm.New()
+	server.APIKey = *apiKey
+	if *script != "" {
+		replies, err := loadScript(*script)
+		if err != nil {
+			log.Fatalf("Failed to load script: %v", err)
+		}
+		server.Enqueue(replies...)
+		log.Printf("Loaded %d scripted replies", len(replies))
+	}
+
+	fmt.Printf("Mock Messages API listening; set AI_API_ENDPOINT=http://%s%s\n", *addr, mockllm.MessagesPath)
+	if err := http.ListenAndServe(*addr, logRequests(server)); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
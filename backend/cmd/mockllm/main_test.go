@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.json")
+	script := `[{"text":"Hi"},{"content":[{"type":"tool_use","id":"tu_1","name":"search_knowledge_base","input":{"query":"x"}}]},{"status":529}]`
+	if err := os.WriteFile(path, []byte(script), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	replies, err := loadScript(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(replies) != 3 || replies[0].Text != "Hi" || replies[1].Content[0].Name != "search_knowledge_base" || replies[2].Status != 529 {
+		t.Errorf("Unexpected replies %+v", replies)
+	}
+
+	os.WriteFile(path, []byte(`{"text":"not a list"}`), 0o600)
+	if _, err := loadScript(path); err == nil {
+		t.Error("Expected an error for a script that is not a list")
+	}
+}
@@ -5,9 +5,8 @@ go 1.24.4
 require (
 	github.com/aws/aws-lambda-go v1.50.0
 	github.com/aws/aws-sdk-go v1.55.8
+	github.com/ledongthuc/pdf v0.0.0-20220302134840-0c2507a12d80
+	golang.org/x/net v0.38.0
 )
 
-require (
-	github.com/golang-jwt/jwt/v5 v5.3.0 // indirect
-	github.com/jmespath/go-jmespath v0.4.0 // indirect
-)
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
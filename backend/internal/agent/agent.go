@@ -0,0 +1,254 @@
+// Package agent runs the tool-use loop for a chat turn: registered tools are
+// advertised to the model, and every tool_use block it returns is executed and
+// fed back as a tool_result until the model gives a final answer.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tuitui-backend/internal/llm"
+)
+
+// DefaultMaxIterations bounds the number of model calls in one turn
+const DefaultMaxIterations = 5
+
+// continuePrompt asks the model to carry on with a reply that hit its token limit
+const continuePrompt = "Your previous reply was cut off. Continue exactly where it stopped, without repeating anything."
+
+// ErrMaxIterations is returned when the model is still calling tools after the last iteration
+var ErrMaxIterations = errors.New("model did not give a final answer within the tool call limit")
+
+// Tool is a capability the model can invoke
+type Tool interface {
+	// Name is the identifier the model uses to call the tool
+	Name() string
+
+	// Description tells the model when to use the tool
+	Description() string
+
+	// InputSchema is the JSON Schema of the tool input
+	InputSchema() json.RawMessage
+
+	// Run executes the tool and returns text for the model
+	Run(ctx context.Context, input json.RawMessage) (string, error)
+}
+
+// Registry holds the tools available to a turn, in registration order
+type Registry struct {
+	tools  []Tool
+	byName map[string]Tool
+}
+
+// NewRegistry creates a registry holding tools
+func NewRegistry(tools ...Tool) *Registry {
+	r := &Registry{byName: make(map[string]Tool)}
+	for _, tool := range tools {
+		r.Register(tool)
+	}
+	return r
+}
+
+// Register adds a tool, replacing any tool with the same name
+func (r *Registry) Register(tool Tool) {
+	if _, ok := r.byName[tool.Name()]; ok {
+		for i, existing := range r.tools {
+			if existing.Name() == tool.Name() {
+				r.tools[i] = tool
+			}
+		}
+	} else {
+		r.tools = append(r.tools, tool)
+	}
+	r.byName[tool.Name()] = tool
+}
+
+// Lookup returns the tool with the given name
+func (r *Registry) Lookup(name string) (Tool, bool) {
+	tool, ok := r.byName[name]
+	return tool, ok
+}
+
+// Definitions describes the registered tools for a model request
+func (r *Registry) Definitions() []llm.Tool {
+	definitions := make([]llm.Tool, len(r.tools))
+	for i, tool := range r.tools {
+		definitions[i] = llm.Tool{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			InputSchema: tool.InputSchema(),
+		}
+	}
+	return definitions
+}
+
+// Call records one tool invocation
+type Call struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Input      json.RawMessage `json:"input"`
+	Output     string          `json:"output"`
+	IsError    bool            `json:"is_error,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+}
+
+// Result is the outcome of a turn
+type Result struct {
+	// Response is the model's final reply
+	Response *llm.Response
+
+	// Usage is summed across every model call in the turn
+	Usage llm.Usage
+
+	// Calls lists every tool invocation in the order it ran
+	Calls []Call
+
+	// Iterations is the number of model calls made
+	Iterations int
+
+	// Truncated is set when the final reply still hit the token limit after
+	// every allowed continuation
+	Truncated bool
+}
+
+// Options control a turn
+type Options struct {
+	// MaxIterations bounds the number of model calls; DefaultMaxIterations when zero
+	MaxIterations int
+
+	// MaxContinuations is how many times a reply cut off at the token limit is
+	// continued with a further model call; zero returns it marked Truncated
+	MaxContinuations int
+
+	// OnDelta, when set, streams the text of every model call as it is generated.
+	// Text the model wrote before calling a tool has then already reached the
+	// user, so it is kept at the start of the final reply.
+	OnDelta func(text string) error
+}
+
+// Run sends req to provider and executes the tools it asks for until it gives a
+// final answer. With a nil or empty registry it makes a single model call.
+func Run(ctx context.Context, provider llm.Provider, req llm.Request, registry *Registry, opts Options) (*Result, error) {
+	maxIterations := opts.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxIterations
+	}
+
+	if registry != nil {
+		req.Tools = registry.Definitions()
+	}
+
+	// Copy the messages so the caller's request is not modified as the turn grows
+	req.Messages = append([]llm.Message(nil), req.Messages...)
+
+	result := &Result{}
+
+	// Everything streamed is recorded, so text sent before a tool call can be
+	// kept in the final reply and what is saved matches what the user saw
+	var streamed strings.Builder
+	onDelta := opts.OnDelta
+	if onDelta != nil {
+		onDelta = func(text string) error {
+			streamed.WriteString(text)
+			return opts.OnDelta(text)
+		}
+	}
+
+	// Continuations do not count towards the tool call limit
+	continuations := 0
+	var continued strings.Builder
+	var kept string
+	for result.Iterations < maxIterations+continuations {
+		resp, err := complete(ctx, provider, req, onDelta)
+		if err != nil {
+			return result, err
+		}
+
+		result.Iterations++
+		result.Usage.InputTokens += resp.Usage.InputTokens
+		result.Usage.OutputTokens += resp.Usage.OutputTokens
+
+		uses := resp.ToolUses()
+		if resp.StopReason == llm.StopReasonMaxTokens && len(uses) == 0 && resp.Text != "" && continuations < opts.MaxContinuations {
+			continuations++
+			continued.WriteString(resp.Text)
+			req.Messages = append(req.Messages,
+				llm.Message{Role: "assistant", Blocks: resp.Content},
+				llm.Message{Role: "user", Content: continuePrompt},
+			)
+			continue
+		}
+
+		if resp.StopReason != llm.StopReasonToolUse || len(uses) == 0 || registry == nil {
+			if kept != "" || continued.Len() > 0 {
+				joined := *resp
+				joined.Text = kept + continued.String() + resp.Text
+				resp = &joined
+			}
+			result.Response = resp
+			result.Truncated = resp.StopReason == llm.StopReasonMaxTokens
+			return result, nil
+		}
+
+		// Text cut off before a tool call is not part of the final answer,
+		// unless it was streamed
+		kept = streamed.String()
+		continued.Reset()
+
+		toolResults := make([]llm.ContentBlock, len(uses))
+		for i, use := range uses {
+			call := runTool(ctx, registry, use)
+			result.Calls = append(result.Calls, call)
+			toolResults[i] = llm.ContentBlock{
+				Type:      llm.BlockToolResult,
+				ToolUseID: use.ID,
+				Content:   call.Output,
+				IsError:   call.IsError,
+			}
+		}
+
+		req.Messages = append(req.Messages,
+			llm.Message{Role: "assistant", Blocks: resp.Content},
+			llm.Message{Role: "user", Blocks: toolResults},
+		)
+	}
+
+	return result, ErrMaxIterations
+}
+
+// complete makes one model call, streaming it when onDelta is set
+func complete(ctx context.Context, provider llm.Provider, req llm.Request, onDelta func(string) error) (*llm.Response, error) {
+	if onDelta != nil {
+		return llm.Stream(ctx, provider, req, onDelta)
+	}
+	return provider.Complete(ctx, req)
+}
+
+// runTool executes one tool_use block. Failures are reported to the model as an
+// error result rather than ending the turn.
+func runTool(ctx context.Context, registry *Registry, use llm.ContentBlock) Call {
+	call := Call{ID: use.ID, Name: use.Name, Input: use.Input}
+	start := time.Now()
+
+	tool, ok := registry.Lookup(use.Name)
+	if !ok {
+		call.Output = fmt.Sprintf("unknown tool %q", use.Name)
+		call.IsError = true
+		return call
+	}
+
+	output, err := tool.Run(ctx, use.Input)
+	call.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		call.Output = err.Error()
+		call.IsError = true
+		return call
+	}
+
+	call.Output = output
+	return call
+}
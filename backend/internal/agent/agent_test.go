@@ -0,0 +1,263 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"tuitui-backend/internal/llm"
+)
+
+// scriptedProvider returns its replies in order and records every request
+type scriptedProvider struct {
+	replies  []*llm.Response
+	requests []llm.Request
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	p.requests = append(p.requests, req)
+	if len(p.replies) == 0 {
+		return nil, errors.New("no more replies")
+	}
+	reply := p.replies[0]
+	p.replies = p.replies[1:]
+	return reply, nil
+}
+
+// echoTool returns its input, or fails when fail is set
+type echoTool struct {
+	fail bool
+}
+
+func (echoTool) Name() string                 { return "echo" }
+func (echoTool) Description() string          { return "Echo the input" }
+func (echoTool) InputSchema() json.RawMessage { return json.RawMessage(`{"type":"object"}`) }
+
+func (e echoTool) Run(ctx context.Context, input json.RawMessage) (string, error) {
+	if e.fail {
+		return "", errors.New("echo failed")
+	}
+	return string(input), nil
+}
+
+func toolUseReply(id, name string) *llm.Response {
+	return &llm.Response{
+		StopReason: llm.StopReasonToolUse,
+		Usage:      llm.Usage{InputTokens: 10, OutputTokens: 2},
+		Content: []llm.ContentBlock{
+			{Type: llm.BlockText, Text: "Checking"},
+			{Type: llm.BlockToolUse, ID: id, Name: name, Input: json.RawMessage(`{"q":1}`)},
+		},
+	}
+}
+
+func finalReply(text string) *llm.Response {
+	return &llm.Response{
+		Text:       text,
+		StopReason: llm.StopReasonEndTurn,
+		Usage:      llm.Usage{InputTokens: 20, OutputTokens: 5},
+		Content:    []llm.ContentBlock{{Type: llm.BlockText, Text: text}},
+	}
+}
+
+func TestRun_ExecutesToolsUntilFinalAnswer(t *testing.T) {
+	provider := &scriptedProvider{replies: []*llm.Response{
+		toolUseReply("tu_1", "echo"),
+		toolUseReply("tu_2", "missing"),
+		finalReply("Done"),
+	}}
+	req := llm.Request{Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+
+	result, err := Run(context.Background(), provider, req, NewRegistry(echoTool{}), Options{})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if result.Response.Text != "Done" || result.Iterations != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.Usage.InputTokens != 40 || result.Usage.OutputTokens != 9 {
+		t.Errorf("Expected usage summed across calls, got %+v", result.Usage)
+	}
+
+	if len(result.Calls) != 2 {
+		t.Fatalf("Expected 2 recorded calls, got %+v", result.Calls)
+	}
+	if result.Calls[0].Name != "echo" || result.Calls[0].Output != `{"q":1}` || result.Calls[0].IsError {
+		t.Errorf("Unexpected first call: %+v", result.Calls[0])
+	}
+	if !result.Calls[1].IsError {
+		t.Errorf("Expected unknown tool to be reported as an error, got %+v", result.Calls[1])
+	}
+
+	if len(provider.requests[0].Tools) != 1 || provider.requests[0].Tools[0].Name != "echo" {
+		t.Errorf("Expected echo tool to be advertised, got %+v", provider.requests[0].Tools)
+	}
+
+	last := provider.requests[2].Messages
+	if len(last) != 5 {
+		t.Fatalf("Expected the tool turns in the final request, got %d messages", len(last))
+	}
+	if last[1].Role != "assistant" || last[1].Blocks[1].Type != llm.BlockToolUse {
+		t.Errorf("Expected assistant tool_use turn, got %+v", last[1])
+	}
+	if result := last[2].Blocks[0]; result.Type != llm.BlockToolResult || result.ToolUseID != "tu_1" {
+		t.Errorf("Expected tool_result for tu_1, got %+v", result)
+	}
+
+	if len(req.Messages) != 1 {
+		t.Error("Expected the caller's request to be left unchanged")
+	}
+}
+
+func TestRun_ToolErrorIsReturnedToModel(t *testing.T) {
+	provider := &scriptedProvider{replies: []*llm.Response{toolUseReply("tu_1", "echo"), finalReply("Sorry")}}
+
+	result, err := Run(context.Background(), provider, llm.Request{}, NewRegistry(echoTool{fail: true}), Options{})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	sent := provider.requests[1].Messages[1].Blocks[0]
+	if !sent.IsError || sent.Content != "echo failed" {
+		t.Errorf("Expected error tool_result, got %+v", sent)
+	}
+	if !result.Calls[0].IsError {
+		t.Error("Expected the failed call to be recorded as an error")
+	}
+}
+
+func TestRun_MaxIterations(t *testing.T) {
+	provider := &scriptedProvider{replies: []*llm.Response{
+		toolUseReply("tu_1", "echo"),
+		toolUseReply("tu_2", "echo"),
+		toolUseReply("tu_3", "echo"),
+	}}
+
+	result, err := Run(context.Background(), provider, llm.Request{}, NewRegistry(echoTool{}), Options{MaxIterations: 2})
+	if !errors.Is(err, ErrMaxIterations) {
+		t.Fatalf("Expected ErrMaxIterations, got %v", err)
+	}
+	if result.Iterations != 2 || len(result.Calls) != 2 {
+		t.Errorf("Expected 2 iterations and calls, got %+v", result)
+	}
+}
+
+func TestRun_StreamsDeltas(t *testing.T) {
+	provider := &scriptedProvider{replies: []*llm.Response{finalReply("Hello")}}
+
+	var deltas []string
+	result, err := Run(context.Background(), provider, llm.Request{}, nil, Options{
+		OnDelta: func(text string) error {
+			deltas = append(deltas, text)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if len(deltas) != 1 || deltas[0] != "Hello" || result.Response.Text != "Hello" {
+		t.Errorf("Expected the reply as a delta, got %v", deltas)
+	}
+	if len(provider.requests[0].Tools) != 0 {
+		t.Error("Expected no tools without a registry")
+	}
+}
+
+func TestRun_StreamedTextBeforeToolsIsKept(t *testing.T) {
+	checking := toolUseReply("tu_1", "echo")
+	checking.Text = "Checking. "
+	provider := &scriptedProvider{replies: []*llm.Response{checking, finalReply("Done")}}
+
+	var streamed string
+	result, err := Run(context.Background(), provider, llm.Request{}, NewRegistry(echoTool{}), Options{
+		OnDelta: func(text string) error {
+			streamed += text
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if streamed != "Checking. Done" || result.Response.Text != streamed {
+		t.Errorf("Expected the reply to match what was streamed %q, got %q", streamed, result.Response.Text)
+	}
+
+	// Without streaming the user never saw the text, so only the answer is returned
+	checking.Text = "Checking. "
+	provider = &scriptedProvider{replies: []*llm.Response{checking, finalReply("Done")}}
+	result, _ = Run(context.Background(), provider, llm.Request{}, NewRegistry(echoTool{}), Options{})
+	if result.Response.Text != "Done" {
+		t.Errorf("Expected only the final answer, got %q", result.Response.Text)
+	}
+}
+
+func TestRegistry_RegisterReplaces(t *testing.T) {
+	registry := NewRegistry(echoTool{}, echoTool{fail: true})
+
+	if len(registry.Definitions()) != 1 {
+		t.Errorf("Expected one definition, got %d", len(registry.Definitions()))
+	}
+	tool, _ := registry.Lookup("echo")
+	if _, err := tool.Run(context.Background(), nil); err == nil {
+		t.Error("Expected the later registration to win")
+	}
+}
+
+func cutOffReply(text string) *llm.Response {
+	reply := finalReply(text)
+	reply.StopReason = llm.StopReasonMaxTokens
+	return reply
+}
+
+func TestRun_ContinuesTruncatedReply(t *testing.T) {
+	provider := &scriptedProvider{replies: []*llm.Response{
+		cutOffReply("Deploys are "),
+		finalReply("frozen on Fridays."),
+	}}
+	req := llm.Request{Messages: []llm.Message{{Role: "user", Content: "Can I deploy?"}}}
+
+	result, err := Run(context.Background(), provider, req, nil, Options{MaxIterations: 1, MaxContinuations: 2})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if result.Response.Text != "Deploys are frozen on Fridays." || result.Truncated {
+		t.Errorf("Expected the joined reply, got %q (truncated %v)", result.Response.Text, result.Truncated)
+	}
+	if result.Iterations != 2 || result.Usage.InputTokens != 40 {
+		t.Errorf("Expected both calls counted, got %+v", result)
+	}
+
+	second := provider.requests[1].Messages
+	if len(second) != 3 || second[1].Role != "assistant" || second[1].Blocks[0].Text != "Deploys are " || second[2].Content != continuePrompt {
+		t.Errorf("Expected the cut-off reply and a continue prompt, got %+v", second)
+	}
+}
+
+func TestRun_MarksTruncatedReply(t *testing.T) {
+	provider := &scriptedProvider{replies: []*llm.Response{
+		cutOffReply("Deploys are "),
+		cutOffReply("frozen on "),
+	}}
+	req := llm.Request{Messages: []llm.Message{{Role: "user", Content: "Can I deploy?"}}}
+
+	result, err := Run(context.Background(), provider, req, nil, Options{MaxContinuations: 1})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if !result.Truncated || result.Response.Text != "Deploys are frozen on " {
+		t.Errorf("Expected a truncated reply after the last continuation, got %q (truncated %v)", result.Response.Text, result.Truncated)
+	}
+
+	result, _ = Run(context.Background(), &scriptedProvider{replies: []*llm.Response{cutOffReply("Deploys")}}, req, nil, Options{})
+	if !result.Truncated || result.Iterations != 1 {
+		t.Errorf("Expected no continuation by default, got %+v", result)
+	}
+}
@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"tuitui-backend/internal/knowledge"
+)
+
+// queryInputSchema is the input schema shared by the search tools
+var queryInputSchema = json.RawMessage(`{"type":"object","properties":{"query":{"type":"string","description":"What to search for"}},"required":["query"]}`)
+
+// queryInput is the decoded input of the search tools
+type queryInput struct {
+	Query string `json:"query"`
+}
+
+func parseQuery(input json.RawMessage) (string, error) {
+	var in queryInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("invalid input: %v", err)
+	}
+	if strings.TrimSpace(in.Query) == "" {
+		return "", fmt.Errorf("query is required")
+	}
+	return in.Query, nil
+}
+
+// KnowledgeSearch looks up entries in the knowledge base
+type KnowledgeSearch struct {
+	Store knowledge.Store
+	Limit int
+}
+
+// NewKnowledgeSearch creates a knowledge base search tool
+func NewKnowledgeSearch(store knowledge.Store) *KnowledgeSearch {
+	return &KnowledgeSearch{Store: store, Limit: 5}
+}
+
+// Name returns the tool name
+func (k *KnowledgeSearch) Name() string {
+	return "search_knowledge_base"
+}
+
+// Description tells the model when to use the tool
+func (k *KnowledgeSearch) Description() string {
+	return "Search the TuiTui team knowledge base for owners, contacts, processes and documentation links."
+}
+
+// InputSchema returns the tool input schema
+func (k *KnowledgeSearch) InputSchema() json.RawMessage {
+	return queryInputSchema
+}
+
+// Run returns the entries matching the query
+func (k *KnowledgeSearch) Run(ctx context.Context, input json.RawMessage) (string, error) {
+	query, err := parseQuery(input)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := k.Store.List(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	matches := knowledge.Relevant(entries, query, k.Limit)
+	if len(matches) == 0 {
+		return "No knowledge base entries matched.", nil
+	}
+	return knowledge.FormatPrompt(matches), nil
+}
+
+// RunbookLinks finds links in the team link list
+type RunbookLinks struct {
+	Links []string
+}
+
+// NewRunbookLinks creates a runbook link lookup over links
+func NewRunbookLinks(links []string) *RunbookLinks {
+	return &RunbookLinks{Links: links}
+}
+
+// Name returns the tool name
+func (r *RunbookLinks) Name() string {
+	return "find_runbook_link"
+}
+
+// Description tells the model when to use the tool
+func (r *RunbookLinks) Description() string {
+	return "Find runbook, documentation and board links from the team link list by keyword, e.g. \"search results\" or \"booking\"."
+}
+
+// InputSchema returns the tool input schema
+func (r *RunbookLinks) InputSchema() json.RawMessage {
+	return queryInputSchema
+}
+
+// Run returns the links whose URL contains any of the query words, best match first
+func (r *RunbookLinks) Run(ctx context.Context, input json.RawMessage) (string, error) {
+	query, err := parseQuery(input)
+	if err != nil {
+		return "", err
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+
+	type match struct {
+		link  string
+		score int
+	}
+	var matches []match
+	for _, link := range r.Links {
+		normalized := strings.ToLower(link)
+		score := 0
+		for _, term := range terms {
+			if len(term) > 2 && strings.Contains(normalized, term) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, match{link, score})
+		}
+	}
+
+	if len(matches) == 0 {
+		return "No runbook links matched.", nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	links := make([]string, len(matches))
+	for i, m := range matches {
+		links[i] = m.link
+	}
+	return strings.Join(links, "\n"), nil
+}
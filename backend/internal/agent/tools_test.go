@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tuitui-backend/internal/knowledge"
+)
+
+var teamLinks = []string{
+	"https://runway.devops.tui/docs/default/component/usl-docs/capabilities/book/#how-to-use",
+	"https://runway.devops.tui/docs/default/component/flightsearchresults/#mfe-search-results",
+	"https://tui.atlassian.net/jira/software/c/projects/SCPKG/boards/3782",
+}
+
+func TestRunbookLinks(t *testing.T) {
+	tool := NewRunbookLinks(teamLinks)
+
+	output, err := tool.Run(context.Background(), json.RawMessage(`{"query": "flight search results"}`))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if output != teamLinks[1] {
+		t.Errorf("Expected the search results link, got %q", output)
+	}
+
+	output, _ = tool.Run(context.Background(), json.RawMessage(`{"query": "payroll"}`))
+	if output != "No runbook links matched." {
+		t.Errorf("Expected no matches, got %q", output)
+	}
+
+	if _, err := tool.Run(context.Background(), json.RawMessage(`{}`)); err == nil {
+		t.Error("Expected error for missing query")
+	}
+}
+
+func TestKnowledgeSearch(t *testing.T) {
+	tool := NewKnowledgeSearch(knowledge.NewMemoryStore(
+		knowledge.Entry{Title: "USL 502 errors", Body: "Contact Rhydian Downing.", Tags: []string{"usl"}},
+	))
+
+	output, err := tool.Run(context.Background(), json.RawMessage(`{"query": "usl"}`))
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.Contains(output, "Contact Rhydian Downing.") {
+		t.Errorf("Expected the USL entry, got %q", output)
+	}
+
+	output, _ = tool.Run(context.Background(), json.RawMessage(`{"query": "payroll"}`))
+	if output != "No knowledge base entries matched." {
+		t.Errorf("Expected no matches, got %q", output)
+	}
+}
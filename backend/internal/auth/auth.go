@@ -0,0 +1,79 @@
+// Package auth extracts the caller's identity from the Cognito authorizer context
+// that API Gateway attaches to authenticated requests.
+package auth
+
+import (
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// User holds the identity claims of an authenticated caller
+type User struct {
+	Sub    string
+	Email  string
+	Name   string
+	Groups []string
+}
+
+// UserFromRequest returns the authenticated user, or false when the request did not
+// pass through the Cognito authorizer
+func UserFromRequest(request events.APIGatewayProxyRequest) (*User, bool) {
+	claims := Claims(request)
+	if claims == nil {
+		return nil, false
+	}
+
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, false
+	}
+
+	user := &User{Sub: sub}
+	user.Email, _ = claims["email"].(string)
+	user.Name, _ = claims["name"].(string)
+	user.Groups = parseGroups(claims["cognito:groups"])
+
+	return user, true
+}
+
+// Claims returns the raw Cognito claims from the authorizer context, or nil
+func Claims(request events.APIGatewayProxyRequest) map[string]interface{} {
+	if request.RequestContext.Authorizer == nil {
+		return nil
+	}
+
+	claims, _ := request.RequestContext.Authorizer["claims"].(map[string]interface{})
+	return claims
+}
+
+// InGroup reports whether the user belongs to the named Cognito group
+func (u *User) InGroup(group string) bool {
+	for _, g := range u.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGroups handles both shapes API Gateway uses for cognito:groups: a list, or
+// a single string such as "[admin editors]" or "admin,editors"
+func parseGroups(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		groups := make([]string, 0, len(v))
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+		return groups
+	case string:
+		v = strings.Trim(v, "[]")
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func requestWithClaims(claims map[string]interface{}) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": claims,
+			},
+		},
+	}
+}
+
+func TestUserFromRequest(t *testing.T) {
+	request := requestWithClaims(map[string]interface{}{
+		"sub":            "user-123",
+		"email":          "test@example.com",
+		"name":           "Test User",
+		"cognito:groups": "[admin editors]",
+	})
+
+	user, ok := UserFromRequest(request)
+	if !ok {
+		t.Fatal("Expected user to be found")
+	}
+
+	if user.Sub != "user-123" {
+		t.Errorf("Expected sub 'user-123', got '%s'", user.Sub)
+	}
+
+	if user.Email != "test@example.com" {
+		t.Errorf("Expected email 'test@example.com', got '%s'", user.Email)
+	}
+
+	if !user.InGroup("admin") || !user.InGroup("editors") {
+		t.Errorf("Expected admin and editors groups, got %v", user.Groups)
+	}
+}
+
+func TestUserFromRequest_NoAuthorizer(t *testing.T) {
+	if _, ok := UserFromRequest(events.APIGatewayProxyRequest{}); ok {
+		t.Error("Expected no user without authorizer context")
+	}
+}
+
+func TestUserFromRequest_MissingSub(t *testing.T) {
+	request := requestWithClaims(map[string]interface{}{
+		"email": "test@example.com",
+	})
+
+	if _, ok := UserFromRequest(request); ok {
+		t.Error("Expected no user without sub claim")
+	}
+}
+
+func TestParseGroups_List(t *testing.T) {
+	groups := parseGroups([]interface{}{"admin", "marvels"})
+
+	if len(groups) != 2 || groups[1] != "marvels" {
+		t.Errorf("Unexpected groups: %v", groups)
+	}
+}
@@ -0,0 +1,23 @@
+package auth
+
+// Challenges Cognito can ask for during sign in that clients answer at
+// POST /auth/challenge
+const (
+	// ChallengeNewPassword is sent for accounts created by an admin, which must
+	// replace their temporary password
+	ChallengeNewPassword = "NEW_PASSWORD_REQUIRED"
+
+	// ChallengeSoftwareTokenMFA asks for the code of the user's authenticator app
+	ChallengeSoftwareTokenMFA = "SOFTWARE_TOKEN_MFA"
+)
+
+// supportedChallenges are the challenges /auth/challenge can answer
+var supportedChallenges = map[string]bool{
+	ChallengeNewPassword:      true,
+	ChallengeSoftwareTokenMFA: true,
+}
+
+// ChallengeSupported reports whether clients can answer the named challenge
+func ChallengeSupported(name string) bool {
+	return supportedChallenges[name]
+}
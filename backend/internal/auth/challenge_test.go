@@ -0,0 +1,16 @@
+package auth
+
+import "testing"
+
+func TestChallengeSupported(t *testing.T) {
+	for _, name := range []string{ChallengeNewPassword, ChallengeSoftwareTokenMFA} {
+		if !ChallengeSupported(name) {
+			t.Errorf("Expected %s to be supported", name)
+		}
+	}
+	for _, name := range []string{"", "SMS_MFA", "CUSTOM_CHALLENGE"} {
+		if ChallengeSupported(name) {
+			t.Errorf("Expected %q not to be supported", name)
+		}
+	}
+}
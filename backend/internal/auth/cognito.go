@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/config"
+)
+
+// HTTPTimeout bounds each call to Cognito, well inside API Gateway's 29 second limit
+const HTTPTimeout = 10 * time.Second
+
+// NewHTTPClient returns an HTTP client for Lambdas to create once at cold start,
+// so warm invocations reuse its connections
+func NewHTTPClient() *http.Client {
+	return &http.Client{Timeout: HTTPTimeout}
+}
+
+// NewCognitoClient creates a user pool client for the region and endpoint in
+// cfg that sends its requests with httpClient
+func NewCognitoClient(cfg *config.Config, httpClient *http.Client) (cognitoidentityprovideriface.CognitoIdentityProviderAPI, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:     aws.String(cfg.AWSRegion),
+		Endpoint:   aws.String(cfg.CognitoEndpoint),
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+	return cognitoidentityprovider.New(sess), nil
+}
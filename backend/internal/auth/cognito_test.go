@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+func TestNewCognitoClient_UsesEndpoint(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_local", "local-client")
+	cfg := &config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL}
+
+	client, err := NewCognitoClient(cfg, NewHTTPClient())
+	if err != nil {
+		t.Fatalf("NewCognitoClient returned error: %v", err)
+	}
+
+	_, err = client.SignUp(&cognitoidentityprovider.SignUpInput{
+		ClientId: aws.String("local-client"),
+		Username: aws.String("test@example.com"),
+		Password: aws.String("Passw0rd!"),
+	})
+	if err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+	if pool.Code("test@example.com") == "" {
+		t.Error("Expected the request to reach the configured endpoint")
+	}
+}
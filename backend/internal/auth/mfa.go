@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// MFAIssuer names TuiTui in authenticator apps
+const MFAIssuer = "TuiTui"
+
+// OTPAuthURI returns the otpauth:// URI that authenticator apps read, usually
+// from a QR code, to add the TOTP secret of account
+func OTPAuthURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + query.Encode()
+}
+
+// TokenSubject returns the sub claim of a JWT without checking its signature,
+// or "" when it cannot be read. Cognito checks the token itself; callers use
+// it to make sure an access token sent in a body belongs to the caller.
+func TokenSubject(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	json.Unmarshal(payload, &claims)
+	return claims.Sub
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestOTPAuthURI(t *testing.T) {
+	uri := OTPAuthURI("TuiTui", "jane+ops@example.com", "JBSWY3DPEHPK3PXP")
+
+	expected := "otpauth://totp/TuiTui:jane+ops@example.com?issuer=TuiTui&secret=JBSWY3DPEHPK3PXP"
+	if uri != expected {
+		t.Errorf("Expected %s, got %s", expected, uri)
+	}
+}
+
+func TestTokenSubject(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-123","token_use":"access"}`))
+
+	tests := []struct {
+		token string
+		sub   string
+	}{
+		{"header." + payload + ".signature", "user-123"},
+		{"header." + payload + "==.signature", "user-123"},
+		{"not-a-jwt", ""},
+		{"header.!!!.signature", ""},
+		{"header." + base64.RawURLEncoding.EncodeToString([]byte(`{}`)) + ".signature", ""},
+	}
+	for _, tt := range tests {
+		if sub := TokenSubject(tt.token); sub != tt.sub {
+			t.Errorf("TokenSubject(%q) = %q, want %q", tt.token, sub, tt.sub)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"tuitui-backend/internal/config"
+)
+
+// ErrInvalidToken is returned for a token the user pool did not issue to the
+// app client, or one that has expired
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier checks Cognito ID tokens the way API Gateway's Cognito authorizer
+// does, for entry points without one such as Lambda Function URLs
+type Verifier struct {
+	issuer     string
+	clientID   string
+	httpClient *http.Client
+
+	// now returns the current time; tests replace it
+	now func() time.Time
+}
+
+// keyRefetchInterval is how long an issuer's key set is kept before an unknown
+// kid may fetch it again, so tokens with made-up kids cannot cause a request each
+const keyRefetchInterval = time.Minute
+
+// keySet is an issuer's signing keys and when they were fetched
+type keySet struct {
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+// keySets caches the signing keys of each issuer, so they are fetched once per
+// cold start rather than on every request
+var keySets = struct {
+	sync.Mutex
+	sets map[string]*keySet
+}{sets: make(map[string]*keySet)}
+
+// NewVerifier returns a verifier for the user pool and app client in cfg. A
+// CognitoEndpoint, such as the fake user pool, issues tokens in place of AWS.
+func NewVerifier(cfg *config.Config, httpClient *http.Client) *Verifier {
+	issuer := fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", cfg.AWSRegion, cfg.CognitoUserPoolID)
+	if cfg.CognitoEndpoint != "" {
+		issuer = strings.TrimRight(cfg.CognitoEndpoint, "/") + "/" + cfg.CognitoUserPoolID
+	}
+	return &Verifier{issuer: issuer, clientID: cfg.CognitoUserPoolClientID, httpClient: httpClient, now: time.Now}
+}
+
+// Verify returns the claims of an ID token, which may carry a "Bearer " prefix.
+// Numeric claims are json.Number, as the revocation list expects.
+func (v *Verifier) Verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "RS256" {
+		return nil, ErrInvalidToken
+	}
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims["iss"] != v.issuer || claims["aud"] != v.clientID || claims["token_use"] != "id" {
+		return nil, ErrInvalidToken
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, ErrInvalidToken
+	}
+	exp, _ := claims["exp"].(json.Number)
+	if seconds, err := exp.Int64(); err != nil || v.now().Unix() >= seconds {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// key returns the issuer's signing key kid. The key set is fetched again for
+// an unknown kid, as Cognito rotates its keys, but at most once per
+// keyRefetchInterval; until then the kid is treated as a miss.
+func (v *Verifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	keySets.Lock()
+	set := keySets.sets[v.issuer]
+	keySets.Unlock()
+	if set != nil {
+		if key, ok := set.keys[kid]; ok {
+			return key, nil
+		}
+		if v.now().Sub(set.fetched) < keyRefetchInterval {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keySets.Lock()
+	keySets.sets[v.issuer] = &keySet{keys: keys, fetched: v.now()}
+	keySets.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+// fetchKeys reads the RSA keys of the issuer's JWKS
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.issuer+"/.well-known/jwks.json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %v", err)
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if k.Kty != "RSA" || errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// decodeSegment decodes a base64url JWT segment into v, keeping numbers as json.Number
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
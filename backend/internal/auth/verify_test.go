@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/fakecognito"
+)
+
+// signIn returns the ID and access tokens of a new user of pool
+func signIn(t *testing.T, cfg *config.Config, pool *fakecognito.Server) (string, string) {
+	t.Helper()
+	pool.AddUser("jane@example.com", "Passw0rd!")
+	cognito, err := NewCognitoClient(cfg, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewCognitoClient returned error: %v", err)
+	}
+	out, err := cognito.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String(cfg.CognitoUserPoolClientID),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String("jane@example.com"),
+			"PASSWORD": aws.String("Passw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	return aws.StringValue(out.AuthenticationResult.IdToken), aws.StringValue(out.AuthenticationResult.AccessToken)
+}
+
+func TestVerifier_AcceptsIDTokens(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_verify", "local-client")
+	cfg := &config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolID: "eu-west-2_verify", CognitoUserPoolClientID: "local-client"}
+	idToken, _ := signIn(t, cfg, pool)
+
+	claims, err := NewVerifier(cfg, http.DefaultClient).Verify(context.Background(), "Bearer "+idToken)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if claims["email"] != "jane@example.com" || claims["sub"] == "" {
+		t.Errorf("Unexpected claims %v", claims)
+	}
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	pool := fakecognito.NewTestServer(t, "eu-west-2_reject", "local-client")
+	cfg := &config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: pool.URL, CognitoUserPoolID: "eu-west-2_reject", CognitoUserPoolClientID: "local-client"}
+	idToken, accessToken := signIn(t, cfg, pool)
+
+	parts := strings.Split(idToken, ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"someone-else","aud":"local-client","token_use":"id"}`)) + "." + parts[2]
+	otherClient := *cfg
+	otherClient.CognitoUserPoolClientID = "other-client"
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		token    string
+	}{
+		{"malformed", NewVerifier(cfg, http.DefaultClient), "not-a-jwt"},
+		{"forged payload", NewVerifier(cfg, http.DefaultClient), forged},
+		{"access token", NewVerifier(cfg, http.DefaultClient), accessToken},
+		{"other client", NewVerifier(&otherClient, http.DefaultClient), idToken},
+	}
+	expired := NewVerifier(cfg, http.DefaultClient)
+	expired.now = func() time.Time { return time.Now().Add(fakecognito.TokenValidity + time.Minute) }
+	tests = append(tests, struct {
+		name     string
+		verifier *Verifier
+		token    string
+	}{"expired", expired, idToken})
+
+	for _, tt := range tests {
+		if _, err := tt.verifier.Verify(context.Background(), tt.token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestVerifier_LimitsKeyFetches(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AWSRegion: "eu-west-2", CognitoEndpoint: server.URL, CognitoUserPoolID: "eu-west-2_fetches", CognitoUserPoolClientID: "local-client"}
+	verifier := NewVerifier(cfg, server.Client())
+	now := time.Now()
+	verifier.now = func() time.Time { return now }
+	withKid := func(kid string) string {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"` + kid + `"}`))
+		return header + ".e30.c2ln"
+	}
+
+	for _, kid := range []string{"unknown-1", "unknown-2"} {
+		if _, err := verifier.Verify(context.Background(), withKid(kid)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", kid, err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected unknown kids to fetch the keys once, got %d", fetches)
+	}
+
+	// Once the interval has passed the keys are fetched again, as after a rotation
+	now = now.Add(keyRefetchInterval)
+	verifier.Verify(context.Background(), withKid("unknown-3"))
+	if fetches != 2 {
+		t.Errorf("Expected a refetch after the interval, got %d fetches", fetches)
+	}
+}
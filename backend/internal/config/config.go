@@ -21,10 +21,51 @@ type Config struct {
 	// Cognito configuration
 	CognitoUserPoolID       string
 	CognitoUserPoolClientID string
+	AdminGroup              string // Cognito group allowed to manage shared data such as the knowledge base
+	CognitoEndpoint         string // e.g. a local emulator; the AWS endpoint when empty
 
 	// AI Model configuration
+	AIProvider    string // "anthropic", "bedrock" or "openai"
 	AIModelName   string
 	AIAPIEndpoint string
+	AIAPIKey      string
+	AIMaxTokens   int // output token limit of each model call
+
+	// AI request resilience
+	AIMaxRetries            int // retries for transient model API failures (429, 5xx, 529)
+	AIRequestTimeoutSeconds int // timeout for each model API attempt
+
+	// Chat configuration
+	ChatResponseStreaming bool // true when the chat Lambda sits behind a RESPONSE_STREAM Function URL
+	AgentMaxIterations    int  // maximum model calls per chat turn when the model uses tools
+	AgentMaxContinuations int  // times a reply cut off at AIMaxTokens is continued before it is returned truncated
+	RedactPII             bool // replace personal data and secrets with placeholders before logging and model calls
+
+	// Chat rate limits per Cognito user; 0 disables a limit
+	RateLimitPerMinute    int    // chat requests per minute
+	RateLimitTokensPerDay int    // model tokens per UTC day
+	RateLimitOverrides    string // limits by team ID, e.g. "search=60:500000,payments=:1000000"
+
+	// Retrieval configuration for uploaded documents
+	RetrievalEmbedder string // "hashing"
+	RetrievalTopK     int    // number of document chunks added to the prompt
+
+	// DynamoDB configuration
+	ConversationsTable   string
+	KnowledgeTable       string
+	UsageTable           string
+	RateLimitTable       string
+	RevokedSessionsTable string
+	TeamsTable           string
+	DocumentsTable       string
+	PromptsTable         string
+
+	// Uploaded document content, stored in S3 or, for local development, a directory
+	DocumentsBucket string
+	DocumentsDir    string // used when DocumentsBucket is unset
+
+	// Knowledge base configuration
+	KnowledgeDir string // directory of markdown/YAML entries used when KnowledgeTable is unset
 
 	// Database configuration (for future use)
 	DBHost     string
@@ -43,8 +84,35 @@ func Load() (*Config, error) {
 		APIVersion:              getEnv("API_VERSION", "v1"),
 		CognitoUserPoolID:       getEnv("COGNITO_USER_POOL_ID", ""),
 		CognitoUserPoolClientID: getEnv("COGNITO_USER_POOL_CLIENT_ID", ""),
+		AdminGroup:              getEnv("ADMIN_GROUP", "admin"),
+		CognitoEndpoint:         getEnv("COGNITO_ENDPOINT", ""),
+		AIProvider:              getEnv("AI_PROVIDER", "anthropic"),
 		AIModelName:             getEnv("AI_MODEL_NAME", "claude-3-haiku-20240307"),                 // Temporary default, will change to Amazon Q model
 		AIAPIEndpoint:           getEnv("AI_API_ENDPOINT", "https://api.anthropic.com/v1/messages"), // Temporary endpoint, will change to Amazon Q endpoint
+		AIAPIKey:                getEnv("AMAZON_AI_API_KEY", ""),
+		AIMaxTokens:             getEnvAsInt("AI_MAX_TOKENS", 4096),
+		AIMaxRetries:            getEnvAsInt("AI_MAX_RETRIES", 3),
+		AIRequestTimeoutSeconds: getEnvAsInt("AI_REQUEST_TIMEOUT_SECONDS", 60),
+		ChatResponseStreaming:   getEnvAsBool("CHAT_RESPONSE_STREAMING", false),
+		AgentMaxIterations:      getEnvAsInt("AGENT_MAX_ITERATIONS", 5),
+		AgentMaxContinuations:   getEnvAsInt("AGENT_MAX_CONTINUATIONS", 1),
+		RedactPII:               getEnvAsBool("REDACT_PII", true),
+		RateLimitPerMinute:      getEnvAsInt("RATE_LIMIT_PER_MINUTE", 20),
+		RateLimitTokensPerDay:   getEnvAsInt("RATE_LIMIT_TOKENS_PER_DAY", 200000),
+		RateLimitOverrides:      getEnv("RATE_LIMIT_TEAM_OVERRIDES", ""),
+		RetrievalEmbedder:       getEnv("RETRIEVAL_EMBEDDER", "hashing"),
+		RetrievalTopK:           getEnvAsInt("RETRIEVAL_TOP_K", 4),
+		ConversationsTable:      getEnv("CONVERSATIONS_TABLE", ""),
+		KnowledgeTable:          getEnv("KNOWLEDGE_TABLE", ""),
+		UsageTable:              getEnv("USAGE_TABLE", ""),
+		RateLimitTable:          getEnv("RATE_LIMIT_TABLE", ""),
+		RevokedSessionsTable:    getEnv("REVOKED_SESSIONS_TABLE", ""),
+		TeamsTable:              getEnv("TEAMS_TABLE", ""),
+		DocumentsTable:          getEnv("DOCUMENTS_TABLE", ""),
+		PromptsTable:            getEnv("PROMPTS_TABLE", ""),
+		DocumentsBucket:         getEnv("DOCUMENTS_BUCKET", ""),
+		DocumentsDir:            getEnv("DOCUMENTS_DIR", ""),
+		KnowledgeDir:            getEnv("KNOWLEDGE_DIR", ""),
 		DBHost:                  getEnv("DB_HOST", ""),
 		DBPort:                  getEnvAsInt("DB_PORT", 5432),
 		DBName:                  getEnv("DB_NAME", ""),
@@ -104,3 +172,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 
 	return value
 }
+
+// getEnvAsBool gets an environment variable as a boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
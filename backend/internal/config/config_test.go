@@ -33,6 +33,10 @@ func TestConfig_Defaults(t *testing.T) {
 	if cfg.LogLevel != "info" {
 		t.Errorf("Expected default log level 'info', got '%s'", cfg.LogLevel)
 	}
+
+	if !cfg.RedactPII {
+		t.Error("Expected redaction to be on by default")
+	}
 }
 
 func TestConfig_IsDevelopment(t *testing.T) {
@@ -89,3 +93,20 @@ func TestGetEnvAsInt(t *testing.T) {
 		t.Errorf("Expected 42, got %d", result)
 	}
 }
+
+func TestGetEnvAsBool(t *testing.T) {
+	result := getEnvAsBool("NONEXISTENT_VAR", true)
+	if !result {
+		t.Error("Expected true, got false")
+	}
+
+	t.Setenv("TEST_BOOL_VAR", "true")
+	if !getEnvAsBool("TEST_BOOL_VAR", false) {
+		t.Error("Expected true for 'true'")
+	}
+
+	t.Setenv("TEST_BOOL_VAR", "not-a-bool")
+	if getEnvAsBool("TEST_BOOL_VAR", false) {
+		t.Error("Expected default false for invalid value")
+	}
+}
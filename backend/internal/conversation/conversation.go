@@ -0,0 +1,91 @@
+// Package conversation stores chat conversations and their messages per Cognito user.
+package conversation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/config"
+)
+
+// DefaultTitle is used when a conversation is created without a title
+const DefaultTitle = "New conversation"
+
+var (
+	// ErrNotFound is returned when a conversation does not exist or belongs to another user
+	ErrNotFound = errors.New("conversation not found")
+
+	// ErrNotConfigured is returned when no conversations table is configured
+	ErrNotConfigured = errors.New("conversation storage not configured")
+)
+
+// Conversation is a chat thread owned by a single user
+type Conversation struct {
+	ID           string    `json:"id" dynamodbav:"ID"`
+	UserID       string    `json:"-" dynamodbav:"UserID"`
+	Title        string    `json:"title" dynamodbav:"Title"`
+	MessageCount int       `json:"message_count" dynamodbav:"MessageCount"`
+	CreatedAt    time.Time `json:"created_at" dynamodbav:"CreatedAt"`
+	UpdatedAt    time.Time `json:"updated_at" dynamodbav:"UpdatedAt"`
+}
+
+// Message is a single turn in a conversation
+type Message struct {
+	Role      string    `json:"role" dynamodbav:"Role"`
+	Content   string    `json:"content" dynamodbav:"Content"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"CreatedAt"`
+}
+
+// Store persists conversations and messages. Every method is scoped to a user, so
+// one user can never read or modify another user's conversations.
+type Store interface {
+	// CreateConversation starts a new conversation for the user
+	CreateConversation(ctx context.Context, userID, title string) (*Conversation, error)
+
+	// ListConversations returns the user's conversations, most recently updated first
+	ListConversations(ctx context.Context, userID string) ([]Conversation, error)
+
+	// GetConversation returns a single conversation or ErrNotFound
+	GetConversation(ctx context.Context, userID, id string) (*Conversation, error)
+
+	// DeleteConversation removes a conversation and all of its messages
+	DeleteConversation(ctx context.Context, userID, id string) error
+
+	// AppendMessages adds messages to the end of a conversation
+	AppendMessages(ctx context.Context, userID, id string, messages ...Message) error
+
+	// ListMessages returns a conversation's messages in the order they were added
+	ListMessages(ctx context.Context, userID, id string) ([]Message, error)
+}
+
+// NewStore creates the DynamoDB store for the table in cfg.ConversationsTable
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.ConversationsTable == "" {
+		return nil, ErrNotConfigured
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.AWSRegion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return NewDynamoStore(dynamodb.New(sess), cfg.ConversationsTable), nil
+}
+
+// newID returns a random conversation ID
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
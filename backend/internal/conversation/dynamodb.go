@@ -0,0 +1,357 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Key layout: every item for a user lives in the partition "USER#<sub>".
+// Conversations use the sort key "CONV#<id>" and messages use
+// "MSG#<id>#<timestamp>#<seq>", so a user's conversations and a conversation's
+// messages can each be read with a single begins_with query.
+const (
+	userPrefix         = "USER#"
+	conversationPrefix = "CONV#"
+	messagePrefix      = "MSG#"
+
+	// sortableTime is a fixed-width timestamp that sorts lexically
+	sortableTime = "2006-01-02T15:04:05.000000000Z"
+
+	// batchWriteLimit is the maximum number of requests in one BatchWriteItem call
+	batchWriteLimit = 25
+
+	// batchWriteAttempts bounds how often a chunk is sent while DynamoDB leaves
+	// items unprocessed, waiting a jittered, exponentially growing backoff between
+	// attempts
+	batchWriteAttempts    = 6
+	batchWriteBaseBackoff = 50 * time.Millisecond
+	batchWriteMaxBackoff  = 2 * time.Second
+)
+
+// ErrUnprocessed is returned when DynamoDB still leaves items unprocessed after
+// batchWriteAttempts, as when the table is throttled
+var ErrUnprocessed = errors.New("conversation items left unprocessed")
+
+// DynamoStore is a Store backed by a single DynamoDB table with string keys PK and SK
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	now    func() time.Time
+
+	// sleep waits for d or until ctx is done; tests replace it
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{
+		client: client,
+		table:  table,
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// conversationItem is the DynamoDB representation of a Conversation
+type conversationItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Conversation
+}
+
+// messageItem is the DynamoDB representation of a Message
+type messageItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Message
+}
+
+// CreateConversation starts a new conversation for the user
+func (s *DynamoStore) CreateConversation(ctx context.Context, userID, title string) (*Conversation, error) {
+	if title == "" {
+		title = DefaultTitle
+	}
+
+	now := s.now().UTC()
+	conv := Conversation{
+		ID:        newID(),
+		UserID:    userID,
+		Title:     title,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	item, err := dynamodbattribute.MarshalMap(conversationItem{
+		PK:           userKey(userID),
+		SK:           conversationKey(conv.ID),
+		Conversation: conv,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal conversation: %v", err)
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %v", err)
+	}
+
+	return &conv, nil
+}
+
+// ListConversations returns the user's conversations, most recently updated first
+func (s *DynamoStore) ListConversations(ctx context.Context, userID string) ([]Conversation, error) {
+	items, err := s.query(ctx, userID, conversationPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %v", err)
+	}
+
+	var conversations []Conversation
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &conversations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversations: %v", err)
+	}
+	if conversations == nil {
+		conversations = []Conversation{}
+	}
+	sortByUpdated(conversations)
+
+	return conversations, nil
+}
+
+// GetConversation returns a single conversation or ErrNotFound
+func (s *DynamoStore) GetConversation(ctx context.Context, userID, id string) (*Conversation, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       itemKey(userID, conversationKey(id)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var conv Conversation
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &conv); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversation: %v", err)
+	}
+
+	return &conv, nil
+}
+
+// DeleteConversation removes a conversation and all of its messages
+func (s *DynamoStore) DeleteConversation(ctx context.Context, userID, id string) error {
+	_, err := s.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 itemKey(userID, conversationKey(id)),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete conversation: %v", err)
+	}
+
+	items, err := s.query(ctx, userID, messageKeyPrefix(id))
+	if err != nil {
+		return fmt.Errorf("failed to list messages for deletion: %v", err)
+	}
+
+	requests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, item := range items {
+		requests = append(requests, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{
+				Key: map[string]*dynamodb.AttributeValue{
+					"PK": item["PK"],
+					"SK": item["SK"],
+				},
+			},
+		})
+	}
+
+	if err := s.batchWrite(ctx, requests); err != nil {
+		return fmt.Errorf("failed to delete messages: %v", err)
+	}
+
+	return nil
+}
+
+// AppendMessages adds messages to the end of a conversation
+func (s *DynamoStore) AppendMessages(ctx context.Context, userID, id string, messages ...Message) error {
+	now := s.now().UTC()
+
+	// Bump the conversation first so appending to a missing conversation fails
+	_, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 itemKey(userID, conversationKey(id)),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		UpdateExpression:    aws.String("SET UpdatedAt = :now ADD MessageCount :count"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now":   {S: aws.String(now.Format(time.RFC3339Nano))},
+			":count": {N: aws.String(fmt.Sprintf("%d", len(messages)))},
+		},
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update conversation: %v", err)
+	}
+
+	requests := make([]*dynamodb.WriteRequest, 0, len(messages))
+	for i, msg := range messages {
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = now
+		}
+
+		item, err := dynamodbattribute.MarshalMap(messageItem{
+			PK:      userKey(userID),
+			SK:      fmt.Sprintf("%s%s#%03d", messageKeyPrefix(id), msg.CreatedAt.UTC().Format(sortableTime), i),
+			Message: msg,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal message: %v", err)
+		}
+
+		requests = append(requests, &dynamodb.WriteRequest{
+			PutRequest: &dynamodb.PutRequest{Item: item},
+		})
+	}
+
+	if err := s.batchWrite(ctx, requests); err != nil {
+		return fmt.Errorf("failed to store messages: %v", err)
+	}
+
+	return nil
+}
+
+// ListMessages returns a conversation's messages in the order they were added
+func (s *DynamoStore) ListMessages(ctx context.Context, userID, id string) ([]Message, error) {
+	if _, err := s.GetConversation(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	items, err := s.query(ctx, userID, messageKeyPrefix(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %v", err)
+	}
+
+	messages := []Message{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &messages); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal messages: %v", err)
+	}
+
+	return messages, nil
+}
+
+// query returns every item in the user's partition whose sort key starts with prefix
+func (s *DynamoStore) query(ctx context.Context, userID, prefix string) ([]map[string]*dynamodb.AttributeValue, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":     {S: aws.String(userKey(userID))},
+			":prefix": {S: aws.String(prefix)},
+		},
+	}
+
+	err := s.client.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// batchWrite sends write requests in chunks and retries unprocessed items,
+// backing off between attempts
+func (s *DynamoStore) batchWrite(ctx context.Context, requests []*dynamodb.WriteRequest) error {
+	for start := 0; start < len(requests); start += batchWriteLimit {
+		end := min(start+batchWriteLimit, len(requests))
+
+		pending := map[string][]*dynamodb.WriteRequest{s.table: requests[start:end]}
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt == batchWriteAttempts {
+				return fmt.Errorf("%w: %d after %d attempts", ErrUnprocessed, len(pending[s.table]), attempt)
+			}
+			if attempt > 0 {
+				if err := s.sleep(ctx, batchWriteBackoff(attempt-1)); err != nil {
+					return err
+				}
+			}
+
+			result, err := s.client.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: pending,
+			})
+			if err != nil {
+				return err
+			}
+			pending = result.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// batchWriteBackoff returns a random wait up to batchWriteBaseBackoff * 2^retry,
+// capped at batchWriteMaxBackoff
+func batchWriteBackoff(retry int) time.Duration {
+	ceiling := min(batchWriteBaseBackoff<<retry, batchWriteMaxBackoff)
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isConditionFailed reports whether err is a failed DynamoDB condition check
+func isConditionFailed(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
+
+func userKey(userID string) string {
+	return userPrefix + userID
+}
+
+func conversationKey(id string) string {
+	return conversationPrefix + id
+}
+
+func messageKeyPrefix(id string) string {
+	return messagePrefix + id + "#"
+}
+
+func itemKey(userID, sortKey string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String(userKey(userID))},
+		"SK": {S: aws.String(sortKey)},
+	}
+}
@@ -0,0 +1,202 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is a minimal in-memory table that understands the operations the
+// store issues against a PK/SK table
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	mu    sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+}
+
+func fakeKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["PK"].S) + "|" + aws.StringValue(key["SK"].S)
+}
+
+func conditionFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+}
+
+func (f *fakeDynamo) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[fakeKey(input.Item)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[fakeKey(input.Key)]}, nil
+}
+
+func (f *fakeDynamo) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fakeKey(input.Key)
+	if _, ok := f.items[key]; !ok && input.ConditionExpression != nil {
+		return nil, conditionFailed()
+	}
+	delete(f.items, key)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (f *fakeDynamo) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[fakeKey(input.Key)]
+	if !ok {
+		return nil, conditionFailed()
+	}
+	item["UpdatedAt"] = input.ExpressionAttributeValues[":now"]
+	count := 0
+	if n := item["MessageCount"]; n != nil {
+		count, _ = strconv.Atoi(aws.StringValue(n.N))
+	}
+	added, _ := strconv.Atoi(aws.StringValue(input.ExpressionAttributeValues[":count"].N))
+	item["MessageCount"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(count + added))}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (f *fakeDynamo) QueryPagesWithContext(ctx aws.Context, input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, opts ...request.Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pk := aws.StringValue(input.ExpressionAttributeValues[":pk"].S)
+	prefix := aws.StringValue(input.ExpressionAttributeValues[":prefix"].S)
+
+	var keys []string
+	for key := range f.items {
+		if strings.HasPrefix(key, pk+"|"+prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	output := &dynamodb.QueryOutput{}
+	for _, key := range keys {
+		output.Items = append(output.Items, f.items[key])
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeDynamo) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, requests := range input.RequestItems {
+		if len(requests) > batchWriteLimit {
+			return nil, awserr.New("ValidationException", "too many items", nil)
+		}
+		for _, req := range requests {
+			if req.PutRequest != nil {
+				f.items[fakeKey(req.PutRequest.Item)] = req.PutRequest.Item
+			}
+			if req.DeleteRequest != nil {
+				delete(f.items, fakeKey(req.DeleteRequest.Key))
+			}
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func TestDynamoStore(t *testing.T) {
+	testStore(t, NewDynamoStore(newFakeDynamo(), "conversations"))
+}
+
+func TestDynamoStore_DeletesMessagesInBatches(t *testing.T) {
+	client := newFakeDynamo()
+	store := NewDynamoStore(client, "conversations")
+	ctx := context.Background()
+
+	conv, err := store.CreateConversation(ctx, "user-1", "Long")
+	if err != nil {
+		t.Fatalf("CreateConversation returned error: %v", err)
+	}
+
+	messages := make([]Message, 60)
+	for i := range messages {
+		messages[i] = Message{Role: "user", Content: "message"}
+	}
+	if err := store.AppendMessages(ctx, "user-1", conv.ID, messages...); err != nil {
+		t.Fatalf("AppendMessages returned error: %v", err)
+	}
+
+	stored, _ := store.ListMessages(ctx, "user-1", conv.ID)
+	if len(stored) != 60 {
+		t.Fatalf("Expected 60 messages, got %d", len(stored))
+	}
+
+	if err := store.DeleteConversation(ctx, "user-1", conv.ID); err != nil {
+		t.Fatalf("DeleteConversation returned error: %v", err)
+	}
+
+	if len(client.items) != 0 {
+		t.Errorf("Expected all items to be deleted, %d remain", len(client.items))
+	}
+}
+
+// throttledDynamo leaves every batch write unprocessed, as a throttled table can
+type throttledDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	calls int
+}
+
+func (f *throttledDynamo) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	f.calls++
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: input.RequestItems}, nil
+}
+
+func TestDynamoStore_BatchWriteGivesUp(t *testing.T) {
+	client := &throttledDynamo{}
+	store := NewDynamoStore(client, "conversations")
+	var waits []time.Duration
+	store.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	requests := []*dynamodb.WriteRequest{{DeleteRequest: &dynamodb.DeleteRequest{Key: map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String("USER#user-1")},
+		"SK": {S: aws.String("MSG#conv-1#1")},
+	}}}}
+	err := store.batchWrite(context.Background(), requests)
+	if !errors.Is(err, ErrUnprocessed) {
+		t.Fatalf("Expected ErrUnprocessed, got %v", err)
+	}
+	if client.calls != batchWriteAttempts || len(waits) != batchWriteAttempts-1 {
+		t.Errorf("Expected %d attempts with a wait between each, got %d calls and %d waits", batchWriteAttempts, client.calls, len(waits))
+	}
+	for i, wait := range waits {
+		if ceiling := min(batchWriteBaseBackoff<<i, batchWriteMaxBackoff); wait <= 0 || wait > ceiling {
+			t.Errorf("Wait %d is %v, expected up to %v", i, wait, ceiling)
+		}
+	}
+
+	// A cancelled request stops waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	store.sleep = sleepContext
+	if err := store.batchWrite(ctx, requests); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancellation, got %v", err)
+	}
+}
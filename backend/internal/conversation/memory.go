@@ -0,0 +1,138 @@
+package conversation
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store for tests and local development
+type MemoryStore struct {
+	mu            sync.Mutex
+	conversations map[string]map[string]*Conversation
+	messages      map[string][]Message
+	now           func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		conversations: make(map[string]map[string]*Conversation),
+		messages:      make(map[string][]Message),
+		now:           time.Now,
+	}
+}
+
+// CreateConversation starts a new conversation for the user
+func (s *MemoryStore) CreateConversation(ctx context.Context, userID, title string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if title == "" {
+		title = DefaultTitle
+	}
+
+	now := s.now().UTC()
+	conv := &Conversation{
+		ID:        newID(),
+		UserID:    userID,
+		Title:     title,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if s.conversations[userID] == nil {
+		s.conversations[userID] = make(map[string]*Conversation)
+	}
+	s.conversations[userID][conv.ID] = conv
+
+	copied := *conv
+	return &copied, nil
+}
+
+// ListConversations returns the user's conversations, most recently updated first
+func (s *MemoryStore) ListConversations(ctx context.Context, userID string) ([]Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversations := make([]Conversation, 0, len(s.conversations[userID]))
+	for _, conv := range s.conversations[userID] {
+		conversations = append(conversations, *conv)
+	}
+	sortByUpdated(conversations)
+
+	return conversations, nil
+}
+
+// GetConversation returns a single conversation or ErrNotFound
+func (s *MemoryStore) GetConversation(ctx context.Context, userID, id string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.conversations[userID][id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	copied := *conv
+	return &copied, nil
+}
+
+// DeleteConversation removes a conversation and all of its messages
+func (s *MemoryStore) DeleteConversation(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.conversations[userID][id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.conversations[userID], id)
+	delete(s.messages, id)
+	return nil
+}
+
+// AppendMessages adds messages to the end of a conversation
+func (s *MemoryStore) AppendMessages(ctx context.Context, userID, id string, messages ...Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.conversations[userID][id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	now := s.now().UTC()
+	for _, msg := range messages {
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = now
+		}
+		s.messages[id] = append(s.messages[id], msg)
+	}
+
+	conv.MessageCount += len(messages)
+	conv.UpdatedAt = now
+	return nil
+}
+
+// ListMessages returns a conversation's messages in the order they were added
+func (s *MemoryStore) ListMessages(ctx context.Context, userID, id string) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.conversations[userID][id]; !ok {
+		return nil, ErrNotFound
+	}
+
+	messages := make([]Message, len(s.messages[id]))
+	copy(messages, s.messages[id])
+	return messages, nil
+}
+
+// sortByUpdated orders conversations with the most recently updated first
+func sortByUpdated(conversations []Conversation) {
+	sort.SliceStable(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+}
@@ -0,0 +1,92 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	conv, err := store.CreateConversation(ctx, "user-1", "")
+	if err != nil {
+		t.Fatalf("CreateConversation returned error: %v", err)
+	}
+	if conv.ID == "" {
+		t.Fatal("Expected conversation ID")
+	}
+	if conv.Title != DefaultTitle {
+		t.Errorf("Expected default title '%s', got '%s'", DefaultTitle, conv.Title)
+	}
+
+	err = store.AppendMessages(ctx, "user-1", conv.ID,
+		Message{Role: "user", Content: "Hello"},
+		Message{Role: "assistant", Content: "Hi there"},
+	)
+	if err != nil {
+		t.Fatalf("AppendMessages returned error: %v", err)
+	}
+
+	messages, err := store.ListMessages(ctx, "user-1", conv.ID)
+	if err != nil {
+		t.Fatalf("ListMessages returned error: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Content != "Hello" || messages[1].Role != "assistant" {
+		t.Errorf("Unexpected messages: %+v", messages)
+	}
+
+	got, err := store.GetConversation(ctx, "user-1", conv.ID)
+	if err != nil {
+		t.Fatalf("GetConversation returned error: %v", err)
+	}
+	if got.MessageCount != 2 {
+		t.Errorf("Expected message count 2, got %d", got.MessageCount)
+	}
+
+	// Another user must not see the conversation
+	if _, err := store.GetConversation(ctx, "user-2", conv.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another user, got %v", err)
+	}
+	if err := store.AppendMessages(ctx, "user-2", conv.ID, Message{Role: "user", Content: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound appending as another user, got %v", err)
+	}
+
+	second, err := store.CreateConversation(ctx, "user-1", "Second")
+	if err != nil {
+		t.Fatalf("CreateConversation returned error: %v", err)
+	}
+
+	list, err := store.ListConversations(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("ListConversations returned error: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 conversations, got %d", len(list))
+	}
+
+	if err := store.DeleteConversation(ctx, "user-1", conv.ID); err != nil {
+		t.Fatalf("DeleteConversation returned error: %v", err)
+	}
+	if _, err := store.ListMessages(ctx, "user-1", conv.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.DeleteConversation(ctx, "user-1", conv.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	list, _ = store.ListConversations(ctx, "user-1")
+	if len(list) != 1 || list[0].ID != second.ID {
+		t.Errorf("Expected only the second conversation, got %+v", list)
+	}
+
+	empty, err := store.ListConversations(ctx, "user-3")
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("Expected empty non-nil list, got %v (%v)", empty, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
@@ -0,0 +1,256 @@
+// Package convert turns uploaded PDF, DOCX and HTML files into normalized
+// markdown, so they can be stored and read by the assistant the same way as
+// markdown documents. Only text and structure are kept: headings, paragraphs,
+// lists, tables, code blocks and links.
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxFileSize is the largest file that can be converted, in bytes. Files arrive
+// base64 encoded, a third larger, in a request body Lambda caps at 6 MB.
+const MaxFileSize = 4 << 20
+
+// Format is a document format that can be converted to markdown
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+	DOCX     Format = "docx"
+	PDF      Format = "pdf"
+)
+
+var (
+	// ErrUnsupported is returned for files that are not PDF, DOCX, HTML or text
+	ErrUnsupported = errors.New("unsupported document format")
+
+	// ErrTooLarge is returned for files over MaxFileSize
+	ErrTooLarge = fmt.Errorf("file must not exceed %d bytes", MaxFileSize)
+
+	// ErrNoText is returned when a file converts to no text at all, such as a scanned PDF
+	ErrNoText = errors.New("no text found in document")
+)
+
+// Detect returns the format of a file from its content, falling back to the
+// extension of name. Plain text and markdown are reported as Markdown.
+func Detect(name string, data []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return PDF, nil
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		if strings.EqualFold(path.Ext(name), ".docx") || bytes.Contains(data, []byte("word/document.xml")) {
+			return DOCX, nil
+		}
+		return "", ErrUnsupported
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm", ".xhtml":
+		return HTML, nil
+	case ".pdf", ".docx":
+		// The extension claims a binary format the content does not match
+		return "", ErrUnsupported
+	}
+
+	if !utf8.Valid(data) {
+		return "", ErrUnsupported
+	}
+	if looksLikeHTML(data) {
+		return HTML, nil
+	}
+	return Markdown, nil
+}
+
+// ToMarkdown converts a file to normalized markdown and reports the format it
+// was read as. name is only used to help detect the format.
+func ToMarkdown(name string, data []byte) (string, Format, error) {
+	if len(data) > MaxFileSize {
+		return "", "", ErrTooLarge
+	}
+
+	format, err := Detect(name, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	var markdown string
+	switch format {
+	case PDF:
+		markdown, err = FromPDF(data)
+	case DOCX:
+		markdown, err = FromDOCX(data)
+	case HTML:
+		markdown, err = FromHTML(data)
+	default:
+		markdown = Normalize(string(data))
+	}
+	if err != nil {
+		return "", format, err
+	}
+
+	if markdown == "" {
+		return "", format, ErrNoText
+	}
+	return markdown, format, nil
+}
+
+// Normalize cleans up markdown: line endings become \n, trailing spaces are
+// removed, runs of blank lines collapse to one and the result ends with a
+// single newline. Whitespace inside fenced code blocks is kept as it is.
+func Normalize(markdown string) string {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = strings.ReplaceAll(markdown, "\r", "\n")
+	markdown = strings.ReplaceAll(markdown, "\u00a0", " ")
+
+	var out strings.Builder
+	inFence := false
+	blank := true
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		} else if !inFence {
+			line = strings.TrimRight(line, " \t")
+		}
+
+		if line == "" && !inFence {
+			blank = true
+			continue
+		}
+		if blank && out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		blank = false
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+// looksLikeHTML reports whether text starts like an HTML document
+func looksLikeHTML(data []byte) bool {
+	head := bytes.ToLower(bytes.TrimSpace(data))
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) ||
+		bytes.HasPrefix(head, []byte("<html")) ||
+		(bytes.HasPrefix(head, []byte("<?xml")) && bytes.Contains(head, []byte("<html")))
+}
+
+// writer builds markdown one block at a time. Blocks are separated by a blank
+// line, except consecutive items of the same list which stay together.
+type writer struct {
+	b           strings.Builder
+	lastList    bool
+	lastOrdered bool
+}
+
+// heading writes a heading; levels outside 1-6 are clamped
+func (w *writer) heading(level int, text string) {
+	text = cleanText(text)
+	if text == "" {
+		return
+	}
+	level = max(1, min(level, 6))
+	w.block(strings.Repeat("#", level)+" "+text, false)
+}
+
+// paragraph writes a paragraph of text
+func (w *writer) paragraph(text string) {
+	text = cleanText(text)
+	if text == "" {
+		return
+	}
+	w.block(text, false)
+}
+
+// listItem writes one item of a list nested depth levels deep. Ordered items
+// are numbered with "1." and markdown renderers number them in sequence.
+func (w *writer) listItem(depth int, ordered bool, text string) {
+	text = cleanText(text)
+	if text == "" {
+		return
+	}
+	marker := "- "
+	if ordered {
+		marker = "1. "
+	}
+	// A top-level item of another kind starts a new list
+	if depth <= 0 && ordered != w.lastOrdered {
+		w.lastList = false
+	}
+	w.block(strings.Repeat("  ", max(depth, 0))+marker+text, true)
+	w.lastOrdered = ordered
+}
+
+// code writes a fenced code block, keeping its whitespace
+func (w *writer) code(text string) {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	w.block("```\n"+text+"\n```", false)
+}
+
+// table writes rows as a markdown table, using the first row as the header
+func (w *writer) table(rows [][]string) {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return
+	}
+
+	var t strings.Builder
+	for i, row := range rows {
+		t.WriteString("|")
+		for c := 0; c < columns; c++ {
+			cell := ""
+			if c < len(row) {
+				cell = strings.ReplaceAll(cleanText(row[c]), "|", `\|`)
+			}
+			t.WriteString(" " + cell + " |")
+		}
+		t.WriteString("\n")
+		if i == 0 {
+			t.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	w.block(strings.TrimSuffix(t.String(), "\n"), false)
+}
+
+// block appends a block, separating it from the previous one
+func (w *writer) block(text string, list bool) {
+	if w.b.Len() > 0 {
+		if list && w.lastList {
+			w.b.WriteString("\n")
+		} else {
+			w.b.WriteString("\n\n")
+		}
+	}
+	w.b.WriteString(text)
+	w.lastList = list
+}
+
+// String returns the normalized markdown written so far
+func (w *writer) String() string {
+	if w.b.Len() == 0 {
+		return ""
+	}
+	return Normalize(w.b.String())
+}
+
+// cleanText collapses runs of whitespace, including newlines, to single spaces
+func cleanText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,125 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFixture returns the contents of a file in testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		want     Format
+		wantErr  error
+	}{
+		{"pdf by content", "upload", []byte("%PDF-1.4\n"), PDF, nil},
+		{"docx by content", "upload", readFixture(t, "runbook.docx"), DOCX, nil},
+		{"html by extension", "page.HTM", []byte("<p>hi</p>"), HTML, nil},
+		{"html by content", "page", []byte("\n<!DOCTYPE html><html></html>"), HTML, nil},
+		{"markdown", "notes.md", []byte("# Notes\n"), Markdown, nil},
+		{"plain text", "notes.txt", []byte("notes"), Markdown, nil},
+		{"other zip", "archive.zip", []byte("PK\x03\x04rest"), "", ErrUnsupported},
+		{"mislabelled pdf", "report.pdf", []byte("not a pdf"), "", ErrUnsupported},
+		{"binary", "image.png", []byte{0x89, 'P', 'N', 'G', 0xff, 0xfe}, "", ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(tt.filename, tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestToMarkdown_Fixtures(t *testing.T) {
+	for name, want := range map[string]Format{"runbook.html": HTML, "runbook.docx": DOCX, "runbook.pdf": PDF} {
+		markdown, format, err := ToMarkdown(name, readFixture(t, name))
+		if err != nil {
+			t.Fatalf("ToMarkdown(%s) returned error: %v", name, err)
+		}
+		if format != want {
+			t.Errorf("Expected %s to be read as %s, got %s", name, want, format)
+		}
+		if !bytes.HasPrefix([]byte(markdown), []byte("# Payments Runbook\n")) {
+			t.Errorf("Expected %s to start with its title, got:\n%s", name, markdown)
+		}
+	}
+}
+
+func TestToMarkdown_Errors(t *testing.T) {
+	if _, _, err := ToMarkdown("big.md", make([]byte, MaxFileSize+1)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if _, _, err := ToMarkdown("empty.html", []byte("<html><body><script>x()</script></body></html>")); !errors.Is(err, ErrNoText) {
+		t.Errorf("Expected ErrNoText, got %v", err)
+	}
+	if _, _, err := ToMarkdown("image.gif", []byte("GIF89a\x01\x00\xff")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestMaxFileSize_FitsLambdaPayload(t *testing.T) {
+	// Leave room for the rest of the JSON body and the request envelope
+	if size := base64.StdEncoding.EncodedLen(MaxFileSize); size > 6<<20-256<<10 {
+		t.Errorf("A file of MaxFileSize encodes to %d bytes, over the Lambda payload limit", size)
+	}
+
+	// "# a\n" repeated is plain markdown, so only the size can fail it
+	data := bytes.Repeat([]byte("# a\n"), MaxFileSize/4)
+	if _, _, err := ToMarkdown("max.md", data); err != nil {
+		t.Errorf("Expected a file of exactly MaxFileSize to convert, got %v", err)
+	}
+	if _, _, err := ToMarkdown("over.md", append(data, 'x')); !errors.Is(err, ErrTooLarge) || err.Error() != "file must not exceed 4194304 bytes" {
+		t.Errorf("Expected ErrTooLarge naming the limit, got %v", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	input := "\r\n# Title  \r\n\r\n\r\n\r\nText here\t\n```\nindented  \n\n\n  code\n```\n\n\n"
+	want := "# Title\n\nText here\n```\nindented  \n\n\n  code\n```\n"
+
+	if got := Normalize(input); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	w := &writer{}
+	w.heading(9, "  Deep\n heading ")
+	w.paragraph("   ")
+	w.listItem(0, false, "one")
+	w.listItem(1, false, "nested")
+	w.listItem(0, true, "first")
+	w.listItem(0, true, "second")
+	w.table([][]string{{"Name", "Value"}, {"a|b"}})
+	w.code("\nline 1\n  line 2\n")
+
+	want := "###### Deep heading\n\n" +
+		"- one\n  - nested\n\n" +
+		"1. first\n1. second\n\n" +
+		"| Name | Value |\n| --- | --- |\n| a\\|b |  |\n\n" +
+		"```\nline 1\n  line 2\n```\n"
+	if got := w.String(); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
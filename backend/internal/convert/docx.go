@@ -0,0 +1,430 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize limits how much of each part of a DOCX is decompressed, so a
+// small file cannot expand into an unbounded amount of XML
+const maxPartSize = 64 << 20
+
+// docxVal is an element whose value is held in its w:val attribute
+type docxVal struct {
+	Val string `xml:"val,attr"`
+}
+
+// docxStyles is word/styles.xml
+type docxStyles struct {
+	Styles []struct {
+		ID      string   `xml:"styleId,attr"`
+		Name    docxVal  `xml:"name"`
+		BasedOn docxVal  `xml:"basedOn"`
+		Outline *docxVal `xml:"pPr>outlineLvl"`
+		NumID   docxVal  `xml:"pPr>numPr>numId"`
+	} `xml:"style"`
+}
+
+// docxNumbering is word/numbering.xml
+type docxNumbering struct {
+	Abstract []struct {
+		ID     string `xml:"abstractNumId,attr"`
+		Levels []struct {
+			Level  string  `xml:"ilvl,attr"`
+			Format docxVal `xml:"numFmt"`
+		} `xml:"lvl"`
+	} `xml:"abstractNum"`
+	Nums []struct {
+		ID       string  `xml:"numId,attr"`
+		Abstract docxVal `xml:"abstractNumId"`
+	} `xml:"num"`
+}
+
+// docxRelationships is word/_rels/document.xml.rels
+type docxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// docxStyle is what a paragraph style says about structure
+type docxStyle struct {
+	level int
+	numID string
+}
+
+// docxParagraph is a paragraph being read
+type docxParagraph struct {
+	style   string
+	outline string
+	numID   string
+	ilvl    string
+	parts   []string
+	links   []docxLink
+	inProps bool
+}
+
+// docxLink marks where a hyperlink started in a paragraph's parts
+type docxLink struct {
+	start int
+	href  string
+}
+
+// docxTable is a table being read
+type docxTable struct {
+	rows [][]string
+	cell []string
+}
+
+// docxDocument holds the parts of a DOCX needed to read its body
+type docxDocument struct {
+	styles    map[string]docxStyle
+	formats   map[string]map[string]string
+	links     map[string]string
+	w         *writer
+	paragraph []*docxParagraph
+	tables    []*docxTable
+}
+
+// FromDOCX converts a Word document to markdown. Headings come from paragraph
+// styles and outline levels, lists from numbering and tables are kept as tables.
+func FromDOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX: %v", err)
+	}
+
+	parts := map[string]*zip.File{}
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+	if parts["word/document.xml"] == nil {
+		return "", fmt.Errorf("failed to read DOCX: word/document.xml is missing")
+	}
+
+	doc := &docxDocument{w: &writer{}}
+	var styles docxStyles
+	var numbering docxNumbering
+	var relationships docxRelationships
+	for name, v := range map[string]interface{}{
+		"word/styles.xml":              &styles,
+		"word/numbering.xml":           &numbering,
+		"word/_rels/document.xml.rels": &relationships,
+	} {
+		if err := readPart(parts[name], v); err != nil {
+			return "", fmt.Errorf("failed to read DOCX %s: %v", name, err)
+		}
+	}
+	doc.styles = resolveStyles(styles)
+	doc.formats = numberFormats(numbering)
+	doc.links = map[string]string{}
+	for _, rel := range relationships.Relationships {
+		doc.links[rel.ID] = rel.Target
+	}
+
+	body, err := openPart(parts["word/document.xml"])
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX: %v", err)
+	}
+	defer body.Close()
+
+	if err := doc.read(xml.NewDecoder(body)); err != nil {
+		return "", fmt.Errorf("failed to read DOCX: %v", err)
+	}
+	return doc.w.String(), nil
+}
+
+// read walks the body of the document, writing each paragraph and table
+func (d *docxDocument) read(decoder *xml.Decoder) error {
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			p := d.current()
+			switch t.Name.Local {
+			case "Fallback":
+				// Alternate content repeats the text of the preferred choice
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+			case "p":
+				d.paragraph = append(d.paragraph, &docxParagraph{})
+			case "pPr":
+				if p != nil {
+					p.inProps = true
+				}
+			case "pStyle":
+				if p != nil && p.inProps {
+					p.style = attr(t, "val")
+				}
+			case "outlineLvl":
+				if p != nil && p.inProps {
+					p.outline = attr(t, "val")
+				}
+			case "numId":
+				if p != nil && p.inProps {
+					p.numID = attr(t, "val")
+				}
+			case "ilvl":
+				if p != nil && p.inProps {
+					p.ilvl = attr(t, "val")
+				}
+			case "t":
+				inText = p != nil
+			case "tab", "br", "cr":
+				// Tab stops in the paragraph properties are also called tab
+				if p != nil && !p.inProps {
+					p.parts = append(p.parts, " ")
+				}
+			case "hyperlink":
+				if p != nil {
+					p.links = append(p.links, docxLink{start: len(p.parts), href: d.links[attr(t, "id")]})
+				}
+			case "tbl":
+				d.tables = append(d.tables, &docxTable{})
+			case "tr":
+				if table := d.table(); table != nil {
+					table.rows = append(table.rows, nil)
+				}
+			case "tc":
+				if table := d.table(); table != nil {
+					table.cell = nil
+				}
+			}
+
+		case xml.CharData:
+			if p := d.current(); inText && p != nil {
+				p.parts = append(p.parts, string(t))
+			}
+
+		case xml.EndElement:
+			p := d.current()
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "pPr":
+				if p != nil {
+					p.inProps = false
+				}
+			case "hyperlink":
+				if p != nil && len(p.links) > 0 {
+					link := p.links[len(p.links)-1]
+					p.links = p.links[:len(p.links)-1]
+					label := cleanText(strings.Join(p.parts[link.start:], ""))
+					if label != "" && isLink(link.href) && label != link.href {
+						p.parts = append(p.parts[:link.start], "["+label+"]("+link.href+")")
+					}
+				}
+			case "p":
+				if p != nil {
+					d.paragraph = d.paragraph[:len(d.paragraph)-1]
+					d.endParagraph(p)
+				}
+			case "tc":
+				if table := d.table(); table != nil && len(table.rows) > 0 {
+					row := &table.rows[len(table.rows)-1]
+					*row = append(*row, strings.Join(table.cell, " "))
+				}
+			case "tbl":
+				if table := d.table(); table != nil {
+					d.tables = d.tables[:len(d.tables)-1]
+					d.endTable(table)
+				}
+			}
+		}
+	}
+}
+
+// current returns the paragraph being read, if any
+func (d *docxDocument) current() *docxParagraph {
+	if len(d.paragraph) == 0 {
+		return nil
+	}
+	return d.paragraph[len(d.paragraph)-1]
+}
+
+// table returns the innermost table being read, if any
+func (d *docxDocument) table() *docxTable {
+	if len(d.tables) == 0 {
+		return nil
+	}
+	return d.tables[len(d.tables)-1]
+}
+
+// endParagraph writes a finished paragraph, or adds it to the table cell it is in
+func (d *docxDocument) endParagraph(p *docxParagraph) {
+	text := cleanText(strings.Join(p.parts, ""))
+	if table := d.table(); table != nil {
+		if text != "" {
+			table.cell = append(table.cell, text)
+		}
+		return
+	}
+
+	style := d.styles[p.style]
+	level := style.level
+	if p.outline != "" {
+		if outline, err := strconv.Atoi(p.outline); err == nil && outline < 9 {
+			level = outline + 1
+		}
+	}
+	numID := p.numID
+	if numID == "" {
+		numID = style.numID
+	}
+
+	switch {
+	case level > 0:
+		d.w.heading(level, text)
+	case numID != "" && numID != "0":
+		depth, _ := strconv.Atoi(p.ilvl)
+		format := d.formats[numID][p.ilvl]
+		if p.ilvl == "" {
+			format = d.formats[numID]["0"]
+		}
+		ordered := format != "" && format != "bullet" && format != "none"
+		d.w.listItem(depth, ordered, text)
+	default:
+		d.w.paragraph(text)
+	}
+}
+
+// endTable writes a finished table. Tables nested in a cell are flattened into
+// the text of that cell.
+func (d *docxDocument) endTable(table *docxTable) {
+	outer := d.table()
+	if outer == nil {
+		d.w.table(table.rows)
+		return
+	}
+	for _, row := range table.rows {
+		outer.cell = append(outer.cell, strings.Join(row, " "))
+	}
+}
+
+// resolveStyles returns the heading level and list numbering of each paragraph
+// style, following basedOn to the styles it inherits from
+func resolveStyles(styles docxStyles) map[string]docxStyle {
+	type definition struct {
+		name, basedOn, outline, numID string
+		hasOutline                    bool
+	}
+	definitions := map[string]definition{}
+	for _, s := range styles.Styles {
+		def := definition{name: strings.ToLower(s.Name.Val), basedOn: s.BasedOn.Val, numID: s.NumID.Val}
+		if s.Outline != nil {
+			def.outline, def.hasOutline = s.Outline.Val, true
+		}
+		definitions[s.ID] = def
+	}
+
+	resolved := map[string]docxStyle{}
+	for id := range definitions {
+		var style docxStyle
+		// Walk up the basedOn chain; the nearest style that says something wins
+		for next, hops := id, 0; next != "" && hops < 10; hops++ {
+			def, ok := definitions[next]
+			if !ok {
+				break
+			}
+			if style.level == 0 {
+				style.level = styleLevel(def.name, def.outline, def.hasOutline)
+			}
+			if style.numID == "" {
+				style.numID = def.numID
+			}
+			next = def.basedOn
+		}
+		resolved[id] = style
+	}
+	return resolved
+}
+
+// styleLevel returns the heading level of a style from its name or outline level
+func styleLevel(name, outline string, hasOutline bool) int {
+	if name == "title" {
+		return 1
+	}
+	if level, ok := strings.CutPrefix(name, "heading "); ok {
+		if n, err := strconv.Atoi(level); err == nil && n > 0 {
+			return n
+		}
+	}
+	if hasOutline {
+		if n, err := strconv.Atoi(outline); err == nil && n < 9 {
+			return n + 1
+		}
+	}
+	return 0
+}
+
+// numberFormats returns the number format of each level of each numbering,
+// such as "bullet" or "decimal"
+func numberFormats(numbering docxNumbering) map[string]map[string]string {
+	abstract := map[string]map[string]string{}
+	for _, a := range numbering.Abstract {
+		levels := map[string]string{}
+		for _, lvl := range a.Levels {
+			levels[lvl.Level] = lvl.Format.Val
+		}
+		abstract[a.ID] = levels
+	}
+
+	formats := map[string]map[string]string{}
+	for _, num := range numbering.Nums {
+		formats[num.ID] = abstract[num.Abstract.Val]
+	}
+	return formats
+}
+
+// readPart unmarshals an XML part of a DOCX; parts that are missing are left empty
+func readPart(f *zip.File, v interface{}) error {
+	if f == nil {
+		return nil
+	}
+	rc, err := openPart(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// openPart opens a part of a DOCX, limited to maxPartSize
+func openPart(f *zip.File) (io.ReadCloser, error) {
+	if f.UncompressedSize64 > maxPartSize {
+		return nil, fmt.Errorf("%s is too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, maxPartSize), rc}, nil
+}
+
+// attr returns the value of an attribute by its local name
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
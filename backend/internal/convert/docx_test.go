@@ -0,0 +1,96 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFromDOCX_Fixture(t *testing.T) {
+	markdown, err := FromDOCX(readFixture(t, "runbook.docx"))
+	if err != nil {
+		t.Fatalf("FromDOCX returned error: %v", err)
+	}
+
+	// The Title style and Heading 1 are both top-level headings, a style based
+	// on Heading 2 inherits its level, and the text box is read once
+	want := `# Payments Runbook
+
+# Overview
+
+The payments service takes card payments for package holidays.
+
+See the [status page](https://status.example.com) before escalating.
+
+## Escalation
+
+- Page the on-call engineer
+  - Use the payments rota
+- Post in the incident channel
+
+1. Check the dashboard
+1. Restart the workers
+
+## Contacts
+
+| Team | Channel |
+| --- | --- |
+| Payments | #payments-oncall |
+
+Refunds over 500 EUR need approval.
+
+## Runbook section
+`
+	if markdown != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, markdown)
+	}
+}
+
+func TestFromDOCX_OnlyDocument(t *testing.T) {
+	// Styles, numbering and relationships are optional; outline levels set on
+	// the paragraph itself still make headings
+	data := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:outlineLvl w:val="2"/></w:pPr><w:r><w:t>Steps</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Run </w:t></w:r><w:r><w:tab/><w:t>it</w:t><w:br/><w:t>now</w:t></w:r></w:p>
+</w:body></w:document>`,
+	})
+
+	markdown, err := FromDOCX(data)
+	if err != nil {
+		t.Fatalf("FromDOCX returned error: %v", err)
+	}
+	if want := "### Steps\n\nRun it now\n"; markdown != want {
+		t.Errorf("Expected %q, got %q", want, markdown)
+	}
+}
+
+func TestFromDOCX_Invalid(t *testing.T) {
+	if _, err := FromDOCX([]byte("PK\x03\x04 truncated")); err == nil {
+		t.Error("Expected an error for a corrupt file")
+	}
+
+	_, err := FromDOCX(zipFiles(t, map[string]string{"xl/workbook.xml": "<workbook/>"}))
+	if err == nil || !strings.Contains(err.Error(), "word/document.xml is missing") {
+		t.Errorf("Expected a missing document error, got %v", err)
+	}
+}
+
+// zipFiles builds a zip archive holding files
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+	return buf.Bytes()
+}
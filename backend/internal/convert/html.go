@@ -0,0 +1,237 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedElements hold no document text: scripts, styles, page chrome and embeds
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Textarea: true,
+}
+
+// blockElements start a new block; everything else is treated as inline text
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Body: true, atom.Dd: true, atom.Details: true, atom.Dialog: true,
+	atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Fieldset: true,
+	atom.Figcaption: true, atom.Figure: true, atom.Footer: true, atom.Form: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Html: true, atom.Li: true, atom.Main: true,
+	atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true, atom.Summary: true,
+	atom.Table: true, atom.Ul: true,
+}
+
+// headingLevels maps heading elements to their markdown level
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// FromHTML converts an HTML page to markdown. Only the body is converted, and
+// scripts, styles and navigation are dropped.
+func FromHTML(data []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %v", err)
+	}
+
+	w := &writer{}
+	root := doc
+	if body := findElement(doc, atom.Body); body != nil {
+		root = body
+	}
+	htmlBlocks(w, root)
+	return w.String(), nil
+}
+
+// htmlBlocks writes the children of n, gathering runs of inline content into paragraphs
+func htmlBlocks(w *writer, n *html.Node) {
+	var inline strings.Builder
+	flush := func() {
+		w.paragraph(inline.String())
+		inline.Reset()
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || !blockElements[c.DataAtom] {
+			htmlInline(&inline, c)
+			continue
+		}
+
+		flush()
+		switch {
+		case headingLevels[c.DataAtom] > 0:
+			var text strings.Builder
+			htmlInline(&text, c)
+			w.heading(headingLevels[c.DataAtom], text.String())
+		case c.DataAtom == atom.Ul || c.DataAtom == atom.Ol:
+			htmlList(w, c, 0)
+		case c.DataAtom == atom.Pre:
+			w.code(textContent(c))
+		case c.DataAtom == atom.Table:
+			w.table(htmlTable(c))
+		case c.DataAtom == atom.Hr:
+		case skippedElements[c.DataAtom]:
+		default:
+			htmlBlocks(w, c)
+		}
+	}
+	flush()
+}
+
+// htmlList writes the items of a ul or ol element, nesting sublists below their item
+func htmlList(w *writer, list *html.Node, depth int) {
+	ordered := list.DataAtom == atom.Ol
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+
+		var text strings.Builder
+		var sublists []*html.Node
+		for c := li.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Ul || c.DataAtom == atom.Ol) {
+				sublists = append(sublists, c)
+				continue
+			}
+			htmlInline(&text, c)
+		}
+
+		w.listItem(depth, ordered, text.String())
+		for _, sublist := range sublists {
+			htmlList(w, sublist, depth+1)
+		}
+	}
+}
+
+// htmlTable returns the text of each cell of a table, row by row
+func htmlTable(table *html.Node) [][]string {
+	var rows [][]string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Tr:
+				var row []string
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						var text strings.Builder
+						htmlInline(&text, cell)
+						row = append(row, text.String())
+					}
+				}
+				rows = append(rows, row)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(c)
+			}
+		}
+	}
+	walk(table)
+	return rows
+}
+
+// htmlInline writes the text of n, keeping links and inline code
+func htmlInline(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch {
+	case skippedElements[n.DataAtom]:
+	case n.DataAtom == atom.Br:
+		b.WriteString(" ")
+	case n.DataAtom == atom.Code:
+		if text := cleanText(textContent(n)); text != "" {
+			b.WriteString("`" + text + "`")
+		}
+	case n.DataAtom == atom.A:
+		var text strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			htmlInline(&text, c)
+		}
+		label := cleanText(text.String())
+		href := attribute(n, "href")
+		if label != "" && isLink(href) && label != href {
+			b.WriteString("[" + label + "](" + href + ")")
+		} else {
+			b.WriteString(label)
+		}
+	default:
+		if blockElements[n.DataAtom] {
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			htmlInline(b, c)
+		}
+		if blockElements[n.DataAtom] {
+			b.WriteString(" ")
+		}
+	}
+}
+
+// textContent returns all text below n as it appears in the source
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Br {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+// findElement returns the first element of type a below n
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attribute returns the value of an element's attribute
+func attribute(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+// isLink reports whether href is an absolute link worth keeping
+func isLink(href string) bool {
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") || strings.HasPrefix(href, "mailto:")
+}
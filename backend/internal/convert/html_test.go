@@ -0,0 +1,72 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromHTML_Fixture(t *testing.T) {
+	markdown, err := FromHTML(readFixture(t, "runbook.html"))
+	if err != nil {
+		t.Fatalf("FromHTML returned error: %v", err)
+	}
+
+	want := `# Payments Runbook
+
+## Overview
+
+The payments service takes card payments for package holidays & flights.
+
+See the [status page](https://status.example.com) before escalating.
+
+Owned by the payments team
+
+## Escalation
+
+- Page the on-call engineer
+  - Use the payments rota
+- Post in the incident channel
+
+1. Check the dashboard
+1. Restart the workers with ` + "`make restart`" + `
+
+### Contacts
+
+| Team | Channel |
+| --- | --- |
+| Payments | #payments-oncall |
+
+` + "```" + `
+kubectl rollout restart deployment/payments
+kubectl get pods -l app=payments
+` + "```" + `
+
+Last updated by the payments team
+`
+	if markdown != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, markdown)
+	}
+}
+
+func TestFromHTML_Fragment(t *testing.T) {
+	// Pages saved without html or body elements still convert
+	markdown, err := FromHTML([]byte(`<h2>Deploys</h2>Run <a href="/relative">the script</a>.<br>Then check<p>Done`))
+	if err != nil {
+		t.Fatalf("FromHTML returned error: %v", err)
+	}
+
+	want := "## Deploys\n\nRun the script. Then check\n\nDone\n"
+	if markdown != want {
+		t.Errorf("Expected %q, got %q", want, markdown)
+	}
+}
+
+func TestFromHTML_SkipsScriptsAndNavigation(t *testing.T) {
+	markdown, err := FromHTML([]byte(`<body><nav>Home</nav><script>alert("<p>x</p>")</script><style>p{}</style><p>Kept</p></body>`))
+	if err != nil {
+		t.Fatalf("FromHTML returned error: %v", err)
+	}
+	if strings.TrimSpace(markdown) != "Kept" {
+		t.Errorf("Expected only the paragraph, got %q", markdown)
+	}
+}
@@ -0,0 +1,469 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+var (
+	// pageNumberLine matches footers such as "3", "Page 3" and "Page 3 of 10"
+	pageNumberLine = regexp.MustCompile(`(?i)^(page\s+)?\d+(\s*(of|/)\s*\d+)?$`)
+
+	// orderedMarker matches the number at the start of a numbered list item
+	orderedMarker = regexp.MustCompile(`^\d{1,3}[.)]\s+`)
+)
+
+// bulletMarkers start lines that are items of a bulleted list. U+F0B7 is the
+// bullet of the Symbol font that word processors use when exporting.
+var bulletMarkers = []string{"•", "◦", "▪", "▫", "‣", "●", "○", "■", "·", "\uf0b7"}
+
+// dashMarkers start list items only when followed by a space
+var dashMarkers = []string{"-", "*", "–"}
+
+// FromPDF converts the text of a PDF to markdown. PDFs have no markup, so the
+// structure is read from the layout: lines set larger than the body text, or
+// short lines set in bold, become headings, gaps between lines separate
+// paragraphs and lines starting with a bullet or number become list items.
+// Scanned PDFs hold images rather than text and convert to nothing.
+func FromPDF(data []byte) (markdown string, err error) {
+	// The PDF reader panics on malformed files rather than returning errors
+	defer func() {
+		if r := recover(); r != nil {
+			markdown, err = "", fmt.Errorf("failed to read PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF: %v", err)
+	}
+
+	var lines []pdfLine
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		for _, line := range pageLines(page, i) {
+			if pageNumberLine.MatchString(line.text) {
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return pdfMarkdown(lines), nil
+}
+
+// pdfSpan is a run of text drawn by a single text operator
+type pdfSpan struct {
+	text  string
+	x, y  float64
+	width float64
+	size  float64
+	bold  bool
+}
+
+// pdfLine is the text drawn on one baseline of a page
+type pdfLine struct {
+	text  string
+	page  int
+	x, y  float64
+	size  float64
+	bold  bool
+	chars int
+}
+
+// matrix is a PDF transformation matrix [a b c d e f]
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// translate returns a matrix that moves by tx, ty
+func translate(tx, ty float64) matrix {
+	return matrix{1, 0, 0, 1, tx, ty}
+}
+
+// textState is the part of the PDF graphics state that positions text
+type textState struct {
+	ctm      matrix
+	tm       matrix
+	tlm      matrix
+	font     pdf.Font
+	fontSize float64
+	bold     bool
+	encoder  pdf.TextEncoding
+	charSp   float64
+	wordSp   float64
+	scale    float64
+	leading  float64
+	rise     float64
+}
+
+// pageLines returns the lines of text drawn on a page, in the order they are drawn
+func pageLines(page pdf.Page, number int) []pdfLine {
+	var spans []pdfSpan
+	g := textState{ctm: identity, tm: identity, tlm: identity, scale: 1}
+	var saved []textState
+	encoders := map[string]pdf.TextEncoding{}
+
+	show := func(raw string) {
+		if g.encoder == nil {
+			return
+		}
+		text := g.encoder.Decode(raw)
+
+		// Simple fonts use one byte per glyph; composite fonts usually two
+		codes := len(raw)
+		if n := len([]rune(text)); n > 0 && len(raw) == 2*n {
+			codes = n
+		}
+		advance := 0.0
+		for i := 0; i < codes; i++ {
+			width := 0.0
+			if codes == len(raw) {
+				width = g.font.Width(int(raw[i]))
+			}
+			if width == 0 {
+				width = 500
+			}
+			tx := width/1000*g.fontSize + g.charSp
+			if codes == len(raw) && raw[i] == ' ' {
+				tx += g.wordSp
+			}
+			advance += tx * g.scale
+		}
+
+		trm := matrix{g.fontSize * g.scale, 0, 0, g.fontSize, 0, g.rise}.mul(g.tm).mul(g.ctm)
+		size := math.Hypot(trm[2], trm[3])
+		start := trm
+		g.tm = translate(advance, 0).mul(g.tm)
+		end := matrix{g.fontSize * g.scale, 0, 0, g.fontSize, 0, g.rise}.mul(g.tm).mul(g.ctm)
+
+		if text == "" {
+			return
+		}
+		spans = append(spans, pdfSpan{
+			text:  text,
+			x:     start[4],
+			y:     start[5],
+			width: math.Hypot(end[4]-start[4], end[5]-start[5]),
+			size:  size,
+			bold:  g.bold,
+		})
+	}
+
+	nextLine := func() {
+		g.tlm = translate(0, -g.leading).mul(g.tlm)
+		g.tm = g.tlm
+	}
+
+	operator := func(stack *pdf.Stack, op string) {
+		n := stack.Len()
+		args := make([]pdf.Value, n)
+		for i := n - 1; i >= 0; i-- {
+			args[i] = stack.Pop()
+		}
+		number := func(i int) float64 {
+			if i < len(args) {
+				return args[i].Float64()
+			}
+			return 0
+		}
+		operands := func(m matrix) matrix {
+			for i := range m {
+				m[i] = number(i)
+			}
+			return m
+		}
+
+		switch op {
+		case "q":
+			saved = append(saved, g)
+		case "Q":
+			if len(saved) > 0 {
+				g = saved[len(saved)-1]
+				saved = saved[:len(saved)-1]
+			}
+		case "cm":
+			if len(args) == 6 {
+				g.ctm = operands(matrix{}).mul(g.ctm)
+			}
+		case "BT":
+			g.tm, g.tlm = identity, identity
+		case "Tf":
+			if len(args) == 2 {
+				name := args[0].Name()
+				g.font = page.Font(name)
+				g.fontSize = number(1)
+				if encoders[name] == nil {
+					encoders[name] = g.font.Encoder()
+				}
+				g.encoder = encoders[name]
+				g.bold = isBold(g.font.BaseFont())
+			}
+		case "Tc":
+			g.charSp = number(0)
+		case "Tw":
+			g.wordSp = number(0)
+		case "Tz":
+			g.scale = number(0) / 100
+		case "TL":
+			g.leading = number(0)
+		case "Ts":
+			g.rise = number(0)
+		case "TD":
+			g.leading = -number(1)
+			fallthrough
+		case "Td":
+			g.tlm = translate(number(0), number(1)).mul(g.tlm)
+			g.tm = g.tlm
+		case "Tm":
+			if len(args) == 6 {
+				g.tlm = operands(matrix{})
+				g.tm = g.tlm
+			}
+		case "T*":
+			nextLine()
+		case "Tj":
+			if len(args) == 1 {
+				show(args[0].RawString())
+			}
+		case "'":
+			if len(args) == 1 {
+				nextLine()
+				show(args[0].RawString())
+			}
+		case "\"":
+			if len(args) == 3 {
+				g.wordSp, g.charSp = number(0), number(1)
+				nextLine()
+				show(args[2].RawString())
+			}
+		case "TJ":
+			if len(args) != 1 {
+				return
+			}
+			for i := 0; i < args[0].Len(); i++ {
+				item := args[0].Index(i)
+				if item.Kind() == pdf.String {
+					show(item.RawString())
+				} else {
+					g.tm = translate(-item.Float64()/1000*g.fontSize*g.scale, 0).mul(g.tm)
+				}
+			}
+		}
+	}
+
+	contents := page.V.Key("Contents")
+	if contents.Kind() == pdf.Array {
+		for i := 0; i < contents.Len(); i++ {
+			pdf.Interpret(contents.Index(i), operator)
+		}
+	} else {
+		pdf.Interpret(contents, operator)
+	}
+
+	return joinSpans(spans, number)
+}
+
+// joinSpans groups spans drawn on the same baseline into lines, adding a space
+// where there is a gap between two spans
+func joinSpans(spans []pdfSpan, page int) []pdfLine {
+	var lines []pdfLine
+	var text strings.Builder
+	var current *pdfLine
+	end := 0.0
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.text = cleanText(text.String())
+		if current.text != "" {
+			lines = append(lines, *current)
+		}
+		current = nil
+		text.Reset()
+	}
+
+	for _, span := range spans {
+		if current != nil && math.Abs(span.y-current.y) > 0.5*math.Max(span.size, current.size) {
+			flush()
+		}
+
+		if current == nil {
+			current = &pdfLine{page: page, x: span.x, y: span.y, bold: true}
+		} else if span.x > end+0.15*span.size || span.x < current.x {
+			text.WriteString(" ")
+		}
+
+		text.WriteString(span.text)
+		end = span.x + span.width
+		chars := len(strings.TrimSpace(span.text))
+		current.chars += chars
+		if chars > 0 {
+			current.size = math.Max(current.size, span.size)
+			current.bold = current.bold && span.bold
+		}
+	}
+	flush()
+
+	return lines
+}
+
+// pdfBlock is a heading, paragraph or list item assembled from lines
+type pdfBlock struct {
+	kind    string
+	level   int
+	ordered bool
+	x       float64
+	text    []string
+}
+
+// pdfMarkdown turns the lines of a PDF into markdown blocks
+func pdfMarkdown(lines []pdfLine) string {
+	levels := pdfHeadingLevels(lines)
+	w := &writer{}
+
+	var block *pdfBlock
+	flush := func() {
+		if block == nil {
+			return
+		}
+		text := strings.Join(block.text, " ")
+		switch block.kind {
+		case "heading":
+			w.heading(block.level, text)
+		case "item":
+			w.listItem(0, block.ordered, text)
+		default:
+			w.paragraph(text)
+		}
+		block = nil
+	}
+
+	for i, line := range lines {
+		follows := i > 0 && continues(lines[i-1], line)
+		level := levels(line)
+
+		switch {
+		case level > 0:
+			if block == nil || block.kind != "heading" || block.level != level || !follows {
+				flush()
+				block = &pdfBlock{kind: "heading", level: level}
+			}
+			block.text = append(block.text, line.text)
+
+		case listMarker(line.text) != "":
+			flush()
+			marker := listMarker(line.text)
+			block = &pdfBlock{kind: "item", ordered: orderedMarker.MatchString(marker), x: line.x}
+			block.text = append(block.text, strings.TrimSpace(strings.TrimPrefix(line.text, marker)))
+
+		case block != nil && block.kind == "item" && follows && line.x > block.x+1:
+			block.text = append(block.text, line.text)
+
+		case block != nil && block.kind == "paragraph" && follows:
+			block.text = append(block.text, line.text)
+
+		default:
+			flush()
+			block = &pdfBlock{kind: "paragraph", text: []string{line.text}}
+		}
+	}
+	flush()
+
+	return w.String()
+}
+
+// continues reports whether line directly follows prev in the same block: it is
+// on the same page, below prev and no more than a normal line spacing away
+func continues(prev, line pdfLine) bool {
+	gap := prev.y - line.y
+	return prev.page == line.page && gap > 0 && gap <= 1.7*math.Max(prev.size, line.size)
+}
+
+// listMarker returns the bullet or number a list item line starts with
+func listMarker(text string) string {
+	if marker := orderedMarker.FindString(text); marker != "" {
+		return marker
+	}
+	for _, bullet := range bulletMarkers {
+		if rest, ok := strings.CutPrefix(text, bullet); ok && strings.TrimSpace(rest) != "" {
+			return bullet
+		}
+	}
+	for _, dash := range dashMarkers {
+		if rest, ok := strings.CutPrefix(text, dash+" "); ok && strings.TrimSpace(rest) != "" {
+			return dash
+		}
+	}
+	return ""
+}
+
+// pdfHeadingLevels returns a function giving the heading level of a line, or 0 for
+// body text. The most common font size is the body size; each larger size is a
+// heading level, largest first, and short bold lines at body size come after them.
+func pdfHeadingLevels(lines []pdfLine) func(pdfLine) int {
+	round := func(size float64) float64 { return math.Round(size*2) / 2 }
+
+	counts := map[float64]int{}
+	for _, line := range lines {
+		counts[round(line.size)] += line.chars
+	}
+	body, most := 0.0, -1
+	for size, count := range counts {
+		if count > most || count == most && size < body {
+			body, most = size, count
+		}
+	}
+
+	var sizes []float64
+	for size := range counts {
+		if size >= body*1.15 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sizes)))
+
+	return func(line pdfLine) int {
+		if len(line.text) > 120 {
+			return 0
+		}
+		size := round(line.size)
+		for i, heading := range sizes {
+			if size == heading {
+				return min(i+1, 6)
+			}
+		}
+		if line.bold && size == body && len(line.text) <= 80 && !strings.HasSuffix(line.text, ".") && listMarker(line.text) == "" {
+			return min(len(sizes)+1, 6)
+		}
+		return 0
+	}
+}
+
+// isBold reports whether a font name is a bold face, such as "ABCDEF+Arial-BoldMT"
+func isBold(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "bold") || strings.Contains(name, "black") || strings.Contains(name, "heavy")
+}
@@ -0,0 +1,90 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestFromPDF_Fixture(t *testing.T) {
+	markdown, err := FromPDF(readFixture(t, "runbook.pdf"))
+	if err != nil {
+		t.Fatalf("FromPDF returned error: %v", err)
+	}
+
+	// Sizes above the 11pt body become headings, largest first, then the short
+	// bold line at body size. Wrapped lines join and page numbers are dropped.
+	want := `# Payments Runbook
+
+## Overview
+
+The payments service takes card payments for package holidays and sends confirmations by email.
+
+## Escalation
+
+- Page the on-call engineer
+- Post in the incident channel
+
+## Refunds
+
+### Before you start
+
+Refunds over 500 EUR need approval from finance.
+`
+	if markdown != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, markdown)
+	}
+}
+
+func TestFromPDF_Invalid(t *testing.T) {
+	if _, err := FromPDF([]byte("%PDF-1.4\nnot really a pdf")); err == nil {
+		t.Error("Expected an error for a corrupt file")
+	}
+}
+
+func TestPDFMarkdown_Layout(t *testing.T) {
+	lines := []pdfLine{
+		{text: "Introduction", page: 1, x: 72, y: 700, size: 16, chars: 12},
+		{text: "First paragraph that wraps", page: 1, x: 72, y: 670, size: 10, chars: 26},
+		{text: "onto a second line.", page: 1, x: 72, y: 658, size: 10, chars: 19},
+		{text: "A new paragraph after a gap.", page: 1, x: 72, y: 630, size: 10, chars: 28},
+		{text: "1. Numbered item that", page: 1, x: 72, y: 610, size: 10, chars: 21},
+		{text: "continues indented", page: 1, x: 86, y: 598, size: 10, chars: 18},
+		{text: "- Dashed item", page: 1, x: 72, y: 586, size: 10, chars: 13},
+		{text: "Back at the margin", page: 1, x: 72, y: 574, size: 10, chars: 18},
+		{text: "Next page starts a paragraph", page: 2, x: 72, y: 760, size: 10, chars: 28},
+	}
+
+	want := `# Introduction
+
+First paragraph that wraps onto a second line.
+
+A new paragraph after a gap.
+
+1. Numbered item that continues indented
+
+- Dashed item
+
+Back at the margin
+
+Next page starts a paragraph
+`
+	if got := pdfMarkdown(lines); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestListMarker(t *testing.T) {
+	tests := map[string]string{
+		"• Bullet":        "•",
+		"\uf0b7Symbol":    "\uf0b7",
+		"- Dash":          "-",
+		"-5 degrees":      "",
+		"2) Second":       "2) ",
+		"2024 was a year": "",
+		"•":               "",
+	}
+	for text, want := range tests {
+		if got := listMarker(text); got != want {
+			t.Errorf("listMarker(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"tuitui-backend/internal/config"
+)
+
+// blobPrefix is prepended to the hash to form an object key
+const blobPrefix = "sha256/"
+
+// NewBlobs creates the content storage in cfg: the S3 bucket in DocumentsBucket,
+// or for local development the directory in DocumentsDir
+func NewBlobs(cfg *config.Config) (Blobs, error) {
+	if cfg.DocumentsBucket != "" {
+		sess, err := session.NewSession(&aws.Config{
+			Region: aws.String(cfg.AWSRegion),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AWS session: %v", err)
+		}
+		return NewS3Blobs(s3.New(sess), cfg.DocumentsBucket), nil
+	}
+
+	if cfg.DocumentsDir != "" {
+		return NewFileBlobs(cfg.DocumentsDir), nil
+	}
+
+	return nil, ErrNotConfigured
+}
+
+// S3Blobs stores content as objects in an S3 bucket
+type S3Blobs struct {
+	client s3iface.S3API
+	bucket string
+}
+
+// NewS3Blobs creates content storage in the given bucket
+func NewS3Blobs(client s3iface.S3API, bucket string) *S3Blobs {
+	return &S3Blobs{client: client, bucket: bucket}
+}
+
+// Put stores content under hash; content already stored is left as it is
+func (b *S3Blobs) Put(ctx context.Context, hash string, content []byte) error {
+	_, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(blobPrefix + hash),
+	})
+	if err == nil {
+		return nil
+	}
+	if !isS3NotFound(err) {
+		return fmt.Errorf("failed to check stored content: %v", err)
+	}
+
+	_, err = b.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.bucket),
+		Key:         aws.String(blobPrefix + hash),
+		Body:        bytes.NewReader(content),
+		ContentType: aws.String("text/markdown; charset=utf-8"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store content: %v", err)
+	}
+
+	return nil
+}
+
+// Get returns the content stored under hash or ErrNotFound
+func (b *S3Blobs) Get(ctx context.Context, hash string) ([]byte, error) {
+	output, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(blobPrefix + hash),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get content: %v", err)
+	}
+	defer output.Body.Close()
+
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %v", err)
+	}
+
+	return content, nil
+}
+
+// Delete removes the content stored under hash; missing content is not an error
+func (b *S3Blobs) Delete(ctx context.Context, hash string) error {
+	_, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(blobPrefix + hash),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete content: %v", err)
+	}
+
+	return nil
+}
+
+// isS3NotFound reports whether err says the object does not exist. HeadObject
+// reports a missing object as "NotFound" and GetObject as NoSuchKey.
+func isS3NotFound(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound"
+	}
+	return false
+}
+
+// FileBlobs stores content as files in a directory, standing in for S3 during
+// local development
+type FileBlobs struct {
+	dir string
+}
+
+// NewFileBlobs creates content storage in dir, which is created on first use
+func NewFileBlobs(dir string) *FileBlobs {
+	return &FileBlobs{dir: dir}
+}
+
+// Put stores content under hash; content already stored is left as it is
+func (b *FileBlobs) Put(ctx context.Context, hash string, content []byte) error {
+	path, err := b.path(hash)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create content directory: %v", err)
+	}
+
+	// Write to a temporary file first so a reader never sees partial content
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to store content: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store content: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store content: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store content: %v", err)
+	}
+
+	return nil
+}
+
+// Get returns the content stored under hash or ErrNotFound
+func (b *FileBlobs) Get(ctx context.Context, hash string) ([]byte, error) {
+	path, err := b.path(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %v", err)
+	}
+
+	return content, nil
+}
+
+// Delete removes the content stored under hash; missing content is not an error
+func (b *FileBlobs) Delete(ctx context.Context, hash string) error {
+	path, err := b.path(hash)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete content: %v", err)
+	}
+
+	return nil
+}
+
+// path returns the file holding hash, fanned out by its first two characters
+func (b *FileBlobs) path(hash string) (string, error) {
+	if !isHash(hash) {
+		return "", fmt.Errorf("invalid content hash %q", hash)
+	}
+	return filepath.Join(b.dir, hash[:2], hash), nil
+}
+
+// isHash reports whether s is a hex SHA-256 hash, so it is safe to use in a path
+func isHash(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
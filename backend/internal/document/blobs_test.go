@@ -0,0 +1,120 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// fakeS3 is an in-memory bucket that counts uploads
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string][]byte
+	puts    int
+}
+
+func (f *fakeS3) HeadObjectWithContext(ctx aws.Context, input *s3.HeadObjectInput, opts ...request.Option) (*s3.HeadObjectOutput, error) {
+	if _, ok := f.objects[aws.StringValue(input.Key)]; !ok {
+		return nil, awserr.New("NotFound", "Not Found", nil)
+	}
+	return &s3.HeadObjectOutput{}, nil
+}
+
+func (f *fakeS3) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	content, _ := io.ReadAll(input.Body)
+	f.objects[aws.StringValue(input.Key)] = content
+	f.puts++
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	content, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(content))}, nil
+}
+
+func (f *fakeS3) DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInput, opts ...request.Option) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// testBlobs runs the behaviour every Blobs implementation must share
+func testBlobs(t *testing.T, blobs Blobs) {
+	ctx := context.Background()
+	content := []byte("# Runbook\n")
+	hash := Hash(content)
+
+	if _, err := blobs.Get(ctx, hash); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound before Put, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := blobs.Put(ctx, hash, content); err != nil {
+			t.Fatalf("Put returned error: %v", err)
+		}
+	}
+
+	got, err := blobs.Get(ctx, hash)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("Expected %q, got %q", content, got)
+	}
+
+	old := []byte("# Old runbook\n")
+	if err := blobs.Put(ctx, Hash(old), old); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := blobs.Delete(ctx, Hash(old)); err != nil {
+			t.Fatalf("Delete returned error: %v", err)
+		}
+	}
+	if _, err := blobs.Get(ctx, Hash(old)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+}
+
+func TestS3Blobs(t *testing.T) {
+	client := &fakeS3{objects: make(map[string][]byte)}
+	testBlobs(t, NewS3Blobs(client, "documents"))
+
+	// Two contents are stored, each once
+	if client.puts != 2 {
+		t.Errorf("Expected each content to be uploaded once, got %d uploads", client.puts)
+	}
+	if _, ok := client.objects["sha256/"+Hash([]byte("# Runbook\n"))]; !ok {
+		t.Errorf("Expected the object to be keyed by its hash, got %v", client.objects)
+	}
+}
+
+func TestFileBlobs(t *testing.T) {
+	dir := t.TempDir()
+	testBlobs(t, NewFileBlobs(dir))
+
+	hash := Hash([]byte("# Runbook\n"))
+	if _, err := os.Stat(filepath.Join(dir, hash[:2], hash)); err != nil {
+		t.Errorf("Expected the content file to exist: %v", err)
+	}
+}
+
+func TestFileBlobs_RejectsInvalidHash(t *testing.T) {
+	blobs := NewFileBlobs(t.TempDir())
+
+	if err := blobs.Put(context.Background(), "../../etc/passwd", []byte("x")); err == nil {
+		t.Error("Expected an error for a hash that is not hex SHA-256")
+	}
+}
@@ -0,0 +1,334 @@
+// Package document stores the markdown documents users upload for the assistant
+// to read. Content is addressed by its SHA-256 hash, so uploading the same text
+// again reuses the stored copy, and every change to a document is kept as a
+// numbered version. Each user's versions of a hash are counted, so content is
+// deleted with the last version that uses it.
+package document
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/config"
+)
+
+// MaxSize is the largest document that can be uploaded, in bytes once escaped
+// as it travels to and from the Lambda: a JSON string in a JSON body, itself a
+// string in the Lambda event or response. Escaping can grow control characters
+// sevenfold, so the raw length alone does not keep a document under the 6 MB
+// Lambda payload limit.
+const MaxSize = 4 << 20
+
+// DefaultName is used when a document is uploaded without a name
+const DefaultName = "Untitled document"
+
+var (
+	// ErrNotFound is returned when a document or version does not exist or belongs to another user
+	ErrNotFound = errors.New("document not found")
+
+	// ErrNotConfigured is returned when no documents table or content storage is configured
+	ErrNotConfigured = errors.New("document storage not configured")
+
+	// ErrEmpty is returned when uploading a document without content
+	ErrEmpty = errors.New("document content is required")
+
+	// ErrTooLarge is returned when uploading a document over MaxSize
+	ErrTooLarge = fmt.Errorf("document must not exceed %d bytes", MaxSize)
+)
+
+// Document is the latest version of a markdown document owned by a single user
+type Document struct {
+	ID        string    `json:"id" dynamodbav:"ID"`
+	UserID    string    `json:"-" dynamodbav:"UserID"`
+	Name      string    `json:"name" dynamodbav:"Name"`
+	Hash      string    `json:"hash" dynamodbav:"Hash"`
+	Size      int64     `json:"size" dynamodbav:"Size"`
+	Version   int       `json:"version" dynamodbav:"Version"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"CreatedAt"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"UpdatedAt"`
+}
+
+// Version is one revision of a document
+type Version struct {
+	Version   int       `json:"version" dynamodbav:"Version"`
+	Name      string    `json:"name" dynamodbav:"Name"`
+	Hash      string    `json:"hash" dynamodbav:"Hash"`
+	Size      int64     `json:"size" dynamodbav:"Size"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"CreatedAt"`
+}
+
+// Store holds document metadata. Every method is scoped to a user, so one user
+// can never read or modify another user's documents.
+type Store interface {
+	// ListDocuments returns the user's documents, most recently updated first
+	ListDocuments(ctx context.Context, userID string) ([]Document, error)
+
+	// GetDocument returns a single document or ErrNotFound
+	GetDocument(ctx context.Context, userID, id string) (*Document, error)
+
+	// CreateDocument adds a new document as version 1
+	CreateDocument(ctx context.Context, userID string, version Version) (*Document, error)
+
+	// AddVersion makes version the document's next version and returns the updated document
+	AddVersion(ctx context.Context, userID, id string, version Version) (*Document, error)
+
+	// ListVersions returns every version of a document, oldest first
+	ListVersions(ctx context.Context, userID, id string) ([]Version, error)
+
+	// DeleteDocument removes a document and all of its versions
+	DeleteDocument(ctx context.Context, userID, id string) error
+
+	// AddReference counts one more version of the user's documents using hash
+	AddReference(ctx context.Context, userID, hash string) error
+
+	// RemoveReferences stops counting count versions of the user's documents
+	// using hash, and reports whether any user's documents still use it.
+	// Content stored before references were counted is always reported in use.
+	RemoveReferences(ctx context.Context, userID, hash string, count int) (bool, error)
+}
+
+// Blobs holds document content keyed by its hash
+type Blobs interface {
+	// Put stores content under hash; content already stored is left as it is
+	Put(ctx context.Context, hash string, content []byte) error
+
+	// Get returns the content stored under hash or ErrNotFound
+	Get(ctx context.Context, hash string) ([]byte, error)
+
+	// Delete removes the content stored under hash; missing content is not an error
+	Delete(ctx context.Context, hash string) error
+}
+
+// Library stores documents: metadata in a Store and content in Blobs
+type Library struct {
+	Store
+	blobs Blobs
+}
+
+// NewLibrary creates a library over store and blobs
+func NewLibrary(store Store, blobs Blobs) *Library {
+	return &Library{Store: store, blobs: blobs}
+}
+
+// New creates the library for the table and content storage in cfg
+func New(cfg *config.Config) (*Library, error) {
+	store, err := NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs, err := NewBlobs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLibrary(store, blobs), nil
+}
+
+// NewStore creates the DynamoDB store for the table in cfg.DocumentsTable
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.DocumentsTable == "" {
+		return nil, ErrNotConfigured
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.AWSRegion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return NewDynamoStore(dynamodb.New(sess), cfg.DocumentsTable), nil
+}
+
+// Hash returns the hex SHA-256 hash content is stored under
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Upload stores a new document for the user. If the user already has a document
+// whose latest version has the same content, that document is returned instead
+// and created is false.
+func (l *Library) Upload(ctx context.Context, userID, name, content string) (doc *Document, created bool, err error) {
+	version, err := newVersion(name, content)
+	if err != nil {
+		return nil, false, err
+	}
+
+	documents, err := l.ListDocuments(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range documents {
+		if documents[i].Hash == version.Hash {
+			return &documents[i], false, nil
+		}
+	}
+
+	if err := l.store(ctx, userID, version.Hash, content); err != nil {
+		return nil, false, err
+	}
+
+	doc, err = l.CreateDocument(ctx, userID, version)
+	if err != nil {
+		return nil, false, err
+	}
+	return doc, true, nil
+}
+
+// Update saves new content for a document as its next version. An empty name
+// keeps the current one, and an update that changes nothing adds no version.
+func (l *Library) Update(ctx context.Context, userID, id, name, content string) (*Document, error) {
+	current, err := l.GetDocument(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = current.Name
+	}
+	version, err := newVersion(name, content)
+	if err != nil {
+		return nil, err
+	}
+	if version.Hash == current.Hash && version.Name == current.Name {
+		return current, nil
+	}
+
+	if err := l.store(ctx, userID, version.Hash, content); err != nil {
+		return nil, err
+	}
+
+	return l.AddVersion(ctx, userID, id, version)
+}
+
+// Content returns a version of a document and its content. Version 0 is the latest.
+func (l *Library) Content(ctx context.Context, userID, id string, version int) (*Version, string, error) {
+	versions, err := l.ListVersions(ctx, userID, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(versions) == 0 {
+		return nil, "", ErrNotFound
+	}
+
+	selected := &versions[len(versions)-1]
+	if version != 0 {
+		selected = nil
+		for i := range versions {
+			if versions[i].Version == version {
+				selected = &versions[i]
+				break
+			}
+		}
+		if selected == nil {
+			return nil, "", ErrNotFound
+		}
+	}
+
+	content, err := l.blobs.Get(ctx, selected.Hash)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, "", fmt.Errorf("content of %s version %d is missing", id, selected.Version)
+		}
+		return nil, "", fmt.Errorf("failed to load document content: %v", err)
+	}
+
+	return selected, string(content), nil
+}
+
+// Delete removes a document and its versions. Content is shared by every document
+// with the same hash, so it is only deleted once no document uses it.
+func (l *Library) Delete(ctx context.Context, userID, id string) error {
+	versions, err := l.ListVersions(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := l.DeleteDocument(ctx, userID, id); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, version := range versions {
+		counts[version.Hash]++
+	}
+	for hash, count := range counts {
+		inUse, err := l.RemoveReferences(ctx, userID, hash, count)
+		if err != nil {
+			return fmt.Errorf("failed to release document content: %v", err)
+		}
+		if inUse {
+			continue
+		}
+		if err := l.blobs.Delete(ctx, hash); err != nil {
+			return fmt.Errorf("failed to delete document content: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// store counts a new version of the user's documents using hash and stores its
+// content. The reference is added first so a concurrent Delete never removes
+// content that is about to be used.
+func (l *Library) store(ctx context.Context, userID, hash, content string) error {
+	if err := l.AddReference(ctx, userID, hash); err != nil {
+		return fmt.Errorf("failed to count document content: %v", err)
+	}
+
+	if err := l.blobs.Put(ctx, hash, []byte(content)); err != nil {
+		return fmt.Errorf("failed to store document content: %v", err)
+	}
+
+	return nil
+}
+
+// escapedSize returns the length of content escaped twice as a JSON string,
+// as in the body of a Lambda event or response, without its quotes
+func escapedSize(content string) int {
+	once, _ := json.Marshal(content)
+	twice, _ := json.Marshal(string(once[1 : len(once)-1]))
+	return len(twice) - 2
+}
+
+// newVersion validates content and describes it as a version
+func newVersion(name, content string) (Version, error) {
+	if strings.TrimSpace(content) == "" {
+		return Version{}, ErrEmpty
+	}
+	if len(content) > MaxSize || escapedSize(content) > MaxSize {
+		return Version{}, ErrTooLarge
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultName
+	}
+
+	return Version{
+		Name: name,
+		Hash: Hash([]byte(content)),
+		Size: int64(len(content)),
+	}, nil
+}
+
+// newID returns a random document ID
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,159 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLibrary_UploadDeduplicates(t *testing.T) {
+	library := NewLibrary(NewMemoryStore(), NewFileBlobs(t.TempDir()))
+	ctx := context.Background()
+
+	first, created, err := library.Upload(ctx, "user-1", " Runbook ", "# Deploys\n")
+	if err != nil {
+		t.Fatalf("Upload returned error: %v", err)
+	}
+	if !created || first.Name != "Runbook" || first.Hash != Hash([]byte("# Deploys\n")) {
+		t.Errorf("Unexpected upload: created=%v %+v", created, first)
+	}
+
+	again, created, err := library.Upload(ctx, "user-1", "Copy", "# Deploys\n")
+	if err != nil {
+		t.Fatalf("Upload returned error: %v", err)
+	}
+	if created || again.ID != first.ID {
+		t.Errorf("Expected the existing document to be returned, got created=%v %+v", created, again)
+	}
+
+	// Dedupe is per user: another user gets their own document
+	other, created, err := library.Upload(ctx, "user-2", "Runbook", "# Deploys\n")
+	if err != nil {
+		t.Fatalf("Upload returned error: %v", err)
+	}
+	if !created || other.ID == first.ID {
+		t.Errorf("Expected a new document for another user, got created=%v %+v", created, other)
+	}
+}
+
+func TestLibrary_UploadValidates(t *testing.T) {
+	library := NewLibrary(NewMemoryStore(), NewFileBlobs(t.TempDir()))
+
+	if _, _, err := library.Upload(context.Background(), "user-1", "Empty", "  \n"); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+	if _, _, err := library.Upload(context.Background(), "user-1", "Huge", strings.Repeat("x", MaxSize+1)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+
+	doc, _, _ := library.Upload(context.Background(), "user-1", "", "text")
+	if doc.Name != DefaultName {
+		t.Errorf("Expected the default name, got %q", doc.Name)
+	}
+}
+
+func TestMaxSize_FitsLambdaPayload(t *testing.T) {
+	library := NewLibrary(NewMemoryStore(), NewFileBlobs(t.TempDir()))
+
+	// Control characters escape to seven bytes each in the Lambda payload, the worst case
+	worst := strings.Repeat("\x01", MaxSize/7)
+	doc, _, err := library.Upload(context.Background(), "user-1", "Worst case", worst)
+	if err != nil {
+		t.Fatalf("Expected a document escaping to MaxSize to upload, got %v", err)
+	}
+	if _, _, err := library.Upload(context.Background(), "user-1", "Over", worst+"\x01"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge once escaped over MaxSize, got %v", err)
+	}
+
+	// Leave room for the rest of the JSON body and the response envelope
+	_, content, err := library.Content(context.Background(), "user-1", doc.ID, 0)
+	if err != nil {
+		t.Fatalf("Content returned error: %v", err)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"document": doc, "content": content})
+	envelope, _ := json.Marshal(map[string]string{"body": string(body)})
+	if len(envelope) > 6<<20-256<<10 {
+		t.Errorf("The largest document returns %d bytes, over the Lambda payload limit", len(envelope))
+	}
+}
+
+func TestLibrary_UpdateAndContent(t *testing.T) {
+	library := NewLibrary(NewMemoryStore(), NewFileBlobs(t.TempDir()))
+	ctx := context.Background()
+
+	doc, _, _ := library.Upload(ctx, "user-1", "Runbook", "first")
+
+	unchanged, err := library.Update(ctx, "user-1", doc.ID, "", "first")
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if unchanged.Version != 1 {
+		t.Errorf("Expected an unchanged update to add no version, got %d", unchanged.Version)
+	}
+
+	updated, err := library.Update(ctx, "user-1", doc.ID, "", "second")
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if updated.Version != 2 || updated.Name != "Runbook" {
+		t.Errorf("Expected version 2 keeping the name, got %+v", updated)
+	}
+
+	version, content, err := library.Content(ctx, "user-1", doc.ID, 0)
+	if err != nil {
+		t.Fatalf("Content returned error: %v", err)
+	}
+	if version.Version != 2 || content != "second" {
+		t.Errorf("Expected the latest content, got version %d %q", version.Version, content)
+	}
+
+	version, content, err = library.Content(ctx, "user-1", doc.ID, 1)
+	if err != nil {
+		t.Fatalf("Content returned error: %v", err)
+	}
+	if version.Version != 1 || content != "first" {
+		t.Errorf("Expected the first version's content, got version %d %q", version.Version, content)
+	}
+
+	if _, _, err := library.Content(ctx, "user-1", doc.ID, 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing version, got %v", err)
+	}
+	if _, err := library.Update(ctx, "user-2", doc.ID, "", "theirs"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating another user's document, got %v", err)
+	}
+}
+
+func TestLibrary_DeleteRemovesUnusedContent(t *testing.T) {
+	dir := t.TempDir()
+	library := NewLibrary(NewMemoryStore(), NewFileBlobs(dir))
+	ctx := context.Background()
+
+	hash := Hash([]byte("shared"))
+	path := filepath.Join(dir, hash[:2], hash)
+
+	mine, _, _ := library.Upload(ctx, "user-1", "Runbook", "shared")
+	library.Update(ctx, "user-1", mine.ID, "Renamed", "shared")
+	theirs, _, _ := library.Upload(ctx, "user-2", "Runbook", "shared")
+
+	if err := library.Delete(ctx, "user-1", mine.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected content another user uses to be kept: %v", err)
+	}
+
+	if err := library.Delete(ctx, "user-2", theirs.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected content to be deleted with its last document, got %v", err)
+	}
+
+	if err := library.Delete(ctx, "user-2", theirs.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+}
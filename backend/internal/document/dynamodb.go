@@ -0,0 +1,425 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Key layout: every item for a user lives in the partition "USER#<sub>".
+// Documents use the sort key "DOC#<id>" and their versions "VER#<id>#<version>"
+// with the version zero-padded, so a user's documents and a document's versions
+// can each be read in order with a single begins_with query.
+//
+// References to content live in the partition "CONTENT#<hash>" with the sort
+// key "USER#<sub>", counting that user's versions using the content, so one
+// query tells whether any user still needs it.
+const (
+	userPrefix     = "USER#"
+	documentPrefix = "DOC#"
+	versionPrefix  = "VER#"
+	contentPrefix  = "CONTENT#"
+
+	// batchWriteLimit is the maximum number of requests in one BatchWriteItem call
+	batchWriteLimit = 25
+)
+
+// DynamoStore is a Store backed by a single DynamoDB table with string keys PK and SK
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{
+		client: client,
+		table:  table,
+		now:    time.Now,
+	}
+}
+
+// documentItem is the DynamoDB representation of a Document
+type documentItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Document
+}
+
+// versionItem is the DynamoDB representation of a Version
+type versionItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Version
+}
+
+// ListDocuments returns the user's documents, most recently updated first
+func (s *DynamoStore) ListDocuments(ctx context.Context, userID string) ([]Document, error) {
+	items, err := s.query(ctx, userID, documentPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %v", err)
+	}
+
+	documents := []Document{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &documents); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal documents: %v", err)
+	}
+	sortByUpdated(documents)
+
+	return documents, nil
+}
+
+// GetDocument returns a single document or ErrNotFound
+func (s *DynamoStore) GetDocument(ctx context.Context, userID, id string) (*Document, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       itemKey(userID, documentKey(id)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %v", err)
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var doc Document
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	return &doc, nil
+}
+
+// CreateDocument adds a new document as version 1
+func (s *DynamoStore) CreateDocument(ctx context.Context, userID string, version Version) (*Document, error) {
+	now := s.now().UTC()
+	version.Version = 1
+	version.CreatedAt = now
+
+	doc := Document{
+		ID:        newID(),
+		UserID:    userID,
+		Name:      version.Name,
+		Hash:      version.Hash,
+		Size:      version.Size,
+		Version:   version.Version,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// Store the version first so a listed document always has its content
+	if err := s.putVersion(ctx, userID, doc.ID, version); err != nil {
+		return nil, err
+	}
+
+	item, err := dynamodbattribute.MarshalMap(documentItem{
+		PK:       userKey(userID),
+		SK:       documentKey(doc.ID),
+		Document: doc,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document: %v", err)
+	}
+
+	return &doc, nil
+}
+
+// AddVersion makes version the document's next version and returns the updated document
+func (s *DynamoStore) AddVersion(ctx context.Context, userID, id string, version Version) (*Document, error) {
+	now := s.now().UTC()
+
+	// Bumping the version number first reserves it, so concurrent updates never
+	// write the same version
+	result, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 itemKey(userID, documentKey(id)),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		UpdateExpression:    aws.String("SET #name = :name, #hash = :hash, #size = :size, UpdatedAt = :now ADD #version :one"),
+		ExpressionAttributeNames: map[string]*string{
+			"#name":    aws.String("Name"),
+			"#hash":    aws.String("Hash"),
+			"#size":    aws.String("Size"),
+			"#version": aws.String("Version"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":name": {S: aws.String(version.Name)},
+			":hash": {S: aws.String(version.Hash)},
+			":size": {N: aws.String(strconv.FormatInt(version.Size, 10))},
+			":now":  {S: aws.String(now.Format(time.RFC3339Nano))},
+			":one":  {N: aws.String("1")},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update document: %v", err)
+	}
+
+	var doc Document
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	version.Version = doc.Version
+	version.CreatedAt = now
+	if err := s.putVersion(ctx, userID, id, version); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// ListVersions returns every version of a document, oldest first
+func (s *DynamoStore) ListVersions(ctx context.Context, userID, id string) ([]Version, error) {
+	if _, err := s.GetDocument(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	items, err := s.query(ctx, userID, versionKeyPrefix(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %v", err)
+	}
+
+	versions := []Version{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &versions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal versions: %v", err)
+	}
+
+	return versions, nil
+}
+
+// DeleteDocument removes a document and all of its versions
+func (s *DynamoStore) DeleteDocument(ctx context.Context, userID, id string) error {
+	_, err := s.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 itemKey(userID, documentKey(id)),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+
+	items, err := s.query(ctx, userID, versionKeyPrefix(id))
+	if err != nil {
+		return fmt.Errorf("failed to list versions for deletion: %v", err)
+	}
+
+	requests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, item := range items {
+		requests = append(requests, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{
+				Key: map[string]*dynamodb.AttributeValue{
+					"PK": item["PK"],
+					"SK": item["SK"],
+				},
+			},
+		})
+	}
+
+	if err := s.batchWrite(ctx, requests); err != nil {
+		return fmt.Errorf("failed to delete versions: %v", err)
+	}
+
+	return nil
+}
+
+// AddReference counts one more version of the user's documents using hash
+func (s *DynamoStore) AddReference(ctx context.Context, userID, hash string) error {
+	_, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(s.table),
+		Key:                      referenceKey(userID, hash),
+		UpdateExpression:         aws.String("ADD #references :count"),
+		ExpressionAttributeNames: map[string]*string{"#references": aws.String("References")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":count": {N: aws.String("1")},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add content reference: %v", err)
+	}
+
+	return nil
+}
+
+// RemoveReferences stops counting count versions of the user's documents
+// using hash, and reports whether any user's documents still use it
+func (s *DynamoStore) RemoveReferences(ctx context.Context, userID, hash string, count int) (bool, error) {
+	// Content stored before references were counted has no reference item
+	result, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(s.table),
+		Key:                      referenceKey(userID, hash),
+		ConditionExpression:      aws.String("attribute_exists(PK)"),
+		UpdateExpression:         aws.String("ADD #references :count"),
+		ExpressionAttributeNames: map[string]*string{"#references": aws.String("References")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":count": {N: aws.String(strconv.Itoa(-count))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to remove content reference: %v", err)
+	}
+
+	remaining, _ := strconv.Atoi(aws.StringValue(result.Attributes["References"].N))
+	if remaining > 0 {
+		return true, nil
+	}
+
+	// Only delete the item while it is unused, in case an upload counted it again
+	_, err = s.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:                aws.String(s.table),
+		Key:                      referenceKey(userID, hash),
+		ConditionExpression:      aws.String("#references <= :zero"),
+		ExpressionAttributeNames: map[string]*string{"#references": aws.String("References")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":zero": {N: aws.String("0")},
+		},
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to remove content reference: %v", err)
+	}
+
+	items, err := s.queryPartition(ctx, contentPrefix+hash, userPrefix)
+	if err != nil {
+		return false, fmt.Errorf("failed to list content references: %v", err)
+	}
+
+	return len(items) > 0, nil
+}
+
+// putVersion stores a version item
+func (s *DynamoStore) putVersion(ctx context.Context, userID, id string, version Version) error {
+	item, err := dynamodbattribute.MarshalMap(versionItem{
+		PK:      userKey(userID),
+		SK:      versionKey(id, version.Version),
+		Version: version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal version: %v", err)
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store version: %v", err)
+	}
+
+	return nil
+}
+
+// query returns every item in the user's partition whose sort key starts with prefix
+func (s *DynamoStore) query(ctx context.Context, userID, prefix string) ([]map[string]*dynamodb.AttributeValue, error) {
+	return s.queryPartition(ctx, userKey(userID), prefix)
+}
+
+// queryPartition returns every item in partition pk whose sort key starts with prefix
+func (s *DynamoStore) queryPartition(ctx context.Context, pk, prefix string) ([]map[string]*dynamodb.AttributeValue, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":     {S: aws.String(pk)},
+			":prefix": {S: aws.String(prefix)},
+		},
+	}
+
+	err := s.client.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// batchWrite sends write requests in chunks and retries unprocessed items
+func (s *DynamoStore) batchWrite(ctx context.Context, requests []*dynamodb.WriteRequest) error {
+	for start := 0; start < len(requests); start += batchWriteLimit {
+		end := min(start+batchWriteLimit, len(requests))
+
+		pending := map[string][]*dynamodb.WriteRequest{s.table: requests[start:end]}
+		for len(pending) > 0 {
+			result, err := s.client.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: pending,
+			})
+			if err != nil {
+				return err
+			}
+			pending = result.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// isConditionFailed reports whether err is a failed DynamoDB condition check
+func isConditionFailed(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
+
+func userKey(userID string) string {
+	return userPrefix + userID
+}
+
+func documentKey(id string) string {
+	return documentPrefix + id
+}
+
+func versionKeyPrefix(id string) string {
+	return versionPrefix + id + "#"
+}
+
+func versionKey(id string, version int) string {
+	return fmt.Sprintf("%s%06d", versionKeyPrefix(id), version)
+}
+
+// referenceKey is the key of the item counting the user's versions using hash
+func referenceKey(userID, hash string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String(contentPrefix + hash)},
+		"SK": {S: aws.String(userKey(userID))},
+	}
+}
+
+func itemKey(userID, sortKey string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String(userKey(userID))},
+		"SK": {S: aws.String(sortKey)},
+	}
+}
@@ -0,0 +1,166 @@
+package document
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is a minimal in-memory table that understands the operations the
+// store issues against a PK/SK table
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	mu    sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+}
+
+func fakeKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["PK"].S) + "|" + aws.StringValue(key["SK"].S)
+}
+
+func conditionFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+}
+
+func (f *fakeDynamo) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[fakeKey(input.Item)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[fakeKey(input.Key)]}, nil
+}
+
+func (f *fakeDynamo) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fakeKey(input.Key)
+	if _, ok := f.items[key]; !ok && input.ConditionExpression != nil {
+		return nil, conditionFailed()
+	}
+	delete(f.items, key)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// UpdateItemWithContext applies the SET and ADD clauses AddVersion issues and
+// the reference counting ADD
+func (f *fakeDynamo) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[fakeKey(input.Key)]
+	if aws.StringValue(input.UpdateExpression) == "ADD #references :count" {
+		if !ok {
+			if input.ConditionExpression != nil {
+				return nil, conditionFailed()
+			}
+			item = map[string]*dynamodb.AttributeValue{"PK": input.Key["PK"], "SK": input.Key["SK"], "References": {N: aws.String("0")}}
+			f.items[fakeKey(input.Key)] = item
+		}
+		references, _ := strconv.Atoi(aws.StringValue(item["References"].N))
+		count, _ := strconv.Atoi(aws.StringValue(input.ExpressionAttributeValues[":count"].N))
+		item["References"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(references + count))}
+		return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+	}
+	if !ok {
+		return nil, conditionFailed()
+	}
+	names := input.ExpressionAttributeNames
+	values := input.ExpressionAttributeValues
+	item[aws.StringValue(names["#name"])] = values[":name"]
+	item[aws.StringValue(names["#hash"])] = values[":hash"]
+	item[aws.StringValue(names["#size"])] = values[":size"]
+	item["UpdatedAt"] = values[":now"]
+	version, _ := strconv.Atoi(aws.StringValue(item["Version"].N))
+	item["Version"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(version + 1))}
+	return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+}
+
+func (f *fakeDynamo) QueryPagesWithContext(ctx aws.Context, input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, opts ...request.Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pk := aws.StringValue(input.ExpressionAttributeValues[":pk"].S)
+	prefix := aws.StringValue(input.ExpressionAttributeValues[":prefix"].S)
+
+	var keys []string
+	for key := range f.items {
+		if strings.HasPrefix(key, pk+"|"+prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	output := &dynamodb.QueryOutput{}
+	for _, key := range keys {
+		output.Items = append(output.Items, f.items[key])
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeDynamo) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, requests := range input.RequestItems {
+		if len(requests) > batchWriteLimit {
+			return nil, awserr.New("ValidationException", "too many items", nil)
+		}
+		for _, req := range requests {
+			if req.PutRequest != nil {
+				f.items[fakeKey(req.PutRequest.Item)] = req.PutRequest.Item
+			}
+			if req.DeleteRequest != nil {
+				delete(f.items, fakeKey(req.DeleteRequest.Key))
+			}
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func TestDynamoStore(t *testing.T) {
+	testStore(t, NewDynamoStore(newFakeDynamo(), "documents"))
+}
+
+func TestDynamoStore_VersionsSortNumerically(t *testing.T) {
+	client := newFakeDynamo()
+	store := NewDynamoStore(client, "documents")
+	ctx := context.Background()
+
+	doc, err := store.CreateDocument(ctx, "user-1", Version{Name: "Notes", Hash: Hash([]byte("0"))})
+	if err != nil {
+		t.Fatalf("CreateDocument returned error: %v", err)
+	}
+	for i := 1; i < 12; i++ {
+		if _, err := store.AddVersion(ctx, "user-1", doc.ID, Version{Name: "Notes", Hash: Hash([]byte(strconv.Itoa(i)))}); err != nil {
+			t.Fatalf("AddVersion returned error: %v", err)
+		}
+	}
+
+	versions, _ := store.ListVersions(ctx, "user-1", doc.ID)
+	if len(versions) != 12 || versions[9].Version != 10 || versions[11].Version != 12 {
+		t.Errorf("Expected versions 1 to 12 in order, got %+v", versions)
+	}
+
+	if err := store.DeleteDocument(ctx, "user-1", doc.ID); err != nil {
+		t.Fatalf("DeleteDocument returned error: %v", err)
+	}
+	if len(client.items) != 0 {
+		t.Errorf("Expected all items to be deleted, %d remain", len(client.items))
+	}
+}
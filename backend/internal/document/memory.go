@@ -0,0 +1,178 @@
+package document
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store for tests and local development
+type MemoryStore struct {
+	mu        sync.Mutex
+	documents map[string]map[string]*Document
+	versions  map[string][]Version
+	refs      map[string]map[string]int // hash to user to versions using it
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		documents: make(map[string]map[string]*Document),
+		versions:  make(map[string][]Version),
+		refs:      make(map[string]map[string]int),
+		now:       time.Now,
+	}
+}
+
+// ListDocuments returns the user's documents, most recently updated first
+func (s *MemoryStore) ListDocuments(ctx context.Context, userID string) ([]Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	documents := []Document{}
+	for _, doc := range s.documents[userID] {
+		documents = append(documents, *doc)
+	}
+	sortByUpdated(documents)
+
+	return documents, nil
+}
+
+// GetDocument returns a single document or ErrNotFound
+func (s *MemoryStore) GetDocument(ctx context.Context, userID, id string) (*Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.documents[userID][id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *doc
+	return &copied, nil
+}
+
+// CreateDocument adds a new document as version 1
+func (s *MemoryStore) CreateDocument(ctx context.Context, userID string, version Version) (*Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	version.Version = 1
+	version.CreatedAt = now
+
+	doc := &Document{
+		ID:        newID(),
+		UserID:    userID,
+		Name:      version.Name,
+		Hash:      version.Hash,
+		Size:      version.Size,
+		Version:   version.Version,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if s.documents[userID] == nil {
+		s.documents[userID] = make(map[string]*Document)
+	}
+	s.documents[userID][doc.ID] = doc
+	s.versions[doc.ID] = []Version{version}
+
+	copied := *doc
+	return &copied, nil
+}
+
+// AddVersion makes version the document's next version and returns the updated document
+func (s *MemoryStore) AddVersion(ctx context.Context, userID, id string, version Version) (*Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.documents[userID][id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	now := s.now().UTC()
+	version.Version = doc.Version + 1
+	version.CreatedAt = now
+
+	doc.Name = version.Name
+	doc.Hash = version.Hash
+	doc.Size = version.Size
+	doc.Version = version.Version
+	doc.UpdatedAt = now
+	s.versions[id] = append(s.versions[id], version)
+
+	copied := *doc
+	return &copied, nil
+}
+
+// ListVersions returns every version of a document, oldest first
+func (s *MemoryStore) ListVersions(ctx context.Context, userID, id string) ([]Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.documents[userID][id]; !ok {
+		return nil, ErrNotFound
+	}
+
+	versions := make([]Version, len(s.versions[id]))
+	copy(versions, s.versions[id])
+	return versions, nil
+}
+
+// DeleteDocument removes a document and all of its versions
+func (s *MemoryStore) DeleteDocument(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.documents[userID][id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.documents[userID], id)
+	delete(s.versions, id)
+
+	return nil
+}
+
+// AddReference counts one more version of the user's documents using hash
+func (s *MemoryStore) AddReference(ctx context.Context, userID, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.refs[hash] == nil {
+		s.refs[hash] = make(map[string]int)
+	}
+	s.refs[hash][userID]++
+
+	return nil
+}
+
+// RemoveReferences stops counting count versions of the user's documents
+// using hash, and reports whether any user's documents still use it
+func (s *MemoryStore) RemoveReferences(ctx context.Context, userID, hash string, count int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.refs[hash][userID]; !ok {
+		return true, nil
+	}
+
+	s.refs[hash][userID] -= count
+	if s.refs[hash][userID] <= 0 {
+		delete(s.refs[hash], userID)
+	}
+	if len(s.refs[hash]) > 0 {
+		return true, nil
+	}
+	delete(s.refs, hash)
+
+	return false, nil
+}
+
+func sortByUpdated(documents []Document) {
+	sort.SliceStable(documents, func(i, j int) bool {
+		return documents[i].UpdatedAt.After(documents[j].UpdatedAt)
+	})
+}
@@ -0,0 +1,104 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	doc, err := store.CreateDocument(ctx, "user-1", Version{Name: "Runbook", Hash: Hash([]byte("v1")), Size: 2})
+	if err != nil {
+		t.Fatalf("CreateDocument returned error: %v", err)
+	}
+	if doc.ID == "" || doc.Version != 1 || doc.Name != "Runbook" {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+
+	updated, err := store.AddVersion(ctx, "user-1", doc.ID, Version{Name: "Runbook v2", Hash: Hash([]byte("v2")), Size: 2})
+	if err != nil {
+		t.Fatalf("AddVersion returned error: %v", err)
+	}
+	if updated.Version != 2 || updated.Name != "Runbook v2" || updated.Hash != Hash([]byte("v2")) {
+		t.Errorf("Unexpected updated document: %+v", updated)
+	}
+
+	got, err := store.GetDocument(ctx, "user-1", doc.ID)
+	if err != nil {
+		t.Fatalf("GetDocument returned error: %v", err)
+	}
+	if got.Version != 2 || !got.CreatedAt.Equal(doc.CreatedAt) {
+		t.Errorf("Expected the latest version with the original creation time, got %+v", got)
+	}
+
+	versions, err := store.ListVersions(ctx, "user-1", doc.ID)
+	if err != nil {
+		t.Fatalf("ListVersions returned error: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 1 || versions[0].Name != "Runbook" || versions[1].Version != 2 {
+		t.Errorf("Unexpected versions: %+v", versions)
+	}
+
+	// Another user must not see or change the document
+	if _, err := store.GetDocument(ctx, "user-2", doc.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another user, got %v", err)
+	}
+	if _, err := store.AddVersion(ctx, "user-2", doc.ID, Version{Name: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound adding a version as another user, got %v", err)
+	}
+	if _, err := store.ListVersions(ctx, "user-2", doc.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound listing versions as another user, got %v", err)
+	}
+
+	second, err := store.CreateDocument(ctx, "user-1", Version{Name: "Second", Hash: Hash([]byte("other")), Size: 5})
+	if err != nil {
+		t.Fatalf("CreateDocument returned error: %v", err)
+	}
+
+	documents, err := store.ListDocuments(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("ListDocuments returned error: %v", err)
+	}
+	if len(documents) != 2 || documents[0].ID != second.ID {
+		t.Errorf("Expected the newest document first, got %+v", documents)
+	}
+
+	if err := store.DeleteDocument(ctx, "user-1", doc.ID); err != nil {
+		t.Fatalf("DeleteDocument returned error: %v", err)
+	}
+	if _, err := store.ListVersions(ctx, "user-1", doc.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.DeleteDocument(ctx, "user-1", doc.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	documents, _ = store.ListDocuments(ctx, "user-2")
+	if len(documents) != 0 {
+		t.Errorf("Expected no documents for another user, got %+v", documents)
+	}
+
+	// Content is in use until every user's references are removed
+	hash := Hash([]byte("shared"))
+	for _, userID := range []string{"user-1", "user-1", "user-2"} {
+		if err := store.AddReference(ctx, userID, hash); err != nil {
+			t.Fatalf("AddReference returned error: %v", err)
+		}
+	}
+	if inUse, err := store.RemoveReferences(ctx, "user-1", hash, 2); err != nil || !inUse {
+		t.Errorf("Expected content another user references to stay in use, got %v %v", inUse, err)
+	}
+	if inUse, err := store.RemoveReferences(ctx, "user-2", hash, 1); err != nil || inUse {
+		t.Errorf("Expected content to be unused after the last reference, got %v %v", inUse, err)
+	}
+	if inUse, _ := store.RemoveReferences(ctx, "user-1", Hash([]byte("untracked")), 1); !inUse {
+		t.Error("Expected content without references to be treated as in use")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
@@ -0,0 +1,938 @@
+// Package fakecognito emulates the parts of the Cognito user pool API the auth
+// Lambdas use, speaking the same JSON protocol as the AWS endpoint so the real
+// SDK client can be pointed at it with COGNITO_ENDPOINT. Users live in memory,
+// verification and reset codes are printed instead of emailed, and tokens are
+// JWTs signed with a key generated at start up, published at
+// /.well-known/jwks.json.
+// Tests start one with NewTestServer and point COGNITO_ENDPOINT at its URL;
+// cmd/fakecognito serves the same handler for local development.
+//
+// It follows the pool in infrastructure/cognito.tf: email usernames, the same
+// password policy, and user existence errors hidden from sign in and resets.
+package fakecognito
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode"
+)
+
+// targetPrefix starts the X-Amz-Target header of every user pool operation
+const targetPrefix = "AWSCognitoIdentityProviderService."
+
+// Token and code lifetimes, matching the pool client in cognito.tf
+const (
+	TokenValidity        = time.Hour
+	RefreshTokenValidity = 30 * 24 * time.Hour
+	CodeValidity         = 24 * time.Hour
+	ResetCodeValidity    = time.Hour
+	SessionValidity      = 3 * time.Minute
+)
+
+// maxCodesPerHour limits ResendConfirmationCode and ForgotPassword, like Cognito's per user quota
+const maxCodesPerHour = 5
+
+// emailPattern is a loose check that a username is an email address
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// user is an account in the pool
+type user struct {
+	Sub          string
+	Username     string
+	PasswordHash string
+	Attributes   map[string]string
+	Groups       []string
+	Confirmed    bool
+	Code         string
+	CodeExpires  time.Time
+	ResetCode    string
+	ResetExpires time.Time
+	CodesSent    []time.Time
+
+	// SignedOutAt is the last GlobalSignOut; older access tokens are rejected
+	SignedOutAt time.Time
+
+	// NewPasswordRequired is set for users created with a temporary password
+	NewPasswordRequired bool
+
+	// TOTPSecret is the software token secret from AssociateSoftwareToken, and
+	// TOTPVerified is set once VerifySoftwareToken accepts a code for it
+	TOTPSecret   string
+	TOTPVerified bool
+
+	// MFAEnabled asks for a SOFTWARE_TOKEN_MFA challenge at sign in
+	MFAEnabled bool
+}
+
+// authSession is a sign in waiting for its challenge to be answered
+type authSession struct {
+	Username  string
+	ClientID  string
+	Challenge string
+	Expires   time.Time
+}
+
+// refreshToken is an issued refresh token. OriginJTI is the origin_jti claim
+// of every token issued from it.
+type refreshToken struct {
+	Username  string
+	ClientID  string
+	OriginJTI string
+	Expires   time.Time
+	Revoked   bool
+}
+
+// Server is an http.Handler serving the user pool API
+type Server struct {
+	// PoolID names the pool in token issuers
+	PoolID string
+
+	// ClientID, when set, is the only app client accepted
+	ClientID string
+
+	// Output receives the verification codes that Cognito would email
+	Output io.Writer
+
+	// Groups are added to users when they sign up, by username
+	Groups map[string][]string
+
+	// URL is the endpoint of a server started by NewTestServer
+	URL string
+
+	mu       sync.Mutex
+	users    map[string]*user
+	refresh  map[string]*refreshToken
+	revoked  map[string]bool // origin_jti of revoked refresh tokens
+	sessions map[string]*authSession
+	key      *rsa.PrivateKey
+	keyID    string
+
+	// now returns the current time; tests replace it
+	now func() time.Time
+}
+
+// New returns an empty pool with a new signing key
+func New(poolID, clientID string) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %v", err)
+	}
+	return &Server{
+		PoolID:   poolID,
+		ClientID: clientID,
+		Output:   os.Stdout,
+		Groups:   make(map[string][]string),
+		users:    make(map[string]*user),
+		refresh:  make(map[string]*refreshToken),
+		revoked:  make(map[string]bool),
+		sessions: make(map[string]*authSession),
+		key:      key,
+		keyID:    randomHex(8),
+		now:      time.Now,
+	}, nil
+}
+
+// NewTestServer starts a pool for the duration of a test. Codes are discarded;
+// read them with Code. URL is ready to use as COGNITO_ENDPOINT.
+func NewTestServer(t testing.TB, poolID, clientID string) *Server {
+	t.Helper()
+	s, err := New(poolID, clientID)
+	if err != nil {
+		t.Fatalf("Failed to start fake Cognito: %v", err)
+	}
+	s.Output = io.Discard
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	s.URL = ts.URL
+	return s
+}
+
+// AddUser adds a confirmed user with a verified email, as if they had signed
+// up and entered their code, and returns their sub
+func (s *Server) AddUser(email, password string, groups ...string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	username := normalize(email)
+	u := &user{
+		Sub:          newUUID(),
+		Username:     username,
+		PasswordHash: hashPassword(password),
+		Attributes:   map[string]string{"email": email, "email_verified": "true"},
+		Groups:       append(groups, s.Groups[username]...),
+		Confirmed:    true,
+	}
+	s.users[username] = u
+	return u.Sub
+}
+
+// AddTemporaryUser adds a user as an admin would create them: confirmed, but
+// asked for a new password with a NEW_PASSWORD_REQUIRED challenge at their
+// first sign in. It returns their sub.
+func (s *Server) AddTemporaryUser(email, password string, groups ...string) string {
+	sub := s.AddUser(email, password, groups...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[normalize(email)].NewPasswordRequired = true
+	return sub
+}
+
+// EnableMFA turns on authenticator app MFA for username, as if they had
+// enrolled, and returns the TOTP secret to make codes with
+func (s *Server) EnableMFA(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[normalize(username)]
+	if !ok {
+		return ""
+	}
+	u.TOTPSecret = newTOTPSecret()
+	u.TOTPVerified = true
+	u.MFAEnabled = true
+	return u.TOTPSecret
+}
+
+// Code returns the last verification code sent to username, for tests
+func (s *Server) Code(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[normalize(username)]; ok {
+		return u.Code
+	}
+	return ""
+}
+
+// ResetCode returns the last password reset code sent to username, for tests
+func (s *Server) ResetCode(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[normalize(username)]; ok {
+		return u.ResetCode
+	}
+	return ""
+}
+
+// apiError is an error in the shape the SDK reads into an awserr.Error
+type apiError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Type + ": " + e.Message
+}
+
+func newError(errType, message string) *apiError {
+	return &apiError{Type: errType, Message: message}
+}
+
+// operation handles one user pool action; body is the decoded request
+type operation func(s *Server, r *http.Request, body []byte) (interface{}, *apiError)
+
+// operations maps the action in X-Amz-Target to its handler
+var operations = map[string]operation{
+	"SignUp":                 (*Server).signUp,
+	"ConfirmSignUp":          (*Server).confirmSignUp,
+	"ResendConfirmationCode": (*Server).resendConfirmationCode,
+	"InitiateAuth":           (*Server).initiateAuth,
+	"ForgotPassword":         (*Server).forgotPassword,
+	"ConfirmForgotPassword":  (*Server).confirmForgotPassword,
+	"RespondToAuthChallenge": (*Server).respondToAuthChallenge,
+	"RevokeToken":            (*Server).revokeToken,
+	"GlobalSignOut":          (*Server).globalSignOut,
+	"AssociateSoftwareToken": (*Server).associateSoftwareToken,
+	"VerifySoftwareToken":    (*Server).verifySoftwareToken,
+	"SetUserMFAPreference":   (*Server).setUserMFAPreference,
+}
+
+// ServeHTTP serves the JWKS and the user pool actions
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/.well-known/jwks.json") {
+		s.serveJWKS(w)
+		return
+	}
+
+	target := r.Header.Get("X-Amz-Target")
+	op, ok := operations[strings.TrimPrefix(target, targetPrefix)]
+	if r.Method != http.MethodPost || !strings.HasPrefix(target, targetPrefix) || !ok {
+		writeError(w, newError("InvalidAction", fmt.Sprintf("Unsupported action %q", target)))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, newError("SerializationException", err.Error()))
+		return
+	}
+
+	result, apiErr := op(s, r, body)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeError writes an error response the SDK's JSON protocol understands
+func writeError(w http.ResponseWriter, err *apiError) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.Header().Set("X-Amzn-ErrorType", err.Type)
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(err)
+}
+
+// decode unmarshals a request body, reporting failures as the SDK expects
+func decode(body []byte, v interface{}) *apiError {
+	if err := json.Unmarshal(body, v); err != nil {
+		return newError("SerializationException", err.Error())
+	}
+	return nil
+}
+
+// checkClient rejects app clients other than the configured one
+func (s *Server) checkClient(clientID string) *apiError {
+	if clientID == "" || s.ClientID != "" && clientID != s.ClientID {
+		return newError("ResourceNotFoundException", "User pool client "+clientID+" does not exist.")
+	}
+	return nil
+}
+
+// codeDelivery describes where a code was sent
+type codeDelivery struct {
+	AttributeName  string
+	DeliveryMedium string
+	Destination    string
+}
+
+// recentCodes counts the codes sent to u in the last hour. The caller holds s.mu.
+func (s *Server) recentCodes(u *user) int {
+	recent := 0
+	for _, sent := range u.CodesSent {
+		if s.now().Sub(sent) < time.Hour {
+			recent++
+		}
+	}
+	return recent
+}
+
+// sendCode creates a new verification code for u and prints it. The caller holds s.mu.
+func (s *Server) sendCode(u *user) codeDelivery {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1000000))
+	u.Code = fmt.Sprintf("%06d", n.Int64())
+	u.CodeExpires = s.now().Add(CodeValidity)
+	u.CodesSent = append(u.CodesSent, s.now())
+
+	fmt.Fprintf(s.Output, "Verification code for %s: %s\n", u.Username, u.Code)
+	return codeDelivery{AttributeName: "email", DeliveryMedium: "EMAIL", Destination: maskEmail(u.Username)}
+}
+
+func (s *Server) signUp(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		ClientId       string
+		Username       string
+		Password       string
+		UserAttributes []struct{ Name, Value string }
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+
+	username := normalize(input.Username)
+	if !emailPattern.MatchString(username) {
+		return nil, newError("InvalidParameterException", "Username should be an email.")
+	}
+	if err := checkPassword(input.Password); err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]string)
+	for _, attr := range input.UserAttributes {
+		attributes[attr.Name] = attr.Value
+	}
+	if attributes["email"] == "" {
+		attributes["email"] = username
+	}
+	for name, value := range attributes {
+		if len(value) > 256 {
+			return nil, newError("InvalidParameterException", "Attribute "+name+" is too long.")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[username]; exists {
+		return nil, newError("UsernameExistsException", "An account with the given email already exists.")
+	}
+	u := &user{
+		Sub:          newUUID(),
+		Username:     username,
+		PasswordHash: hashPassword(input.Password),
+		Attributes:   attributes,
+		Groups:       s.Groups[username],
+	}
+	s.users[username] = u
+
+	return map[string]interface{}{
+		"UserConfirmed":       false,
+		"UserSub":             u.Sub,
+		"CodeDeliveryDetails": s.sendCode(u),
+	}, nil
+}
+
+func (s *Server) confirmSignUp(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		ClientId         string
+		Username         string
+		ConfirmationCode string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[normalize(input.Username)]
+	switch {
+	case !ok:
+		// Hidden like a wrong code, so unknown emails cannot be discovered
+		return nil, newError("CodeMismatchException", "Invalid verification code provided, please try again.")
+	case u.Confirmed:
+		return nil, newError("NotAuthorizedException", "User cannot be confirmed. Current status is CONFIRMED")
+	case u.Code == "" || input.ConfirmationCode != u.Code:
+		return nil, newError("CodeMismatchException", "Invalid verification code provided, please try again.")
+	case s.now().After(u.CodeExpires):
+		return nil, newError("ExpiredCodeException", "Invalid code provided, please request a code again.")
+	}
+
+	u.Confirmed = true
+	u.Code = ""
+	u.Attributes["email_verified"] = "true"
+	return map[string]interface{}{}, nil
+}
+
+func (s *Server) resendConfirmationCode(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		ClientId string
+		Username string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[normalize(input.Username)]
+	if !ok {
+		return nil, newError("UserNotFoundException", "Username/client id combination not found.")
+	}
+	if u.Confirmed {
+		return nil, newError("InvalidParameterException", "User is already confirmed.")
+	}
+
+	if s.recentCodes(u) >= maxCodesPerHour {
+		return nil, newError("LimitExceededException", "Attempt limit exceeded, please try after some time.")
+	}
+
+	return map[string]interface{}{"CodeDeliveryDetails": s.sendCode(u)}, nil
+}
+
+func (s *Server) forgotPassword(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		ClientId string
+		Username string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	username := normalize(input.Username)
+	u, ok := s.users[username]
+	if !ok {
+		// prevent_user_existence_errors: pretend a code was sent
+		return map[string]interface{}{"CodeDeliveryDetails": codeDelivery{
+			AttributeName: "email", DeliveryMedium: "EMAIL", Destination: maskEmail(username),
+		}}, nil
+	}
+	if u.Attributes["email_verified"] != "true" {
+		return nil, newError("InvalidParameterException", "Cannot reset password for the user as there is no registered/verified email or phone_number")
+	}
+	if s.recentCodes(u) >= maxCodesPerHour {
+		return nil, newError("LimitExceededException", "Attempt limit exceeded, please try after some time.")
+	}
+
+	n, _ := rand.Int(rand.Reader, big.NewInt(1000000))
+	u.ResetCode = fmt.Sprintf("%06d", n.Int64())
+	u.ResetExpires = s.now().Add(ResetCodeValidity)
+	u.CodesSent = append(u.CodesSent, s.now())
+
+	fmt.Fprintf(s.Output, "Password reset code for %s: %s\n", u.Username, u.ResetCode)
+	return map[string]interface{}{"CodeDeliveryDetails": codeDelivery{
+		AttributeName: "email", DeliveryMedium: "EMAIL", Destination: maskEmail(u.Username),
+	}}, nil
+}
+
+func (s *Server) confirmForgotPassword(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		ClientId         string
+		Username         string
+		ConfirmationCode string
+		Password         string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+	if err := checkPassword(input.Password); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[normalize(input.Username)]
+	switch {
+	case !ok || u.ResetCode == "" || input.ConfirmationCode != u.ResetCode:
+		// Unknown users look like a wrong code, so they cannot be discovered
+		return nil, newError("CodeMismatchException", "Invalid verification code provided, please try again.")
+	case s.now().After(u.ResetExpires):
+		return nil, newError("ExpiredCodeException", "Invalid code provided, please request a code again.")
+	}
+
+	u.PasswordHash = hashPassword(input.Password)
+	u.ResetCode = ""
+	return map[string]interface{}{}, nil
+}
+
+func (s *Server) initiateAuth(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		AuthFlow       string
+		ClientId       string
+		AuthParameters map[string]string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch input.AuthFlow {
+	case "USER_PASSWORD_AUTH":
+		u, ok := s.users[normalize(input.AuthParameters["USERNAME"])]
+		if !ok || u.PasswordHash != hashPassword(input.AuthParameters["PASSWORD"]) {
+			// prevent_user_existence_errors: unknown users look like a wrong password
+			return nil, newError("NotAuthorizedException", "Incorrect username or password.")
+		}
+		if !u.Confirmed {
+			return nil, newError("UserNotConfirmedException", "User is not confirmed.")
+		}
+		if u.NewPasswordRequired {
+			return s.challenge(u, input.ClientId, "NEW_PASSWORD_REQUIRED"), nil
+		}
+		if u.MFAEnabled {
+			return s.challenge(u, input.ClientId, "SOFTWARE_TOKEN_MFA"), nil
+		}
+		return s.authenticationResult(r, u, input.ClientId, nil), nil
+
+	case "REFRESH_TOKEN_AUTH", "REFRESH_TOKEN":
+		token, ok := s.refresh[input.AuthParameters["REFRESH_TOKEN"]]
+		if !ok || token.ClientID != input.ClientId {
+			return nil, newError("NotAuthorizedException", "Invalid Refresh Token")
+		}
+		if token.Revoked {
+			return nil, newError("NotAuthorizedException", "Refresh Token has been revoked")
+		}
+		if s.now().After(token.Expires) {
+			return nil, newError("NotAuthorizedException", "Refresh Token has expired")
+		}
+		u, ok := s.users[token.Username]
+		if !ok {
+			return nil, newError("NotAuthorizedException", "Invalid Refresh Token")
+		}
+		return s.authenticationResult(r, u, input.ClientId, token), nil
+	}
+	return nil, newError("InvalidParameterException", "Unsupported auth flow "+input.AuthFlow)
+}
+
+// challenge starts a session for u to answer the named challenge in. The
+// caller holds s.mu.
+func (s *Server) challenge(u *user, clientID, name string) map[string]interface{} {
+	session := randomHex(32)
+	s.sessions[session] = &authSession{
+		Username:  u.Username,
+		ClientID:  clientID,
+		Challenge: name,
+		Expires:   s.now().Add(SessionValidity),
+	}
+
+	parameters := map[string]string{"USER_ID_FOR_SRP": u.Sub}
+	if name == "NEW_PASSWORD_REQUIRED" {
+		attributes, _ := json.Marshal(u.Attributes)
+		parameters["userAttributes"] = string(attributes)
+		parameters["requiredAttributes"] = "[]"
+	}
+	return map[string]interface{}{
+		"ChallengeName":       name,
+		"Session":             session,
+		"ChallengeParameters": parameters,
+	}
+}
+
+// respondToAuthChallenge answers the challenge of a sign in session. A wrong
+// answer keeps the session, so the user can try again until it expires.
+func (s *Server) respondToAuthChallenge(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		ClientId           string
+		ChallengeName      string
+		Session            string
+		ChallengeResponses map[string]string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[input.Session]
+	if !ok || session.ClientID != input.ClientId || !s.now().Before(session.Expires) {
+		return nil, newError("NotAuthorizedException", "Invalid session for the user, session is expired.")
+	}
+	if input.ChallengeName != session.Challenge {
+		return nil, newError("InvalidParameterException", "Challenge name does not match the session")
+	}
+	u, ok := s.users[normalize(input.ChallengeResponses["USERNAME"])]
+	if !ok || u.Username != session.Username {
+		return nil, newError("NotAuthorizedException", "Invalid session for the user.")
+	}
+
+	switch session.Challenge {
+	case "NEW_PASSWORD_REQUIRED":
+		password := input.ChallengeResponses["NEW_PASSWORD"]
+		if password == "" {
+			return nil, newError("InvalidParameterException", "NEW_PASSWORD is required")
+		}
+		if err := checkPassword(password); err != nil {
+			return nil, err
+		}
+		u.PasswordHash = hashPassword(password)
+		u.NewPasswordRequired = false
+
+	case "SOFTWARE_TOKEN_MFA":
+		if !checkTOTP(u.TOTPSecret, input.ChallengeResponses["SOFTWARE_TOKEN_MFA_CODE"], s.now()) {
+			return nil, newError("CodeMismatchException", "Invalid code received for user")
+		}
+	}
+
+	delete(s.sessions, input.Session)
+	if session.Challenge == "NEW_PASSWORD_REQUIRED" && u.MFAEnabled {
+		// A new password still needs the second factor
+		return s.challenge(u, input.ClientId, "SOFTWARE_TOKEN_MFA"), nil
+	}
+	return s.authenticationResult(r, u, input.ClientId, nil), nil
+}
+
+// authenticationResult issues tokens for u. A sign in, with a nil refresh,
+// also returns a new refresh token; a refresh only returns new ID and access
+// tokens from the same origin, like Cognito. The caller holds s.mu.
+func (s *Server) authenticationResult(r *http.Request, u *user, clientID string, refresh *refreshToken) map[string]interface{} {
+	result := map[string]interface{}{
+		"ExpiresIn": int(TokenValidity.Seconds()),
+		"TokenType": "Bearer",
+	}
+	if refresh == nil {
+		refresh = &refreshToken{
+			Username:  u.Username,
+			ClientID:  clientID,
+			OriginJTI: newUUID(),
+			Expires:   s.now().Add(RefreshTokenValidity),
+		}
+		token := randomHex(32)
+		s.refresh[token] = refresh
+		result["RefreshToken"] = token
+	}
+	result["AccessToken"] = s.accessToken(r, u, clientID, refresh.OriginJTI)
+	result["IdToken"] = s.idToken(r, u, clientID, refresh.OriginJTI)
+	return map[string]interface{}{
+		"AuthenticationResult": result,
+		"ChallengeParameters":  map[string]string{},
+	}
+}
+
+// userForAccessToken returns the user an access token was issued to. Expired
+// tokens and tokens signed out by RevokeToken or GlobalSignOut are refused, as
+// Cognito does once token revocation is enabled. The caller holds s.mu.
+func (s *Server) userForAccessToken(token string) (*user, *apiError) {
+	claims, ok := s.verify(token)
+	if !ok || claims["token_use"] != "access" {
+		return nil, newError("NotAuthorizedException", "Invalid Access Token")
+	}
+	if exp, _ := claims["exp"].(float64); s.now().Unix() >= int64(exp) {
+		return nil, newError("NotAuthorizedException", "Access Token has expired")
+	}
+
+	sub, _ := claims["sub"].(string)
+	origin, _ := claims["origin_jti"].(string)
+	iat, _ := claims["iat"].(float64)
+	for _, u := range s.users {
+		if u.Sub != sub {
+			continue
+		}
+		if s.revoked[origin] || int64(iat) < u.SignedOutAt.Unix() {
+			return nil, newError("NotAuthorizedException", "Access Token has been revoked")
+		}
+		return u, nil
+	}
+	return nil, newError("NotAuthorizedException", "Invalid Access Token")
+}
+
+// revokeToken revokes a refresh token and every token issued from it
+func (s *Server) revokeToken(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		ClientId string
+		Token    string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.checkClient(input.ClientId); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refresh[input.Token]
+	if !ok {
+		return nil, newError("UnsupportedTokenTypeException", "Unsupported token type")
+	}
+	if token.ClientID != input.ClientId {
+		return nil, newError("UnauthorizedException", "Token was not issued to this client")
+	}
+	token.Revoked = true
+	s.revoked[token.OriginJTI] = true
+	return map[string]interface{}{}, nil
+}
+
+// globalSignOut revokes every refresh token of the access token's user, and
+// every ID and access token issued to them so far
+func (s *Server) globalSignOut(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		AccessToken string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := s.userForAccessToken(input.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	u.SignedOutAt = s.now()
+	for _, token := range s.refresh {
+		if token.Username == u.Username {
+			token.Revoked = true
+		}
+	}
+	return map[string]interface{}{}, nil
+}
+
+// associateSoftwareToken generates a new TOTP secret for the access token's
+// user. It replaces any earlier secret, which must be verified again.
+func (s *Server) associateSoftwareToken(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		AccessToken string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := s.userForAccessToken(input.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	u.TOTPSecret = newTOTPSecret()
+	u.TOTPVerified = false
+	return map[string]interface{}{"SecretCode": u.TOTPSecret}, nil
+}
+
+// verifySoftwareToken checks a code for the secret from AssociateSoftwareToken,
+// so the secret can be enabled with SetUserMFAPreference. It is part of
+// enrolling, so a secret that is already verified is refused until a new one
+// is associated: nothing shows Cognito checks codes for enrolled apps this way.
+func (s *Server) verifySoftwareToken(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		AccessToken string
+		UserCode    string
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := s.userForAccessToken(input.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if u.TOTPSecret == "" {
+		return nil, newError("InvalidParameterException", "User has not set up software token mfa")
+	}
+	if u.TOTPVerified {
+		return nil, newError("InvalidParameterException", "Software token is already verified, associate a new one first")
+	}
+	if !checkTOTP(u.TOTPSecret, input.UserCode, s.now()) {
+		return nil, newError("EnableSoftwareTokenMFAException", "Code mismatch and fail enable Software Token MFA")
+	}
+	u.TOTPVerified = true
+	return map[string]interface{}{"Status": "SUCCESS"}, nil
+}
+
+// setUserMFAPreference turns the software token MFA of the access token's user
+// on or off. SMS MFA is not configured in the pool, so its settings are ignored.
+func (s *Server) setUserMFAPreference(r *http.Request, body []byte) (interface{}, *apiError) {
+	var input struct {
+		AccessToken              string
+		SoftwareTokenMfaSettings *struct {
+			Enabled      bool
+			PreferredMfa bool
+		}
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := s.userForAccessToken(input.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	settings := input.SoftwareTokenMfaSettings
+	if settings == nil {
+		return map[string]interface{}{}, nil
+	}
+	if (settings.Enabled || settings.PreferredMfa) && !u.TOTPVerified {
+		return nil, newError("InvalidParameterException", "User has not verified software token mfa")
+	}
+	u.MFAEnabled = settings.Enabled || settings.PreferredMfa
+	return map[string]interface{}{}, nil
+}
+
+// checkPassword applies the password policy of cognito.tf
+func checkPassword(password string) *apiError {
+	var lower, upper, digit, symbol bool
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsDigit(c):
+			digit = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c):
+			symbol = true
+		}
+	}
+
+	var problem string
+	switch {
+	case len(password) < 8:
+		problem = "Password not long enough"
+	case !lower:
+		problem = "Password must have lowercase characters"
+	case !upper:
+		problem = "Password must have uppercase characters"
+	case !digit:
+		problem = "Password must have numeric characters"
+	case !symbol:
+		problem = "Password must have symbol characters"
+	default:
+		return nil
+	}
+	return newError("InvalidPasswordException", "Password did not conform with policy: "+problem)
+}
+
+// normalize returns the pool's form of a username; emails are case insensitive
+func normalize(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// hashPassword keeps plain passwords out of memory dumps of the emulator
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskEmail hides an address the way Cognito's delivery details do, e.g. j***@e***
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" || domain == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain[:1] + "***"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func newUUID() string {
+	s := randomHex(16)
+	return s[0:8] + "-" + s[8:12] + "-4" + s[13:16] + "-" + s[16:20] + "-" + s[20:32]
+}
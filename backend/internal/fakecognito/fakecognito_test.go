@@ -0,0 +1,734 @@
+package fakecognito
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+const (
+	testPool     = "eu-west-2_local"
+	testClient   = "local-client"
+	testEmail    = "Jane@Example.com"
+	testPassword = "Passw0rd!"
+)
+
+// newTestPool starts the emulator and returns an SDK client pointed at it
+func newTestPool(t *testing.T) (*Server, *cognitoidentityprovider.CognitoIdentityProvider) {
+	t.Helper()
+	s := NewTestServer(t, testPool, testClient)
+	s.Output = &bytes.Buffer{}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:   aws.String("eu-west-2"),
+		Endpoint: aws.String(s.URL),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	return s, cognitoidentityprovider.New(sess)
+}
+
+func signUp(client *cognitoidentityprovider.CognitoIdentityProvider, email, password string) (*cognitoidentityprovider.SignUpOutput, error) {
+	return client.SignUp(&cognitoidentityprovider.SignUpInput{
+		ClientId: aws.String(testClient),
+		Username: aws.String(email),
+		Password: aws.String(password),
+		UserAttributes: []*cognitoidentityprovider.AttributeType{
+			{Name: aws.String("email"), Value: aws.String(email)},
+			{Name: aws.String("name"), Value: aws.String("Jane")},
+		},
+	})
+}
+
+func login(client *cognitoidentityprovider.CognitoIdentityProvider, email, password string) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	return client.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId: aws.String(testClient),
+		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String(email),
+			"PASSWORD": aws.String(password),
+		},
+	})
+}
+
+func refresh(client *cognitoidentityprovider.CognitoIdentityProvider, token string) error {
+	_, err := client.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId:       aws.String(testClient),
+		AuthFlow:       aws.String("REFRESH_TOKEN_AUTH"),
+		AuthParameters: map[string]*string{"REFRESH_TOKEN": aws.String(token)},
+	})
+	return err
+}
+
+func errorCode(err error) string {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code()
+	}
+	return ""
+}
+
+// verifyToken checks a JWT's signature against the published JWKS and returns its claims
+func verifyToken(t *testing.T, jwksURL, token string) map[string]interface{} {
+	t.Helper()
+	resp, err := http.Get(jwksURL)
+	if err != nil {
+		t.Fatalf("Failed to fetch JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+	var jwks struct {
+		Keys []struct{ Kid, N, E string }
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil || len(jwks.Keys) != 1 {
+		t.Fatalf("Unexpected JWKS: %v %+v", err, jwks)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[0].N)
+	e, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[0].E)
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a JWT, got %q", token)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("Token signature does not verify: %v", err)
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("Failed to parse claims: %v", err)
+	}
+	return claims
+}
+
+func TestServer_AuthJourney(t *testing.T) {
+	s, client := newTestPool(t)
+	s.Groups["jane@example.com"] = []string{"admin"}
+
+	signUpOut, err := signUp(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+	if aws.BoolValue(signUpOut.UserConfirmed) || aws.StringValue(signUpOut.UserSub) == "" {
+		t.Errorf("Unexpected sign up result %+v", signUpOut)
+	}
+	if got := aws.StringValue(signUpOut.CodeDeliveryDetails.Destination); got != "j***@e***" {
+		t.Errorf("Expected a masked destination, got %q", got)
+	}
+
+	code := s.Code(testEmail)
+	if !strings.Contains(s.Output.(*bytes.Buffer).String(), "Verification code for jane@example.com: "+code) {
+		t.Errorf("Expected the code to be printed, got %q", s.Output)
+	}
+
+	if _, err := login(client, testEmail, testPassword); errorCode(err) != "UserNotConfirmedException" {
+		t.Errorf("Expected UserNotConfirmedException before confirming, got %v", err)
+	}
+
+	// A resent code replaces the first one
+	if _, err := client.ResendConfirmationCode(&cognitoidentityprovider.ResendConfirmationCodeInput{
+		ClientId: aws.String(testClient),
+		Username: aws.String(testEmail),
+	}); err != nil {
+		t.Fatalf("ResendConfirmationCode returned error: %v", err)
+	}
+	code = s.Code(testEmail)
+
+	if _, err := client.ConfirmSignUp(&cognitoidentityprovider.ConfirmSignUpInput{
+		ClientId:         aws.String(testClient),
+		Username:         aws.String(testEmail),
+		ConfirmationCode: aws.String(code),
+	}); err != nil {
+		t.Fatalf("ConfirmSignUp returned error: %v", err)
+	}
+
+	authOut, err := login(client, "jane@example.com", testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	result := authOut.AuthenticationResult
+	if aws.StringValue(result.TokenType) != "Bearer" || aws.Int64Value(result.ExpiresIn) != 3600 || aws.StringValue(result.RefreshToken) == "" {
+		t.Errorf("Unexpected authentication result %+v", result)
+	}
+
+	claims := verifyToken(t, s.URL+"/"+testPool+"/.well-known/jwks.json", aws.StringValue(result.IdToken))
+	if claims["sub"] != aws.StringValue(signUpOut.UserSub) || claims["email"] != testEmail || claims["name"] != "Jane" {
+		t.Errorf("Unexpected ID token claims %v", claims)
+	}
+	if claims["aud"] != testClient || claims["token_use"] != "id" || claims["email_verified"] != true || claims["iss"] != s.URL+"/"+testPool {
+		t.Errorf("Unexpected ID token claims %v", claims)
+	}
+	if groups, _ := claims["cognito:groups"].([]interface{}); len(groups) != 1 || groups[0] != "admin" {
+		t.Errorf("Expected the admin group, got %v", claims["cognito:groups"])
+	}
+
+	refreshOut, err := client.InitiateAuth(&cognitoidentityprovider.InitiateAuthInput{
+		ClientId:       aws.String(testClient),
+		AuthFlow:       aws.String("REFRESH_TOKEN_AUTH"),
+		AuthParameters: map[string]*string{"REFRESH_TOKEN": result.RefreshToken},
+	})
+	if err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if refreshOut.AuthenticationResult.RefreshToken != nil || aws.StringValue(refreshOut.AuthenticationResult.AccessToken) == "" {
+		t.Errorf("Expected new tokens without a refresh token, got %+v", refreshOut.AuthenticationResult)
+	}
+}
+
+func TestServer_SignUpErrors(t *testing.T) {
+	_, client := newTestPool(t)
+
+	for password, problem := range map[string]string{
+		"Sh0rt!":    "not long enough",
+		"PASSW0RD!": "lowercase",
+		"passw0rd!": "uppercase",
+		"Password!": "numeric",
+		"Passw0rdd": "symbol",
+	} {
+		_, err := signUp(client, testEmail, password)
+		if errorCode(err) != "InvalidPasswordException" || !strings.Contains(err.Error(), problem) {
+			t.Errorf("%s: expected InvalidPasswordException about %s, got %v", password, problem, err)
+		}
+	}
+
+	if _, err := signUp(client, "not-an-email", testPassword); errorCode(err) != "InvalidParameterException" {
+		t.Errorf("Expected InvalidParameterException for a bad email, got %v", err)
+	}
+
+	if _, err := signUp(client, testEmail, testPassword); err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+	if _, err := signUp(client, "jane@example.com", testPassword); errorCode(err) != "UsernameExistsException" {
+		t.Errorf("Expected UsernameExistsException, got %v", err)
+	}
+
+	_, err := client.SignUp(&cognitoidentityprovider.SignUpInput{
+		ClientId: aws.String("other-client"),
+		Username: aws.String("other@example.com"),
+		Password: aws.String(testPassword),
+	})
+	if errorCode(err) != "ResourceNotFoundException" {
+		t.Errorf("Expected ResourceNotFoundException for another client, got %v", err)
+	}
+}
+
+func TestServer_ConfirmErrors(t *testing.T) {
+	s, client := newTestPool(t)
+	if _, err := signUp(client, testEmail, testPassword); err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+	confirm := func(email, code string) error {
+		_, err := client.ConfirmSignUp(&cognitoidentityprovider.ConfirmSignUpInput{
+			ClientId:         aws.String(testClient),
+			Username:         aws.String(email),
+			ConfirmationCode: aws.String(code),
+		})
+		return err
+	}
+
+	if err := confirm(testEmail, "000000x"); errorCode(err) != "CodeMismatchException" {
+		t.Errorf("Expected CodeMismatchException for a wrong code, got %v", err)
+	}
+	if err := confirm("nobody@example.com", "123456"); errorCode(err) != "CodeMismatchException" {
+		t.Errorf("Expected CodeMismatchException for an unknown user, got %v", err)
+	}
+
+	later := time.Now().Add(CodeValidity + time.Minute)
+	s.now = func() time.Time { return later }
+	if err := confirm(testEmail, s.Code(testEmail)); errorCode(err) != "ExpiredCodeException" {
+		t.Errorf("Expected ExpiredCodeException, got %v", err)
+	}
+	s.now = time.Now
+
+	resend := func() error {
+		_, err := client.ResendConfirmationCode(&cognitoidentityprovider.ResendConfirmationCodeInput{
+			ClientId: aws.String(testClient),
+			Username: aws.String(testEmail),
+		})
+		return err
+	}
+	for i := 1; i < maxCodesPerHour; i++ {
+		if err := resend(); err != nil {
+			t.Fatalf("ResendConfirmationCode %d returned error: %v", i, err)
+		}
+	}
+	if err := resend(); errorCode(err) != "LimitExceededException" {
+		t.Errorf("Expected LimitExceededException, got %v", err)
+	}
+
+	if err := confirm(testEmail, s.Code(testEmail)); err != nil {
+		t.Fatalf("ConfirmSignUp returned error: %v", err)
+	}
+	if err := confirm(testEmail, "123456"); errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected NotAuthorizedException once confirmed, got %v", err)
+	}
+}
+
+func TestServer_LoginHidesUnknownUsers(t *testing.T) {
+	_, client := newTestPool(t)
+
+	_, unknownErr := login(client, "nobody@example.com", testPassword)
+	if errorCode(unknownErr) != "NotAuthorizedException" {
+		t.Errorf("Expected NotAuthorizedException for an unknown user, got %v", unknownErr)
+	}
+
+	if _, err := signUp(client, testEmail, testPassword); err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+	_, wrongErr := login(client, testEmail, "Wrong0ne!")
+	if errorCode(wrongErr) != "NotAuthorizedException" || wrongErr.(awserr.Error).Message() != unknownErr.(awserr.Error).Message() {
+		t.Errorf("Expected the same error for a wrong password, got %v", wrongErr)
+	}
+}
+
+func TestServer_RejectsUnknownActions(t *testing.T) {
+	s, _ := newTestPool(t)
+
+	req, _ := http.NewRequest("POST", s.URL, strings.NewReader(`{}`))
+	req.Header.Set("X-Amz-Target", targetPrefix+"AdminDeleteUser")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 400 || resp.Header.Get("X-Amzn-ErrorType") != "InvalidAction" {
+		t.Errorf("Expected an InvalidAction error, got %d %s", resp.StatusCode, resp.Header.Get("X-Amzn-ErrorType"))
+	}
+}
+
+func TestServer_AddUser(t *testing.T) {
+	s, client := newTestPool(t)
+	sub := s.AddUser(testEmail, testPassword, "admin")
+
+	authOut, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	claims := verifyToken(t, s.URL+"/"+testPool+"/.well-known/jwks.json", aws.StringValue(authOut.AuthenticationResult.IdToken))
+	if claims["sub"] != sub || claims["email_verified"] != true {
+		t.Errorf("Unexpected ID token claims %v", claims)
+	}
+}
+
+func TestServer_ForgotPassword(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddUser(testEmail, testPassword)
+
+	forgot := func(email string) error {
+		_, err := client.ForgotPassword(&cognitoidentityprovider.ForgotPasswordInput{
+			ClientId: aws.String(testClient),
+			Username: aws.String(email),
+		})
+		return err
+	}
+	reset := func(email, code, password string) error {
+		_, err := client.ConfirmForgotPassword(&cognitoidentityprovider.ConfirmForgotPasswordInput{
+			ClientId:         aws.String(testClient),
+			Username:         aws.String(email),
+			ConfirmationCode: aws.String(code),
+			Password:         aws.String(password),
+		})
+		return err
+	}
+
+	if err := forgot("nobody@example.com"); err != nil {
+		t.Errorf("Expected unknown users to look like real ones, got %v", err)
+	}
+	if err := reset("nobody@example.com", "123456", "N3wPassw0rd!"); errorCode(err) != "CodeMismatchException" {
+		t.Errorf("Expected CodeMismatchException for an unknown user, got %v", err)
+	}
+
+	if err := forgot(testEmail); err != nil {
+		t.Fatalf("ForgotPassword returned error: %v", err)
+	}
+	code := s.ResetCode(testEmail)
+	if !strings.Contains(s.Output.(*bytes.Buffer).String(), "Password reset code for jane@example.com: "+code) {
+		t.Errorf("Expected the reset code to be printed, got %q", s.Output)
+	}
+
+	if err := reset(testEmail, code, "weak"); errorCode(err) != "InvalidPasswordException" {
+		t.Errorf("Expected InvalidPasswordException, got %v", err)
+	}
+	if err := reset(testEmail, "000000x", "N3wPassw0rd!"); errorCode(err) != "CodeMismatchException" {
+		t.Errorf("Expected CodeMismatchException for a wrong code, got %v", err)
+	}
+	if err := reset(testEmail, code, "N3wPassw0rd!"); err != nil {
+		t.Fatalf("ConfirmForgotPassword returned error: %v", err)
+	}
+
+	if _, err := login(client, testEmail, testPassword); errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected the old password to be refused, got %v", err)
+	}
+	if _, err := login(client, testEmail, "N3wPassw0rd!"); err != nil {
+		t.Errorf("Expected the new password to work, got %v", err)
+	}
+	if err := reset(testEmail, code, "0therPassw0rd!"); errorCode(err) != "CodeMismatchException" {
+		t.Errorf("Expected a used code to be refused, got %v", err)
+	}
+}
+
+func TestServer_ForgotPasswordNeedsVerifiedEmail(t *testing.T) {
+	s, client := newTestPool(t)
+	if _, err := signUp(client, testEmail, testPassword); err != nil {
+		t.Fatalf("SignUp returned error: %v", err)
+	}
+
+	_, err := client.ForgotPassword(&cognitoidentityprovider.ForgotPasswordInput{
+		ClientId: aws.String(testClient),
+		Username: aws.String(testEmail),
+	})
+	if errorCode(err) != "InvalidParameterException" || s.ResetCode(testEmail) != "" {
+		t.Errorf("Expected InvalidParameterException for an unverified email, got %v", err)
+	}
+}
+
+func TestServer_RefreshErrors(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddUser(testEmail, testPassword)
+	authOut, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+
+	if err := refresh(client, "not-a-token"); errorCode(err) != "NotAuthorizedException" || !strings.Contains(err.Error(), "Invalid Refresh Token") {
+		t.Errorf("Expected an invalid token error, got %v", err)
+	}
+
+	later := time.Now().Add(RefreshTokenValidity + time.Minute)
+	s.now = func() time.Time { return later }
+	if err := refresh(client, aws.StringValue(authOut.AuthenticationResult.RefreshToken)); errorCode(err) != "NotAuthorizedException" || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected an expired token error, got %v", err)
+	}
+}
+
+func TestServer_RevokeToken(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddUser(testEmail, testPassword)
+	first, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	second, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+
+	jwks := s.URL + "/" + testPool + "/.well-known/jwks.json"
+	firstOrigin := verifyToken(t, jwks, aws.StringValue(first.AuthenticationResult.IdToken))["origin_jti"]
+	secondOrigin := verifyToken(t, jwks, aws.StringValue(second.AuthenticationResult.IdToken))["origin_jti"]
+	if firstOrigin == "" || firstOrigin == secondOrigin {
+		t.Errorf("Expected each sign in to have its own origin_jti, got %v and %v", firstOrigin, secondOrigin)
+	}
+
+	_, err = client.RevokeToken(&cognitoidentityprovider.RevokeTokenInput{
+		ClientId: aws.String(testClient),
+		Token:    first.AuthenticationResult.RefreshToken,
+	})
+	if err != nil {
+		t.Fatalf("RevokeToken returned error: %v", err)
+	}
+
+	if err := refresh(client, aws.StringValue(first.AuthenticationResult.RefreshToken)); errorCode(err) != "NotAuthorizedException" || !strings.Contains(err.Error(), "revoked") {
+		t.Errorf("Expected a revoked token error, got %v", err)
+	}
+	_, err = client.GlobalSignOut(&cognitoidentityprovider.GlobalSignOutInput{AccessToken: first.AuthenticationResult.AccessToken})
+	if errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected the revoked session's access token to be refused, got %v", err)
+	}
+	if err := refresh(client, aws.StringValue(second.AuthenticationResult.RefreshToken)); err != nil {
+		t.Errorf("Expected the other session to stay signed in, got %v", err)
+	}
+
+	_, err = client.RevokeToken(&cognitoidentityprovider.RevokeTokenInput{
+		ClientId: aws.String(testClient),
+		Token:    aws.String("not-a-token"),
+	})
+	if errorCode(err) != "UnsupportedTokenTypeException" {
+		t.Errorf("Expected UnsupportedTokenTypeException, got %v", err)
+	}
+}
+
+func TestServer_GlobalSignOut(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddUser(testEmail, testPassword)
+	first, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	second, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+
+	// Sign out a second later, so the tokens above count as issued before it
+	later := time.Now().Add(time.Second)
+	s.now = func() time.Time { return later }
+
+	_, err = client.GlobalSignOut(&cognitoidentityprovider.GlobalSignOutInput{AccessToken: second.AuthenticationResult.AccessToken})
+	if err != nil {
+		t.Fatalf("GlobalSignOut returned error: %v", err)
+	}
+
+	for _, out := range []*cognitoidentityprovider.InitiateAuthOutput{first, second} {
+		if err := refresh(client, aws.StringValue(out.AuthenticationResult.RefreshToken)); errorCode(err) != "NotAuthorizedException" || !strings.Contains(err.Error(), "revoked") {
+			t.Errorf("Expected every refresh token to be revoked, got %v", err)
+		}
+	}
+	_, err = client.GlobalSignOut(&cognitoidentityprovider.GlobalSignOutInput{AccessToken: first.AuthenticationResult.AccessToken})
+	if errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected earlier access tokens to be refused, got %v", err)
+	}
+
+	// Signing in again starts a new session
+	third, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	if err := refresh(client, aws.StringValue(third.AuthenticationResult.RefreshToken)); err != nil {
+		t.Errorf("Expected a new sign in to work, got %v", err)
+	}
+
+	_, err = client.GlobalSignOut(&cognitoidentityprovider.GlobalSignOutInput{AccessToken: third.AuthenticationResult.IdToken})
+	if errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected an ID token to be refused, got %v", err)
+	}
+}
+
+func TestServer_NewPasswordChallenge(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddTemporaryUser(testEmail, testPassword)
+
+	authOut, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	if authOut.AuthenticationResult != nil || aws.StringValue(authOut.ChallengeName) != "NEW_PASSWORD_REQUIRED" || aws.StringValue(authOut.Session) == "" {
+		t.Fatalf("Expected a NEW_PASSWORD_REQUIRED challenge, got %v", authOut)
+	}
+
+	respond := func(session, password string) (*cognitoidentityprovider.RespondToAuthChallengeOutput, error) {
+		return client.RespondToAuthChallenge(&cognitoidentityprovider.RespondToAuthChallengeInput{
+			ClientId:      aws.String(testClient),
+			ChallengeName: aws.String("NEW_PASSWORD_REQUIRED"),
+			Session:       aws.String(session),
+			ChallengeResponses: map[string]*string{
+				"USERNAME":     aws.String(testEmail),
+				"NEW_PASSWORD": aws.String(password),
+			},
+		})
+	}
+
+	if _, err := respond(aws.StringValue(authOut.Session), "weak"); errorCode(err) != "InvalidPasswordException" {
+		t.Errorf("Expected InvalidPasswordException, got %v", err)
+	}
+	if _, err := respond(strings.Repeat("0", 64), "N3wPassw0rd!"); errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected NotAuthorizedException for an unknown session, got %v", err)
+	}
+
+	// A rejected password keeps the session for another try
+	out, err := respond(aws.StringValue(authOut.Session), "N3wPassw0rd!")
+	if err != nil {
+		t.Fatalf("RespondToAuthChallenge returned error: %v", err)
+	}
+	if out.AuthenticationResult == nil || aws.StringValue(out.AuthenticationResult.RefreshToken) == "" {
+		t.Errorf("Expected tokens after setting a new password, got %v", out)
+	}
+
+	if _, err := respond(aws.StringValue(authOut.Session), "N3wPassw0rd!"); errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected a used session to be refused, got %v", err)
+	}
+	if _, err := login(client, testEmail, testPassword); errorCode(err) != "NotAuthorizedException" {
+		t.Errorf("Expected the temporary password to stop working, got %v", err)
+	}
+	if out, err := login(client, testEmail, "N3wPassw0rd!"); err != nil || out.AuthenticationResult == nil {
+		t.Errorf("Expected sign in with the new password, got %v, %v", out, err)
+	}
+}
+
+func TestServer_ChallengeSessionExpires(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddTemporaryUser(testEmail, testPassword)
+	authOut, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+
+	later := time.Now().Add(SessionValidity + time.Second)
+	s.now = func() time.Time { return later }
+
+	_, err = client.RespondToAuthChallenge(&cognitoidentityprovider.RespondToAuthChallengeInput{
+		ClientId:      aws.String(testClient),
+		ChallengeName: aws.String("NEW_PASSWORD_REQUIRED"),
+		Session:       authOut.Session,
+		ChallengeResponses: map[string]*string{
+			"USERNAME":     aws.String(testEmail),
+			"NEW_PASSWORD": aws.String("N3wPassw0rd!"),
+		},
+	})
+	if errorCode(err) != "NotAuthorizedException" || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected an expired session error, got %v", err)
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 test vector for SHA1, secret "12345678901234567890"
+	secret := secretEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	if code := TOTP(secret, time.Unix(59, 0)); code != "287082" {
+		t.Errorf("Expected 287082, got %s", code)
+	}
+	if code := TOTP(secret, time.Unix(1111111109, 0)); code != "081804" {
+		t.Errorf("Expected 081804, got %s", code)
+	}
+	if code := TOTP("not base32!", time.Now()); code != "" {
+		t.Errorf("Expected no code for an invalid secret, got %s", code)
+	}
+}
+
+func TestServer_SoftwareTokenMFA(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddUser(testEmail, testPassword)
+	authOut, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	accessToken := authOut.AuthenticationResult.AccessToken
+
+	enable := func() error {
+		_, err := client.SetUserMFAPreference(&cognitoidentityprovider.SetUserMFAPreferenceInput{
+			AccessToken: accessToken,
+			SoftwareTokenMfaSettings: &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+				Enabled:      aws.Bool(true),
+				PreferredMfa: aws.Bool(true),
+			},
+		})
+		return err
+	}
+
+	if err := enable(); errorCode(err) != "InvalidParameterException" {
+		t.Errorf("Expected MFA to need a verified token, got %v", err)
+	}
+
+	associateOut, err := client.AssociateSoftwareToken(&cognitoidentityprovider.AssociateSoftwareTokenInput{AccessToken: accessToken})
+	if err != nil {
+		t.Fatalf("AssociateSoftwareToken returned error: %v", err)
+	}
+	secret := aws.StringValue(associateOut.SecretCode)
+
+	verify := func(code string) error {
+		_, err := client.VerifySoftwareToken(&cognitoidentityprovider.VerifySoftwareTokenInput{
+			AccessToken: accessToken,
+			UserCode:    aws.String(code),
+		})
+		return err
+	}
+	if err := verify("000000"); errorCode(err) != "EnableSoftwareTokenMFAException" {
+		t.Errorf("Expected EnableSoftwareTokenMFAException for a wrong code, got %v", err)
+	}
+	if err := verify(TOTP(secret, time.Now())); err != nil {
+		t.Fatalf("VerifySoftwareToken returned error: %v", err)
+	}
+	if err := verify(TOTP(secret, time.Now())); errorCode(err) != "InvalidParameterException" {
+		t.Errorf("Expected a verified token not to be verified again, got %v", err)
+	}
+	if err := enable(); err != nil {
+		t.Fatalf("SetUserMFAPreference returned error: %v", err)
+	}
+
+	authOut, err = login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	if authOut.AuthenticationResult != nil || aws.StringValue(authOut.ChallengeName) != "SOFTWARE_TOKEN_MFA" {
+		t.Fatalf("Expected a SOFTWARE_TOKEN_MFA challenge, got %v", authOut)
+	}
+
+	respond := func(code string) (*cognitoidentityprovider.RespondToAuthChallengeOutput, error) {
+		return client.RespondToAuthChallenge(&cognitoidentityprovider.RespondToAuthChallengeInput{
+			ClientId:      aws.String(testClient),
+			ChallengeName: aws.String("SOFTWARE_TOKEN_MFA"),
+			Session:       authOut.Session,
+			ChallengeResponses: map[string]*string{
+				"USERNAME":                aws.String(testEmail),
+				"SOFTWARE_TOKEN_MFA_CODE": aws.String(code),
+			},
+		})
+	}
+	if _, err := respond("000000"); errorCode(err) != "CodeMismatchException" {
+		t.Errorf("Expected CodeMismatchException, got %v", err)
+	}
+	out, err := respond(TOTP(secret, time.Now()))
+	if err != nil || out.AuthenticationResult == nil {
+		t.Fatalf("Expected tokens for the right code, got %v, %v", out, err)
+	}
+
+	// Disabling MFA signs in with the password alone again
+	_, err = client.SetUserMFAPreference(&cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken: out.AuthenticationResult.AccessToken,
+		SoftwareTokenMfaSettings: &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+			Enabled:      aws.Bool(false),
+			PreferredMfa: aws.Bool(false),
+		},
+	})
+	if err != nil {
+		t.Fatalf("SetUserMFAPreference returned error: %v", err)
+	}
+	if out, err := login(client, testEmail, testPassword); err != nil || out.AuthenticationResult == nil {
+		t.Errorf("Expected sign in without MFA, got %v, %v", out, err)
+	}
+}
+
+func TestServer_NewPasswordThenMFA(t *testing.T) {
+	s, client := newTestPool(t)
+	s.AddTemporaryUser(testEmail, testPassword)
+	secret := s.EnableMFA(testEmail)
+
+	authOut, err := login(client, testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("InitiateAuth returned error: %v", err)
+	}
+	out, err := client.RespondToAuthChallenge(&cognitoidentityprovider.RespondToAuthChallengeInput{
+		ClientId:      aws.String(testClient),
+		ChallengeName: aws.String("NEW_PASSWORD_REQUIRED"),
+		Session:       authOut.Session,
+		ChallengeResponses: map[string]*string{
+			"USERNAME":     aws.String(testEmail),
+			"NEW_PASSWORD": aws.String("N3wPassw0rd!"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("RespondToAuthChallenge returned error: %v", err)
+	}
+	if out.AuthenticationResult != nil || aws.StringValue(out.ChallengeName) != "SOFTWARE_TOKEN_MFA" {
+		t.Fatalf("Expected a SOFTWARE_TOKEN_MFA challenge after the new password, got %v", out)
+	}
+
+	out, err = client.RespondToAuthChallenge(&cognitoidentityprovider.RespondToAuthChallengeInput{
+		ClientId:      aws.String(testClient),
+		ChallengeName: aws.String("SOFTWARE_TOKEN_MFA"),
+		Session:       out.Session,
+		ChallengeResponses: map[string]*string{
+			"USERNAME":                aws.String(testEmail),
+			"SOFTWARE_TOKEN_MFA_CODE": aws.String(TOTP(secret, time.Now())),
+		},
+	})
+	if err != nil || out.AuthenticationResult == nil {
+		t.Errorf("Expected tokens after both challenges, got %v, %v", out, err)
+	}
+}
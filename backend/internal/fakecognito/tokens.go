@@ -0,0 +1,131 @@
+package fakecognito
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// issuer returns the token issuer for a request, in Cognito's
+// https://cognito-idp.<region>.amazonaws.com/<pool> form but on this server
+func (s *Server) issuer(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/" + s.PoolID
+}
+
+// idToken returns an ID token with the user's attributes and groups, which is
+// what API Gateway's Cognito authorizer passes on as claims
+func (s *Server) idToken(r *http.Request, u *user, clientID, originJTI string) string {
+	now := s.now()
+	claims := map[string]interface{}{
+		"sub":              u.Sub,
+		"aud":              clientID,
+		"iss":              s.issuer(r),
+		"token_use":        "id",
+		"cognito:username": u.Sub,
+		"auth_time":        now.Unix(),
+		"iat":              now.Unix(),
+		"exp":              now.Add(TokenValidity).Unix(),
+		"jti":              newUUID(),
+		"origin_jti":       originJTI,
+		"email_verified":   u.Attributes["email_verified"] == "true",
+	}
+	for name, value := range u.Attributes {
+		if name != "email_verified" {
+			claims[name] = value
+		}
+	}
+	if len(u.Groups) > 0 {
+		claims["cognito:groups"] = u.Groups
+	}
+	return s.sign(claims)
+}
+
+// accessToken returns an access token for the user
+func (s *Server) accessToken(r *http.Request, u *user, clientID, originJTI string) string {
+	now := s.now()
+	claims := map[string]interface{}{
+		"sub":        u.Sub,
+		"client_id":  clientID,
+		"iss":        s.issuer(r),
+		"token_use":  "access",
+		"scope":      "aws.cognito.signin.user.admin",
+		"username":   u.Sub,
+		"auth_time":  now.Unix(),
+		"iat":        now.Unix(),
+		"exp":        now.Add(TokenValidity).Unix(),
+		"jti":        newUUID(),
+		"origin_jti": originJTI,
+	}
+	if len(u.Groups) > 0 {
+		claims["cognito:groups"] = u.Groups
+	}
+	return s.sign(claims)
+}
+
+// sign returns claims as a JWT signed with RS256, like Cognito's tokens
+func (s *Server) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": s.keyID, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		// Only fails for keys too small for SHA-256, which New never creates
+		panic(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// verify returns the claims of a token signed by this server, or false when it
+// is malformed or the signature does not match
+func (s *Server) verify(token string) (map[string]interface{}, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, false
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(&s.key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+		return nil, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, false
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// serveJWKS publishes the public signing key
+func (s *Server) serveJWKS(w http.ResponseWriter) {
+	pub := s.key.PublicKey
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": s.keyID,
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
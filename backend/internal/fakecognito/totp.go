@@ -0,0 +1,57 @@
+package fakecognito
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// totpStep is the period of a TOTP code, as authenticator apps use
+const totpStep = 30 * time.Second
+
+// secretEncoding is the unpadded base32 of TOTP secrets in otpauth URIs
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newTOTPSecret returns a random base32 secret, as AssociateSoftwareToken does
+func newTOTPSecret() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return secretEncoding.EncodeToString(b)
+}
+
+// TOTP returns the 6-digit code of secret at t (RFC 6238), as an
+// authenticator app would show it. Tests use it to answer MFA challenges.
+func TOTP(secret string, t time.Time) string {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return ""
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(totpStep.Seconds())))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", code%1000000)
+}
+
+// checkTOTP reports whether code is the code of secret at now, or of the step
+// either side of it to allow for clock drift
+func checkTOTP(secret, code string, now time.Time) bool {
+	if secret == "" || code == "" {
+		return false
+	}
+	for _, drift := range []time.Duration{0, -totpStep, totpStep} {
+		if hmac.Equal([]byte(TOTP(secret, now.Add(drift))), []byte(code)) {
+			return true
+		}
+	}
+	return false
+}
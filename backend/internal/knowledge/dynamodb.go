@@ -0,0 +1,139 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DynamoStore is a Store backed by a DynamoDB table with the string hash key ID.
+// The knowledge base is small, so List reads the whole table with a scan.
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{
+		client: client,
+		table:  table,
+		now:    time.Now,
+	}
+}
+
+// List returns every entry ordered by ID
+func (s *DynamoStore) List(ctx context.Context) ([]Entry, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	err := s.client.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(s.table),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge entries: %v", err)
+	}
+
+	entries := []Entry{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal knowledge entries: %v", err)
+	}
+	for i := range entries {
+		entries[i] = normalize(entries[i])
+	}
+	sortByID(entries)
+
+	return entries, nil
+}
+
+// Get returns a single entry or ErrNotFound
+func (s *DynamoStore) Get(ctx context.Context, id string) (*Entry, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"ID": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get knowledge entry: %v", err)
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var entry Entry
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal knowledge entry: %v", err)
+	}
+
+	entry = normalize(entry)
+	return &entry, nil
+}
+
+// Create adds a new entry, deriving its ID from the title when empty
+func (s *DynamoStore) Create(ctx context.Context, entry Entry) (*Entry, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	entry = normalize(entry)
+	entry.UpdatedAt = s.now().UTC()
+	if err := s.put(ctx, entry, "attribute_not_exists(ID)"); err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrExists
+		}
+		return nil, fmt.Errorf("failed to create knowledge entry: %v", err)
+	}
+
+	return &entry, nil
+}
+
+// Update replaces an existing entry or returns ErrNotFound
+func (s *DynamoStore) Update(ctx context.Context, entry Entry) (*Entry, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	entry = normalize(entry)
+	entry.UpdatedAt = s.now().UTC()
+	if err := s.put(ctx, entry, "attribute_exists(ID)"); err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update knowledge entry: %v", err)
+	}
+
+	return &entry, nil
+}
+
+// put writes entry, failing if condition does not hold
+func (s *DynamoStore) put(ctx context.Context, entry Entry, condition string) error {
+	item, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                item,
+		ConditionExpression: aws.String(condition),
+	})
+	return err
+}
+
+// isConditionFailed reports whether err is a failed DynamoDB condition check
+func isConditionFailed(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Package knowledge manages the team knowledge base: short, owned facts that the
+// chat handler adds to the system prompt when they are relevant to a question.
+package knowledge
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/config"
+)
+
+var (
+	// ErrNotFound is returned when an entry does not exist
+	ErrNotFound = errors.New("knowledge entry not found")
+
+	// ErrExists is returned when creating an entry whose ID is already taken
+	ErrExists = errors.New("knowledge entry already exists")
+)
+
+// defaults holds the entries shipped with the backend, used when no table is configured
+//
+//go:embed defaults/*.md
+var defaults embed.FS
+
+// Entry is a single fact in the knowledge base
+type Entry struct {
+	ID        string    `json:"id" dynamodbav:"ID"`
+	Title     string    `json:"title" dynamodbav:"Title"`
+	Body      string    `json:"body" dynamodbav:"Body"`
+	Tags      []string  `json:"tags" dynamodbav:"Tags"`
+	Owner     string    `json:"owner,omitempty" dynamodbav:"Owner"`
+	Links     []string  `json:"links" dynamodbav:"Links"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"UpdatedAt"`
+	UpdatedBy string    `json:"updated_by,omitempty" dynamodbav:"UpdatedBy"`
+}
+
+// Validate checks that the entry has the fields every entry needs
+func (e *Entry) Validate() error {
+	if strings.TrimSpace(e.Title) == "" || strings.TrimSpace(e.Body) == "" {
+		return fmt.Errorf("title and body are required")
+	}
+	return nil
+}
+
+// Store holds knowledge base entries
+type Store interface {
+	// List returns every entry
+	List(ctx context.Context) ([]Entry, error)
+
+	// Get returns a single entry or ErrNotFound
+	Get(ctx context.Context, id string) (*Entry, error)
+
+	// Create adds a new entry, deriving its ID from the title when empty
+	Create(ctx context.Context, entry Entry) (*Entry, error)
+
+	// Update replaces an existing entry or returns ErrNotFound
+	Update(ctx context.Context, entry Entry) (*Entry, error)
+}
+
+// NewStore creates the store for cfg. With a table configured entries live in
+// DynamoDB; otherwise they are loaded into memory from cfg.KnowledgeDir, or from
+// the defaults shipped with the backend.
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.KnowledgeTable != "" {
+		sess, err := session.NewSession(&aws.Config{
+			Region: aws.String(cfg.AWSRegion),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AWS session: %v", err)
+		}
+		return NewDynamoStore(dynamodb.New(sess), cfg.KnowledgeTable), nil
+	}
+
+	var fsys fs.FS
+	if cfg.KnowledgeDir != "" {
+		fsys = os.DirFS(cfg.KnowledgeDir)
+	} else {
+		sub, err := fs.Sub(defaults, "defaults")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	}
+
+	entries, err := LoadDir(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMemoryStore(entries...), nil
+}
+
+// Slugify turns a title into an entry ID, e.g. "USL 502 errors" -> "usl-502-errors"
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
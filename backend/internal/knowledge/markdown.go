@@ -0,0 +1,225 @@
+package knowledge
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// LoadDir reads every .md, .yaml and .yml file in fsys as a knowledge entry.
+// The file name without its extension is used as the ID unless the file sets one.
+func LoadDir(fsys fs.FS) ([]Entry, error) {
+	var entries []Entry
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		ext := path.Ext(name)
+		if ext != ".md" && ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		id := strings.TrimSuffix(path.Base(name), ext)
+		var entry Entry
+		if ext == ".md" {
+			entry, err = ParseMarkdown(id, data)
+		} else {
+			entry, err = ParseYAML(id, data)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// ParseMarkdown parses a markdown document with optional YAML front matter.
+// The front matter supplies title, tags, owner and links; the rest is the body.
+func ParseMarkdown(id string, data []byte) (Entry, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	fields := map[string]interface{}{}
+	if strings.HasPrefix(text, "---\n") {
+		end := strings.Index(text[4:], "\n---")
+		if end < 0 {
+			return Entry{}, fmt.Errorf("unterminated front matter")
+		}
+
+		var err error
+		fields, err = parseYAML(text[4 : 4+end])
+		if err != nil {
+			return Entry{}, err
+		}
+
+		text = text[4+end+4:]
+	}
+
+	if _, ok := fields["body"]; !ok {
+		fields["body"] = strings.TrimSpace(text)
+	}
+
+	return entryFromFields(id, fields)
+}
+
+// ParseYAML parses an entry written entirely in YAML, with the body under "body".
+func ParseYAML(id string, data []byte) (Entry, error) {
+	fields, err := parseYAML(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	if err != nil {
+		return Entry{}, err
+	}
+	return entryFromFields(id, fields)
+}
+
+func entryFromFields(id string, fields map[string]interface{}) (Entry, error) {
+	entry := Entry{ID: id}
+
+	for key, value := range fields {
+		switch key {
+		case "id":
+			entry.ID = scalar(value)
+		case "title":
+			entry.Title = scalar(value)
+		case "body":
+			entry.Body = strings.TrimSpace(scalar(value))
+		case "owner":
+			entry.Owner = scalar(value)
+		case "tags":
+			entry.Tags = list(value)
+		case "links":
+			entry.Links = list(value)
+		default:
+			return Entry{}, fmt.Errorf("unknown field %q", key)
+		}
+	}
+
+	if err := entry.Validate(); err != nil {
+		return Entry{}, err
+	}
+
+	return normalize(entry), nil
+}
+
+// parseYAML understands the small subset of YAML used by knowledge files:
+// "key: value" scalars, "key: [a, b]" and "- item" lists, and "key: |" blocks.
+func parseYAML(text string) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	lines := strings.Split(text, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("line %d: unexpected indentation", i+1)
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", i+1)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case value == "|" || value == ">":
+			var block []string
+			for i+1 < len(lines) && (lines[i+1] == "" || isIndented(lines[i+1])) {
+				i++
+				block = append(block, strings.TrimSpace(lines[i]))
+			}
+			sep := "\n"
+			if value == ">" {
+				sep = " "
+			}
+			fields[key] = strings.TrimSpace(strings.Join(block, sep))
+
+		case value == "":
+			var items []string
+			for i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "- ") {
+				i++
+				items = append(items, unquote(strings.TrimPrefix(strings.TrimSpace(lines[i]), "- ")))
+			}
+			fields[key] = items
+
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			items := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquote(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			fields[key] = items
+
+		default:
+			fields[key] = unquote(value)
+		}
+	}
+
+	return fields, nil
+}
+
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func scalar(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, "\n")
+	}
+	return ""
+}
+
+func list(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	}
+	return nil
+}
+
+// normalize fills in the defaults every stored entry should have
+func normalize(entry Entry) Entry {
+	if entry.ID == "" {
+		entry.ID = Slugify(entry.Title)
+	}
+	if entry.Tags == nil {
+		entry.Tags = []string{}
+	}
+	if entry.Links == nil {
+		entry.Links = []string{}
+	}
+	return entry
+}
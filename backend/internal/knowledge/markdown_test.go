@@ -0,0 +1,115 @@
+package knowledge
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"tuitui-backend/internal/config"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	data := []byte(`---
+title: USL 502 errors
+tags: [usl, "502"]
+owner: Rhydian Downing
+links:
+  - https://example.com/usl
+---
+
+Contact Rhydian Downing.
+`)
+
+	entry, err := ParseMarkdown("usl", data)
+	if err != nil {
+		t.Fatalf("ParseMarkdown returned error: %v", err)
+	}
+
+	want := Entry{
+		ID:    "usl",
+		Title: "USL 502 errors",
+		Body:  "Contact Rhydian Downing.",
+		Tags:  []string{"usl", "502"},
+		Owner: "Rhydian Downing",
+		Links: []string{"https://example.com/usl"},
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("Expected %+v, got %+v", want, entry)
+	}
+}
+
+func TestParseMarkdown_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unterminated front matter", "---\ntitle: x\n"},
+		{"missing title", "no front matter at all"},
+		{"unknown field", "---\ntitle: x\nauthor: y\n---\nbody"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMarkdown("x", []byte(tt.data)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestParseYAML_BlockBody(t *testing.T) {
+	data := []byte(`id: deploys
+title: Deploy freeze
+tags:
+  - deploy
+body: |
+  No deploys on Fridays.
+  Ask the release manager.
+`)
+
+	entry, err := ParseYAML("file-name", data)
+	if err != nil {
+		t.Fatalf("ParseYAML returned error: %v", err)
+	}
+	if entry.ID != "deploys" {
+		t.Errorf("Expected id from file to win, got '%s'", entry.ID)
+	}
+	if entry.Body != "No deploys on Fridays.\nAsk the release manager." {
+		t.Errorf("Unexpected body: %q", entry.Body)
+	}
+	if len(entry.Tags) != 1 || entry.Tags[0] != "deploy" {
+		t.Errorf("Unexpected tags: %v", entry.Tags)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"b.md":         {Data: []byte("---\ntitle: B\n---\nSecond")},
+		"nested/a.yml": {Data: []byte("title: A\nbody: First")},
+		"README.txt":   {Data: []byte("ignored")},
+	}
+
+	entries, err := LoadDir(fsys)
+	if err != nil {
+		t.Fatalf("LoadDir returned error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "a" || entries[1].ID != "b" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	store, err := NewStore(&config.Config{})
+	if err != nil {
+		t.Fatalf("NewStore returned error: %v", err)
+	}
+
+	entry, err := store.Get(context.Background(), "usl-502-errors")
+	if err != nil {
+		t.Fatalf("Expected the USL 502 default entry: %v", err)
+	}
+	if entry.Owner != "Rhydian Downing" || len(entry.Links) != 1 {
+		t.Errorf("Unexpected default entry: %+v", entry)
+	}
+}
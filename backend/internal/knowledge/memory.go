@@ -0,0 +1,98 @@
+package knowledge
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store for tests, local development and the
+// read-mostly defaults used when no table is configured
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a store holding the given entries
+func NewMemoryStore(entries ...Entry) *MemoryStore {
+	s := &MemoryStore{
+		entries: make(map[string]Entry),
+		now:     time.Now,
+	}
+	for _, entry := range entries {
+		entry = normalize(entry)
+		s.entries[entry.ID] = entry
+	}
+	return s
+}
+
+// List returns every entry ordered by ID
+func (s *MemoryStore) List(ctx context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	sortByID(entries)
+
+	return entries, nil
+}
+
+// Get returns a single entry or ErrNotFound
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entry, nil
+}
+
+// Create adds a new entry, deriving its ID from the title when empty
+func (s *MemoryStore) Create(ctx context.Context, entry Entry) (*Entry, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry = normalize(entry)
+	if _, ok := s.entries[entry.ID]; ok {
+		return nil, ErrExists
+	}
+
+	entry.UpdatedAt = s.now().UTC()
+	s.entries[entry.ID] = entry
+	return &entry, nil
+}
+
+// Update replaces an existing entry or returns ErrNotFound
+func (s *MemoryStore) Update(ctx context.Context, entry Entry) (*Entry, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[entry.ID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	entry = normalize(entry)
+	entry.UpdatedAt = s.now().UTC()
+	s.entries[entry.ID] = entry
+	return &entry, nil
+}
+
+// sortByID orders entries by ID so listings are stable
+func sortByID(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+}
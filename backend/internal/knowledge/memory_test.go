@@ -0,0 +1,86 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	created, err := store.Create(ctx, Entry{
+		Title: "USL 502 errors",
+		Body:  "Contact Rhydian Downing.",
+		Tags:  []string{"usl", "502"},
+		Owner: "Rhydian Downing",
+	})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if created.ID != "usl-502-errors" {
+		t.Errorf("Expected ID 'usl-502-errors', got '%s'", created.ID)
+	}
+	if created.UpdatedAt.IsZero() {
+		t.Error("Expected UpdatedAt to be set")
+	}
+	if created.Links == nil {
+		t.Error("Expected Links to be an empty slice, not nil")
+	}
+
+	if _, err := store.Create(ctx, Entry{Title: "USL 502 errors", Body: "Duplicate"}); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists for duplicate ID, got %v", err)
+	}
+	if _, err := store.Create(ctx, Entry{Title: "No body"}); err == nil {
+		t.Error("Expected error for entry without a body")
+	}
+
+	created.Body = "Ask in #search-results."
+	if _, err := store.Update(ctx, *created); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	got, err := store.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got.Body != "Ask in #search-results." || got.Owner != "Rhydian Downing" {
+		t.Errorf("Unexpected entry after update: %+v", got)
+	}
+
+	if _, err := store.Update(ctx, Entry{ID: "missing", Title: "Missing", Body: "Missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a missing entry, got %v", err)
+	}
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := store.Create(ctx, Entry{ID: "alpha", Title: "Alpha", Body: "First"}); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	entries, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "alpha" || entries[1].ID != "usl-502-errors" {
+		t.Errorf("Expected entries ordered by ID, got %+v", entries)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestNewMemoryStore_SeedsEntries(t *testing.T) {
+	store := NewMemoryStore(Entry{Title: "Seeded", Body: "From disk"})
+
+	entry, err := store.Get(context.Background(), "seeded")
+	if err != nil {
+		t.Fatalf("Expected seeded entry, got error: %v", err)
+	}
+	if entry.Body != "From disk" {
+		t.Errorf("Unexpected seeded entry: %+v", entry)
+	}
+}
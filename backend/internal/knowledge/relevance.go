@@ -0,0 +1,128 @@
+package knowledge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stopWords are ignored when matching a question against entries or documents
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "about": true, "as": true,
+	"at": true, "be": true, "by": true, "can": true, "do": true, "does": true,
+	"for": true, "from": true, "get": true, "getting": true, "how": true, "i": true,
+	"in": true, "is": true, "it": true, "me": true, "my": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "we": true,
+	"what": true, "when": true, "where": true, "who": true, "why": true, "with": true,
+	"you": true,
+}
+
+// Relevant returns up to limit entries that match the question, best first.
+// Tag matches count most, then title words, then body words.
+func Relevant(entries []Entry, question string, limit int) []Entry {
+	terms := tokenize(question)
+	if len(terms) == 0 || limit <= 0 {
+		return nil
+	}
+
+	type scored struct {
+		entry Entry
+		score int
+	}
+
+	var matches []scored
+	for _, entry := range entries {
+		tags := map[string]bool{}
+		for _, tag := range entry.Tags {
+			for word := range tokenize(tag) {
+				tags[word] = true
+			}
+		}
+		title := wordSet(entry.Title)
+		body := wordSet(entry.Body)
+
+		score := 0
+		for term := range terms {
+			switch {
+			case tags[term]:
+				score += 3
+			case title[term]:
+				score += 2
+			case body[term]:
+				score++
+			}
+		}
+
+		if score > 0 {
+			matches = append(matches, scored{entry, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	result := make([]Entry, len(matches))
+	for i, m := range matches {
+		result[i] = m.entry
+	}
+	return result
+}
+
+// FormatPrompt renders entries as the knowledge base section of a system prompt
+func FormatPrompt(entries []Entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("KNOWLEDGE BASE - Use this information when answering questions:\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "\n• %s: %s", entry.Title, entry.Body)
+		if entry.Owner != "" {
+			fmt.Fprintf(&b, " Owner: %s.", entry.Owner)
+		}
+		for _, link := range entry.Links {
+			fmt.Fprintf(&b, "\n  %s", link)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Terms lowercases text and splits it into words, in order, dropping stop
+// words. Document retrieval uses it too, so questions match the same way.
+func Terms(text string) []string {
+	var terms []string
+	for _, word := range words(text) {
+		if !stopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func tokenize(text string) map[string]bool {
+	terms := map[string]bool{}
+	for _, term := range Terms(text) {
+		terms[term] = true
+	}
+	return terms
+}
+
+func wordSet(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range words(text) {
+		set[word] = true
+	}
+	return set
+}
+
+// words lowercases text and splits it into runs of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+}
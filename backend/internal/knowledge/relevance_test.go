@@ -0,0 +1,66 @@
+package knowledge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRelevant(t *testing.T) {
+	entries := []Entry{
+		{ID: "usl", Title: "USL 502 errors", Body: "Contact Rhydian Downing.", Tags: []string{"usl", "502"}},
+		{ID: "deploys", Title: "Deploy freeze", Body: "No deploys on Fridays.", Tags: []string{"deploy"}},
+		{ID: "errors", Title: "Error budgets", Body: "Search results has a 99.9% target for 502 responses."},
+	}
+
+	got := Relevant(entries, "Who do I ask about USL 502s? I keep getting a 502", 5)
+	if len(got) != 2 || got[0].ID != "usl" || got[1].ID != "errors" {
+		t.Errorf("Expected usl then errors, got %+v", got)
+	}
+
+	if got := Relevant(entries, "what is the weather like", 5); len(got) != 0 {
+		t.Errorf("Expected no matches, got %+v", got)
+	}
+
+	if got := Relevant(entries, "502", 1); len(got) != 1 {
+		t.Errorf("Expected limit to apply, got %d entries", len(got))
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := strings.Join(Terms("How do I restart the USL-502 service, and is this safe?"), " ")
+	if got != "restart usl 502 service safe" {
+		t.Errorf("Expected lowercased terms in order without stop words, got %q", got)
+	}
+}
+
+func TestFormatPrompt(t *testing.T) {
+	if FormatPrompt(nil) != "" {
+		t.Error("Expected empty prompt for no entries")
+	}
+
+	prompt := FormatPrompt([]Entry{{
+		Title: "USL 502 errors",
+		Body:  "Contact Rhydian Downing.",
+		Owner: "Rhydian Downing",
+		Links: []string{"https://example.com/usl"},
+	}})
+
+	for _, want := range []string{"KNOWLEDGE BASE", "USL 502 errors: Contact Rhydian Downing.", "Owner: Rhydian Downing.", "https://example.com/usl"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"USL 502 errors":      "usl-502-errors",
+		"  Deploy -- freeze!": "deploy-freeze",
+		"":                    "",
+	}
+	for in, want := range tests {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion is the Messages API version sent with every request
+const anthropicVersion = "2023-06-01"
+
+// Anthropic calls the Anthropic Messages API
+type Anthropic struct {
+	Endpoint   string
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// NewAnthropic creates a provider for the Anthropic Messages API
+func NewAnthropic(endpoint, apiKey, model string) *Anthropic {
+	return &Anthropic{
+		Endpoint:   endpoint,
+		APIKey:     apiKey,
+		Model:      model,
+		HTTPClient: defaultHTTPClient,
+	}
+}
+
+// anthropicResponse is the Messages API response body
+type anthropicResponse struct {
+	Content    []ContentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      Usage          `json:"usage"`
+}
+
+// parseAnthropicResponse parses a Messages API response body, shared by Anthropic and Bedrock
+func parseAnthropicResponse(body []byte) (*Response, error) {
+	var parsed anthropicResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+	if parsed.Content == nil {
+		return nil, fmt.Errorf("%w: no content in reply", ErrUnexpectedResponse)
+	}
+
+	return &Response{
+		Text:       joinText(parsed.Content),
+		StopReason: parsed.StopReason,
+		Usage:      parsed.Usage,
+		Content:    parsed.Content,
+	}, nil
+}
+
+// anthropicRequestBody builds the Messages API body shared by Anthropic and Bedrock
+func anthropicRequestBody(req Request) map[string]interface{} {
+	requestBody := map[string]interface{}{
+		"max_tokens": req.maxTokens(),
+		"messages":   req.Messages,
+	}
+	if req.System != "" {
+		requestBody["system"] = req.System
+	}
+	if len(req.Tools) > 0 {
+		requestBody["tools"] = req.Tools
+	}
+	return requestBody
+}
+
+// Name returns the provider name
+func (a *Anthropic) Name() string {
+	return ProviderAnthropic
+}
+
+// Complete sends the request and waits for the whole reply
+func (a *Anthropic) Complete(ctx context.Context, req Request) (*Response, error) {
+	resp, err := a.do(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	return parseAnthropicResponse(body)
+}
+
+// Stream sends the request with streaming enabled and calls onDelta with each text delta
+func (a *Anthropic) Stream(ctx context.Context, req Request, onDelta func(text string) error) (*Response, error) {
+	resp, err := a.do(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{}
+	var text bytes.Buffer
+
+	// Blocks arrive as start, deltas and stop events; tool input is sent as JSON fragments
+	var blocks []ContentBlock
+	var inputs []strings.Builder
+	err = readSSE(resp.Body, func(ev sseEvent) error {
+		switch ev.Event {
+		case "message_start":
+			var payload struct {
+				Message struct {
+					Usage Usage `json:"usage"`
+				} `json:"message"`
+			}
+			if err := json.Unmarshal([]byte(ev.Data), &payload); err != nil {
+				return fmt.Errorf("failed to unmarshal stream event: %v", err)
+			}
+			result.Usage.InputTokens = payload.Message.Usage.InputTokens
+		case "content_block_start":
+			var payload struct {
+				Index        int          `json:"index"`
+				ContentBlock ContentBlock `json:"content_block"`
+			}
+			if err := json.Unmarshal([]byte(ev.Data), &payload); err != nil {
+				return fmt.Errorf("failed to unmarshal stream event: %v", err)
+			}
+			for len(blocks) <= payload.Index {
+				blocks = append(blocks, ContentBlock{})
+				inputs = append(inputs, strings.Builder{})
+			}
+			block := payload.ContentBlock
+			block.Input = nil
+			blocks[payload.Index] = block
+		case "content_block_delta":
+			var payload struct {
+				Index int `json:"index"`
+				Delta struct {
+					Type        string `json:"type"`
+					Text        string `json:"text"`
+					PartialJSON string `json:"partial_json"`
+				} `json:"delta"`
+			}
+			if err := json.Unmarshal([]byte(ev.Data), &payload); err != nil {
+				return fmt.Errorf("failed to unmarshal stream event: %v", err)
+			}
+			switch payload.Delta.Type {
+			case "text_delta":
+				if payload.Index < len(blocks) {
+					blocks[payload.Index].Text += payload.Delta.Text
+				}
+				text.WriteString(payload.Delta.Text)
+				return onDelta(payload.Delta.Text)
+			case "input_json_delta":
+				if payload.Index < len(inputs) {
+					inputs[payload.Index].WriteString(payload.Delta.PartialJSON)
+				}
+			}
+		case "message_delta":
+			var payload struct {
+				Delta struct {
+					StopReason string `json:"stop_reason"`
+				} `json:"delta"`
+				Usage struct {
+					OutputTokens int `json:"output_tokens"`
+				} `json:"usage"`
+			}
+			if err := json.Unmarshal([]byte(ev.Data), &payload); err != nil {
+				return fmt.Errorf("failed to unmarshal stream event: %v", err)
+			}
+			result.StopReason = payload.Delta.StopReason
+			result.Usage.OutputTokens = payload.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("model API error: %s", ev.Data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range blocks {
+		if blocks[i].Type == BlockToolUse {
+			blocks[i].Input = toolInput(inputs[i].String())
+		}
+	}
+
+	result.Text = text.String()
+	result.Content = blocks
+	return result, nil
+}
+
+// do sends the Messages API request and checks the status code
+func (a *Anthropic) do(ctx context.Context, req Request, stream bool) (*http.Response, error) {
+	if a.APIKey == "" {
+		return nil, fmt.Errorf("Amazon AI API key not configured")
+	}
+
+	requestBody := anthropicRequestBody(req)
+	requestBody["model"] = a.Model
+	if stream {
+		requestBody["stream"] = true
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", a.Endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", a.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := a.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model API: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,216 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnthropic_Complete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("Expected x-api-key header, got '%s'", r.Header.Get("x-api-key"))
+		}
+		if r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("Expected anthropic-version header, got '%s'", r.Header.Get("anthropic-version"))
+		}
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["system"] != "be helpful" {
+			t.Errorf("Expected system prompt, got %v", body["system"])
+		}
+		if body["model"] != "test-model" {
+			t.Errorf("Expected model 'test-model', got %v", body["model"])
+		}
+
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi there"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}`)
+	}))
+	defer server.Close()
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+	resp, err := p.Complete(context.Background(), Request{
+		System:   "be helpful",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if resp.Text != "Hi there" {
+		t.Errorf("Expected text 'Hi there', got '%s'", resp.Text)
+	}
+
+	if resp.StopReason != StopReasonEndTurn {
+		t.Errorf("Expected stop reason '%s', got '%s'", StopReasonEndTurn, resp.StopReason)
+	}
+
+	if resp.Usage.InputTokens != 12 || resp.Usage.OutputTokens != 3 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAnthropic_CompleteJoinsTextBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"message","content":[{"type":"text","text":"Deploys are frozen "},{"type":"text","text":"on Fridays."}],"stop_reason":"max_tokens"}`)
+	}))
+	defer server.Close()
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+	resp, err := p.Complete(context.Background(), Request{Messages: []Message{{Role: "user", Content: "Can I deploy?"}}})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if resp.Text != "Deploys are frozen on Fridays." {
+		t.Errorf("Expected every text block, got '%s'", resp.Text)
+	}
+	if resp.StopReason != StopReasonMaxTokens {
+		t.Errorf("Expected stop reason '%s', got '%s'", StopReasonMaxTokens, resp.StopReason)
+	}
+}
+
+func TestAnthropic_UnexpectedShape(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"completion":"Hi there"}`)
+	}))
+	defer server.Close()
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+	_, err := p.Complete(context.Background(), Request{})
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Errorf("Expected ErrUnexpectedResponse, got %v", err)
+	}
+}
+
+func TestAnthropic_MissingAPIKey(t *testing.T) {
+	p := NewAnthropic("http://localhost", "", "test-model")
+	if _, err := p.Complete(context.Background(), Request{}); err == nil {
+		t.Error("Expected error for missing API key")
+	}
+}
+
+func TestAnthropic_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error"}}`)
+	}))
+	defer server.Close()
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+	if _, err := p.Complete(context.Background(), Request{}); err == nil {
+		t.Error("Expected error for non-200 status")
+	}
+}
+
+func TestAnthropic_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":9}}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"lo\"}}\n\n")
+		fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":2}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer server.Close()
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+
+	var deltas []string
+	resp, err := p.Stream(context.Background(), Request{}, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if len(deltas) != 2 {
+		t.Errorf("Expected 2 deltas, got %d", len(deltas))
+	}
+
+	if resp.Text != "Hello" {
+		t.Errorf("Expected text 'Hello', got '%s'", resp.Text)
+	}
+
+	if resp.StopReason != StopReasonEndTurn {
+		t.Errorf("Expected stop reason '%s', got '%s'", StopReasonEndTurn, resp.StopReason)
+	}
+
+	if resp.Usage.InputTokens != 9 || resp.Usage.OutputTokens != 2 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAnthropic_CompleteToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Tools    []Tool            `json:"tools"`
+			Messages []json.RawMessage `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Tools) != 1 || body.Tools[0].Name != "search" {
+			t.Errorf("Expected the search tool to be advertised, got %+v", body.Tools)
+		}
+		if len(body.Messages) != 2 || string(body.Messages[1]) != `{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu_0","content":"found"}]}` {
+			t.Errorf("Expected tool result as a content array, got %s", body.Messages)
+		}
+
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Searching"},{"type":"tool_use","id":"tu_1","name":"search","input":{"query":"usl"}}],"stop_reason":"tool_use","usage":{"input_tokens":20,"output_tokens":8}}`)
+	}))
+	defer server.Close()
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+	resp, err := p.Complete(context.Background(), Request{
+		Messages: []Message{
+			{Role: "assistant", Blocks: []ContentBlock{{Type: BlockToolUse, ID: "tu_0", Name: "search", Input: json.RawMessage(`{}`)}}},
+			{Role: "user", Blocks: []ContentBlock{{Type: BlockToolResult, ToolUseID: "tu_0", Content: "found"}}},
+		},
+		Tools: []Tool{{Name: "search", Description: "Search", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if resp.StopReason != StopReasonToolUse {
+		t.Errorf("Expected stop reason '%s', got '%s'", StopReasonToolUse, resp.StopReason)
+	}
+
+	uses := resp.ToolUses()
+	if len(uses) != 1 || uses[0].ID != "tu_1" || string(uses[0].Input) != `{"query":"usl"}` {
+		t.Errorf("Unexpected tool uses: %+v", uses)
+	}
+}
+
+func TestAnthropic_StreamToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: content_block_start\ndata: {\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Let me check\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_start\ndata: {\"index\":1,\"content_block\":{\"type\":\"tool_use\",\"id\":\"tu_1\",\"name\":\"search\",\"input\":{}}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"query\\\":\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\" \\\"usl\\\"}\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_stop\ndata: {\"index\":1}\n\n")
+		fmt.Fprint(w, "event: message_delta\ndata: {\"delta\":{\"stop_reason\":\"tool_use\"},\"usage\":{\"output_tokens\":6}}\n\n")
+	}))
+	defer server.Close()
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+	resp, err := p.Stream(context.Background(), Request{}, func(text string) error { return nil })
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if len(resp.Content) != 2 || resp.Content[0].Text != "Let me check" {
+		t.Fatalf("Unexpected content: %+v", resp.Content)
+	}
+
+	uses := resp.ToolUses()
+	if len(uses) != 1 || uses[0].Name != "search" || string(uses[0].Input) != `{"query": "usl"}` {
+		t.Errorf("Unexpected tool uses: %+v", uses)
+	}
+}
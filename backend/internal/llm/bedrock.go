@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go/service/bedrockruntime/bedrockruntimeiface"
+)
+
+// bedrockAnthropicVersion is the Anthropic API version Bedrock expects in the body
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// Bedrock calls Anthropic models hosted on Amazon Bedrock. Requests are signed with
+// the Lambda execution role, so no API key is needed.
+type Bedrock struct {
+	Model  string
+	Client bedrockruntimeiface.BedrockRuntimeAPI
+}
+
+// NewBedrock creates a provider for Bedrock in the given region. Retries are left
+// to httpClient, so the SDK's own retryer is turned off.
+func NewBedrock(region, model string, httpClient *http.Client) (*Bedrock, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return &Bedrock{
+		Model: model,
+		Client: bedrockruntime.New(sess, &aws.Config{
+			HTTPClient: httpClient,
+			MaxRetries: aws.Int(0),
+		}),
+	}, nil
+}
+
+// Name returns the provider name
+func (b *Bedrock) Name() string {
+	return ProviderBedrock
+}
+
+// Complete sends the request and waits for the whole reply
+func (b *Bedrock) Complete(ctx context.Context, req Request) (*Response, error) {
+	requestBody := anthropicRequestBody(req)
+	requestBody["anthropic_version"] = bedrockAnthropicVersion
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	output, err := b.Client.InvokeModelWithContext(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(b.Model),
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+		Body:        jsonData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Bedrock: %w", bedrockError(err))
+	}
+
+	return parseAnthropicResponse(output.Body)
+}
+
+// bedrockError unwraps SDK errors so callers can recognise an open circuit
+// breaker or a throttled request
+func bedrockError(err error) error {
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() != 0 {
+		return &APIError{StatusCode: reqErr.StatusCode(), Body: reqErr.Message()}
+	}
+	for cause := err; cause != nil; {
+		if errors.Is(cause, ErrCircuitOpen) {
+			return ErrCircuitOpen
+		}
+		aerr, ok := cause.(awserr.Error)
+		if !ok {
+			break
+		}
+		cause = aerr.OrigErr()
+	}
+	return err
+}
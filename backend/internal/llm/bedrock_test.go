@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go/service/bedrockruntime/bedrockruntimeiface"
+)
+
+// fakeBedrock records the InvokeModel input and returns a canned body
+type fakeBedrock struct {
+	bedrockruntimeiface.BedrockRuntimeAPI
+	input *bedrockruntime.InvokeModelInput
+	body  string
+}
+
+func (f *fakeBedrock) InvokeModelWithContext(ctx aws.Context, input *bedrockruntime.InvokeModelInput, opts ...request.Option) (*bedrockruntime.InvokeModelOutput, error) {
+	f.input = input
+	return &bedrockruntime.InvokeModelOutput{Body: []byte(f.body)}, nil
+}
+
+func TestBedrock_Complete(t *testing.T) {
+	client := &fakeBedrock{
+		body: `{"content":[{"type":"text","text":"From Bedrock"}],"stop_reason":"end_turn","usage":{"input_tokens":4,"output_tokens":2}}`,
+	}
+	p := &Bedrock{Model: "anthropic.claude-3-haiku-20240307-v1:0", Client: client}
+
+	resp, err := p.Complete(context.Background(), Request{
+		System:   "be helpful",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if aws.StringValue(client.input.ModelId) != p.Model {
+		t.Errorf("Expected model ID '%s', got '%s'", p.Model, aws.StringValue(client.input.ModelId))
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(client.input.Body, &body); err != nil {
+		t.Fatalf("Failed to parse request body: %v", err)
+	}
+	if body["anthropic_version"] != bedrockAnthropicVersion {
+		t.Errorf("Expected anthropic_version '%s', got %v", bedrockAnthropicVersion, body["anthropic_version"])
+	}
+	if _, ok := body["model"]; ok {
+		t.Error("Expected model to be omitted from the Bedrock body")
+	}
+
+	if resp.Text != "From Bedrock" {
+		t.Errorf("Expected text 'From Bedrock', got '%s'", resp.Text)
+	}
+
+	if resp.Usage.InputTokens != 4 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+}
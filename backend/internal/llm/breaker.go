@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling the model API while the breaker is open
+var ErrCircuitOpen = errors.New("model API circuit breaker is open")
+
+// Breaker is a circuit breaker. After Threshold consecutive failures it opens and
+// rejects calls for Cooldown; then it lets a single trial call through, closing
+// again if that call succeeds and reopening if it fails.
+type Breaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+	now       func() time.Time
+}
+
+// NewBreaker creates a closed breaker
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may be made now
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.Threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.trial {
+		return false
+	}
+
+	// Half-open: let one trial call through
+	b.trial = true
+	return true
+}
+
+// Success records a successful call and closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure records a failed call, opening the breaker at the threshold
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.Threshold {
+		b.openUntil = b.now().Add(b.Cooldown)
+	}
+}
+
+// Open reports whether the breaker is currently rejecting calls
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures >= b.Threshold && b.now().Before(b.openUntil)
+}
+
+// breakers holds one breaker per model endpoint. It lives for the life of the
+// Lambda container so failures are remembered across warm invocations.
+var breakers = struct {
+	sync.Mutex
+	byEndpoint map[string]*Breaker
+}{byEndpoint: make(map[string]*Breaker)}
+
+// breakerFor returns the shared breaker for endpoint
+func breakerFor(endpoint string) *Breaker {
+	breakers.Lock()
+	defer breakers.Unlock()
+
+	b, ok := breakers.byEndpoint[endpoint]
+	if !ok {
+		b = NewBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)
+		breakers.byEndpoint[endpoint] = b
+	}
+	return b
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBreaker(3, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		b.Failure()
+	}
+	if !b.Allow() {
+		t.Fatal("Expected breaker to stay closed below the threshold")
+	}
+
+	b.Failure()
+	if b.Allow() || !b.Open() {
+		t.Fatal("Expected breaker to open at the threshold")
+	}
+
+	// After the cooldown a single trial call is let through
+	now = now.Add(31 * time.Second)
+	if !b.Allow() {
+		t.Fatal("Expected a trial call after the cooldown")
+	}
+	if b.Allow() {
+		t.Error("Expected only one trial call while half-open")
+	}
+
+	// A failed trial reopens the breaker
+	b.Failure()
+	if b.Allow() {
+		t.Error("Expected breaker to reopen after a failed trial")
+	}
+
+	// A successful trial closes it
+	now = now.Add(31 * time.Second)
+	if !b.Allow() {
+		t.Fatal("Expected a trial call after the second cooldown")
+	}
+	b.Success()
+	if !b.Allow() || b.Open() {
+		t.Error("Expected breaker to close after a successful trial")
+	}
+}
+
+func TestBreakerFor_SharedPerEndpoint(t *testing.T) {
+	if breakerFor("https://a.example") != breakerFor("https://a.example") {
+		t.Error("Expected the same breaker for the same endpoint")
+	}
+	if breakerFor("https://a.example") == breakerFor("https://b.example") {
+		t.Error("Expected separate breakers for different endpoints")
+	}
+}
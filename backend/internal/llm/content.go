@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Content block types, in the Anthropic Messages vocabulary
+const (
+	BlockText       = "text"
+	BlockToolUse    = "tool_use"
+	BlockToolResult = "tool_result"
+)
+
+// ContentBlock is one part of a message. Text blocks carry Text; tool_use blocks
+// carry ID, Name and Input; tool_result blocks carry ToolUseID, Content and IsError.
+type ContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+// Tool describes a tool the model may call
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// MarshalJSON sends Content as a plain string, or Blocks as a content array when set
+func (m Message) MarshalJSON() ([]byte, error) {
+	if len(m.Blocks) == 0 {
+		return json.Marshal(struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		}{m.Role, m.Content})
+	}
+
+	return json.Marshal(struct {
+		Role    string         `json:"role"`
+		Content []ContentBlock `json:"content"`
+	}{m.Role, m.Blocks})
+}
+
+// ToolUses returns the tool_use blocks of the reply
+func (r *Response) ToolUses() []ContentBlock {
+	var uses []ContentBlock
+	for _, block := range r.Content {
+		if block.Type == BlockToolUse {
+			uses = append(uses, block)
+		}
+	}
+	return uses
+}
+
+// joinText concatenates the text blocks of a reply in order. Models may split
+// one answer across several text blocks, e.g. around tool calls or citations.
+func joinText(blocks []ContentBlock) string {
+	var text strings.Builder
+	for _, block := range blocks {
+		if block.Type == BlockText {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String()
+}
+
+// emptyInput is sent for tool calls whose input was empty
+var emptyInput = json.RawMessage("{}")
+
+// toolInput returns raw as tool input, or an empty object when it is not valid JSON
+func toolInput(raw string) json.RawMessage {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || !json.Valid([]byte(raw)) {
+		return emptyInput
+	}
+	return json.RawMessage(raw)
+}
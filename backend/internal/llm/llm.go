@@ -0,0 +1,167 @@
+// Package llm provides a provider-agnostic interface to the language models
+// behind the chat endpoint, so switching backends is a configuration change.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tuitui-backend/internal/config"
+)
+
+// Supported provider names for config.AIProvider
+const (
+	ProviderAnthropic = "anthropic"
+	ProviderBedrock   = "bedrock"
+	ProviderOpenAI    = "openai"
+)
+
+// Stop reasons, normalized to the Anthropic Messages vocabulary
+const (
+	StopReasonEndTurn      = "end_turn"
+	StopReasonMaxTokens    = "max_tokens"
+	StopReasonStopSequence = "stop_sequence"
+	StopReasonToolUse      = "tool_use"
+)
+
+// DefaultMaxTokens is used when a request does not set MaxTokens
+const DefaultMaxTokens = 4096
+
+// ErrUnexpectedResponse is returned when a reply does not have the shape of a model response
+var ErrUnexpectedResponse = errors.New("unexpected model response")
+
+// Message represents a single turn in a conversation. Plain turns use Content;
+// turns that carry tool calls or tool results use Blocks instead.
+type Message struct {
+	Role    string
+	Content string
+	Blocks  []ContentBlock
+}
+
+// Request is the input to a model call
+type Request struct {
+	System    string
+	Messages  []Message
+	MaxTokens int
+
+	// Tools are advertised to the model, which may answer with tool_use blocks
+	Tools []Tool
+}
+
+// Usage reports the tokens consumed by a model call
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Response is the output of a model call
+type Response struct {
+	// Text joins every text block of the reply
+	Text  string
+	Usage Usage
+
+	// StopReason says why the model stopped; StopReasonMaxTokens means Text was cut off
+	StopReason string
+
+	// Content holds every block of the reply, including tool_use blocks
+	Content []ContentBlock
+}
+
+// Provider sends a conversation to a language model and returns its reply
+type Provider interface {
+	// Name returns the provider name used in configuration
+	Name() string
+
+	// Complete sends the request and waits for the whole reply
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+// StreamingProvider is implemented by providers that can deliver text as it is generated
+type StreamingProvider interface {
+	Provider
+
+	// Stream sends the request and calls onDelta with each text fragment as it
+	// arrives. The returned Response carries the full text.
+	Stream(ctx context.Context, req Request, onDelta func(text string) error) (*Response, error)
+}
+
+// APIError is a non-200 reply from the model API
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("model API error: %s", e.Body)
+}
+
+// IsUnavailable reports whether err means the model API is temporarily unavailable:
+// the circuit breaker is open, or a transient status outlasted the retries.
+func IsUnavailable(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && retryableStatus(apiErr.StatusCode)
+}
+
+// New creates the provider selected by cfg.AIProvider. Providers share a
+// retrying HTTP client with a circuit breaker per endpoint.
+func New(cfg *config.Config) (Provider, error) {
+	switch cfg.AIProvider {
+	case ProviderAnthropic, "":
+		p := NewAnthropic(cfg.AIAPIEndpoint, cfg.AIAPIKey, cfg.AIModelName)
+		p.HTTPClient = resilientClient(cfg, cfg.AIAPIEndpoint)
+		return p, nil
+	case ProviderOpenAI:
+		p := NewOpenAI(cfg.AIAPIEndpoint, cfg.AIAPIKey, cfg.AIModelName)
+		p.HTTPClient = resilientClient(cfg, cfg.AIAPIEndpoint)
+		return p, nil
+	case ProviderBedrock:
+		return NewBedrock(cfg.AWSRegion, cfg.AIModelName, resilientClient(cfg, "bedrock:"+cfg.AWSRegion))
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.AIProvider)
+	}
+}
+
+// Stream streams the reply when the provider supports it, and otherwise falls back
+// to Complete and delivers the whole reply as a single delta
+func Stream(ctx context.Context, p Provider, req Request, onDelta func(text string) error) (*Response, error) {
+	if sp, ok := p.(StreamingProvider); ok {
+		return sp.Stream(ctx, req, onDelta)
+	}
+
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Text != "" {
+		if err := onDelta(resp.Text); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// maxTokens returns the request's token limit or the default
+func (r Request) maxTokens() int {
+	if r.MaxTokens > 0 {
+		return r.MaxTokens
+	}
+	return DefaultMaxTokens
+}
+
+// defaultHTTPClient is shared by providers that are not given a client
+var defaultHTTPClient = &http.Client{}
+
+// resilientClient returns an HTTP client that retries transient failures and
+// shares the circuit breaker for breakerKey
+func resilientClient(cfg *config.Config, breakerKey string) *http.Client {
+	timeout := time.Duration(cfg.AIRequestTimeoutSeconds) * time.Second
+	return &http.Client{
+		Transport: NewRetryTransport(http.DefaultTransport, cfg.AIMaxRetries, timeout, breakerFor(breakerKey)),
+	}
+}
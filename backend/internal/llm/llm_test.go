@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tuitui-backend/internal/config"
+)
+
+// fakeProvider is a non-streaming provider that returns a canned reply
+type fakeProvider struct {
+	reply *Response
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	return f.reply, nil
+}
+
+func TestNew_SelectsProvider(t *testing.T) {
+	tests := []struct {
+		provider string
+		expected string
+	}{
+		{"", ProviderAnthropic},
+		{ProviderAnthropic, ProviderAnthropic},
+		{ProviderOpenAI, ProviderOpenAI},
+		{ProviderBedrock, ProviderBedrock},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.Config{AIProvider: tt.provider, AWSRegion: "eu-west-2"}
+		p, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New(%q) returned error: %v", tt.provider, err)
+		}
+		if p.Name() != tt.expected {
+			t.Errorf("New(%q) returned provider '%s', expected '%s'", tt.provider, p.Name(), tt.expected)
+		}
+	}
+}
+
+func TestNew_UnknownProvider(t *testing.T) {
+	_, err := New(&config.Config{AIProvider: "unknown"})
+	if err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
+
+func TestStream_FallsBackToComplete(t *testing.T) {
+	p := &fakeProvider{reply: &Response{Text: "whole reply", StopReason: StopReasonEndTurn}}
+
+	var deltas []string
+	resp, err := Stream(context.Background(), p, Request{}, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if len(deltas) != 1 || deltas[0] != "whole reply" {
+		t.Errorf("Expected a single delta with the whole reply, got %v", deltas)
+	}
+
+	if resp.Text != "whole reply" {
+		t.Errorf("Expected text 'whole reply', got '%s'", resp.Text)
+	}
+}
+
+func TestRequest_MaxTokensDefault(t *testing.T) {
+	if (Request{}).maxTokens() != DefaultMaxTokens {
+		t.Errorf("Expected default max tokens %d", DefaultMaxTokens)
+	}
+
+	if (Request{MaxTokens: 50}).maxTokens() != 50 {
+		t.Error("Expected explicit max tokens to be used")
+	}
+}
+
+func TestReadSSE(t *testing.T) {
+	stream := "event: ping\ndata: {}\n\nevent: content_block_delta\ndata: line1\ndata: line2\n\n"
+
+	var got []sseEvent
+	err := readSSE(strings.NewReader(stream), func(ev sseEvent) error {
+		got = append(got, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE returned error: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(got))
+	}
+
+	if got[1].Event != "content_block_delta" || got[1].Data != "line1\nline2" {
+		t.Errorf("Unexpected event: %+v", got[1])
+	}
+}
+
+func TestMessage_MarshalJSON(t *testing.T) {
+	plain, _ := json.Marshal(Message{Role: "user", Content: "Hi"})
+	if string(plain) != `{"role":"user","content":"Hi"}` {
+		t.Errorf("Unexpected plain message JSON: %s", plain)
+	}
+
+	blocks, _ := json.Marshal(Message{Role: "assistant", Blocks: []ContentBlock{
+		{Type: BlockText, Text: "Checking"},
+		{Type: BlockToolUse, ID: "tu_1", Name: "search", Input: json.RawMessage(`{"query":"usl"}`)},
+	}})
+	want := `{"role":"assistant","content":[{"type":"text","text":"Checking"},{"type":"tool_use","id":"tu_1","name":"search","input":{"query":"usl"}}]}`
+	if string(blocks) != want {
+		t.Errorf("Expected %s, got %s", want, blocks)
+	}
+}
+
+func TestToolInput(t *testing.T) {
+	tests := map[string]string{
+		`{"query":"usl"}`: `{"query":"usl"}`,
+		"":                `{}`,
+		`{"query":`:       `{}`,
+	}
+	for in, want := range tests {
+		if got := string(toolInput(in)); got != want {
+			t.Errorf("toolInput(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAI calls an OpenAI-compatible Chat Completions API
+type OpenAI struct {
+	Endpoint   string
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// NewOpenAI creates a provider for an OpenAI-compatible Chat Completions API
+func NewOpenAI(endpoint, apiKey, model string) *OpenAI {
+	return &OpenAI{
+		Endpoint:   endpoint,
+		APIKey:     apiKey,
+		Model:      model,
+		HTTPClient: defaultHTTPClient,
+	}
+}
+
+// openAIMessage is a Chat Completions message. Content is null on assistant
+// turns that only call tools.
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    *string          `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIToolCall is a function call requested by the model. Index is only set
+// on streamed deltas.
+type openAIToolCall struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAIResponse is the Chat Completions response body
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content   openAIContentText `json:"content"`
+			ToolCalls []openAIToolCall  `json:"tool_calls"`
+		} `json:"message"`
+		Delta struct {
+			Content   openAIContentText `json:"content"`
+			ToolCalls []openAIToolCall  `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openAIContentText is the text of a message's content, which compatible APIs send
+// either as a string or as an array of parts; parts other than text are ignored
+type openAIContentText string
+
+// UnmarshalJSON accepts a string, null or an array of content parts
+func (t *openAIContentText) UnmarshalJSON(data []byte) error {
+	var text *string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if text != nil {
+			*t = openAIContentText(*text)
+		}
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return errors.New("content is neither a string nor an array of parts")
+	}
+
+	var joined strings.Builder
+	for _, part := range parts {
+		if part.Type == "text" {
+			joined.WriteString(part.Text)
+		}
+	}
+	*t = openAIContentText(joined.String())
+	return nil
+}
+
+// Name returns the provider name
+func (o *OpenAI) Name() string {
+	return ProviderOpenAI
+}
+
+// Complete sends the request and waits for the whole reply
+func (o *OpenAI) Complete(ctx context.Context, req Request) (*Response, error) {
+	resp, err := o.do(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	var parsed openAIResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	if len(parsed.Choices) == 0 {
+		return nil, fmt.Errorf("%w: no choices in reply", ErrUnexpectedResponse)
+	}
+
+	choice := parsed.Choices[0]
+	result := &Response{
+		Text:       string(choice.Message.Content),
+		StopReason: openAIStopReason(choice.FinishReason),
+		Content:    openAIContent(string(choice.Message.Content), choice.Message.ToolCalls),
+	}
+	if parsed.Usage != nil {
+		result.Usage = Usage{
+			InputTokens:  parsed.Usage.PromptTokens,
+			OutputTokens: parsed.Usage.CompletionTokens,
+		}
+	}
+
+	return result, nil
+}
+
+// Stream sends the request with streaming enabled and calls onDelta with each text delta
+func (o *OpenAI) Stream(ctx context.Context, req Request, onDelta func(text string) error) (*Response, error) {
+	resp, err := o.do(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{}
+	var text bytes.Buffer
+
+	// Tool calls arrive in fragments keyed by index
+	var toolCalls []openAIToolCall
+	err = readSSE(resp.Body, func(ev sseEvent) error {
+		if ev.Data == "[DONE]" {
+			return nil
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(ev.Data), &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal stream event: %v", err)
+		}
+		if chunk.Usage != nil {
+			result.Usage = Usage{
+				InputTokens:  chunk.Usage.PromptTokens,
+				OutputTokens: chunk.Usage.CompletionTokens,
+			}
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+		if reason := chunk.Choices[0].FinishReason; reason != "" {
+			result.StopReason = openAIStopReason(reason)
+		}
+		for _, call := range chunk.Choices[0].Delta.ToolCalls {
+			index := len(toolCalls)
+			if call.Index != nil {
+				index = *call.Index
+			}
+			for len(toolCalls) <= index {
+				toolCalls = append(toolCalls, openAIToolCall{})
+			}
+			if call.ID != "" {
+				toolCalls[index].ID = call.ID
+			}
+			if call.Function.Name != "" {
+				toolCalls[index].Function.Name = call.Function.Name
+			}
+			toolCalls[index].Function.Arguments += call.Function.Arguments
+		}
+		if delta := string(chunk.Choices[0].Delta.Content); delta != "" {
+			text.WriteString(delta)
+			return onDelta(delta)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Text = text.String()
+	result.Content = openAIContent(result.Text, toolCalls)
+	return result, nil
+}
+
+// do sends the Chat Completions request and checks the status code
+func (o *OpenAI) do(ctx context.Context, req Request, stream bool) (*http.Response, error) {
+	if o.APIKey == "" {
+		return nil, fmt.Errorf("Amazon AI API key not configured")
+	}
+
+	requestBody := map[string]interface{}{
+		"model":      o.Model,
+		"max_tokens": req.maxTokens(),
+		"messages":   openAIMessages(req),
+	}
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]interface{}, len(req.Tools))
+		for i, tool := range req.Tools {
+			tools[i] = map[string]interface{}{
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":        tool.Name,
+					"description": tool.Description,
+					"parameters":  tool.InputSchema,
+				},
+			}
+		}
+		requestBody["tools"] = tools
+	}
+	if stream {
+		requestBody["stream"] = true
+		requestBody["stream_options"] = map[string]bool{"include_usage": true}
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.Endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+o.APIKey)
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := o.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model API: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return resp, nil
+}
+
+// openAIMessages converts the request into Chat Completions messages. The system
+// prompt travels as the first message, tool calls become assistant tool_calls and
+// each tool result becomes a "tool" message.
+func openAIMessages(req Request) []openAIMessage {
+	messages := make([]openAIMessage, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: &req.System})
+	}
+
+	for _, msg := range req.Messages {
+		if len(msg.Blocks) == 0 {
+			content := msg.Content
+			messages = append(messages, openAIMessage{Role: msg.Role, Content: &content})
+			continue
+		}
+
+		var text strings.Builder
+		var calls []openAIToolCall
+		for _, block := range msg.Blocks {
+			switch block.Type {
+			case BlockText:
+				text.WriteString(block.Text)
+			case BlockToolUse:
+				call := openAIToolCall{ID: block.ID, Type: "function"}
+				call.Function.Name = block.Name
+				call.Function.Arguments = string(block.Input)
+				calls = append(calls, call)
+			case BlockToolResult:
+				content := block.Content
+				messages = append(messages, openAIMessage{Role: "tool", Content: &content, ToolCallID: block.ToolUseID})
+			}
+		}
+
+		if text.Len() > 0 || len(calls) > 0 {
+			converted := openAIMessage{Role: msg.Role, ToolCalls: calls}
+			if text.Len() > 0 {
+				content := text.String()
+				converted.Content = &content
+			}
+			messages = append(messages, converted)
+		}
+	}
+
+	return messages
+}
+
+// openAIContent converts a reply's text and tool calls into content blocks
+func openAIContent(text string, calls []openAIToolCall) []ContentBlock {
+	var blocks []ContentBlock
+	if text != "" {
+		blocks = append(blocks, ContentBlock{Type: BlockText, Text: text})
+	}
+	for _, call := range calls {
+		blocks = append(blocks, ContentBlock{
+			Type:  BlockToolUse,
+			ID:    call.ID,
+			Name:  call.Function.Name,
+			Input: toolInput(call.Function.Arguments),
+		})
+	}
+	return blocks
+}
+
+// openAIStopReason maps an OpenAI finish_reason onto the Anthropic stop reasons
+func openAIStopReason(reason string) string {
+	switch reason {
+	case "stop":
+		return StopReasonEndTurn
+	case "length":
+		return StopReasonMaxTokens
+	case "tool_calls", "function_call":
+		return StopReasonToolUse
+	default:
+		return reason
+	}
+}
@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAI_Complete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Expected bearer token, got '%s'", r.Header.Get("Authorization"))
+		}
+
+		var body struct {
+			Messages []Message `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Messages) != 2 || body.Messages[0].Role != "system" {
+			t.Errorf("Expected system prompt as first message, got %+v", body.Messages)
+		}
+
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Hi there"},"finish_reason":"length"}],"usage":{"prompt_tokens":7,"completion_tokens":4}}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAI(server.URL, "test-key", "gpt-test")
+	resp, err := p.Complete(context.Background(), Request{
+		System:   "be helpful",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if resp.Text != "Hi there" {
+		t.Errorf("Expected text 'Hi there', got '%s'", resp.Text)
+	}
+
+	if resp.StopReason != StopReasonMaxTokens {
+		t.Errorf("Expected stop reason '%s', got '%s'", StopReasonMaxTokens, resp.StopReason)
+	}
+
+	if resp.Usage.InputTokens != 7 || resp.Usage.OutputTokens != 4 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestOpenAI_CompleteContentParts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":[{"type":"text","text":"Hi "},{"type":"image_url"},{"type":"text","text":"there"}]},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAI(server.URL, "test-key", "gpt-test")
+	resp, err := p.Complete(context.Background(), Request{Messages: []Message{{Role: "user", Content: "Hello"}}})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if resp.Text != "Hi there" {
+		t.Errorf("Expected the text parts joined, got '%s'", resp.Text)
+	}
+}
+
+func TestOpenAI_NoChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[]}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAI(server.URL, "test-key", "gpt-test")
+	_, err := p.Complete(context.Background(), Request{})
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Errorf("Expected ErrUnexpectedResponse, got %v", err)
+	}
+}
+
+func TestOpenAI_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewOpenAI(server.URL, "test-key", "gpt-test")
+
+	var deltas []string
+	resp, err := p.Stream(context.Background(), Request{}, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if len(deltas) != 2 || resp.Text != "Hello" {
+		t.Errorf("Expected deltas 'Hel','lo', got %v (text '%s')", deltas, resp.Text)
+	}
+
+	if resp.StopReason != StopReasonEndTurn {
+		t.Errorf("Expected stop reason '%s', got '%s'", StopReasonEndTurn, resp.StopReason)
+	}
+
+	if resp.Usage.OutputTokens != 2 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestOpenAIStopReason(t *testing.T) {
+	if openAIStopReason("stop") != StopReasonEndTurn {
+		t.Error("Expected 'stop' to map to end_turn")
+	}
+	if openAIStopReason("length") != StopReasonMaxTokens {
+		t.Error("Expected 'length' to map to max_tokens")
+	}
+}
+
+func TestOpenAI_ToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []openAIMessage          `json:"messages"`
+			Tools    []map[string]interface{} `json:"tools"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if len(body.Tools) != 1 || body.Tools[0]["type"] != "function" {
+			t.Errorf("Expected a function tool, got %+v", body.Tools)
+		}
+		if len(body.Messages) != 3 {
+			t.Fatalf("Expected user, assistant and tool messages, got %+v", body.Messages)
+		}
+		if call := body.Messages[1].ToolCalls; len(call) != 1 || call[0].Function.Arguments != `{"query":"usl"}` || body.Messages[1].Content != nil {
+			t.Errorf("Expected assistant tool call with null content, got %+v", body.Messages[1])
+		}
+		if body.Messages[2].Role != "tool" || body.Messages[2].ToolCallID != "call_1" {
+			t.Errorf("Expected tool result message, got %+v", body.Messages[2])
+		}
+
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_2","type":"function","function":{"name":"search","arguments":"{\"query\":\"deploy\"}"}}]},"finish_reason":"tool_calls"}]}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAI(server.URL, "test-key", "gpt-test")
+	resp, err := p.Complete(context.Background(), Request{
+		Messages: []Message{
+			{Role: "user", Content: "Find the USL docs"},
+			{Role: "assistant", Blocks: []ContentBlock{{Type: BlockToolUse, ID: "call_1", Name: "search", Input: json.RawMessage(`{"query":"usl"}`)}}},
+			{Role: "user", Blocks: []ContentBlock{{Type: BlockToolResult, ToolUseID: "call_1", Content: "found"}}},
+		},
+		Tools: []Tool{{Name: "search", Description: "Search", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if resp.StopReason != StopReasonToolUse {
+		t.Errorf("Expected stop reason '%s', got '%s'", StopReasonToolUse, resp.StopReason)
+	}
+	uses := resp.ToolUses()
+	if len(uses) != 1 || uses[0].ID != "call_2" || string(uses[0].Input) != `{"query":"deploy"}` {
+		t.Errorf("Unexpected tool uses: %+v", uses)
+	}
+}
+
+func TestOpenAI_StreamToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"search\",\"arguments\":\"\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"{\\\"query\\\":\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"\\\"usl\\\"}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewOpenAI(server.URL, "test-key", "gpt-test")
+	resp, err := p.Stream(context.Background(), Request{}, func(text string) error { return nil })
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	uses := resp.ToolUses()
+	if len(uses) != 1 || uses[0].Name != "search" || string(uses[0].Input) != `{"query":"usl"}` {
+		t.Errorf("Unexpected tool uses: %+v", uses)
+	}
+}
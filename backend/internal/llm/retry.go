@@ -0,0 +1,198 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Retry defaults
+const (
+	DefaultMaxRetries     = 3
+	DefaultAttemptTimeout = 60 * time.Second
+	DefaultBaseBackoff    = 500 * time.Millisecond
+	DefaultMaxBackoff     = 8 * time.Second
+)
+
+// RetryTransport is an http.RoundTripper that retries transient model API
+// failures with jittered exponential backoff, honours Retry-After, bounds each
+// attempt by a timeout and the request deadline, and fails fast through a
+// circuit breaker when the API keeps failing.
+type RetryTransport struct {
+	Base           http.RoundTripper
+	MaxRetries     int
+	AttemptTimeout time.Duration
+	BaseBackoff    time.Duration
+	MaxBackoff     time.Duration
+	Breaker        *Breaker
+
+	// sleep waits for d or until ctx is done; tests replace it
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport creates a transport with the default retry policy
+func NewRetryTransport(base http.RoundTripper, maxRetries int, attemptTimeout time.Duration, breaker *Breaker) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{
+		Base:           base,
+		MaxRetries:     maxRetries,
+		AttemptTimeout: attemptTimeout,
+		BaseBackoff:    DefaultBaseBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		Breaker:        breaker,
+		sleep:          sleepContext,
+	}
+}
+
+// RoundTrip sends the request, retrying transient failures
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if t.Breaker != nil && !t.Breaker.Allow() {
+			return nil, ErrCircuitOpen
+		}
+
+		resp, err := t.attempt(ctx, req)
+		transient := isTransient(ctx, resp, err)
+
+		if t.Breaker != nil {
+			if transient {
+				t.Breaker.Failure()
+			} else {
+				t.Breaker.Success()
+			}
+		}
+
+		if !transient || attempt >= t.MaxRetries || req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = max(wait, retryAfter)
+			}
+		}
+
+		// Give up with the last result when the wait would outlast the caller's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt makes a single request with its own timeout. The timeout also covers
+// reading the body, so it is released when the body is closed.
+func (t *RetryTransport) attempt(ctx context.Context, req *http.Request) (*http.Response, error) {
+	attemptReq := req
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attemptReq = req.Clone(ctx)
+		attemptReq.Body = body
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if t.AttemptTimeout > 0 {
+		var attemptCtx context.Context
+		attemptCtx, cancel = context.WithTimeout(ctx, t.AttemptTimeout)
+		attemptReq = attemptReq.WithContext(attemptCtx)
+	}
+
+	resp, err := t.Base.RoundTrip(attemptReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff returns a random wait up to BaseBackoff * 2^attempt, capped at MaxBackoff
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	ceiling := t.BaseBackoff << attempt
+	if ceiling <= 0 || ceiling > t.MaxBackoff {
+		ceiling = t.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// isTransient reports whether a failed attempt is worth retrying. Failures caused
+// by the caller's own context ending are not.
+func isTransient(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled)
+	}
+	return retryableStatus(resp.StatusCode)
+}
+
+// retryableStatus reports whether the model API may succeed if the request is repeated.
+// 529 is Anthropic's "overloaded" status.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cancelBody releases an attempt's timeout when its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTransport returns a transport that records its waits instead of sleeping
+func newTestTransport(maxRetries int, breaker *Breaker) (*RetryTransport, *[]time.Duration) {
+	var waits []time.Duration
+	transport := NewRetryTransport(http.DefaultTransport, maxRetries, time.Second, breaker)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return transport, &waits
+}
+
+// statusServer replies with statuses in order, then 200 with body "ok"
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "payload") {
+			t.Errorf("Expected the request body on every attempt, got %q", body)
+		}
+
+		n := int(atomic.AddInt32(&calls, 1))
+		if n <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n-1])
+			fmt.Fprint(w, `{"error":"busy"}`)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func post(t *testing.T, transport http.RoundTripper, ctx context.Context, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	return (&http.Client{Transport: transport}).Do(req)
+}
+
+func TestRetryTransport_RetriesTransientStatuses(t *testing.T) {
+	server, calls := statusServer(t, 529, 429, 503)
+	transport, waits := newTestTransport(3, nil)
+
+	resp, err := post(t, transport, context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Request returned error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if *calls != 4 || len(*waits) != 3 {
+		t.Errorf("Expected 4 attempts and 3 waits, got %d and %d", *calls, len(*waits))
+	}
+	for i, wait := range *waits {
+		if ceiling := DefaultBaseBackoff << i; wait <= 0 || wait > ceiling {
+			t.Errorf("Wait %d = %v, expected within (0, %v]", i, wait, ceiling)
+		}
+	}
+}
+
+func TestRetryTransport_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := statusServer(t, 400)
+	transport, _ := newTestTransport(3, nil)
+
+	resp, err := post(t, transport, context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Request returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 400 || *calls != 1 {
+		t.Errorf("Expected a single 400 attempt, got status %d after %d calls", resp.StatusCode, *calls)
+	}
+}
+
+func TestRetryTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	server, calls := statusServer(t, 529, 529, 529)
+	transport, _ := newTestTransport(2, nil)
+
+	p := NewAnthropic(server.URL, "test-key", "test-model")
+	p.HTTPClient = &http.Client{Transport: transport}
+
+	_, err := p.Complete(context.Background(), Request{Messages: []Message{{Role: "user", Content: "payload"}}})
+	if err == nil {
+		t.Fatal("Expected error after retries were exhausted")
+	}
+	if !IsUnavailable(err) {
+		t.Errorf("Expected an unavailable error, got %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestRetryTransport_HonoursRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "20")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	transport, waits := newTestTransport(1, nil)
+	resp, err := post(t, transport, context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Request returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(*waits) != 1 || (*waits)[0] != 20*time.Second {
+		t.Errorf("Expected a 20s wait from Retry-After, got %v", *waits)
+	}
+}
+
+func TestRetryTransport_StopsAtDeadline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	transport, waits := newTestTransport(3, nil)
+	resp, err := post(t, transport, ctx, server.URL)
+	if err != nil {
+		t.Fatalf("Request returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 429 || calls != 1 || len(*waits) != 0 {
+		t.Errorf("Expected the 429 to be returned without waiting past the deadline, got status %d after %d calls", resp.StatusCode, calls)
+	}
+}
+
+func TestRetryTransport_AttemptTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(300 * time.Millisecond):
+			}
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	transport, _ := newTestTransport(1, nil)
+	transport.AttemptTimeout = 50 * time.Millisecond
+
+	resp, err := post(t, transport, context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Request returned error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "ok" || calls != 2 {
+		t.Errorf("Expected the slow attempt to time out and the retry to succeed, got %q after %d calls", body, calls)
+	}
+}
+
+func TestRetryTransport_CircuitBreaker(t *testing.T) {
+	server, calls := statusServer(t, 500, 500, 500, 500)
+	breaker := NewBreaker(2, time.Minute)
+	transport, _ := newTestTransport(5, breaker)
+
+	_, err := post(t, transport, context.Background(), server.URL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("Expected the breaker to stop after 2 failures, got %d calls", *calls)
+	}
+
+	// Later requests fail fast without reaching the server
+	_, err = post(t, transport, context.Background(), server.URL)
+	if !errors.Is(err, ErrCircuitOpen) || *calls != 2 {
+		t.Errorf("Expected a fast failure, got %v after %d calls", err, *calls)
+	}
+	if !IsUnavailable(err) {
+		t.Error("Expected an open circuit to count as unavailable")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"3", 3 * time.Second, true},
+		{"1.5", 1500 * time.Millisecond, true},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sseEvent is a single Server-Sent Event read from a model API
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// readSSE parses a Server-Sent Events stream and calls fn for every complete event
+func readSSE(r io.Reader, fn func(sseEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var ev sseEvent
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if ev.Event != "" || len(data) > 0 {
+				ev.Data = strings.Join(data, "\n")
+				if err := fn(ev); err != nil {
+					return err
+				}
+			}
+			ev = sseEvent{}
+			data = nil
+		case strings.HasPrefix(line, "event:"):
+			ev.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %v", err)
+	}
+
+	// Flush a trailing event that was not followed by a blank line
+	if ev.Event != "" || len(data) > 0 {
+		ev.Data = strings.Join(data, "\n")
+		return fn(ev)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Package logging writes structured JSON logs with log/slog. Wrap gives every
+// request a logger carrying the API Gateway and Lambda request IDs, the
+// caller's Cognito sub and the route, and logs how each request ended, so a
+// single request or user can be followed through CloudWatch Logs Insights.
+package logging
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+)
+
+// Output is where logs are written; tests replace it
+var Output io.Writer = os.Stdout
+
+// contextKey is the context key for the request's logger
+type contextKey struct{}
+
+// ParseLevel returns the level named by LOG_LEVEL, e.g. "debug" or "warn".
+// Unknown names log at info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// New returns a JSON logger at the configured level. A nil cfg, e.g. when the
+// configuration failed to load, logs at info.
+func New(cfg *config.Config) *slog.Logger {
+	level := ""
+	if cfg != nil {
+		level = cfg.LogLevel
+	}
+	return slog.New(slog.NewJSONHandler(Output, &slog.HandlerOptions{Level: ParseLevel(level)}))
+}
+
+// NewContext returns ctx carrying logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request's logger, or an info level logger when ctx has none
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return New(nil)
+}
+
+// ForRequest returns logger with the attributes identifying an API Gateway request
+func ForRequest(ctx context.Context, logger *slog.Logger, request events.APIGatewayProxyRequest) *slog.Logger {
+	route := request.Resource
+	if route == "" {
+		route = request.Path
+	}
+
+	attrs := requestAttrs(ctx, request.RequestContext.RequestID, request.HTTPMethod+" "+route)
+	if user, ok := auth.UserFromRequest(request); ok {
+		attrs = append(attrs, slog.String("sub", user.Sub))
+	}
+	return logger.With(attrs...)
+}
+
+// ForURLRequest returns logger with the attributes identifying a Function URL request
+func ForURLRequest(ctx context.Context, logger *slog.Logger, request events.LambdaFunctionURLRequest) *slog.Logger {
+	http := request.RequestContext.HTTP
+	return logger.With(requestAttrs(ctx, request.RequestContext.RequestID, http.Method+" "+http.Path)...)
+}
+
+// requestAttrs returns the attributes shared by both kinds of request, leaving
+// out IDs that are not set, e.g. in tests
+func requestAttrs(ctx context.Context, apiRequestID, route string) []any {
+	attrs := []any{slog.String("route", strings.TrimSpace(route))}
+	if apiRequestID != "" {
+		attrs = append(attrs, slog.String("api_request_id", apiRequestID))
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+		attrs = append(attrs, slog.String("lambda_request_id", lc.AwsRequestID))
+	}
+	return attrs
+}
+
+// Wrap returns handler with a request logger in its context, logging the
+// status and duration of every request: server errors at error, client errors
+// at warn and the rest at info
+func Wrap(logger *slog.Logger, handler func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)) func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		requestLogger := ForRequest(ctx, logger, request)
+		start := time.Now()
+
+		response, err := handler(NewContext(ctx, requestLogger), request)
+		completed(ctx, requestLogger, response.StatusCode, start, err)
+		return response, err
+	}
+}
+
+// WrapStream is Wrap for streaming Function URL handlers. The duration covers
+// the time to the first byte; the body may still be streaming.
+func WrapStream(logger *slog.Logger, handler func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error)) func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return func(ctx context.Context, request events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+		requestLogger := ForURLRequest(ctx, logger, request)
+		start := time.Now()
+
+		response, err := handler(NewContext(ctx, requestLogger), request)
+		status := 0
+		if response != nil {
+			status = response.StatusCode
+		}
+		completed(ctx, requestLogger, status, start, err)
+		return response, err
+	}
+}
+
+// completed logs the end of a request
+func completed(ctx context.Context, logger *slog.Logger, status int, start time.Time, err error) {
+	level := slog.LevelInfo
+	switch {
+	case err != nil || status >= 500:
+		level = slog.LevelError
+	case status >= 400:
+		level = slog.LevelWarn
+	}
+
+	attrs := []any{
+		slog.Int("status", status),
+		slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	logger.Log(ctx, level, "request completed", attrs...)
+}
+
+// HashEmail returns a short, stable hash of an email address, so a user's
+// sign-in attempts can be found in the logs without writing the address there
+func HashEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:8])
+}
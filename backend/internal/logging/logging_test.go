@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"tuitui-backend/internal/config"
+)
+
+// captureOutput sends logs to a buffer for the rest of the test
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := Output
+	Output = &buf
+	t.Cleanup(func() { Output = previous })
+	return &buf
+}
+
+// lines decodes the JSON log lines written to buf
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		" error ": slog.LevelError,
+		"":        slog.LevelInfo,
+		"verbose": slog.LevelInfo,
+	}
+	for name, want := range tests {
+		if got := ParseLevel(name); got != want {
+			t.Errorf("ParseLevel(%q): expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestNew_HonoursLogLevel(t *testing.T) {
+	buf := captureOutput(t)
+
+	logger := New(&config.Config{LogLevel: "warn"})
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	records := lines(t, buf)
+	if len(records) != 1 || records[0]["msg"] != "shown" {
+		t.Fatalf("Expected only the warning, got %v", records)
+	}
+}
+
+func TestWrap_AddsRequestAttributes(t *testing.T) {
+	buf := captureOutput(t)
+
+	handler := Wrap(New(nil), func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		FromContext(ctx).Warn("login failed", "email_hash", HashEmail("Jane@Example.com"))
+		return events.APIGatewayProxyResponse{StatusCode: 401}, nil
+	})
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-1"})
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Resource:   "/auth/login",
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID: "api-1",
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{"sub": "user-1"},
+			},
+		},
+	}
+	if _, err := handler(ctx, request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records := lines(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %s", len(records), buf.String())
+	}
+	for _, record := range records {
+		for key, want := range map[string]string{
+			"api_request_id":    "api-1",
+			"lambda_request_id": "lambda-1",
+			"sub":               "user-1",
+			"route":             "POST /auth/login",
+		} {
+			if record[key] != want {
+				t.Errorf("Expected %s %q, got %v", key, want, record[key])
+			}
+		}
+	}
+	if records[0]["email_hash"] != HashEmail("jane@example.com") {
+		t.Errorf("Expected the email hash to ignore case, got %v", records[0]["email_hash"])
+	}
+
+	completed := records[1]
+	if completed["msg"] != "request completed" || completed["level"] != "WARN" || completed["status"] != float64(401) {
+		t.Errorf("Expected a warning for the 401, got %v", completed)
+	}
+	if _, ok := completed["duration_ms"]; !ok {
+		t.Error("Expected duration_ms to be logged")
+	}
+}
+
+func TestWrap_ServerErrorsLogAtError(t *testing.T) {
+	buf := captureOutput(t)
+
+	handler := Wrap(New(nil), func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{}, errors.New("boom")
+	})
+	handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+
+	records := lines(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 log line, got %d", len(records))
+	}
+	if records[0]["level"] != "ERROR" || records[0]["error"] != "boom" || records[0]["route"] != "GET /health" {
+		t.Errorf("Unexpected record: %v", records[0])
+	}
+	if _, ok := records[0]["sub"]; ok {
+		t.Error("Expected no sub for an unauthenticated request")
+	}
+}
+
+func TestWrapStream_LogsStatus(t *testing.T) {
+	buf := captureOutput(t)
+
+	handler := WrapStream(New(nil), func(ctx context.Context, request events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+		return &events.LambdaFunctionURLStreamingResponse{StatusCode: 200}, nil
+	})
+	request := events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			RequestID: "url-1",
+			HTTP:      events.LambdaFunctionURLRequestContextHTTPDescription{Method: "POST", Path: "/"},
+		},
+	}
+	handler(context.Background(), request)
+
+	records := lines(t, buf)
+	if len(records) != 1 || records[0]["level"] != "INFO" || records[0]["api_request_id"] != "url-1" || records[0]["route"] != "POST /" {
+		t.Errorf("Unexpected records: %v", records)
+	}
+}
+
+func TestFromContext_DefaultsWithoutLogger(t *testing.T) {
+	if FromContext(context.Background()) == nil {
+		t.Fatal("Expected a default logger")
+	}
+}
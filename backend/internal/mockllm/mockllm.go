@@ -0,0 +1,486 @@
+// Package mockllm implements enough of the Anthropic Messages API to run the
+// chat Lambda without network access. Replies are scripted in order: text,
+// tool_use blocks, chosen stop reasons and usage, or failures such as a 429 or
+// 529. Once the script runs out the server echoes the last user message.
+//
+// Tests start one with NewTestServer and point AI_API_ENDPOINT at its URL;
+// cmd/mockllm serves the same handler for local development, where replies
+// can also be scripted at runtime through the /_mock/ endpoints.
+package mockllm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tuitui-backend/internal/llm"
+)
+
+// MessagesPath is where the Messages API is served
+const MessagesPath = "/v1/messages"
+
+// Reply is one scripted answer. A Status other than 0 or 200 answers with an
+// API error instead of a message.
+type Reply struct {
+	// Text is shorthand for a single text block, placed before Content
+	Text    string             `json:"text,omitempty"`
+	Content []llm.ContentBlock `json:"content,omitempty"`
+
+	// StopReason defaults to tool_use when Content has a tool_use block, else end_turn
+	StopReason string `json:"stop_reason,omitempty"`
+
+	// Usage is estimated from the request and reply when nil
+	Usage *llm.Usage `json:"usage,omitempty"`
+
+	// Chunks are the text deltas a streamed reply is split into, overriding
+	// the default of one delta per word
+	Chunks []string `json:"chunks,omitempty"`
+
+	Status     int    `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`       // message of the API error
+	RetryAfter int    `json:"retry_after,omitempty"` // seconds sent in Retry-After with the error
+
+	// StreamError ends a streamed reply with an error event after the first block
+	StreamError string `json:"stream_error,omitempty"`
+
+	DelayMS int `json:"delay_ms,omitempty"` // wait before answering
+}
+
+// Text returns a reply of one text block
+func Text(text string) Reply {
+	return Reply{Text: text}
+}
+
+// ToolUse returns a reply asking for one tool call
+func ToolUse(id, name string, input interface{}) Reply {
+	raw, _ := json.Marshal(input)
+	return Reply{Content: []llm.ContentBlock{{Type: llm.BlockToolUse, ID: id, Name: name, Input: raw}}}
+}
+
+// Failure returns a reply that fails with status, e.g. 429, 500 or 529
+func Failure(status int) Reply {
+	return Reply{Status: status}
+}
+
+// Request is a Messages API request the server received
+type Request struct {
+	Model     string     `json:"model"`
+	System    string     `json:"system,omitempty"`
+	MaxTokens int        `json:"max_tokens"`
+	Stream    bool       `json:"stream,omitempty"`
+	Messages  []Message  `json:"messages"`
+	Tools     []llm.Tool `json:"tools,omitempty"`
+}
+
+// Message is a request message, whose content is a string or a list of blocks
+type Message struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// Blocks returns the message content as blocks; a string is one text block
+func (m Message) Blocks() []llm.ContentBlock {
+	var text string
+	if err := json.Unmarshal(m.Content, &text); err == nil {
+		return []llm.ContentBlock{{Type: llm.BlockText, Text: text}}
+	}
+	var blocks []llm.ContentBlock
+	json.Unmarshal(m.Content, &blocks)
+	return blocks
+}
+
+// Text returns the text of the message's text blocks
+func (m Message) Text() string {
+	var parts []string
+	for _, block := range m.Blocks() {
+		if block.Type == llm.BlockText {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// Server is an http.Handler serving the Messages API from a script of replies
+type Server struct {
+	// APIKey, when set, must be sent as x-api-key; otherwise any key is accepted
+	APIKey string
+
+	// URL is the Messages API endpoint of a server started by NewTestServer
+	URL string
+
+	mu       sync.Mutex
+	replies  []Reply
+	requests []Request
+}
+
+// New returns a server that answers with replies in order
+func New(replies ...Reply) *Server {
+	return &Server{replies: replies}
+}
+
+// NewTestServer starts a server for the test, closed when the test ends. Its
+// URL is ready to use as AI_API_ENDPOINT.
+func NewTestServer(t testing.TB, replies ...Reply) *Server {
+	t.Helper()
+	s := New(replies...)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	s.URL = ts.URL + MessagesPath
+	return s
+}
+
+// Enqueue adds replies to the end of the script
+func (s *Server) Enqueue(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, replies...)
+}
+
+// Requests returns the Messages API requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset drops the remaining script and the recorded requests
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies, s.requests = nil, nil
+}
+
+// next records req and returns the reply for it
+func (s *Server) next(req Request) Reply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req)
+	if len(s.replies) == 0 {
+		return echo(req)
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply
+}
+
+// echo is the reply once the script has run out
+func echo(req Request) Reply {
+	last := ""
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			last = req.Messages[i].Text()
+			break
+		}
+	}
+	return Text("Mock reply to: " + last)
+}
+
+// ServeHTTP serves the Messages API and the /_mock/ control endpoints
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/_mock/") {
+		s.serveControl(w, r)
+		return
+	}
+	if r.URL.Path != MessagesPath {
+		writeError(w, 404, "not_found_error", "Not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, 405, "invalid_request_error", "Method not allowed")
+		return
+	}
+
+	apiKey := r.Header.Get("x-api-key")
+	if apiKey == "" || s.APIKey != "" && apiKey != s.APIKey {
+		writeError(w, 401, "authentication_error", "invalid x-api-key")
+		return
+	}
+	if r.Header.Get("anthropic-version") == "" {
+		writeError(w, 400, "invalid_request_error", "anthropic-version: header is required")
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if msg := validate(req); msg != "" {
+		writeError(w, 400, "invalid_request_error", msg)
+		return
+	}
+
+	reply := s.next(req)
+	if reply.DelayMS > 0 {
+		select {
+		case <-time.After(time.Duration(reply.DelayMS) * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if reply.Status != 0 && reply.Status != http.StatusOK {
+		if reply.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(reply.RetryAfter))
+		}
+		message := reply.Error
+		if message == "" {
+			message = http.StatusText(reply.Status)
+		}
+		writeError(w, reply.Status, errorType(reply.Status), message)
+		return
+	}
+
+	message := newMessage(req, reply)
+	if req.Stream {
+		writeStream(w, message, reply)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(message)
+}
+
+// validate returns why req is not a valid Messages API request, or ""
+func validate(req Request) string {
+	switch {
+	case req.Model == "":
+		return "model: field required"
+	case req.MaxTokens <= 0:
+		return "max_tokens: must be greater than 0"
+	case len(req.Messages) == 0:
+		return "messages: at least one message is required"
+	case req.Messages[0].Role != "user":
+		return "messages: first message must use the \"user\" role"
+	}
+	return ""
+}
+
+// serveControl scripts the server at runtime:
+//
+//	POST   /_mock/replies   enqueue a reply or a list of replies
+//	DELETE /_mock/replies   drop the script and the recorded requests
+//	GET    /_mock/requests  list the requests received
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/_mock/replies" && r.Method == http.MethodPost:
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			writeError(w, 400, "invalid_request_error", fmt.Sprintf("invalid replies: %v", err))
+			return
+		}
+		var replies []Reply
+		if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+			if err := json.Unmarshal(raw, &replies); err != nil {
+				writeError(w, 400, "invalid_request_error", fmt.Sprintf("invalid replies: %v", err))
+				return
+			}
+		} else {
+			var reply Reply
+			if err := json.Unmarshal(raw, &reply); err != nil {
+				writeError(w, 400, "invalid_request_error", fmt.Sprintf("invalid reply: %v", err))
+				return
+			}
+			replies = []Reply{reply}
+		}
+		s.Enqueue(replies...)
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/_mock/replies" && r.Method == http.MethodDelete:
+		s.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/_mock/requests" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"requests": s.Requests()})
+	default:
+		writeError(w, 404, "not_found_error", "Not found")
+	}
+}
+
+// message is a Messages API response body
+type message struct {
+	ID         string             `json:"id"`
+	Type       string             `json:"type"`
+	Role       string             `json:"role"`
+	Model      string             `json:"model"`
+	Content    []llm.ContentBlock `json:"content"`
+	StopReason string             `json:"stop_reason"`
+	Usage      llm.Usage          `json:"usage"`
+}
+
+// newMessage builds the response to req from reply
+func newMessage(req Request, reply Reply) message {
+	content := make([]llm.ContentBlock, 0, len(reply.Content)+1)
+	if reply.Text != "" {
+		content = append(content, llm.ContentBlock{Type: llm.BlockText, Text: reply.Text})
+	}
+	content = append(content, reply.Content...)
+
+	stopReason := reply.StopReason
+	if stopReason == "" {
+		stopReason = llm.StopReasonEndTurn
+		for _, block := range content {
+			if block.Type == llm.BlockToolUse {
+				stopReason = llm.StopReasonToolUse
+			}
+		}
+	}
+
+	usage := estimateUsage(req, content)
+	if reply.Usage != nil {
+		usage = *reply.Usage
+	}
+
+	return message{
+		ID:         fmt.Sprintf("msg_mock_%d", time.Now().UnixNano()),
+		Type:       "message",
+		Role:       "assistant",
+		Model:      req.Model,
+		Content:    content,
+		StopReason: stopReason,
+		Usage:      usage,
+	}
+}
+
+// estimateUsage counts roughly four characters to a token, like the real API
+// does on average for English text
+func estimateUsage(req Request, content []llm.ContentBlock) llm.Usage {
+	input := len(req.System)
+	for _, m := range req.Messages {
+		input += len(m.Content)
+	}
+	output := 0
+	for _, block := range content {
+		output += len(block.Text) + len(block.Input)
+	}
+	return llm.Usage{InputTokens: tokens(input), OutputTokens: tokens(output)}
+}
+
+func tokens(chars int) int {
+	return max(1, (chars+3)/4)
+}
+
+// writeStream sends message as Messages API server-sent events
+func writeStream(w http.ResponseWriter, msg message, reply Reply) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+	send := func(event string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	start := msg
+	start.Content = []llm.ContentBlock{}
+	start.StopReason = ""
+	start.Usage = llm.Usage{InputTokens: msg.Usage.InputTokens, OutputTokens: 1}
+	send("message_start", map[string]interface{}{"type": "message_start", "message": start})
+	send("ping", map[string]string{"type": "ping"})
+
+	for i, block := range msg.Content {
+		opening := block
+		opening.Text = ""
+		if block.Type == llm.BlockToolUse {
+			opening.Input = json.RawMessage("{}")
+		}
+		send("content_block_start", map[string]interface{}{"type": "content_block_start", "index": i, "content_block": opening})
+
+		for _, delta := range blockDeltas(block, reply.Chunks) {
+			send("content_block_delta", map[string]interface{}{"type": "content_block_delta", "index": i, "delta": delta})
+		}
+		send("content_block_stop", map[string]interface{}{"type": "content_block_stop", "index": i})
+
+		if reply.StreamError != "" {
+			send("error", map[string]interface{}{
+				"type":  "error",
+				"error": map[string]string{"type": "overloaded_error", "message": reply.StreamError},
+			})
+			return
+		}
+	}
+
+	send("message_delta", map[string]interface{}{
+		"type":  "message_delta",
+		"delta": map[string]interface{}{"stop_reason": msg.StopReason, "stop_sequence": nil},
+		"usage": map[string]int{"output_tokens": msg.Usage.OutputTokens},
+	})
+	send("message_stop", map[string]string{"type": "message_stop"})
+}
+
+// blockDeltas splits a block into stream deltas: text by chunks, or by word
+// when chunks is empty, and tool input as two JSON fragments
+func blockDeltas(block llm.ContentBlock, chunks []string) []map[string]string {
+	var deltas []map[string]string
+	switch block.Type {
+	case llm.BlockText:
+		if len(chunks) == 0 {
+			chunks = splitWords(block.Text)
+		}
+		for _, chunk := range chunks {
+			deltas = append(deltas, map[string]string{"type": "text_delta", "text": chunk})
+		}
+	case llm.BlockToolUse:
+		input := string(block.Input)
+		half := len(input) / 2
+		for _, part := range []string{input[:half], input[half:]} {
+			if part != "" {
+				deltas = append(deltas, map[string]string{"type": "input_json_delta", "partial_json": part})
+			}
+		}
+	}
+	return deltas
+}
+
+// splitWords splits text after each space, so the chunks join back to text
+func splitWords(text string) []string {
+	var chunks []string
+	for text != "" {
+		i := strings.IndexByte(text, ' ')
+		if i < 0 {
+			chunks = append(chunks, text)
+			break
+		}
+		chunks = append(chunks, text[:i+1])
+		text = text[i+1:]
+	}
+	return chunks
+}
+
+// errorType returns the Messages API error type for a status code
+func errorType(status int) string {
+	switch status {
+	case 400:
+		return "invalid_request_error"
+	case 401:
+		return "authentication_error"
+	case 403:
+		return "permission_error"
+	case 404:
+		return "not_found_error"
+	case 413:
+		return "request_too_large"
+	case 429:
+		return "rate_limit_error"
+	case 529:
+		return "overloaded_error"
+	}
+	return "api_error"
+}
+
+// writeError writes a Messages API error body
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":  "error",
+		"error": map[string]string{"type": errType, "message": message},
+	})
+}
@@ -0,0 +1,163 @@
+package mockllm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"tuitui-backend/internal/llm"
+)
+
+func testRequest(text string) llm.Request {
+	return llm.Request{
+		System:   "be helpful",
+		Messages: []llm.Message{{Role: "user", Content: text}},
+	}
+}
+
+func TestServer_Complete(t *testing.T) {
+	s := NewTestServer(t, Reply{Text: "Hi there", Usage: &llm.Usage{InputTokens: 12, OutputTokens: 3}})
+	p := llm.NewAnthropic(s.URL, "test-key", "test-model")
+
+	resp, err := p.Complete(context.Background(), testRequest("Hello"))
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	if resp.Text != "Hi there" || resp.StopReason != llm.StopReasonEndTurn {
+		t.Errorf("Unexpected reply %+v", resp)
+	}
+	if resp.Usage.InputTokens != 12 || resp.Usage.OutputTokens != 3 {
+		t.Errorf("Expected the scripted usage, got %+v", resp.Usage)
+	}
+
+	requests := s.Requests()
+	if len(requests) != 1 || requests[0].Model != "test-model" || requests[0].System != "be helpful" || requests[0].Messages[0].Text() != "Hello" {
+		t.Errorf("Unexpected recorded request %+v", requests)
+	}
+}
+
+func TestServer_EchoesOnceScriptRunsOut(t *testing.T) {
+	s := NewTestServer(t)
+	p := llm.NewAnthropic(s.URL, "test-key", "test-model")
+
+	resp, err := p.Complete(context.Background(), testRequest("What is TUI?"))
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	if resp.Text != "Mock reply to: What is TUI?" {
+		t.Errorf("Expected an echo, got %q", resp.Text)
+	}
+	if resp.Usage.InputTokens == 0 || resp.Usage.OutputTokens == 0 {
+		t.Errorf("Expected estimated usage, got %+v", resp.Usage)
+	}
+}
+
+func TestServer_StreamsTextAndToolUse(t *testing.T) {
+	reply := ToolUse("tu_1", "search_knowledge_base", map[string]string{"query": "deploy"})
+	reply.Text = "Let me check. "
+	reply.Chunks = []string{"Let me", " check. "}
+	s := NewTestServer(t, reply)
+	p := llm.NewAnthropic(s.URL, "test-key", "test-model")
+
+	var deltas []string
+	resp, err := p.Stream(context.Background(), testRequest("Can I deploy?"), func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	if strings.Join(deltas, "|") != "Let me| check. " {
+		t.Errorf("Expected the scripted chunks, got %q", deltas)
+	}
+	if resp.StopReason != llm.StopReasonToolUse {
+		t.Errorf("Expected stop reason tool_use, got %q", resp.StopReason)
+	}
+	uses := resp.ToolUses()
+	if len(uses) != 1 || uses[0].ID != "tu_1" || string(uses[0].Input) != `{"query":"deploy"}` {
+		t.Errorf("Expected the tool call, got %+v", uses)
+	}
+	if resp.Usage.InputTokens == 0 || resp.Usage.OutputTokens == 0 {
+		t.Errorf("Expected usage in the stream, got %+v", resp.Usage)
+	}
+	if !s.Requests()[0].Stream {
+		t.Error("Expected a streamed request to be recorded")
+	}
+}
+
+func TestServer_Failures(t *testing.T) {
+	for _, status := range []int{429, 500, 529} {
+		s := NewTestServer(t, Reply{Status: status, RetryAfter: 2})
+		p := llm.NewAnthropic(s.URL, "test-key", "test-model")
+
+		_, err := p.Complete(context.Background(), testRequest("Hello"))
+		var apiErr *llm.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("%d: expected an API error, got %v", status, err)
+			continue
+		}
+		if !strings.Contains(apiErr.Body, errorType(status)) {
+			t.Errorf("%d: expected error type %s, got %s", status, errorType(status), apiErr.Body)
+		}
+
+		// The next call gets the echo, so a retry after a failure succeeds
+		if _, err := p.Complete(context.Background(), testRequest("Hello")); err != nil {
+			t.Errorf("%d: expected the retry to succeed, got %v", status, err)
+		}
+	}
+}
+
+func TestServer_StreamError(t *testing.T) {
+	s := NewTestServer(t, Reply{Text: "Partial", StreamError: "Overloaded"})
+	p := llm.NewAnthropic(s.URL, "test-key", "test-model")
+
+	_, err := p.Stream(context.Background(), testRequest("Hello"), func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "overloaded_error") {
+		t.Errorf("Expected the stream error, got %v", err)
+	}
+}
+
+func TestServer_RejectsBadRequests(t *testing.T) {
+	s := NewTestServer(t)
+	s.APIKey = "right-key"
+
+	_, err := llm.NewAnthropic(s.URL, "wrong-key", "test-model").Complete(context.Background(), testRequest("Hello"))
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		t.Errorf("Expected 401 for the wrong key, got %v", err)
+	}
+
+	_, err = llm.NewAnthropic(s.URL, "right-key", "").Complete(context.Background(), testRequest("Hello"))
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+		t.Errorf("Expected 400 without a model, got %v", err)
+	}
+}
+
+func TestServer_ControlEndpoints(t *testing.T) {
+	s := NewTestServer(t)
+	base := strings.TrimSuffix(s.URL, MessagesPath)
+
+	resp, err := http.Post(base+"/_mock/replies", "application/json", strings.NewReader(`[{"text":"one"},{"status":529}]`))
+	if err != nil || resp.StatusCode != 204 {
+		t.Fatalf("Failed to enqueue replies: %v %v", err, resp)
+	}
+
+	p := llm.NewAnthropic(s.URL, "test-key", "test-model")
+	if resp, err := p.Complete(context.Background(), testRequest("Hello")); err != nil || resp.Text != "one" {
+		t.Errorf("Expected the enqueued reply, got %v %v", resp, err)
+	}
+	if _, err := p.Complete(context.Background(), testRequest("Hello")); err == nil {
+		t.Error("Expected the enqueued failure")
+	}
+
+	req, _ := http.NewRequest("DELETE", base+"/_mock/replies", nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != 204 {
+		t.Fatalf("Failed to reset: %v %v", err, resp)
+	}
+	if len(s.Requests()) != 0 {
+		t.Errorf("Expected no recorded requests after reset, got %d", len(s.Requests()))
+	}
+}
@@ -0,0 +1,280 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Key layout: templates are shared by everyone, so they all live in the
+// partition "PROMPTS". Templates use the sort key "TEMPLATE#<name>" and their
+// versions "VERSION#<name>#<version>" with the version zero-padded, so the
+// templates and a template's versions can each be read in order with a single
+// begins_with query.
+const (
+	promptsPartition = "PROMPTS"
+	templatePrefix   = "TEMPLATE#"
+	versionPrefix    = "VERSION#"
+)
+
+// DynamoStore is a Store backed by a single DynamoDB table with string keys PK and SK
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{
+		client: client,
+		table:  table,
+		now:    time.Now,
+	}
+}
+
+// templateItem is the DynamoDB representation of a Template
+type templateItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Template
+}
+
+// versionItem is the DynamoDB representation of a Version
+type versionItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Version
+}
+
+// ListTemplates returns every saved template ordered by name
+func (s *DynamoStore) ListTemplates(ctx context.Context) ([]Template, error) {
+	items, err := s.query(ctx, templatePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %v", err)
+	}
+
+	templates := []Template{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &templates); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prompt templates: %v", err)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+// GetTemplate returns a single template or ErrNotFound
+func (s *DynamoStore) GetTemplate(ctx context.Context, name string) (*Template, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       itemKey(templateKey(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt template: %v", err)
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var tmpl Template
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prompt template: %v", err)
+	}
+
+	return &tmpl, nil
+}
+
+// CreateTemplate saves a new template as version 1, or returns ErrExists
+func (s *DynamoStore) CreateTemplate(ctx context.Context, name, description string, version Version) (*Template, error) {
+	now := s.now().UTC()
+	version.Version = 1
+	version.CreatedAt = now
+
+	tmpl := Template{
+		Name:        name,
+		Description: description,
+		Body:        version.Body,
+		Version:     version.Version,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		UpdatedBy:   version.CreatedBy,
+	}
+
+	item, err := dynamodbattribute.MarshalMap(templateItem{
+		PK:       promptsPartition,
+		SK:       templateKey(name),
+		Template: tmpl,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal prompt template: %v", err)
+	}
+
+	// The template is claimed before its first version is written, so creating
+	// a name that is taken never overwrites that template's history
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrExists
+		}
+		return nil, fmt.Errorf("failed to create prompt template: %v", err)
+	}
+
+	if err := s.putVersion(ctx, name, version); err != nil {
+		return nil, err
+	}
+
+	return &tmpl, nil
+}
+
+// AddVersion makes version the template's next version and returns the updated template
+func (s *DynamoStore) AddVersion(ctx context.Context, name, description string, version Version) (*Template, error) {
+	now := s.now().UTC()
+
+	update := "SET Body = :body, UpdatedAt = :now, UpdatedBy = :by"
+	values := map[string]*dynamodb.AttributeValue{
+		":body": {S: aws.String(version.Body)},
+		":now":  {S: aws.String(now.Format(time.RFC3339Nano))},
+		":by":   {S: aws.String(version.CreatedBy)},
+		":one":  {N: aws.String("1")},
+	}
+	if description != "" {
+		update += ", Description = :description"
+		values[":description"] = &dynamodb.AttributeValue{S: aws.String(description)}
+	}
+
+	// Bumping the version number first reserves it, so concurrent updates never
+	// write the same version
+	result, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       itemKey(templateKey(name)),
+		ConditionExpression:       aws.String("attribute_exists(PK)"),
+		UpdateExpression:          aws.String(update + " ADD #version :one"),
+		ExpressionAttributeNames:  map[string]*string{"#version": aws.String("Version")},
+		ExpressionAttributeValues: values,
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update prompt template: %v", err)
+	}
+
+	var tmpl Template
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prompt template: %v", err)
+	}
+
+	version.Version = tmpl.Version
+	version.CreatedAt = now
+	if err := s.putVersion(ctx, name, version); err != nil {
+		return nil, err
+	}
+
+	return &tmpl, nil
+}
+
+// ListVersions returns every version of a template, oldest first
+func (s *DynamoStore) ListVersions(ctx context.Context, name string) ([]Version, error) {
+	if _, err := s.GetTemplate(ctx, name); err != nil {
+		return nil, err
+	}
+
+	items, err := s.query(ctx, versionKeyPrefix(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt versions: %v", err)
+	}
+
+	versions := []Version{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &versions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prompt versions: %v", err)
+	}
+
+	return versions, nil
+}
+
+// putVersion stores a version item
+func (s *DynamoStore) putVersion(ctx context.Context, name string, version Version) error {
+	item, err := dynamodbattribute.MarshalMap(versionItem{
+		PK:      promptsPartition,
+		SK:      versionKey(name, version.Version),
+		Version: version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal prompt version: %v", err)
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store prompt version: %v", err)
+	}
+
+	return nil
+}
+
+// query returns every item whose sort key starts with prefix
+func (s *DynamoStore) query(ctx context.Context, prefix string) ([]map[string]*dynamodb.AttributeValue, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":     {S: aws.String(promptsPartition)},
+			":prefix": {S: aws.String(prefix)},
+		},
+	}
+
+	err := s.client.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// isConditionFailed reports whether err is a failed DynamoDB condition check
+func isConditionFailed(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
+
+func templateKey(name string) string {
+	return templatePrefix + name
+}
+
+func versionKeyPrefix(name string) string {
+	return versionPrefix + name + "#"
+}
+
+func versionKey(name string, version int) string {
+	return fmt.Sprintf("%s%06d", versionKeyPrefix(name), version)
+}
+
+func itemKey(sortKey string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String(promptsPartition)},
+		"SK": {S: aws.String(sortKey)},
+	}
+}
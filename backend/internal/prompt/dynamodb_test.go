@@ -0,0 +1,115 @@
+package prompt
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is a minimal in-memory table that understands the operations the
+// store issues against a PK/SK table
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	mu    sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+}
+
+func fakeKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["PK"].S) + "|" + aws.StringValue(key["SK"].S)
+}
+
+func conditionFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+}
+
+func (f *fakeDynamo) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fakeKey(input.Item)
+	if _, ok := f.items[key]; ok && input.ConditionExpression != nil {
+		return nil, conditionFailed()
+	}
+	f.items[key] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[fakeKey(input.Key)]}, nil
+}
+
+// UpdateItemWithContext applies the SET and ADD clauses AddVersion issues
+func (f *fakeDynamo) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[fakeKey(input.Key)]
+	if !ok {
+		return nil, conditionFailed()
+	}
+	values := input.ExpressionAttributeValues
+	item["Body"] = values[":body"]
+	item["UpdatedAt"] = values[":now"]
+	item["UpdatedBy"] = values[":by"]
+	if description, ok := values[":description"]; ok {
+		item["Description"] = description
+	}
+	version, _ := strconv.Atoi(aws.StringValue(item["Version"].N))
+	item["Version"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(version + 1))}
+	return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+}
+
+func (f *fakeDynamo) QueryPagesWithContext(ctx aws.Context, input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, opts ...request.Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pk := aws.StringValue(input.ExpressionAttributeValues[":pk"].S)
+	prefix := aws.StringValue(input.ExpressionAttributeValues[":prefix"].S)
+
+	var keys []string
+	for key := range f.items {
+		if strings.HasPrefix(key, pk+"|"+prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	output := &dynamodb.QueryOutput{}
+	for _, key := range keys {
+		output.Items = append(output.Items, f.items[key])
+	}
+	fn(output, true)
+	return nil
+}
+
+func TestDynamoStore(t *testing.T) {
+	testStore(t, NewDynamoStore(newFakeDynamo(), "prompts"))
+}
+
+func TestDynamoStore_DuplicateKeepsHistory(t *testing.T) {
+	client := newFakeDynamo()
+	store := NewDynamoStore(client, "prompts")
+	ctx := context.Background()
+
+	if _, err := store.CreateTemplate(ctx, "support", "", Version{Body: "original"}); err != nil {
+		t.Fatalf("CreateTemplate returned error: %v", err)
+	}
+	store.CreateTemplate(ctx, "support", "", Version{Body: "duplicate"})
+
+	versions, _ := store.ListVersions(ctx, "support")
+	if len(versions) != 1 || versions[0].Body != "original" {
+		t.Errorf("Expected the original version to survive a duplicate create, got %+v", versions)
+	}
+}
@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store for tests and local development
+type MemoryStore struct {
+	mu        sync.Mutex
+	templates map[string]*Template
+	versions  map[string][]Version
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		templates: make(map[string]*Template),
+		versions:  make(map[string][]Version),
+		now:       time.Now,
+	}
+}
+
+// ListTemplates returns every saved template ordered by name
+func (s *MemoryStore) ListTemplates(ctx context.Context) ([]Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templates := []Template{}
+	for _, tmpl := range s.templates {
+		templates = append(templates, *tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+// GetTemplate returns a single template or ErrNotFound
+func (s *MemoryStore) GetTemplate(ctx context.Context, name string) (*Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpl, ok := s.templates[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *tmpl
+	return &copied, nil
+}
+
+// CreateTemplate saves a new template as version 1, or returns ErrExists
+func (s *MemoryStore) CreateTemplate(ctx context.Context, name, description string, version Version) (*Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.templates[name]; ok {
+		return nil, ErrExists
+	}
+
+	now := s.now().UTC()
+	version.Version = 1
+	version.CreatedAt = now
+
+	tmpl := &Template{
+		Name:        name,
+		Description: description,
+		Body:        version.Body,
+		Version:     version.Version,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		UpdatedBy:   version.CreatedBy,
+	}
+	s.templates[name] = tmpl
+	s.versions[name] = []Version{version}
+
+	copied := *tmpl
+	return &copied, nil
+}
+
+// AddVersion makes version the template's next version and returns the updated template
+func (s *MemoryStore) AddVersion(ctx context.Context, name, description string, version Version) (*Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpl, ok := s.templates[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	now := s.now().UTC()
+	version.Version = tmpl.Version + 1
+	version.CreatedAt = now
+
+	if description != "" {
+		tmpl.Description = description
+	}
+	tmpl.Body = version.Body
+	tmpl.Version = version.Version
+	tmpl.UpdatedAt = now
+	tmpl.UpdatedBy = version.CreatedBy
+	s.versions[name] = append(s.versions[name], version)
+
+	copied := *tmpl
+	return &copied, nil
+}
+
+// ListVersions returns every version of a template, oldest first
+func (s *MemoryStore) ListVersions(ctx context.Context, name string) ([]Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.templates[name]; !ok {
+		return nil, ErrNotFound
+	}
+
+	versions := make([]Version, len(s.versions[name]))
+	copy(versions, s.versions[name])
+	return versions, nil
+}
@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	created, err := store.CreateTemplate(ctx, "support", "Support desk", Version{Body: "v1", CreatedBy: "admin-1"})
+	if err != nil {
+		t.Fatalf("CreateTemplate returned error: %v", err)
+	}
+	if created.Name != "support" || created.Version != 1 || created.Body != "v1" || created.UpdatedBy != "admin-1" {
+		t.Errorf("Unexpected template: %+v", created)
+	}
+
+	if _, err := store.CreateTemplate(ctx, "support", "", Version{Body: "other"}); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists creating a duplicate, got %v", err)
+	}
+
+	updated, err := store.AddVersion(ctx, "support", "", Version{Body: "v2", Note: "Tweak", CreatedBy: "admin-2"})
+	if err != nil {
+		t.Fatalf("AddVersion returned error: %v", err)
+	}
+	if updated.Version != 2 || updated.Body != "v2" || updated.Description != "Support desk" || updated.UpdatedBy != "admin-2" {
+		t.Errorf("Expected version 2 keeping the description, got %+v", updated)
+	}
+
+	updated, err = store.AddVersion(ctx, "support", "Support and billing", Version{Body: "v3"})
+	if err != nil {
+		t.Fatalf("AddVersion returned error: %v", err)
+	}
+	if updated.Version != 3 || updated.Description != "Support and billing" {
+		t.Errorf("Expected version 3 with the new description, got %+v", updated)
+	}
+
+	got, err := store.GetTemplate(ctx, "support")
+	if err != nil {
+		t.Fatalf("GetTemplate returned error: %v", err)
+	}
+	if got.Version != 3 || got.Body != "v3" || !got.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Expected the latest version with the original creation time, got %+v", got)
+	}
+
+	versions, err := store.ListVersions(ctx, "support")
+	if err != nil {
+		t.Fatalf("ListVersions returned error: %v", err)
+	}
+	if len(versions) != 3 || versions[0].Body != "v1" || versions[1].Note != "Tweak" || versions[2].Version != 3 {
+		t.Errorf("Unexpected versions: %+v", versions)
+	}
+
+	if _, err := store.CreateTemplate(ctx, "billing", "", Version{Body: "b1"}); err != nil {
+		t.Fatalf("CreateTemplate returned error: %v", err)
+	}
+	templates, err := store.ListTemplates(ctx)
+	if err != nil {
+		t.Fatalf("ListTemplates returned error: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "billing" || templates[1].Name != "support" {
+		t.Errorf("Expected templates ordered by name, got %+v", templates)
+	}
+
+	if _, err := store.GetTemplate(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.AddVersion(ctx, "missing", "", Version{Body: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound adding a version, got %v", err)
+	}
+	if _, err := store.ListVersions(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound listing versions, got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
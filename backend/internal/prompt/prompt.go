@@ -0,0 +1,315 @@
+// Package prompt stores the templates the chat handler renders into the system
+// prompt. Templates use text/template and are versioned: every change is kept
+// as a numbered version, and rolling back saves an earlier version again as the
+// newest one, so the history is never rewritten.
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/config"
+)
+
+// DefaultName is the template used by teams that have not selected one
+const DefaultName = "default"
+
+// DefaultBody is the built-in default template, used until a template named
+// DefaultName is saved
+const DefaultBody = `You are a helpful assistant for the TuiTui team.
+
+{{with .Knowledge}}{{.}}
+{{end -}}
+{{if or .Team .TeamInfo .Documents -}}
+ADDITIONAL CONTEXT:
+{{- with .Team}}
+Team: {{.}}
+{{- end}}
+{{- with .TeamInstructions}}
+
+Team Instructions:
+{{.}}
+{{- end}}
+{{- with .TeamInfo}}
+
+Team Information: {{join . ", "}}
+{{- end}}
+{{- with .Documents}}
+
+Relevant sections from uploaded document:
+{{.}}
+{{- end}}
+{{- end}}`
+
+// MaxBodySize is the largest template that can be saved, in bytes
+const MaxBodySize = 64 << 10
+
+var (
+	// ErrNotFound is returned when a template or version does not exist
+	ErrNotFound = errors.New("prompt template not found")
+
+	// ErrExists is returned when creating a template whose name is already taken
+	ErrExists = errors.New("prompt template already exists")
+
+	// ErrNotConfigured is returned when no prompts table is configured
+	ErrNotConfigured = errors.New("prompt storage not configured")
+
+	// ErrInvalid is returned for a template that does not parse or render
+	ErrInvalid = errors.New("invalid prompt template")
+)
+
+// validName matches template names: lowercase letters, digits and dashes
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// funcs are the functions available to templates besides the text/template builtins
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// Template is the latest version of a named prompt template
+type Template struct {
+	Name        string    `json:"name" dynamodbav:"Name"`
+	Description string    `json:"description" dynamodbav:"Description"`
+	Body        string    `json:"body" dynamodbav:"Body"`
+	Version     int       `json:"version" dynamodbav:"Version"`
+	CreatedAt   time.Time `json:"created_at" dynamodbav:"CreatedAt"`
+	UpdatedAt   time.Time `json:"updated_at" dynamodbav:"UpdatedAt"`
+	UpdatedBy   string    `json:"updated_by,omitempty" dynamodbav:"UpdatedBy"`
+}
+
+// Version is one revision of a template
+type Version struct {
+	Version   int       `json:"version" dynamodbav:"Version"`
+	Body      string    `json:"body" dynamodbav:"Body"`
+	Note      string    `json:"note,omitempty" dynamodbav:"Note"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"CreatedAt"`
+	CreatedBy string    `json:"created_by,omitempty" dynamodbav:"CreatedBy"`
+}
+
+// Data is what a template can refer to, e.g. {{.Team}} or {{.Date}}
+type Data struct {
+	// Team is the name of the team the user is chatting as, if any
+	Team string
+
+	// TeamInstructions is the team's own system prompt
+	TeamInstructions string
+
+	// TeamInfo is the team's runbook links
+	TeamInfo []string
+
+	// UserName and UserEmail identify the user chatting
+	UserName  string
+	UserEmail string
+
+	// Date is today's date in UTC, e.g. 2024-05-01
+	Date string
+
+	// Knowledge is the knowledge base entries relevant to the question
+	Knowledge string
+
+	// Documents is the sections of uploaded documents relevant to the question
+	Documents string
+}
+
+// SampleData returns data with every field set, used to check templates
+// before they are saved and to fill in previews
+func SampleData() Data {
+	return Data{
+		Team:             "Example Team",
+		TeamInstructions: "Instructions the team gives the assistant.",
+		TeamInfo:         []string{"https://example.com/runbook"},
+		UserName:         "Example User",
+		UserEmail:        "user@example.com",
+		Date:             time.Now().UTC().Format("2006-01-02"),
+		Knowledge:        "KNOWLEDGE BASE - Use this information when answering questions:\n\n• Example entry: Knowledge relevant to the question.\n",
+		Documents:        "## Example heading\nA section of an uploaded document relevant to the question.",
+	}
+}
+
+// Store holds templates and their versions
+type Store interface {
+	// ListTemplates returns every saved template ordered by name
+	ListTemplates(ctx context.Context) ([]Template, error)
+
+	// GetTemplate returns a single template or ErrNotFound
+	GetTemplate(ctx context.Context, name string) (*Template, error)
+
+	// CreateTemplate saves a new template as version 1, or returns ErrExists
+	CreateTemplate(ctx context.Context, name, description string, version Version) (*Template, error)
+
+	// AddVersion makes version the template's next version and returns the
+	// updated template. An empty description keeps the current one.
+	AddVersion(ctx context.Context, name, description string, version Version) (*Template, error)
+
+	// ListVersions returns every version of a template, oldest first
+	ListVersions(ctx context.Context, name string) ([]Version, error)
+}
+
+// NewStore creates the DynamoDB store for the table in cfg.PromptsTable
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.PromptsTable == "" {
+		return nil, ErrNotConfigured
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.AWSRegion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return NewDynamoStore(dynamodb.New(sess), cfg.PromptsTable), nil
+}
+
+// Builtin returns the built-in default template as version 0
+func Builtin() Template {
+	return Template{
+		Name:        DefaultName,
+		Description: "Built-in default prompt",
+		Body:        DefaultBody,
+	}
+}
+
+// ValidName reports whether name can be used as a template name
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// Parse parses a template body
+func Parse(body string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Funcs(funcs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return tmpl, nil
+}
+
+// Render renders a template body with data
+func Render(body string, data Data) (string, error) {
+	tmpl, err := Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return b.String(), nil
+}
+
+// Validate checks that a template body can be saved: it is not empty or too
+// large, and it renders with SampleData and with no data at all
+func Validate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: body is required", ErrInvalid)
+	}
+	if len(body) > MaxBodySize {
+		return fmt.Errorf("%w: body must not exceed %d bytes", ErrInvalid, MaxBodySize)
+	}
+	if _, err := Render(body, SampleData()); err != nil {
+		return err
+	}
+	_, err := Render(body, Data{})
+	return err
+}
+
+// Get returns a saved template, or the built-in default for DefaultName when
+// none has been saved
+func Get(ctx context.Context, store Store, name string) (*Template, error) {
+	tmpl, err := store.GetTemplate(ctx, name)
+	if errors.Is(err, ErrNotFound) && name == DefaultName {
+		builtin := Builtin()
+		return &builtin, nil
+	}
+	return tmpl, err
+}
+
+// Create validates and saves a new template
+func Create(ctx context.Context, store Store, name, description, body, by string) (*Template, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("%w: name must be lowercase letters, digits and dashes", ErrInvalid)
+	}
+	if err := Validate(body); err != nil {
+		return nil, err
+	}
+	return store.CreateTemplate(ctx, name, strings.TrimSpace(description), Version{Body: body, CreatedBy: by})
+}
+
+// Update validates body and saves it as the template's next version. Saving the
+// default template for the first time creates it. An update that changes
+// nothing adds no version.
+func Update(ctx context.Context, store Store, name, description, body, by string) (*Template, error) {
+	if err := Validate(body); err != nil {
+		return nil, err
+	}
+	description = strings.TrimSpace(description)
+
+	current, err := store.GetTemplate(ctx, name)
+	if errors.Is(err, ErrNotFound) && name == DefaultName {
+		return store.CreateTemplate(ctx, name, description, Version{Body: body, CreatedBy: by})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current.Body == body && (description == "" || description == current.Description) {
+		return current, nil
+	}
+
+	return store.AddVersion(ctx, name, description, Version{Body: body, CreatedBy: by})
+}
+
+// Rollback saves an earlier version of a template again as its newest version
+func Rollback(ctx context.Context, store Store, name string, version int, by string) (*Template, error) {
+	versions, err := store.ListVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range versions {
+		if v.Version == version {
+			return store.AddVersion(ctx, name, "", Version{
+				Body:      v.Body,
+				Note:      fmt.Sprintf("Rolled back to version %d", version),
+				CreatedBy: by,
+			})
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Load returns the body of the named template for rendering a chat's prompt.
+// An empty name selects DefaultName, and the built-in default is used when no
+// store is configured or the template does not exist, so a missing template
+// never stops a chat.
+func Load(ctx context.Context, store Store, name string) (string, error) {
+	if name == "" {
+		name = DefaultName
+	}
+	if store == nil {
+		return DefaultBody, nil
+	}
+
+	tmpl, err := store.GetTemplate(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		if name == DefaultName {
+			return DefaultBody, nil
+		}
+		return Load(ctx, store, DefaultName)
+	}
+	if err != nil {
+		return DefaultBody, err
+	}
+	return tmpl.Body, nil
+}
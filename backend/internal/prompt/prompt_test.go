@@ -0,0 +1,152 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRender_DefaultBody(t *testing.T) {
+	got, err := Render(DefaultBody, Data{})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if want := "You are a helpful assistant for the TuiTui team.\n\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	got, err = Render(DefaultBody, Data{
+		Team:             "Payments",
+		TeamInstructions: "Answer in British English.",
+		TeamInfo:         []string{"https://a.example.com", "https://b.example.com"},
+		Knowledge:        "KNOWLEDGE BASE:\n\n• Entry\n",
+		Documents:        "## Refunds\nAsk finance.",
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	want := "You are a helpful assistant for the TuiTui team.\n\n" +
+		"KNOWLEDGE BASE:\n\n• Entry\n\n" +
+		"ADDITIONAL CONTEXT:\n" +
+		"Team: Payments\n\n" +
+		"Team Instructions:\nAnswer in British English.\n\n" +
+		"Team Information: https://a.example.com, https://b.example.com\n\n" +
+		"Relevant sections from uploaded document:\n## Refunds\nAsk finance."
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]string{
+		"empty":         "  \n",
+		"unclosed":      "Hello {{.Team",
+		"unknown field": "Hello {{.Manager}}",
+		"unknown func":  "{{shout .Team}}",
+		"too large":     strings.Repeat("x", MaxBodySize+1),
+	}
+	for name, body := range tests {
+		if err := Validate(body); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", name, err)
+		}
+	}
+
+	if err := Validate("You help {{.UserName}} on {{.Date}}.{{with .Team}} Team: {{upper .}}{{end}}"); err != nil {
+		t.Errorf("Expected a valid template, got %v", err)
+	}
+}
+
+func TestCreateUpdateRollback(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if _, err := Create(ctx, store, "Bad Name", "", "Hi", "admin"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a bad name, got %v", err)
+	}
+	if _, err := Create(ctx, store, "support", "", "{{.Nope}}", "admin"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a bad body, got %v", err)
+	}
+
+	if _, err := Create(ctx, store, "support", " Support ", "First {{.Team}}", "admin"); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if _, err := Update(ctx, store, "support", "", "Second {{.Team}}", "admin"); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	// Saving the same body again adds no version
+	tmpl, err := Update(ctx, store, "support", "Support", "Second {{.Team}}", "admin")
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if tmpl.Version != 2 {
+		t.Errorf("Expected an unchanged update to keep version 2, got %d", tmpl.Version)
+	}
+
+	tmpl, err = Rollback(ctx, store, "support", 1, "other-admin")
+	if err != nil {
+		t.Fatalf("Rollback returned error: %v", err)
+	}
+	if tmpl.Version != 3 || tmpl.Body != "First {{.Team}}" || tmpl.Description != "Support" {
+		t.Errorf("Expected version 3 with the first body, got %+v", tmpl)
+	}
+
+	versions, _ := store.ListVersions(ctx, "support")
+	if len(versions) != 3 || versions[2].Note != "Rolled back to version 1" || versions[2].CreatedBy != "other-admin" {
+		t.Errorf("Unexpected versions after rollback: %+v", versions)
+	}
+
+	if _, err := Rollback(ctx, store, "support", 9, "admin"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound rolling back to a missing version, got %v", err)
+	}
+
+	// The default template is created on its first save
+	if _, err := Update(ctx, store, "missing", "", "Hi", "admin"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a missing template, got %v", err)
+	}
+	tmpl, err = Update(ctx, store, DefaultName, "", "Custom default", "admin")
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if tmpl.Version != 1 || tmpl.Body != "Custom default" {
+		t.Errorf("Expected the default template to be created, got %+v", tmpl)
+	}
+}
+
+func TestGetAndLoad(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	tmpl, err := Get(ctx, store, DefaultName)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if tmpl.Body != DefaultBody || tmpl.Version != 0 {
+		t.Errorf("Expected the built-in default, got %+v", tmpl)
+	}
+	if _, err := Get(ctx, store, "support"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if body, err := Load(ctx, nil, "support"); err != nil || body != DefaultBody {
+		t.Errorf("Expected the built-in default without a store, got %q, %v", body, err)
+	}
+	if body, err := Load(ctx, store, "support"); err != nil || body != DefaultBody {
+		t.Errorf("Expected a missing template to fall back to the built-in default, got %q, %v", body, err)
+	}
+
+	Create(ctx, store, DefaultName, "", "Saved default", "admin")
+	Create(ctx, store, "support", "", "Support prompt", "admin")
+
+	if body, _ := Load(ctx, store, ""); body != "Saved default" {
+		t.Errorf("Expected the saved default, got %q", body)
+	}
+	if body, _ := Load(ctx, store, "support"); body != "Support prompt" {
+		t.Errorf("Expected the selected template, got %q", body)
+	}
+	if body, _ := Load(ctx, store, "deleted"); body != "Saved default" {
+		t.Errorf("Expected a missing template to fall back to the saved default, got %q", body)
+	}
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DynamoStore is a Store backed by a DynamoDB table with string key Key. Keys
+// name their window, so a counter is never reused; ExpiresAt is the table's TTL
+// attribute and only serves to clean up old windows.
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{client: client, table: table}
+}
+
+// Add increments the counter by amount and returns the new total
+func (s *DynamoStore) Add(ctx context.Context, key string, amount int, expires time.Time) (int, error) {
+	output, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Key": {S: aws.String(key)},
+		},
+		UpdateExpression: aws.String("SET ExpiresAt = :expires ADD #count :amount"),
+		ExpressionAttributeNames: map[string]*string{
+			"#count": aws.String("Count"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":expires": {N: aws.String(strconv.FormatInt(expires.Unix(), 10))},
+			":amount":  {N: aws.String(strconv.Itoa(amount))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update rate limit counter: %v", err)
+	}
+
+	return numberAttribute(output.Attributes, "Count"), nil
+}
+
+// Get returns the current total, or 0 for a missing counter
+func (s *DynamoStore) Get(ctx context.Context, key string) (int, error) {
+	output, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Key": {S: aws.String(key)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rate limit counter: %v", err)
+	}
+
+	return numberAttribute(output.Item, "Count"), nil
+}
+
+// numberAttribute returns the numeric attribute name of item, or 0 when it is missing
+func numberAttribute(item map[string]*dynamodb.AttributeValue, name string) int {
+	if item[name] == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(aws.StringValue(item[name].N))
+	return n
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is a minimal in-memory table that understands the counter updates
+// the store issues
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	mu    sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+}
+
+func (f *fakeDynamo) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := aws.StringValue(input.Key["Key"].S)
+	item := f.items[key]
+	if item == nil {
+		item = map[string]*dynamodb.AttributeValue{"Key": input.Key["Key"]}
+		f.items[key] = item
+	}
+
+	count := numberAttribute(item, "Count")
+	amount, _ := strconv.Atoi(aws.StringValue(input.ExpressionAttributeValues[":amount"].N))
+	item["Count"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(count + amount))}
+	item["ExpiresAt"] = input.ExpressionAttributeValues[":expires"]
+
+	return &dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"Count": item["Count"], "ExpiresAt": item["ExpiresAt"]},
+	}, nil
+}
+
+func (f *fakeDynamo) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return &dynamodb.GetItemOutput{Item: f.items[aws.StringValue(input.Key["Key"].S)]}, nil
+}
+
+func TestDynamoStore(t *testing.T) {
+	testStore(t, NewDynamoStore(newFakeDynamo(), "rate-limits"))
+}
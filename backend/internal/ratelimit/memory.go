@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type counter struct {
+	count   int
+	expires time.Time
+}
+
+// MemoryStore is an in-memory Store for tests and local development. Counters
+// are not shared between Lambda instances.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]counter
+	now      func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]counter), now: time.Now}
+}
+
+// Add increments the counter by amount and returns the new total
+func (s *MemoryStore) Add(ctx context.Context, key string, amount int, expires time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.counters[key]
+	if !s.now().Before(c.expires) {
+		c = counter{}
+	}
+	c.count += amount
+	c.expires = expires
+	s.counters[key] = c
+
+	return c.count, nil
+}
+
+// Get returns the current total, or 0 for a missing or expired counter
+func (s *MemoryStore) Get(ctx context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || !s.now().Before(c.expires) {
+		return 0, nil
+	}
+	return c.count, nil
+}
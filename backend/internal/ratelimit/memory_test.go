@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	expires := time.Now().Add(time.Minute)
+
+	if n, err := store.Get(ctx, "missing"); err != nil || n != 0 {
+		t.Errorf("Expected 0 for a missing counter, got %d, %v", n, err)
+	}
+
+	for _, step := range []struct{ amount, total int }{{1, 1}, {1, 2}, {10, 12}} {
+		n, err := store.Add(ctx, "counter", step.amount, expires)
+		if err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+		if n != step.total {
+			t.Errorf("Expected total %d after adding %d, got %d", step.total, step.amount, n)
+		}
+	}
+
+	if n, err := store.Get(ctx, "counter"); err != nil || n != 12 {
+		t.Errorf("Expected 12, got %d, %v", n, err)
+	}
+	if n, _ := store.Get(ctx, "other"); n != 0 {
+		t.Errorf("Expected counters to be independent, got %d", n)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2025, 4, 2, 12, 0, 30, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	store.Add(context.Background(), "counter", 5, now.Add(30*time.Second))
+	now = now.Add(30 * time.Second)
+
+	if n, _ := store.Get(context.Background(), "counter"); n != 0 {
+		t.Errorf("Expected an expired counter to read 0, got %d", n)
+	}
+	if n, _ := store.Add(context.Background(), "counter", 1, now.Add(time.Minute)); n != 1 {
+		t.Errorf("Expected an expired counter to restart, got %d", n)
+	}
+}
@@ -0,0 +1,229 @@
+// Package ratelimit limits how often each Cognito user may call the model and
+// how many tokens they may spend per day, with per-team overrides.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/config"
+)
+
+// ErrNotConfigured is returned when no rate limit table is configured
+var ErrNotConfigured = errors.New("rate limit storage not configured")
+
+// Store holds counters that expire at the end of their window. Implementations
+// must add atomically so concurrent Lambdas share one count.
+type Store interface {
+	// Add increments the counter by amount and returns the new total
+	Add(ctx context.Context, key string, amount int, expires time.Time) (int, error)
+
+	// Get returns the current total, or 0 for a missing or expired counter
+	Get(ctx context.Context, key string) (int, error)
+}
+
+// Limits are the allowances of one user; a zero value disables that limit
+type Limits struct {
+	RequestsPerMinute int
+	TokensPerDay      int
+}
+
+// Policy holds the default limits and the overrides for individual teams,
+// keyed by team ID
+type Policy struct {
+	Default Limits
+	Teams   map[string]Limits
+}
+
+// For returns the limits that apply to a member of team teamID, or the
+// default limits when teamID is empty or has no override
+func (p Policy) For(teamID string) Limits {
+	if limits, ok := p.Teams[normalizeTeam(teamID)]; ok {
+		return limits
+	}
+	return p.Default
+}
+
+// ParsePolicy builds a policy from the default limits and a list of overrides
+// by team ID such as "search=60:500000,payments=:1000000". An empty value keeps
+// the default for that limit.
+func ParsePolicy(defaults Limits, overrides string) (Policy, error) {
+	policy := Policy{Default: defaults, Teams: make(map[string]Limits)}
+
+	for _, override := range strings.Split(overrides, ",") {
+		if strings.TrimSpace(override) == "" {
+			continue
+		}
+
+		team, values, ok := strings.Cut(override, "=")
+		requests, tokens, ok2 := strings.Cut(values, ":")
+		if !ok || !ok2 || normalizeTeam(team) == "" {
+			return Policy{}, fmt.Errorf("invalid team rate limit %q, expected team=requests:tokens", override)
+		}
+
+		limits := defaults
+		for _, field := range []struct {
+			value  string
+			target *int
+		}{{requests, &limits.RequestsPerMinute}, {tokens, &limits.TokensPerDay}} {
+			if strings.TrimSpace(field.value) == "" {
+				continue
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(field.value))
+			if err != nil || n < 0 {
+				return Policy{}, fmt.Errorf("invalid team rate limit %q, expected team=requests:tokens", override)
+			}
+			*field.target = n
+		}
+		policy.Teams[normalizeTeam(team)] = limits
+	}
+
+	return policy, nil
+}
+
+// Window is the state of one limit after a check
+type Window struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Decision is the outcome of checking a request against the caller's limits
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+
+	// Requests and Tokens are nil when that limit is disabled
+	Requests *Window
+	Tokens   *Window
+}
+
+// Headers returns the Retry-After and X-RateLimit-* headers describing the decision
+func (d Decision) Headers() map[string]string {
+	headers := make(map[string]string)
+	if !d.Allowed {
+		seconds := int((d.RetryAfter + time.Second - 1) / time.Second)
+		headers["Retry-After"] = strconv.Itoa(max(seconds, 1))
+	}
+
+	for suffix, window := range map[string]*Window{"Requests": d.Requests, "Tokens": d.Tokens} {
+		if window == nil {
+			continue
+		}
+		headers["X-RateLimit-Limit-"+suffix] = strconv.Itoa(window.Limit)
+		headers["X-RateLimit-Remaining-"+suffix] = strconv.Itoa(window.Remaining)
+		headers["X-RateLimit-Reset-"+suffix] = strconv.FormatInt(window.Reset.Unix(), 10)
+	}
+
+	return headers
+}
+
+// Limiter applies a policy using counters in a store
+type Limiter struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter over store
+func NewLimiter(store Store, policy Policy) *Limiter {
+	return &Limiter{store: store, policy: policy, now: time.Now}
+}
+
+// New creates a limiter from configuration, backed by the configured DynamoDB table
+func New(cfg *config.Config) (*Limiter, error) {
+	if cfg.RateLimitTable == "" {
+		return nil, ErrNotConfigured
+	}
+
+	policy, err := ParsePolicy(Limits{
+		RequestsPerMinute: cfg.RateLimitPerMinute,
+		TokensPerDay:      cfg.RateLimitTokensPerDay,
+	}, cfg.RateLimitOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.AWSRegion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return NewLimiter(NewDynamoStore(dynamodb.New(sess), cfg.RateLimitTable), policy), nil
+}
+
+// Allow checks a request from user sub in team teamID and counts it against
+// the per-minute limit. teamID must be a team the user was checked to belong
+// to, or empty. Requests are refused once the day's tokens are spent.
+func (l *Limiter) Allow(ctx context.Context, sub, teamID string) (Decision, error) {
+	limits := l.policy.For(teamID)
+	now := l.now().UTC()
+	decision := Decision{Allowed: true}
+
+	// Tokens are checked first so a refused request does not use up the minute's allowance
+	if limits.TokensPerDay > 0 {
+		reset := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		used, err := l.store.Get(ctx, tokensKey(sub, now))
+		if err != nil {
+			return Decision{}, err
+		}
+
+		decision.Tokens = &Window{Limit: limits.TokensPerDay, Remaining: max(limits.TokensPerDay-used, 0), Reset: reset}
+		if used >= limits.TokensPerDay {
+			decision.Allowed = false
+			decision.RetryAfter = reset.Sub(now)
+			return decision, nil
+		}
+	}
+
+	if limits.RequestsPerMinute > 0 {
+		reset := now.Truncate(time.Minute).Add(time.Minute)
+		count, err := l.store.Add(ctx, requestsKey(sub, now), 1, reset)
+		if err != nil {
+			return Decision{}, err
+		}
+
+		decision.Requests = &Window{Limit: limits.RequestsPerMinute, Remaining: max(limits.RequestsPerMinute-count, 0), Reset: reset}
+		if count > limits.RequestsPerMinute {
+			decision.Allowed = false
+			decision.RetryAfter = reset.Sub(now)
+		}
+	}
+
+	return decision, nil
+}
+
+// Consume counts tokens spent by user sub against today's allowance
+func (l *Limiter) Consume(ctx context.Context, sub string, tokens int) error {
+	if tokens <= 0 {
+		return nil
+	}
+
+	now := l.now().UTC()
+	_, err := l.store.Add(ctx, tokensKey(sub, now), tokens, now.Truncate(24*time.Hour).Add(24*time.Hour))
+	return err
+}
+
+// requestsKey is the counter for sub's requests in the minute containing t
+func requestsKey(sub string, t time.Time) string {
+	return "REQUESTS#" + sub + "#" + t.Format("2006-01-02T15:04")
+}
+
+// tokensKey is the counter for sub's tokens on the UTC day containing t
+func tokensKey(sub string, t time.Time) string {
+	return "TOKENS#" + sub + "#" + t.Format("2006-01-02")
+}
+
+// normalizeTeam trims and lowercases a team name so overrides match however it is spelt
+func normalizeTeam(team string) string {
+	return strings.ToLower(strings.TrimSpace(team))
+}
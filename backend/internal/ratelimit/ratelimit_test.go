@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestLimiter returns a limiter over a memory store whose clock is *now
+func newTestLimiter(policy Policy, now *time.Time) *Limiter {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return *now }
+	limiter := NewLimiter(store, policy)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(Limits{RequestsPerMinute: 20, TokensPerDay: 1000}, " Search=60:5000, payments=:0 ")
+	if err != nil {
+		t.Fatalf("ParsePolicy returned error: %v", err)
+	}
+
+	if got := policy.For("search"); got != (Limits{60, 5000}) {
+		t.Errorf("Unexpected search limits: %+v", got)
+	}
+	if got := policy.For("Payments "); got != (Limits{20, 0}) {
+		t.Errorf("Expected payments to keep the default request limit and drop the token limit, got %+v", got)
+	}
+	if got := policy.For(""); got != (Limits{20, 1000}) {
+		t.Errorf("Expected the default limits without a team, got %+v", got)
+	}
+
+	for _, invalid := range []string{"search", "search=60", "=1:2", "search=many:1", "search=-1:1"} {
+		if _, err := ParsePolicy(Limits{}, invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestLimiter_RequestsPerMinute(t *testing.T) {
+	now := time.Date(2025, 4, 2, 12, 0, 15, 0, time.UTC)
+	limiter := newTestLimiter(Policy{Default: Limits{RequestsPerMinute: 2}}, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		decision, err := limiter.Allow(ctx, "user-1", "")
+		if err != nil {
+			t.Fatalf("Allow returned error: %v", err)
+		}
+		if !decision.Allowed || decision.Requests.Remaining != 1-i {
+			t.Fatalf("Expected request %d to be allowed, got %+v", i+1, decision)
+		}
+	}
+
+	decision, _ := limiter.Allow(ctx, "user-1", "")
+	if decision.Allowed || decision.RetryAfter != 45*time.Second {
+		t.Errorf("Expected the third request to wait 45s, got %+v", decision)
+	}
+
+	if other, _ := limiter.Allow(ctx, "user-2", ""); !other.Allowed {
+		t.Error("Expected limits to be per user")
+	}
+
+	now = now.Add(45 * time.Second)
+	if next, _ := limiter.Allow(ctx, "user-1", ""); !next.Allowed {
+		t.Error("Expected the next minute to start a new window")
+	}
+}
+
+func TestLimiter_TokensPerDay(t *testing.T) {
+	now := time.Date(2025, 4, 2, 23, 0, 0, 0, time.UTC)
+	limiter := newTestLimiter(Policy{
+		Default: Limits{RequestsPerMinute: 10, TokensPerDay: 100},
+		Teams:   map[string]Limits{"search": {RequestsPerMinute: 10, TokensPerDay: 500}},
+	}, &now)
+	ctx := context.Background()
+
+	if err := limiter.Consume(ctx, "user-1", 150); err != nil {
+		t.Fatalf("Consume returned error: %v", err)
+	}
+
+	decision, _ := limiter.Allow(ctx, "user-1", "")
+	if decision.Allowed || decision.RetryAfter != time.Hour || decision.Tokens.Remaining != 0 {
+		t.Errorf("Expected the spent quota to refuse until midnight, got %+v", decision)
+	}
+	if decision.Requests != nil {
+		t.Error("Expected a token refusal not to count against the request limit")
+	}
+
+	if team, _ := limiter.Allow(ctx, "user-1", "Search"); !team.Allowed || team.Tokens.Remaining != 350 {
+		t.Errorf("Expected the team override to allow the request, got %+v", team)
+	}
+
+	now = now.Add(time.Hour)
+	if tomorrow, _ := limiter.Allow(ctx, "user-1", ""); !tomorrow.Allowed {
+		t.Error("Expected the quota to reset at midnight UTC")
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(Policy{}, &now)
+
+	decision, err := limiter.Allow(context.Background(), "user-1", "")
+	if err != nil || !decision.Allowed {
+		t.Fatalf("Expected no limits to allow the request, got %+v, %v", decision, err)
+	}
+	if len(decision.Headers()) != 0 {
+		t.Errorf("Expected no headers without limits, got %v", decision.Headers())
+	}
+}
+
+func TestDecision_Headers(t *testing.T) {
+	reset := time.Unix(1743595260, 0)
+	decision := Decision{
+		RetryAfter: 1500 * time.Millisecond,
+		Requests:   &Window{Limit: 20, Remaining: 0, Reset: reset},
+	}
+
+	headers := decision.Headers()
+	want := map[string]string{
+		"Retry-After":                    "2",
+		"X-RateLimit-Limit-Requests":     "20",
+		"X-RateLimit-Remaining-Requests": "0",
+		"X-RateLimit-Reset-Requests":     "1743595260",
+	}
+	if len(headers) != len(want) {
+		t.Errorf("Expected %d headers, got %v", len(want), headers)
+	}
+	for name, value := range want {
+		if headers[name] != value {
+			t.Errorf("Expected %s %q, got %q", name, value, headers[name])
+		}
+	}
+}
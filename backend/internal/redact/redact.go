@@ -0,0 +1,269 @@
+// Package redact replaces personal data and secrets in text with placeholders
+// such as [EMAIL_1] before the text is logged or sent to a model. A Redactor
+// remembers what each placeholder stands for, so the model's answer can be
+// restored before it is shown to the user.
+package redact
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Kind is a type of value that is redacted; it names the placeholder
+type Kind string
+
+const (
+	Email      Kind = "EMAIL"
+	Phone      Kind = "PHONE"
+	Card       Kind = "CARD"
+	AWSKey     Kind = "AWS_KEY"
+	JWT        Kind = "JWT"
+	BookingRef Kind = "BOOKING_REF"
+)
+
+// detector finds one kind of value. When group is set only that submatch is
+// replaced, so labels such as "booking ref:" stay readable. valid rejects
+// matches that only look like the kind, e.g. digit runs that fail the Luhn check.
+type detector struct {
+	kind    Kind
+	pattern *regexp.Regexp
+	group   int
+	valid   func(string) bool
+}
+
+// detectors run in order on text that earlier detectors have already redacted,
+// so the most specific patterns come first: a JWT contains dots an email
+// pattern could latch onto, and card numbers would otherwise read as phones.
+var detectors = []detector{
+	{
+		kind:    JWT,
+		pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{2,}\.eyJ[A-Za-z0-9_-]{2,}\.[A-Za-z0-9_-]*`),
+	},
+	{
+		// Access key IDs have a fixed prefix; secret keys are only recognisable by their label
+		kind:    AWSKey,
+		pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AIDA|AROA|AIPA|ANPA|ANVA|AGPA)[A-Z0-9]{16}\b`),
+	},
+	{
+		kind:    AWSKey,
+		pattern: regexp.MustCompile(`(?i)\b(?:aws_?)?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+=]|$)`),
+		group:   1,
+	},
+	{
+		kind:    Email,
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		kind:    Card,
+		pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid:   luhn,
+	},
+	{
+		// TUI booking references are 6 to 10 letters and digits after a label such
+		// as "booking ref", "booking number" or "PNR"
+		kind:    BookingRef,
+		pattern: regexp.MustCompile(`(?i)\b(?:booking|reservation|confirmation|pnr)(?:\s+(?:ref(?:erence)?|number|no\.?|id))?(?:\s+is)?\s*[:#-]?\s*([A-Z0-9]{6,10})\b`),
+		group:   1,
+		valid:   hasDigit,
+	},
+	{
+		// and on their own as "TUI" followed by the reference digits
+		kind:    BookingRef,
+		pattern: regexp.MustCompile(`\bTUI[- ]?\d{6,10}\b`),
+	},
+	{
+		// International numbers or UK numbers with a leading zero, with spaces,
+		// dots, dashes or brackets between the digits
+		kind:    Phone,
+		pattern: regexp.MustCompile(`(?:\+|\b0)\d(?:[\s.()-]{0,2}\d){8,13}\b`),
+		valid:   func(s string) bool { n := countDigits(s); return n >= 10 && n <= 15 },
+	},
+}
+
+// placeholderPattern matches the placeholders Redact writes
+var placeholderPattern = regexp.MustCompile(`\[(EMAIL|PHONE|CARD|AWS_KEY|JWT|BOOKING_REF)_(\d+)\]`)
+
+// maxPlaceholderLen bounds how much of a stream is held back waiting for the
+// end of a placeholder
+const maxPlaceholderLen = 32
+
+// Redactor replaces values with placeholders and restores them. The same value
+// always gets the same placeholder, so the model can tell repeated values apart
+// from different ones. A nil Redactor leaves text unchanged.
+type Redactor struct {
+	mu           sync.Mutex
+	values       map[string]string // placeholder to original value
+	placeholders map[string]string // original value to placeholder
+	counts       map[Kind]int
+}
+
+// New creates a Redactor with no remembered values
+func New() *Redactor {
+	return &Redactor{
+		values:       make(map[string]string),
+		placeholders: make(map[string]string),
+		counts:       make(map[Kind]int),
+	}
+}
+
+// String redacts text for a log line, where nothing needs to be restored
+func String(text string) string {
+	return New().Redact(text)
+}
+
+// Redact returns text with every detected value replaced by its placeholder
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range detectors {
+		text = r.replace(text, d)
+	}
+	return text
+}
+
+// replace substitutes the matches of one detector
+func (r *Redactor) replace(text string, d detector) string {
+	matches := d.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*d.group], m[2*d.group+1]
+		if start < 0 {
+			continue
+		}
+		value := text[start:end]
+		if d.valid != nil && !d.valid(value) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(r.placeholder(d.kind, value))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// placeholder returns the placeholder for value, allocating one on first use
+func (r *Redactor) placeholder(kind Kind, value string) string {
+	if p, ok := r.placeholders[value]; ok {
+		return p
+	}
+	r.counts[kind]++
+	p := "[" + string(kind) + "_" + strconv.Itoa(r.counts[kind]) + "]"
+	r.placeholders[value] = p
+	r.values[p] = value
+	return p
+}
+
+// Restore returns text with every placeholder this Redactor wrote replaced by
+// its original value. Placeholders it did not write are left as they are.
+func (r *Redactor) Restore(text string) string {
+	if r == nil || !strings.Contains(text, "[") {
+		return text
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(p string) string {
+		if value, ok := r.values[p]; ok {
+			return value
+		}
+		return p
+	})
+}
+
+// Len returns how many distinct values have been redacted
+func (r *Redactor) Len() int {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.values)
+}
+
+// Restorer restores placeholders in text that arrives in pieces, such as a
+// streamed reply, where a placeholder can be split between two pieces
+type Restorer struct {
+	redactor *Redactor
+	write    func(text string) error
+	pending  string
+}
+
+// Restorer returns a Restorer that passes restored text to write
+func (r *Redactor) Restorer(write func(text string) error) *Restorer {
+	return &Restorer{redactor: r, write: write}
+}
+
+// Write restores text and passes it on, holding back a trailing piece that may
+// be the start of a placeholder until the next Write or Flush
+func (s *Restorer) Write(text string) error {
+	text = s.pending + text
+	s.pending = ""
+
+	if i := strings.LastIndex(text, "["); i >= 0 && !strings.Contains(text[i:], "]") && len(text)-i < maxPlaceholderLen {
+		text, s.pending = text[:i], text[i:]
+	}
+	if text == "" {
+		return nil
+	}
+	return s.write(s.redactor.Restore(text))
+}
+
+// Flush passes on any text still held back
+func (s *Restorer) Flush() error {
+	if s.pending == "" {
+		return nil
+	}
+	text := s.pending
+	s.pending = ""
+	return s.write(s.redactor.Restore(text))
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by card numbers
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func hasDigit(s string) bool {
+	return countDigits(s) > 0
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,203 @@
+package retrieval
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultChunkSize is the target maximum length of a chunk in characters
+const DefaultChunkSize = 1500
+
+// Chunk is a section of a markdown document
+type Chunk struct {
+	// Index is the chunk's position in the document
+	Index int
+
+	// Heading is the path of headings above the chunk, e.g. "Setup > Install"
+	Heading string
+
+	// Text is the chunk body, without its heading line
+	Text string
+}
+
+// ChunkMarkdown splits a markdown document at its headings. Sections longer
+// than maxChars are split again at paragraph breaks, then sentences and words,
+// so no chunk is longer than maxChars. Headings inside fenced code blocks are
+// left alone.
+func ChunkMarkdown(document string, maxChars int) []Chunk {
+	type heading struct {
+		level int
+		title string
+	}
+
+	var (
+		chunks  []Chunk
+		stack   []heading
+		body    []string
+		inFence bool
+	)
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(body, "\n"))
+		body = body[:0]
+		if text == "" {
+			return
+		}
+		titles := make([]string, len(stack))
+		for i, h := range stack {
+			titles[i] = h.title
+		}
+		path := strings.Join(titles, " > ")
+		for _, part := range splitParagraphs(text, maxChars) {
+			chunks = append(chunks, Chunk{Index: len(chunks), Heading: path, Text: part})
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(document, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		if level, title := parseHeading(line); !inFence && level > 0 {
+			flush()
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, heading{level, title})
+			continue
+		}
+
+		body = append(body, line)
+	}
+	flush()
+
+	return chunks
+}
+
+// parseHeading returns the level and title of an ATX heading line, or 0
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(line[level:], "# "))
+}
+
+// splitParagraphs breaks text into pieces of at most maxChars at blank lines.
+// A single paragraph longer than maxChars is split by splitLong.
+func splitParagraphs(text string, maxChars int) []string {
+	if maxChars <= 0 || len(text) <= maxChars {
+		return []string{text}
+	}
+
+	var (
+		parts   []string
+		current strings.Builder
+	)
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		if len(para) > maxChars {
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			parts = append(parts, splitLong(para, maxChars)...)
+			continue
+		}
+		if current.Len() > 0 && current.Len()+len(para)+2 > maxChars {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(para)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// splitLong breaks a paragraph longer than maxChars between sentences, and a
+// sentence still too long between words. A word longer than maxChars, such as
+// a long URL, is cut. The text between pieces is kept as it was.
+func splitLong(para string, maxChars int) []string {
+	var spans []string
+	for _, sentence := range splitAfter(para, sentenceEnd) {
+		if len(strings.TrimRightFunc(sentence, unicode.IsSpace)) <= maxChars {
+			spans = append(spans, sentence)
+			continue
+		}
+		for _, word := range splitAfter(sentence, wordEnd) {
+			for len(strings.TrimRightFunc(word, unicode.IsSpace)) > maxChars {
+				// Cut at a rune boundary so no piece holds half a character
+				cut := maxChars
+				for cut > 0 && !utf8.RuneStart(word[cut]) {
+					cut--
+				}
+				if cut == 0 {
+					_, cut = utf8.DecodeRuneInString(word)
+				}
+				spans = append(spans, word[:cut])
+				word = word[cut:]
+			}
+			spans = append(spans, word)
+		}
+	}
+
+	var (
+		parts   []string
+		current strings.Builder
+	)
+	for _, span := range spans {
+		if current.Len() > 0 && current.Len()+len(strings.TrimRightFunc(span, unicode.IsSpace)) > maxChars {
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+		current.WriteString(span)
+	}
+	if last := strings.TrimSpace(current.String()); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// splitAfter splits text after every byte where boundary is true. Joining the
+// pieces gives text back.
+func splitAfter(text string, boundary func(text string, i int) bool) []string {
+	var pieces []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if boundary(text, i) {
+			pieces = append(pieces, text[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
+
+// sentenceEnd reports whether a sentence ends at text[i]: a line break, or a
+// space after a full stop, question mark or exclamation mark
+func sentenceEnd(text string, i int) bool {
+	if text[i] == '\n' {
+		return true
+	}
+	return text[i] == ' ' && i > 0 && strings.IndexByte(".?!", text[i-1]) >= 0
+}
+
+// wordEnd reports whether a word ends at text[i]
+func wordEnd(text string, i int) bool {
+	return text[i] == ' ' || text[i] == '\t' || text[i] == '\n'
+}
@@ -0,0 +1,126 @@
+package retrieval
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunkMarkdown(t *testing.T) {
+	doc := `Intro text before any heading.
+
+# Guide
+
+Overview paragraph.
+
+## Setup
+
+Install the tools.
+
+` + "```sh\n# not a heading\nmake build\n```" + `
+
+### Windows
+
+Use WSL.
+
+## Usage
+
+Run it.
+`
+
+	chunks := ChunkMarkdown(doc, DefaultChunkSize)
+
+	want := []struct {
+		heading string
+		prefix  string
+	}{
+		{"", "Intro text"},
+		{"Guide", "Overview"},
+		{"Guide > Setup", "Install the tools."},
+		{"Guide > Setup > Windows", "Use WSL."},
+		{"Guide > Usage", "Run it."},
+	}
+
+	if len(chunks) != len(want) {
+		t.Fatalf("Expected %d chunks, got %d: %+v", len(want), len(chunks), chunks)
+	}
+	for i, w := range want {
+		if chunks[i].Index != i {
+			t.Errorf("Chunk %d has index %d", i, chunks[i].Index)
+		}
+		if chunks[i].Heading != w.heading {
+			t.Errorf("Chunk %d: expected heading %q, got %q", i, w.heading, chunks[i].Heading)
+		}
+		if !strings.HasPrefix(chunks[i].Text, w.prefix) {
+			t.Errorf("Chunk %d: expected text starting %q, got %q", i, w.prefix, chunks[i].Text)
+		}
+	}
+
+	if !strings.Contains(chunks[2].Text, "# not a heading") {
+		t.Errorf("Expected fenced code to stay in its section, got %q", chunks[2].Text)
+	}
+}
+
+func TestChunkMarkdown_SplitsLongSections(t *testing.T) {
+	para := strings.Repeat("word ", 30)
+	doc := "# Long\n\n" + para + "\n\n" + para + "\n\n" + para
+
+	chunks := ChunkMarkdown(doc, 2*len(para))
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if chunk.Heading != "Long" {
+			t.Errorf("Expected every piece to keep its heading, got %q", chunk.Heading)
+		}
+	}
+}
+
+func TestChunkMarkdown_SplitsLongParagraphs(t *testing.T) {
+	sentence := strings.TrimSpace(strings.Repeat("word ", 20)) + ". "
+	url := "https://example.com/" + strings.Repeat("é", 100)
+	para := strings.Repeat(sentence, 5) + strings.Repeat("x ", 200) + url
+	doc := "# Long\n\n" + para
+
+	maxChars := len(sentence) * 2
+	chunks := ChunkMarkdown(doc, maxChars)
+
+	var words []string
+	for _, chunk := range chunks {
+		if len(chunk.Text) > maxChars {
+			t.Errorf("Expected chunks of at most %d characters, got %d: %q", maxChars, len(chunk.Text), chunk.Text)
+		}
+		if !utf8.ValidString(chunk.Text) {
+			t.Errorf("Expected chunks to be cut between characters, got %q", chunk.Text)
+		}
+		words = append(words, strings.Fields(chunk.Text)...)
+	}
+
+	// Whole sentences stay together, and no text is lost or repeated
+	if chunks[0].Text != strings.TrimSpace(strings.Repeat(sentence, 2)) {
+		t.Errorf("Expected the first chunk to hold two whole sentences, got %q", chunks[0].Text)
+	}
+	if strings.Join(words, "") != strings.Join(strings.Fields(para), "") {
+		t.Errorf("Expected the chunks to hold the paragraph's text in order")
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		title string
+	}{
+		{"# Title", 1, "Title"},
+		{"### Closed ###", 3, "Closed"},
+		{"#hashtag", 0, ""},
+		{"####### too deep", 0, ""},
+		{"plain", 0, ""},
+	}
+	for _, tt := range tests {
+		level, title := parseHeading(tt.line)
+		if level != tt.level || title != tt.title {
+			t.Errorf("parseHeading(%q) = %d, %q; want %d, %q", tt.line, level, title, tt.level, tt.title)
+		}
+	}
+}
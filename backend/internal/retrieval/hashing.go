@@ -0,0 +1,71 @@
+package retrieval
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+
+	"tuitui-backend/internal/knowledge"
+)
+
+// DefaultDimensions is the vector size used by the hashing embedder
+const DefaultDimensions = 1024
+
+// HashingEmbedder embeds text offline using the hashing trick: each word and
+// word pair is hashed to a dimension, weighted by log term frequency, and the
+// vector is L2-normalised. It needs no model or network access.
+type HashingEmbedder struct {
+	Dimensions int
+}
+
+// NewHashingEmbedder creates a hashing embedder with the given vector size
+func NewHashingEmbedder(dimensions int) *HashingEmbedder {
+	return &HashingEmbedder{Dimensions: dimensions}
+}
+
+// Embed returns one vector per text
+func (e *HashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *HashingEmbedder) embed(text string) []float32 {
+	counts := map[string]int{}
+	words := knowledge.Terms(text)
+	for i, word := range words {
+		counts[word]++
+		if i > 0 {
+			counts[words[i-1]+" "+word]++
+		}
+	}
+
+	vector := make([]float32, e.Dimensions)
+	for feature, count := range counts {
+		h := fnv.New32a()
+		h.Write([]byte(feature))
+		sum := h.Sum32()
+
+		// The top bit picks a sign so that collisions tend to cancel out
+		weight := float32(1 + math.Log(float64(count)))
+		if sum&0x80000000 != 0 {
+			weight = -weight
+		}
+		vector[int(sum&0x7fffffff)%e.Dimensions] += weight
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+
+	return vector
+}
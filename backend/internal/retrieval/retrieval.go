@@ -0,0 +1,133 @@
+// Package retrieval selects the parts of an uploaded document that are relevant
+// to a question, so the chat prompt carries a few sections instead of the whole file.
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"tuitui-backend/internal/config"
+)
+
+// Supported embedders
+const (
+	EmbedderHashing = "hashing"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how related they are
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder creates the embedder selected by cfg.RetrievalEmbedder
+func NewEmbedder(cfg *config.Config) (Embedder, error) {
+	switch cfg.RetrievalEmbedder {
+	case EmbedderHashing, "":
+		return NewHashingEmbedder(DefaultDimensions), nil
+	default:
+		return nil, fmt.Errorf("unknown embedder %q", cfg.RetrievalEmbedder)
+	}
+}
+
+// Result is a chunk matched by a search
+type Result struct {
+	Chunk Chunk
+	Score float64
+}
+
+// Index holds embedded chunks of one or more documents
+type Index struct {
+	embedder Embedder
+	chunks   []Chunk
+	vectors  [][]float32
+}
+
+// NewIndex embeds chunks with embedder
+func NewIndex(ctx context.Context, embedder Embedder, chunks []Chunk) (*Index, error) {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Heading + "\n" + chunk.Text
+	}
+
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed chunks: %v", err)
+	}
+	if len(vectors) != len(chunks) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d chunks", len(vectors), len(chunks))
+	}
+
+	return &Index{embedder: embedder, chunks: chunks, vectors: vectors}, nil
+}
+
+// Search returns the k chunks most similar to query, best first
+func (ix *Index) Search(ctx context.Context, query string, k int) ([]Result, error) {
+	if k <= 0 || len(ix.chunks) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %v", err)
+	}
+
+	results := make([]Result, len(ix.chunks))
+	for i, chunk := range ix.chunks {
+		results[i] = Result{Chunk: chunk, Score: cosine(vectors[0], ix.vectors[i])}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Retrieve chunks document and returns the k chunks most relevant to query in
+// the order they appear in the document
+func Retrieve(ctx context.Context, embedder Embedder, document, query string, k int) ([]Chunk, error) {
+	chunks := ChunkMarkdown(document, DefaultChunkSize)
+	if len(chunks) <= k {
+		return chunks, nil
+	}
+
+	index, err := NewIndex(ctx, embedder, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := index.Search(ctx, query, k)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]Chunk, len(results))
+	for i, result := range results {
+		selected[i] = result.Chunk
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Index < selected[j].Index })
+
+	return selected, nil
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		dot += float64(a[i]) * float64(b[i])
+	}
+	for _, v := range a {
+		normA += float64(v) * float64(v)
+	}
+	for _, v := range b {
+		normB += float64(v) * float64(v)
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,114 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"tuitui-backend/internal/config"
+)
+
+const runbook = `# TuiTui runbook
+
+## Deployments
+
+Deploy with terraform apply from the infrastructure directory after running make build.
+
+## USL errors
+
+USL 502 errors come from the search results gateway. Check the flight search results dashboard.
+
+## Holidays
+
+Annual leave is booked through the HR portal.
+
+## Onboarding
+
+New starters need AWS access and a Cognito account.
+`
+
+func TestHashingEmbedder(t *testing.T) {
+	embedder := NewHashingEmbedder(DefaultDimensions)
+
+	vectors, err := embedder.Embed(context.Background(), []string{"USL 502 errors", "usl 502 ERRORS!", "annual leave", ""})
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+
+	if got := cosine(vectors[0], vectors[1]); math.Abs(got-1) > 1e-6 {
+		t.Errorf("Expected identical normalised text to have similarity 1, got %f", got)
+	}
+	if got := cosine(vectors[0], vectors[2]); got > 0.5 {
+		t.Errorf("Expected unrelated text to have low similarity, got %f", got)
+	}
+	if got := cosine(vectors[0], vectors[3]); got != 0 {
+		t.Errorf("Expected empty text to have similarity 0, got %f", got)
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	ctx := context.Background()
+	index, err := NewIndex(ctx, NewHashingEmbedder(DefaultDimensions), ChunkMarkdown(runbook, DefaultChunkSize))
+	if err != nil {
+		t.Fatalf("NewIndex returned error: %v", err)
+	}
+
+	results, err := index.Search(ctx, "Why am I seeing a USL 502?", 2)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Chunk.Heading != "TuiTui runbook > USL errors" {
+		t.Errorf("Expected USL section first, got %q", results[0].Chunk.Heading)
+	}
+	if results[0].Score < results[1].Score {
+		t.Error("Expected results ordered by score")
+	}
+}
+
+func TestRetrieve(t *testing.T) {
+	ctx := context.Background()
+	embedder := NewHashingEmbedder(DefaultDimensions)
+
+	chunks, err := Retrieve(ctx, embedder, runbook, "how do I deploy with terraform? also AWS access for new starters", 2)
+	if err != nil {
+		t.Fatalf("Retrieve returned error: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d", len(chunks))
+	}
+	if !strings.HasSuffix(chunks[0].Heading, "Deployments") || !strings.HasSuffix(chunks[1].Heading, "Onboarding") {
+		t.Errorf("Expected deployments then onboarding in document order, got %q, %q", chunks[0].Heading, chunks[1].Heading)
+	}
+
+	// Short documents are returned whole
+	chunks, err = Retrieve(ctx, embedder, "Just one paragraph.", "anything", 4)
+	if err != nil || len(chunks) != 1 {
+		t.Errorf("Expected the whole short document, got %v, %v", chunks, err)
+	}
+}
+
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, errors.New("embedding service unavailable")
+}
+
+func TestRetrieve_EmbedderError(t *testing.T) {
+	if _, err := Retrieve(context.Background(), failingEmbedder{}, runbook, "deploy", 1); err == nil {
+		t.Error("Expected embedder error to be returned")
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	if _, err := NewEmbedder(&config.Config{}); err != nil {
+		t.Errorf("Expected default embedder, got error: %v", err)
+	}
+	if _, err := NewEmbedder(&config.Config{RetrievalEmbedder: "unknown"}); err == nil {
+		t.Error("Expected error for unknown embedder")
+	}
+}
@@ -0,0 +1,70 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DynamoStore is a Store backed by a DynamoDB table with string key Key.
+// ExpiresAt is the table's TTL attribute; as DynamoDB deletes expired items
+// late, Get also checks it.
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{client: client, table: table, now: time.Now}
+}
+
+// Put records that key was revoked at revokedAt
+func (s *DynamoStore) Put(ctx context.Context, key string, revokedAt, expires time.Time) error {
+	_, err := s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item: map[string]*dynamodb.AttributeValue{
+			"Key":       {S: aws.String(key)},
+			"RevokedAt": {N: aws.String(strconv.FormatInt(revokedAt.Unix(), 10))},
+			"ExpiresAt": {N: aws.String(strconv.FormatInt(expires.Unix(), 10))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store revocation: %v", err)
+	}
+	return nil
+}
+
+// Get returns when key was revoked, or the zero time for a missing or expired entry
+func (s *DynamoStore) Get(ctx context.Context, key string) (time.Time, error) {
+	output, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Key": {S: aws.String(key)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get revocation: %v", err)
+	}
+
+	if output.Item == nil || s.now().Unix() >= numberAttribute(output.Item, "ExpiresAt") {
+		return time.Time{}, nil
+	}
+	return time.Unix(numberAttribute(output.Item, "RevokedAt"), 0), nil
+}
+
+// numberAttribute returns the numeric attribute name of item, or 0 when it is missing
+func numberAttribute(item map[string]*dynamodb.AttributeValue, name string) int64 {
+	if item[name] == nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(aws.StringValue(item[name].N), 10, 64)
+	return n
+}
@@ -0,0 +1,62 @@
+package revocation
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is a minimal in-memory table that stores the items the store puts
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	mu    sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+}
+
+func (f *fakeDynamo) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.items[aws.StringValue(input.Item["Key"].S)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return &dynamodb.GetItemOutput{Item: f.items[aws.StringValue(input.Key["Key"].S)]}, nil
+}
+
+func TestDynamoStore(t *testing.T) {
+	testStore(t, NewDynamoStore(newFakeDynamo(), "revoked-sessions"))
+}
+
+func TestDynamoStore_ExpiredItemNotYetDeleted(t *testing.T) {
+	client := newFakeDynamo()
+	store := NewDynamoStore(client, "revoked-sessions")
+	now := time.Date(2025, 4, 2, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	store.Put(context.Background(), "SESSION#abc", now, now.Add(time.Hour))
+	if got := aws.StringValue(client.items["SESSION#abc"]["ExpiresAt"].N); got != strconv.FormatInt(now.Add(time.Hour).Unix(), 10) {
+		t.Errorf("Expected ExpiresAt to be the TTL in Unix seconds, got %s", got)
+	}
+
+	// DynamoDB can take a while to delete items past their TTL
+	now = now.Add(time.Hour)
+	if at, _ := store.Get(context.Background(), "SESSION#abc"); !at.IsZero() {
+		t.Errorf("Expected an expired item to read as missing, got %v", at)
+	}
+}
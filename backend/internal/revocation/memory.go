@@ -0,0 +1,46 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	revokedAt time.Time
+	expires   time.Time
+}
+
+// MemoryStore is an in-memory Store for tests and local development. Entries
+// are not shared between Lambda instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]entry), now: time.Now}
+}
+
+// Put records that key was revoked at revokedAt
+func (s *MemoryStore) Put(ctx context.Context, key string, revokedAt, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = entry{revokedAt: revokedAt, expires: expires}
+	return nil
+}
+
+// Get returns when key was revoked, or the zero time for a missing or expired entry
+func (s *MemoryStore) Get(ctx context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || !s.now().Before(e.expires) {
+		return time.Time{}, nil
+	}
+	return e.revokedAt, nil
+}
@@ -0,0 +1,52 @@
+package revocation
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	revokedAt := time.Unix(1743595200, 0)
+	expires := time.Now().Add(time.Hour)
+
+	if at, err := store.Get(ctx, "missing"); err != nil || !at.IsZero() {
+		t.Errorf("Expected the zero time for a missing entry, got %v, %v", at, err)
+	}
+
+	if err := store.Put(ctx, "USER#user-1", revokedAt, expires); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if at, err := store.Get(ctx, "USER#user-1"); err != nil || !at.Equal(revokedAt) {
+		t.Errorf("Expected %v, got %v, %v", revokedAt, at, err)
+	}
+
+	if err := store.Put(ctx, "USER#user-1", revokedAt.Add(time.Minute), expires); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if at, _ := store.Get(ctx, "USER#user-1"); !at.Equal(revokedAt.Add(time.Minute)) {
+		t.Errorf("Expected a later revocation to replace the first, got %v", at)
+	}
+	if at, _ := store.Get(ctx, "USER#user-2"); !at.IsZero() {
+		t.Errorf("Expected entries to be independent, got %v", at)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2025, 4, 2, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	store.Put(context.Background(), "SESSION#abc", now, now.Add(time.Hour))
+	now = now.Add(time.Hour)
+
+	if at, _ := store.Get(context.Background(), "SESSION#abc"); !at.IsZero() {
+		t.Errorf("Expected an expired entry to read as missing, got %v", at)
+	}
+}
@@ -0,0 +1,164 @@
+// Package revocation records signed out Cognito sessions. The API Gateway
+// authorizer accepts an ID token until it expires, even after its refresh token
+// is revoked or its user signs out everywhere, so guarded Lambdas check the
+// caller's token against this list as well.
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+)
+
+// ErrNotConfigured is returned when no revoked sessions table is configured
+var ErrNotConfigured = errors.New("revocation storage not configured")
+
+// ErrNoSession is returned when revoking a token without an origin_jti claim,
+// which Cognito only adds when the app client has token revocation enabled
+var ErrNoSession = errors.New("token has no session to revoke")
+
+// TokenLifetime is the longest an ID or access token stays valid, matching the
+// user pool client in infrastructure/cognito.tf. Entries are kept this long, as
+// every token they reject has expired by then.
+const TokenLifetime = time.Hour
+
+// Store holds revocation times that expire once no token they apply to can
+// still be valid
+type Store interface {
+	// Put records that key was revoked at revokedAt
+	Put(ctx context.Context, key string, revokedAt, expires time.Time) error
+
+	// Get returns when key was revoked, or the zero time for a missing or expired entry
+	Get(ctx context.Context, key string) (time.Time, error)
+}
+
+// Session identifies the sign in a token was issued for
+type Session struct {
+	Sub string
+
+	// OriginJTI is shared by every token issued from one refresh token
+	OriginJTI string
+
+	IssuedAt time.Time
+}
+
+// SessionFromRequest returns the session of the caller's token, or false when
+// the request did not pass through the Cognito authorizer
+func SessionFromRequest(request events.APIGatewayProxyRequest) (Session, bool) {
+	user, ok := auth.UserFromRequest(request)
+	if !ok {
+		return Session{}, false
+	}
+
+	claims := auth.Claims(request)
+	s := Session{Sub: user.Sub}
+	s.OriginJTI, _ = claims["origin_jti"].(string)
+	s.IssuedAt = parseClaimTime(claims["iat"])
+	return s, true
+}
+
+// claimTimeLayout is how the REST API Cognito authorizer formats iat and exp
+const claimTimeLayout = "Mon Jan 02 15:04:05 MST 2006"
+
+// parseClaimTime reads a time claim given as Unix seconds or in the
+// authorizer's date format, returning the zero time when it is neither
+func parseClaimTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0)
+	case json.Number:
+		if seconds, err := v.Int64(); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	case string:
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+		if t, err := time.Parse(claimTimeLayout, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// List checks sessions against the revocations in a store
+type List struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewList creates a list over store
+func NewList(store Store) *List {
+	return &List{store: store, now: time.Now}
+}
+
+// New creates a list from configuration, backed by the configured DynamoDB table
+func New(cfg *config.Config) (*List, error) {
+	if cfg.RevokedSessionsTable == "" {
+		return nil, ErrNotConfigured
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.AWSRegion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return NewList(NewDynamoStore(dynamodb.New(sess), cfg.RevokedSessionsTable)), nil
+}
+
+// RevokeSession rejects every token issued from the same refresh token as s
+func (l *List) RevokeSession(ctx context.Context, s Session) error {
+	if s.OriginJTI == "" {
+		return ErrNoSession
+	}
+	now := l.now()
+	return l.store.Put(ctx, sessionKey(s.OriginJTI), now, now.Add(TokenLifetime))
+}
+
+// RevokeUser rejects every token issued to user sub until now. Tokens issued
+// in the same second are still accepted, so signing straight back in works.
+func (l *List) RevokeUser(ctx context.Context, sub string) error {
+	now := l.now().Truncate(time.Second)
+	return l.store.Put(ctx, userKey(sub), now, now.Add(TokenLifetime))
+}
+
+// Revoked reports whether s was signed out, alone or with all of its user's sessions
+func (l *List) Revoked(ctx context.Context, s Session) (bool, error) {
+	if s.OriginJTI != "" {
+		revokedAt, err := l.store.Get(ctx, sessionKey(s.OriginJTI))
+		if err != nil {
+			return false, err
+		}
+		if !revokedAt.IsZero() {
+			return true, nil
+		}
+	}
+
+	revokedAt, err := l.store.Get(ctx, userKey(s.Sub))
+	if err != nil {
+		return false, err
+	}
+	return !revokedAt.IsZero() && s.IssuedAt.Before(revokedAt), nil
+}
+
+// sessionKey is the store key of a revoked refresh token
+func sessionKey(originJTI string) string {
+	return "SESSION#" + originJTI
+}
+
+// userKey is the store key of a user's global sign out
+func userKey(sub string) string {
+	return "USER#" + sub
+}
@@ -0,0 +1,133 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"tuitui-backend/internal/config"
+)
+
+// newTestList returns a list over a memory store whose clock is *now
+func newTestList(now *time.Time) *List {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return *now }
+	list := NewList(store)
+	list.now = func() time.Time { return *now }
+	return list
+}
+
+func requestWithClaims(claims map[string]interface{}) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": claims,
+			},
+		},
+	}
+}
+
+func TestSessionFromRequest(t *testing.T) {
+	issued := time.Date(2025, 4, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		iat  interface{}
+	}{
+		{"authorizer date", "Wed Apr 02 12:00:00 UTC 2025"},
+		{"unix seconds", "1743595200"},
+		{"number", float64(1743595200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := SessionFromRequest(requestWithClaims(map[string]interface{}{
+				"sub":        "user-1",
+				"origin_jti": "origin-1",
+				"iat":        tt.iat,
+			}))
+			if !ok {
+				t.Fatal("Expected a session")
+			}
+			if s.Sub != "user-1" || s.OriginJTI != "origin-1" || !s.IssuedAt.Equal(issued) {
+				t.Errorf("Unexpected session: %+v", s)
+			}
+		})
+	}
+
+	if _, ok := SessionFromRequest(events.APIGatewayProxyRequest{}); ok {
+		t.Error("Expected no session without authorizer context")
+	}
+}
+
+func TestList_RevokeSession(t *testing.T) {
+	now := time.Date(2025, 4, 2, 12, 0, 0, 0, time.UTC)
+	list := newTestList(&now)
+	ctx := context.Background()
+
+	revoked := Session{Sub: "user-1", OriginJTI: "origin-1", IssuedAt: now.Add(-time.Minute)}
+	other := Session{Sub: "user-1", OriginJTI: "origin-2", IssuedAt: now.Add(-time.Minute)}
+
+	if err := list.RevokeSession(ctx, revoked); err != nil {
+		t.Fatalf("RevokeSession returned error: %v", err)
+	}
+
+	if ok, err := list.Revoked(ctx, revoked); err != nil || !ok {
+		t.Errorf("Expected the session to be revoked, got %v, %v", ok, err)
+	}
+	if ok, _ := list.Revoked(ctx, other); ok {
+		t.Error("Expected the user's other sessions to stay valid")
+	}
+
+	// Every token of the session has expired once the entry does
+	now = now.Add(TokenLifetime)
+	if ok, _ := list.Revoked(ctx, revoked); ok {
+		t.Error("Expected the entry to expire after the token lifetime")
+	}
+
+	if err := list.RevokeSession(ctx, Session{Sub: "user-1"}); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Expected ErrNoSession without origin_jti, got %v", err)
+	}
+}
+
+func TestList_RevokeUser(t *testing.T) {
+	now := time.Date(2025, 4, 2, 12, 0, 0, 500, time.UTC)
+	list := newTestList(&now)
+	ctx := context.Background()
+
+	if err := list.RevokeUser(ctx, "user-1"); err != nil {
+		t.Fatalf("RevokeUser returned error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		session Session
+		revoked bool
+	}{
+		{"earlier token", Session{Sub: "user-1", OriginJTI: "a", IssuedAt: now.Add(-time.Minute)}, true},
+		{"token without origin", Session{Sub: "user-1", IssuedAt: now.Add(-time.Minute)}, true},
+		{"token issued in the same second", Session{Sub: "user-1", OriginJTI: "b", IssuedAt: now.Truncate(time.Second)}, false},
+		{"later token", Session{Sub: "user-1", OriginJTI: "c", IssuedAt: now.Add(time.Minute)}, false},
+		{"other user", Session{Sub: "user-2", OriginJTI: "d", IssuedAt: now.Add(-time.Minute)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := list.Revoked(ctx, tt.session)
+			if err != nil {
+				t.Fatalf("Revoked returned error: %v", err)
+			}
+			if ok != tt.revoked {
+				t.Errorf("Expected revoked %v, got %v", tt.revoked, ok)
+			}
+		})
+	}
+}
+
+func TestNew_NotConfigured(t *testing.T) {
+	if _, err := New(&config.Config{}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Expected ErrNotConfigured, got %v", err)
+	}
+}
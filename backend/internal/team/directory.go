@@ -0,0 +1,75 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"tuitui-backend/internal/auth"
+	"tuitui-backend/internal/config"
+)
+
+// ErrUserNotFound is returned when no Cognito user has the email address
+var ErrUserNotFound = errors.New("user not found")
+
+// Directory finds Cognito users, so members can be added by email address
+type Directory interface {
+	// LookupEmail returns the sub of the user with the email address, or ErrUserNotFound
+	LookupEmail(ctx context.Context, email string) (string, error)
+}
+
+// CognitoDirectory is a Directory over a Cognito user pool
+type CognitoDirectory struct {
+	client     cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	userPoolID string
+}
+
+// NewCognitoDirectory creates a directory for the user pool
+func NewCognitoDirectory(client cognitoidentityprovideriface.CognitoIdentityProviderAPI, userPoolID string) *CognitoDirectory {
+	return &CognitoDirectory{client: client, userPoolID: userPoolID}
+}
+
+// NewDirectory creates the directory for the user pool in cfg
+func NewDirectory(cfg *config.Config) (Directory, error) {
+	if cfg.CognitoUserPoolID == "" {
+		return nil, fmt.Errorf("Cognito user pool not configured")
+	}
+
+	client, err := auth.NewCognitoClient(cfg, auth.NewHTTPClient())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCognitoDirectory(client, cfg.CognitoUserPoolID), nil
+}
+
+// LookupEmail returns the sub of the user with the email address, or ErrUserNotFound
+func (d *CognitoDirectory) LookupEmail(ctx context.Context, email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || strings.ContainsAny(email, `"\`) {
+		return "", ErrUserNotFound
+	}
+
+	output, err := d.client.ListUsersWithContext(ctx, &cognitoidentityprovider.ListUsersInput{
+		UserPoolId:      aws.String(d.userPoolID),
+		Filter:          aws.String(fmt.Sprintf("email = %q", email)),
+		AttributesToGet: []*string{aws.String("sub")},
+		Limit:           aws.Int64(1),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up user: %v", err)
+	}
+
+	for _, user := range output.Users {
+		for _, attr := range user.Attributes {
+			if aws.StringValue(attr.Name) == "sub" {
+				return aws.StringValue(attr.Value), nil
+			}
+		}
+	}
+	return "", ErrUserNotFound
+}
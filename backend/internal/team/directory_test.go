@@ -0,0 +1,55 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// fakeCognito answers ListUsers from a map of email to sub
+type fakeCognito struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	users   map[string]string
+	filters []string
+}
+
+func (f *fakeCognito) ListUsersWithContext(ctx aws.Context, input *cognitoidentityprovider.ListUsersInput, opts ...request.Option) (*cognitoidentityprovider.ListUsersOutput, error) {
+	f.filters = append(f.filters, aws.StringValue(input.Filter))
+
+	output := &cognitoidentityprovider.ListUsersOutput{}
+	for email, sub := range f.users {
+		if aws.StringValue(input.Filter) == `email = "`+email+`"` {
+			output.Users = append(output.Users, &cognitoidentityprovider.UserType{
+				Attributes: []*cognitoidentityprovider.AttributeType{{Name: aws.String("sub"), Value: aws.String(sub)}},
+			})
+		}
+	}
+	return output, nil
+}
+
+func TestCognitoDirectory_LookupEmail(t *testing.T) {
+	client := &fakeCognito{users: map[string]string{"dev@tui.co.uk": "user-1"}}
+	directory := NewCognitoDirectory(client, "pool")
+
+	sub, err := directory.LookupEmail(context.Background(), " dev@tui.co.uk ")
+	if err != nil || sub != "user-1" {
+		t.Errorf("Expected user-1, got %q, %v", sub, err)
+	}
+
+	if _, err := directory.LookupEmail(context.Background(), "nobody@tui.co.uk"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	// Quotes would change the meaning of the filter, so they are never sent
+	if _, err := directory.LookupEmail(context.Background(), `x" or email ^= "`); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if len(client.filters) != 2 {
+		t.Errorf("Expected 2 lookups, got %v", client.filters)
+	}
+}
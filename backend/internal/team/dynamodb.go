@@ -0,0 +1,392 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Key layout: each team has the partition "TEAM#<id>" holding the team under the
+// sort key "TEAM" and one "MEMBER#<sub>" item per member. Member items carry
+// UserID and TeamID, which key the UserTeamsIndex used to find a user's teams.
+const (
+	teamPrefix   = "TEAM#"
+	teamSortKey  = "TEAM"
+	memberPrefix = "MEMBER#"
+
+	// UserTeamsIndex is the global secondary index on member items by UserID and TeamID
+	UserTeamsIndex = "UserTeams"
+
+	// batchWriteLimit is the maximum number of requests in one BatchWriteItem call
+	batchWriteLimit = 25
+)
+
+// DynamoStore is a Store backed by a single DynamoDB table with string keys PK and SK
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{
+		client: client,
+		table:  table,
+		now:    time.Now,
+	}
+}
+
+// teamItem is the DynamoDB representation of a Team
+type teamItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Team
+}
+
+// memberItem is the DynamoDB representation of a Member
+type memberItem struct {
+	PK string `dynamodbav:"PK"`
+	SK string `dynamodbav:"SK"`
+	Member
+}
+
+func teamKey(id string) string {
+	return teamPrefix + id
+}
+
+func itemKey(pk, sk string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String(pk)},
+		"SK": {S: aws.String(sk)},
+	}
+}
+
+// List returns every team ordered by ID. There are few teams, so this scans the table.
+func (s *DynamoStore) List(ctx context.Context) ([]Team, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	err := s.client.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName:        aws.String(s.table),
+		FilterExpression: aws.String("SK = :sk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":sk": {S: aws.String(teamSortKey)},
+		},
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %v", err)
+	}
+
+	teams := []Team{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &teams); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal teams: %v", err)
+	}
+	for i := range teams {
+		teams[i] = normalize(teams[i])
+	}
+	sortTeams(teams)
+
+	return teams, nil
+}
+
+// Get returns a single team or ErrNotFound
+func (s *DynamoStore) Get(ctx context.Context, id string) (*Team, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       itemKey(teamKey(id), teamSortKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %v", err)
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var team Team
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &team); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal team: %v", err)
+	}
+
+	team = normalize(team)
+	return &team, nil
+}
+
+// Create adds a new team, deriving its ID from the name when empty
+func (s *DynamoStore) Create(ctx context.Context, team Team) (*Team, error) {
+	if err := team.Validate(); err != nil {
+		return nil, err
+	}
+
+	team = normalize(team)
+	team.CreatedAt = s.now().UTC()
+	team.UpdatedAt = team.CreatedAt
+	if err := s.putTeam(ctx, team, "attribute_not_exists(PK)"); err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrExists
+		}
+		return nil, fmt.Errorf("failed to create team: %v", err)
+	}
+
+	return &team, nil
+}
+
+// Update replaces an existing team's settings or returns ErrNotFound
+func (s *DynamoStore) Update(ctx context.Context, team Team) (*Team, error) {
+	if err := team.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.Get(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	team = normalize(team)
+	team.CreatedAt = existing.CreatedAt
+	team.UpdatedAt = s.now().UTC()
+	if err := s.putTeam(ctx, team, "attribute_exists(PK)"); err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update team: %v", err)
+	}
+
+	return &team, nil
+}
+
+// Delete removes a team and all of its memberships
+func (s *DynamoStore) Delete(ctx context.Context, id string) error {
+	items, err := s.query(ctx, teamKey(id), "")
+	if err != nil {
+		return fmt.Errorf("failed to load team: %v", err)
+	}
+	if len(items) == 0 {
+		return ErrNotFound
+	}
+
+	requests := make([]*dynamodb.WriteRequest, len(items))
+	for i, item := range items {
+		requests[i] = &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{
+				Key: map[string]*dynamodb.AttributeValue{"PK": item["PK"], "SK": item["SK"]},
+			},
+		}
+	}
+
+	if err := s.batchWrite(ctx, requests); err != nil {
+		return fmt.Errorf("failed to delete team: %v", err)
+	}
+
+	return nil
+}
+
+// ListMembers returns the team's members ordered by user ID
+func (s *DynamoStore) ListMembers(ctx context.Context, teamID string) ([]Member, error) {
+	if _, err := s.Get(ctx, teamID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.query(ctx, teamKey(teamID), memberPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %v", err)
+	}
+
+	members := []Member{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &members); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal team members: %v", err)
+	}
+	sortMembers(members, false)
+
+	return members, nil
+}
+
+// GetMember returns a user's membership or ErrMemberNotFound
+func (s *DynamoStore) GetMember(ctx context.Context, teamID, userID string) (*Member, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       itemKey(teamKey(teamID), memberPrefix+userID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team member: %v", err)
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrMemberNotFound
+	}
+
+	var member Member
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &member); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal team member: %v", err)
+	}
+
+	return &member, nil
+}
+
+// PutMember adds a member or changes their role; ErrNotFound if the team does not exist
+func (s *DynamoStore) PutMember(ctx context.Context, member Member) (*Member, error) {
+	if err := member.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.Get(ctx, member.TeamID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetMember(ctx, member.TeamID, member.UserID)
+	switch {
+	case err == nil:
+		member.AddedAt = existing.AddedAt
+	case errors.Is(err, ErrMemberNotFound):
+		member.AddedAt = s.now().UTC()
+	default:
+		return nil, err
+	}
+
+	item, err := dynamodbattribute.MarshalMap(memberItem{
+		PK:     teamKey(member.TeamID),
+		SK:     memberPrefix + member.UserID,
+		Member: member,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal team member: %v", err)
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store team member: %v", err)
+	}
+
+	return &member, nil
+}
+
+// RemoveMember removes a user from the team or returns ErrMemberNotFound
+func (s *DynamoStore) RemoveMember(ctx context.Context, teamID, userID string) error {
+	_, err := s.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 itemKey(teamKey(teamID), memberPrefix+userID),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return ErrMemberNotFound
+		}
+		return fmt.Errorf("failed to remove team member: %v", err)
+	}
+
+	return nil
+}
+
+// Memberships returns the teams a user belongs to, ordered by team ID
+func (s *DynamoStore) Memberships(ctx context.Context, userID string) ([]Member, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		IndexName:              aws.String(UserTeamsIndex),
+		KeyConditionExpression: aws.String("UserID = :user"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user": {S: aws.String(userID)},
+		},
+	}
+
+	err := s.client.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %v", err)
+	}
+
+	memberships := []Member{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &memberships); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal memberships: %v", err)
+	}
+	sortMembers(memberships, true)
+
+	return memberships, nil
+}
+
+// putTeam writes team, failing if condition does not hold
+func (s *DynamoStore) putTeam(ctx context.Context, team Team, condition string) error {
+	item, err := dynamodbattribute.MarshalMap(teamItem{
+		PK:   teamKey(team.ID),
+		SK:   teamSortKey,
+		Team: team,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                item,
+		ConditionExpression: aws.String(condition),
+	})
+	return err
+}
+
+// query returns every item in the partition whose sort key starts with prefix
+func (s *DynamoStore) query(ctx context.Context, pk, prefix string) ([]map[string]*dynamodb.AttributeValue, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String(pk)},
+		},
+	}
+	if prefix != "" {
+		input.KeyConditionExpression = aws.String("PK = :pk AND begins_with(SK, :prefix)")
+		input.ExpressionAttributeValues[":prefix"] = &dynamodb.AttributeValue{S: aws.String(prefix)}
+	}
+
+	err := s.client.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// batchWrite sends write requests in chunks and retries unprocessed items
+func (s *DynamoStore) batchWrite(ctx context.Context, requests []*dynamodb.WriteRequest) error {
+	for start := 0; start < len(requests); start += batchWriteLimit {
+		end := min(start+batchWriteLimit, len(requests))
+
+		pending := map[string][]*dynamodb.WriteRequest{s.table: requests[start:end]}
+		for len(pending) > 0 {
+			result, err := s.client.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: pending,
+			})
+			if err != nil {
+				return err
+			}
+			pending = result.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// isConditionFailed reports whether err is a failed DynamoDB condition check
+func isConditionFailed(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package team
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is a minimal in-memory table that understands the operations the
+// store issues against a PK/SK table and its UserTeams index
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	mu    sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+}
+
+func fakeKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["PK"].S) + "|" + aws.StringValue(key["SK"].S)
+}
+
+func conditionFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+}
+
+// checkCondition evaluates the attribute_exists/attribute_not_exists conditions the store uses
+func (f *fakeDynamo) checkCondition(condition *string, key string) error {
+	_, exists := f.items[key]
+	switch aws.StringValue(condition) {
+	case "attribute_exists(PK)":
+		if !exists {
+			return conditionFailed()
+		}
+	case "attribute_not_exists(PK)":
+		if exists {
+			return conditionFailed()
+		}
+	}
+	return nil
+}
+
+func (f *fakeDynamo) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fakeKey(input.Item)
+	if err := f.checkCondition(input.ConditionExpression, key); err != nil {
+		return nil, err
+	}
+	f.items[key] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[fakeKey(input.Key)]}, nil
+}
+
+func (f *fakeDynamo) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fakeKey(input.Key)
+	if err := f.checkCondition(input.ConditionExpression, key); err != nil {
+		return nil, err
+	}
+	delete(f.items, key)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (f *fakeDynamo) ScanPagesWithContext(ctx aws.Context, input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool, opts ...request.Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sk := aws.StringValue(input.ExpressionAttributeValues[":sk"].S)
+
+	output := &dynamodb.ScanOutput{}
+	for _, item := range f.items {
+		if aws.StringValue(item["SK"].S) == sk {
+			output.Items = append(output.Items, item)
+		}
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeDynamo) QueryPagesWithContext(ctx aws.Context, input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, opts ...request.Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := input.ExpressionAttributeValues
+
+	var keys []string
+	for key, item := range f.items {
+		if aws.StringValue(input.IndexName) == UserTeamsIndex {
+			if item["UserID"] != nil && aws.StringValue(item["UserID"].S) == aws.StringValue(values[":user"].S) {
+				keys = append(keys, key)
+			}
+			continue
+		}
+
+		prefix := ""
+		if values[":prefix"] != nil {
+			prefix = aws.StringValue(values[":prefix"].S)
+		}
+		if strings.HasPrefix(key, aws.StringValue(values[":pk"].S)+"|"+prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	output := &dynamodb.QueryOutput{}
+	for _, key := range keys {
+		output.Items = append(output.Items, f.items[key])
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeDynamo) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, requests := range input.RequestItems {
+		if len(requests) > batchWriteLimit {
+			return nil, awserr.New("ValidationException", "too many items", nil)
+		}
+		for _, req := range requests {
+			if req.DeleteRequest != nil {
+				delete(f.items, fakeKey(req.DeleteRequest.Key))
+			}
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func TestDynamoStore(t *testing.T) {
+	testStore(t, NewDynamoStore(newFakeDynamo(), "teams"))
+}
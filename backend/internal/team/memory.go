@@ -0,0 +1,186 @@
+package team
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store for tests and local development
+type MemoryStore struct {
+	mu      sync.Mutex
+	teams   map[string]Team
+	members map[string]map[string]Member
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a store holding teams
+func NewMemoryStore(teams ...Team) *MemoryStore {
+	s := &MemoryStore{
+		teams:   make(map[string]Team),
+		members: make(map[string]map[string]Member),
+		now:     time.Now,
+	}
+	for _, team := range teams {
+		team = normalize(team)
+		s.teams[team.ID] = team
+	}
+	return s
+}
+
+// List returns every team ordered by ID
+func (s *MemoryStore) List(ctx context.Context) ([]Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	teams := make([]Team, 0, len(s.teams))
+	for _, team := range s.teams {
+		teams = append(teams, team)
+	}
+	sortTeams(teams)
+	return teams, nil
+}
+
+// Get returns a single team or ErrNotFound
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teams[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &team, nil
+}
+
+// Create adds a new team, deriving its ID from the name when empty
+func (s *MemoryStore) Create(ctx context.Context, team Team) (*Team, error) {
+	if err := team.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team = normalize(team)
+	if _, ok := s.teams[team.ID]; ok {
+		return nil, ErrExists
+	}
+	team.CreatedAt = s.now().UTC()
+	team.UpdatedAt = team.CreatedAt
+	s.teams[team.ID] = team
+	return &team, nil
+}
+
+// Update replaces an existing team's settings or returns ErrNotFound
+func (s *MemoryStore) Update(ctx context.Context, team Team) (*Team, error) {
+	if err := team.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team = normalize(team)
+	existing, ok := s.teams[team.ID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	team.CreatedAt = existing.CreatedAt
+	team.UpdatedAt = s.now().UTC()
+	s.teams[team.ID] = team
+	return &team, nil
+}
+
+// Delete removes a team and all of its memberships
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.teams, id)
+	delete(s.members, id)
+	return nil
+}
+
+// ListMembers returns the team's members ordered by user ID
+func (s *MemoryStore) ListMembers(ctx context.Context, teamID string) ([]Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[teamID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	members := []Member{}
+	for _, member := range s.members[teamID] {
+		members = append(members, member)
+	}
+	sortMembers(members, false)
+	return members, nil
+}
+
+// GetMember returns a user's membership or ErrMemberNotFound
+func (s *MemoryStore) GetMember(ctx context.Context, teamID, userID string) (*Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[teamID][userID]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+	return &member, nil
+}
+
+// PutMember adds a member or changes their role; ErrNotFound if the team does not exist
+func (s *MemoryStore) PutMember(ctx context.Context, member Member) (*Member, error) {
+	if err := member.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[member.TeamID]; !ok {
+		return nil, ErrNotFound
+	}
+	if s.members[member.TeamID] == nil {
+		s.members[member.TeamID] = make(map[string]Member)
+	}
+	if existing, ok := s.members[member.TeamID][member.UserID]; ok {
+		member.AddedAt = existing.AddedAt
+	} else {
+		member.AddedAt = s.now().UTC()
+	}
+	s.members[member.TeamID][member.UserID] = member
+	return &member, nil
+}
+
+// RemoveMember removes a user from the team or returns ErrMemberNotFound
+func (s *MemoryStore) RemoveMember(ctx context.Context, teamID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[teamID][userID]; !ok {
+		return ErrMemberNotFound
+	}
+	delete(s.members[teamID], userID)
+	return nil
+}
+
+// Memberships returns the teams a user belongs to, ordered by team ID
+func (s *MemoryStore) Memberships(ctx context.Context, userID string) ([]Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	memberships := []Member{}
+	for _, members := range s.members {
+		if member, ok := members[userID]; ok {
+			memberships = append(memberships, member)
+		}
+	}
+	sortMembers(memberships, true)
+	return memberships, nil
+}
@@ -0,0 +1,187 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	created, err := store.Create(ctx, Team{
+		Name:         "Syntax Swing",
+		SystemPrompt: "We own search results.",
+		Documents:    []Document{{Name: "Runbook", Content: "# Search\nRestart the indexer."}},
+		Model:        ModelSettings{MaxTokens: 2048},
+	})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if created.ID != "syntax-swing" || created.CreatedAt.IsZero() || created.Links == nil {
+		t.Errorf("Expected ID from the name, timestamps and empty links, got %+v", created)
+	}
+
+	if _, err := store.Create(ctx, Team{Name: "Syntax  Swing"}); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+	if _, err := store.Create(ctx, Team{Name: " "}); err == nil {
+		t.Error("Expected a validation error for a team without a name")
+	}
+	if _, err := store.Create(ctx, Team{ID: "marvels", Name: "Marvels"}); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	got, err := store.Get(ctx, "syntax-swing")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got.SystemPrompt != "We own search results." || len(got.Documents) != 1 || got.Model.MaxTokens != 2048 {
+		t.Errorf("Unexpected team: %+v", got)
+	}
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	got.Description = "Search squad"
+	updated, err := store.Update(ctx, *got)
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if updated.Description != "Search squad" || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Expected the update to keep the creation time, got %+v", updated)
+	}
+	if _, err := store.Update(ctx, Team{ID: "missing", Name: "Missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	teams, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(teams) != 2 || teams[0].ID != "marvels" || teams[1].ID != "syntax-swing" {
+		t.Errorf("Unexpected teams: %+v", teams)
+	}
+
+	// Members
+	if _, err := store.PutMember(ctx, Member{TeamID: "syntax-swing", UserID: "user-1", Email: "one@tui.co.uk", Role: RoleAdmin}); err != nil {
+		t.Fatalf("PutMember returned error: %v", err)
+	}
+	if _, err := store.PutMember(ctx, Member{TeamID: "syntax-swing", UserID: "user-2", Role: RoleMember}); err != nil {
+		t.Fatalf("PutMember returned error: %v", err)
+	}
+	if _, err := store.PutMember(ctx, Member{TeamID: "marvels", UserID: "user-1", Role: RoleMember}); err != nil {
+		t.Fatalf("PutMember returned error: %v", err)
+	}
+	if _, err := store.PutMember(ctx, Member{TeamID: "missing", UserID: "user-1", Role: RoleMember}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing team, got %v", err)
+	}
+	if _, err := store.PutMember(ctx, Member{TeamID: "marvels", UserID: "user-1", Role: "owner"}); err == nil {
+		t.Error("Expected a validation error for an unknown role")
+	}
+
+	members, err := store.ListMembers(ctx, "syntax-swing")
+	if err != nil {
+		t.Fatalf("ListMembers returned error: %v", err)
+	}
+	if len(members) != 2 || members[0].UserID != "user-1" || !members[0].IsAdmin() || members[1].IsAdmin() {
+		t.Errorf("Unexpected members: %+v", members)
+	}
+
+	// Changing a role keeps the original join time
+	promoted, err := store.PutMember(ctx, Member{TeamID: "syntax-swing", UserID: "user-2", Role: RoleAdmin})
+	if err != nil || !promoted.AddedAt.Equal(members[1].AddedAt) {
+		t.Errorf("Expected the join time to be kept, got %+v, %v", promoted, err)
+	}
+
+	memberships, err := store.Memberships(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("Memberships returned error: %v", err)
+	}
+	if len(memberships) != 2 || memberships[0].TeamID != "marvels" || memberships[1].TeamID != "syntax-swing" {
+		t.Errorf("Unexpected memberships: %+v", memberships)
+	}
+
+	if err := store.RemoveMember(ctx, "syntax-swing", "user-2"); err != nil {
+		t.Fatalf("RemoveMember returned error: %v", err)
+	}
+	if _, err := store.GetMember(ctx, "syntax-swing", "user-2"); !errors.Is(err, ErrMemberNotFound) {
+		t.Errorf("Expected ErrMemberNotFound after removal, got %v", err)
+	}
+	if err := store.RemoveMember(ctx, "syntax-swing", "user-2"); !errors.Is(err, ErrMemberNotFound) {
+		t.Errorf("Expected ErrMemberNotFound, got %v", err)
+	}
+
+	// Deleting a team removes its memberships
+	if err := store.Delete(ctx, "syntax-swing"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if err := store.Delete(ctx, "syntax-swing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	memberships, _ = store.Memberships(ctx, "user-1")
+	if len(memberships) != 1 || memberships[0].TeamID != "marvels" {
+		t.Errorf("Expected only the marvels membership to remain, got %+v", memberships)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(Team{Name: "Syntax Swing"}, Team{Name: "Marvels"}, Team{Name: "Payments"})
+	store.PutMember(ctx, Member{TeamID: "syntax-swing", UserID: "user-1", Role: RoleMember})
+	store.PutMember(ctx, Member{TeamID: "marvels", UserID: "user-1", Role: RoleMember})
+
+	for _, tc := range []struct {
+		requested string
+		want      string
+	}{
+		{"", "marvels"},
+		{"syntax-swing", "syntax-swing"},
+		{"Syntax Swing", "syntax-swing"},
+	} {
+		got, err := Resolve(ctx, store, "user-1", tc.requested)
+		if err != nil || got == nil || got.ID != tc.want {
+			t.Errorf("Resolve(%q) = %+v, %v; want %s", tc.requested, got, err, tc.want)
+		}
+	}
+
+	if _, err := Resolve(ctx, store, "user-1", "Payments"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Expected ErrNotMember for another team, got %v", err)
+	}
+	if got, err := Resolve(ctx, store, "user-1", "Frontend Guild"); got != nil || err != nil {
+		t.Errorf("Expected no team for a label no team has, got %+v, %v", got, err)
+	}
+	for _, requested := range []string{"", "Payments"} {
+		if got, err := Resolve(ctx, store, "user-2", requested); got != nil || err != nil {
+			t.Errorf("Expected no team for a user without memberships asking for %q, got %+v, %v", requested, got, err)
+		}
+	}
+}
+
+func TestCanManage(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(Team{Name: "Marvels"})
+	store.PutMember(ctx, Member{TeamID: "marvels", UserID: "lead", Role: RoleAdmin})
+	store.PutMember(ctx, Member{TeamID: "marvels", UserID: "dev", Role: RoleMember})
+
+	for _, tc := range []struct {
+		user        string
+		globalAdmin bool
+		want        bool
+	}{
+		{"lead", false, true},
+		{"dev", false, false},
+		{"stranger", false, false},
+		{"stranger", true, true},
+	} {
+		got, err := CanManage(ctx, store, tc.user, tc.globalAdmin, "marvels")
+		if err != nil || got != tc.want {
+			t.Errorf("CanManage(%s, %v) = %v, %v; want %v", tc.user, tc.globalAdmin, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Package team manages teams, their Cognito members and team admins, and the
+// assistant configuration the chat handler applies to each member's questions.
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/config"
+	"tuitui-backend/internal/knowledge"
+	"tuitui-backend/internal/prompt"
+)
+
+var (
+	// ErrNotFound is returned when a team does not exist
+	ErrNotFound = errors.New("team not found")
+
+	// ErrExists is returned when creating a team whose ID is already taken
+	ErrExists = errors.New("team already exists")
+
+	// ErrMemberNotFound is returned when a user is not a member of the team
+	ErrMemberNotFound = errors.New("team member not found")
+
+	// ErrNotConfigured is returned when no teams table is configured
+	ErrNotConfigured = errors.New("team storage not configured")
+)
+
+// Member roles. Team admins can edit their team and manage its members.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
+// Document is reference material added to every question asked by the team's members
+type Document struct {
+	Name    string `json:"name" dynamodbav:"Name"`
+	Content string `json:"content" dynamodbav:"Content"`
+}
+
+// ModelSettings override the configured model for the team's chats; zero values keep the default
+type ModelSettings struct {
+	Model     string `json:"model,omitempty" dynamodbav:"Model,omitempty"`
+	MaxTokens int    `json:"max_tokens,omitempty" dynamodbav:"MaxTokens,omitempty"`
+}
+
+// Team is a group of users sharing an assistant configuration
+type Team struct {
+	ID          string `json:"id" dynamodbav:"ID"`
+	Name        string `json:"name" dynamodbav:"Name"`
+	Description string `json:"description" dynamodbav:"Description"`
+
+	// SystemPrompt is added to the system prompt of every chat by a member
+	SystemPrompt string `json:"system_prompt" dynamodbav:"SystemPrompt"`
+
+	// PromptTemplate names the prompt template the team's chats use; empty uses the default
+	PromptTemplate string `json:"prompt_template" dynamodbav:"PromptTemplate"`
+
+	// Links are the team's runbooks, offered to the model through the runbook tool
+	Links []string `json:"links" dynamodbav:"Links"`
+
+	// Documents are searched for sections relevant to each question
+	Documents []Document `json:"documents" dynamodbav:"Documents"`
+
+	Model ModelSettings `json:"model" dynamodbav:"Model"`
+
+	CreatedAt time.Time `json:"created_at" dynamodbav:"CreatedAt"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"UpdatedAt"`
+	UpdatedBy string    `json:"updated_by,omitempty" dynamodbav:"UpdatedBy"`
+}
+
+// Validate checks that the team has the fields every team needs
+func (t *Team) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if t.Model.MaxTokens < 0 {
+		return fmt.Errorf("max tokens must not be negative")
+	}
+	if t.PromptTemplate != "" && !prompt.ValidName(t.PromptTemplate) {
+		return fmt.Errorf("prompt template %q is not a valid template name", t.PromptTemplate)
+	}
+	return nil
+}
+
+// Member is a Cognito user's membership of a team
+type Member struct {
+	TeamID  string    `json:"team_id" dynamodbav:"TeamID"`
+	UserID  string    `json:"user_id" dynamodbav:"UserID"`
+	Email   string    `json:"email,omitempty" dynamodbav:"Email"`
+	Role    string    `json:"role" dynamodbav:"Role"`
+	AddedAt time.Time `json:"added_at" dynamodbav:"AddedAt"`
+}
+
+// Validate checks the member names a user and a known role
+func (m *Member) Validate() error {
+	if m.UserID == "" {
+		return fmt.Errorf("user is required")
+	}
+	if m.Role != RoleMember && m.Role != RoleAdmin {
+		return fmt.Errorf("role must be %q or %q", RoleMember, RoleAdmin)
+	}
+	return nil
+}
+
+// IsAdmin reports whether the member administers the team
+func (m *Member) IsAdmin() bool {
+	return m.Role == RoleAdmin
+}
+
+// Store holds teams and their members
+type Store interface {
+	// List returns every team ordered by ID
+	List(ctx context.Context) ([]Team, error)
+
+	// Get returns a single team or ErrNotFound
+	Get(ctx context.Context, id string) (*Team, error)
+
+	// Create adds a new team, deriving its ID from the name when empty
+	Create(ctx context.Context, team Team) (*Team, error)
+
+	// Update replaces an existing team's settings or returns ErrNotFound
+	Update(ctx context.Context, team Team) (*Team, error)
+
+	// Delete removes a team and all of its memberships
+	Delete(ctx context.Context, id string) error
+
+	// ListMembers returns the team's members ordered by user ID
+	ListMembers(ctx context.Context, teamID string) ([]Member, error)
+
+	// GetMember returns a user's membership or ErrMemberNotFound
+	GetMember(ctx context.Context, teamID, userID string) (*Member, error)
+
+	// PutMember adds a member or changes their role; ErrNotFound if the team does not exist
+	PutMember(ctx context.Context, member Member) (*Member, error)
+
+	// RemoveMember removes a user from the team or returns ErrMemberNotFound
+	RemoveMember(ctx context.Context, teamID, userID string) error
+
+	// Memberships returns the teams a user belongs to, ordered by team ID
+	Memberships(ctx context.Context, userID string) ([]Member, error)
+}
+
+// NewStore creates the DynamoDB store for the table in cfg.TeamsTable
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.TeamsTable == "" {
+		return nil, ErrNotConfigured
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.AWSRegion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return NewDynamoStore(dynamodb.New(sess), cfg.TeamsTable), nil
+}
+
+// ErrNotMember is returned by Resolve when the user asks for a team they do not belong to
+var ErrNotMember = errors.New("not a member of the team")
+
+// Resolve returns the team a user's chat runs as. A requested team, matched by
+// ID or name, must be one of the user's teams; without one the user's first team
+// is used. It returns nil when the user belongs to no team, or when the requested
+// team is a label no stored team has, as older clients send.
+func Resolve(ctx context.Context, store Store, userID, requested string) (*Team, error) {
+	memberships, err := store.Memberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, nil
+	}
+
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return store.Get(ctx, memberships[0].TeamID)
+	}
+
+	for _, m := range memberships {
+		if m.TeamID == requested || m.TeamID == knowledge.Slugify(requested) {
+			return store.Get(ctx, m.TeamID)
+		}
+	}
+
+	for _, id := range []string{requested, knowledge.Slugify(requested)} {
+		_, err := store.Get(ctx, id)
+		if err == nil {
+			return nil, ErrNotMember
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// CanManage reports whether a user may edit a team and its members: global
+// admins can manage every team, team admins their own.
+func CanManage(ctx context.Context, store Store, userID string, globalAdmin bool, teamID string) (bool, error) {
+	if globalAdmin {
+		return true, nil
+	}
+
+	member, err := store.GetMember(ctx, teamID, userID)
+	if errors.Is(err, ErrMemberNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return member.IsAdmin(), nil
+}
+
+// normalize fills in the fields a stored team always has
+func normalize(team Team) Team {
+	team.Name = strings.TrimSpace(team.Name)
+	if team.ID == "" {
+		team.ID = knowledge.Slugify(team.Name)
+	}
+	if team.Links == nil {
+		team.Links = []string{}
+	}
+	if team.Documents == nil {
+		team.Documents = []Document{}
+	}
+	return team
+}
+
+func sortTeams(teams []Team) {
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+}
+
+func sortMembers(members []Member, byTeam bool) {
+	sort.Slice(members, func(i, j int) bool {
+		if byTeam {
+			return members[i].TeamID < members[j].TeamID
+		}
+		return members[i].UserID < members[j].UserID
+	})
+}
@@ -0,0 +1,99 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Key layout: each subject has a partition "USER#<sub>" or "TEAM#<team>" holding
+// one counter item per period, with sort keys "DAY#2006-01-02" and "MONTH#2006-01".
+// Recording an event atomically increments four counters, so reads never scan raw events.
+const (
+	dayPrefix   = "DAY#"
+	monthPrefix = "MONTH#"
+)
+
+// DynamoStore is a Store backed by a DynamoDB table with string keys PK and SK
+type DynamoStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// NewDynamoStore creates a store for the given table
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, table string) *DynamoStore {
+	return &DynamoStore{client: client, table: table}
+}
+
+// Record adds an event to the daily and monthly totals of its user and team
+func (s *DynamoStore) Record(ctx context.Context, event Event) error {
+	for _, sub := range event.subjects() {
+		for _, period := range []string{PeriodDay, PeriodMonth} {
+			periodKey := PeriodKey(period, event.Time)
+
+			_, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+				TableName: aws.String(s.table),
+				Key: map[string]*dynamodb.AttributeValue{
+					"PK": {S: aws.String(sub.key())},
+					"SK": {S: aws.String(sortKey(period, periodKey))},
+				},
+				UpdateExpression: aws.String("SET Period = :period ADD InputTokens :in, OutputTokens :out, Requests :one"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":period": {S: aws.String(periodKey)},
+					":in":     {N: aws.String(fmt.Sprint(event.InputTokens))},
+					":out":    {N: aws.String(fmt.Sprint(event.OutputTokens))},
+					":one":    {N: aws.String("1")},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record usage: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Aggregates returns the totals matching the query, oldest first
+func (s *DynamoStore) Aggregates(ctx context.Context, query Query) ([]Aggregate, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("PK = :pk AND SK BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":   {S: aws.String(subject{query.Subject, query.ID}.key())},
+			":from": {S: aws.String(sortKey(query.Period, query.From))},
+			":to":   {S: aws.String(sortKey(query.Period, query.To))},
+		},
+	}
+
+	err := s.client.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %v", err)
+	}
+
+	aggregates := []Aggregate{}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &aggregates); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal usage: %v", err)
+	}
+	for i := range aggregates {
+		aggregates[i].TotalTokens = aggregates[i].InputTokens + aggregates[i].OutputTokens
+	}
+
+	return aggregates, nil
+}
+
+func sortKey(period, periodKey string) string {
+	if period == PeriodMonth {
+		return monthPrefix + periodKey
+	}
+	return dayPrefix + periodKey
+}
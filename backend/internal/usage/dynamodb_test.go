@@ -0,0 +1,83 @@
+package usage
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is a minimal in-memory table that understands the counter updates
+// and range queries the store issues against a PK/SK table
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	mu    sync.Mutex
+	items map[string]map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[string]map[string]*dynamodb.AttributeValue)}
+}
+
+func (f *fakeDynamo) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pk := aws.StringValue(input.Key["PK"].S)
+	sk := aws.StringValue(input.Key["SK"].S)
+	if f.items[pk] == nil {
+		f.items[pk] = make(map[string]map[string]*dynamodb.AttributeValue)
+	}
+	item := f.items[pk][sk]
+	if item == nil {
+		item = map[string]*dynamodb.AttributeValue{"PK": input.Key["PK"], "SK": input.Key["SK"]}
+		f.items[pk][sk] = item
+	}
+
+	values := input.ExpressionAttributeValues
+	item["Period"] = values[":period"]
+	for attr, placeholder := range map[string]string{"InputTokens": ":in", "OutputTokens": ":out", "Requests": ":one"} {
+		current := 0
+		if item[attr] != nil {
+			current, _ = strconv.Atoi(aws.StringValue(item[attr].N))
+		}
+		add, _ := strconv.Atoi(aws.StringValue(values[placeholder].N))
+		item[attr] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(current + add))}
+	}
+
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (f *fakeDynamo) QueryPagesWithContext(ctx aws.Context, input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, opts ...request.Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pk := aws.StringValue(input.ExpressionAttributeValues[":pk"].S)
+	from := aws.StringValue(input.ExpressionAttributeValues[":from"].S)
+	to := aws.StringValue(input.ExpressionAttributeValues[":to"].S)
+
+	// Items come back in sort key order, as they would from DynamoDB
+	var keys []string
+	for sk := range f.items[pk] {
+		if sk >= from && sk <= to {
+			keys = append(keys, sk)
+		}
+	}
+	sort.Strings(keys)
+
+	page := &dynamodb.QueryOutput{}
+	for _, sk := range keys {
+		page.Items = append(page.Items, f.items[pk][sk])
+	}
+	fn(page, true)
+	return nil
+}
+
+func TestDynamoStore(t *testing.T) {
+	testStore(t, NewDynamoStore(newFakeDynamo(), "usage"))
+}
@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-memory Store for tests and local development
+type MemoryStore struct {
+	mu     sync.Mutex
+	totals map[string]map[string]*Aggregate
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{totals: make(map[string]map[string]*Aggregate)}
+}
+
+// Record adds an event to the daily and monthly totals of its user and team
+func (s *MemoryStore) Record(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sub := range event.subjects() {
+		for _, period := range []string{PeriodDay, PeriodMonth} {
+			key := sub.key() + "|" + period
+			if s.totals[key] == nil {
+				s.totals[key] = make(map[string]*Aggregate)
+			}
+
+			periodKey := PeriodKey(period, event.Time)
+			agg, ok := s.totals[key][periodKey]
+			if !ok {
+				agg = &Aggregate{Period: periodKey}
+				s.totals[key][periodKey] = agg
+			}
+			agg.InputTokens += event.InputTokens
+			agg.OutputTokens += event.OutputTokens
+			agg.TotalTokens = agg.InputTokens + agg.OutputTokens
+			agg.Requests++
+		}
+	}
+
+	return nil
+}
+
+// Aggregates returns the totals matching the query, oldest first
+func (s *MemoryStore) Aggregates(ctx context.Context, query Query) ([]Aggregate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aggregates := []Aggregate{}
+	for periodKey, agg := range s.totals[subject{query.Subject, query.ID}.key()+"|"+query.Period] {
+		if periodKey >= query.From && periodKey <= query.To {
+			aggregates = append(aggregates, *agg)
+		}
+	}
+	sort.Slice(aggregates, func(i, j int) bool { return aggregates[i].Period < aggregates[j].Period })
+
+	return aggregates, nil
+}
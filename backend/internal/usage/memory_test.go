@@ -0,0 +1,73 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	day1 := time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	events := []Event{
+		{UserID: "user-1", Team: "Search Results", InputTokens: 100, OutputTokens: 10, Time: day1},
+		{UserID: "user-1", Team: "search results ", InputTokens: 200, OutputTokens: 20, Time: day2},
+		{UserID: "user-2", Team: "Search Results", InputTokens: 50, OutputTokens: 5, Time: day2},
+		{UserID: "user-2", InputTokens: 1, OutputTokens: 1, Time: day2},
+	}
+	for _, event := range events {
+		if err := store.Record(ctx, event); err != nil {
+			t.Fatalf("Record returned error: %v", err)
+		}
+	}
+
+	daily, err := store.Aggregates(ctx, Query{Subject: SubjectUser, ID: "user-1", Period: PeriodDay, From: "2025-03-01", To: "2025-04-30"})
+	if err != nil {
+		t.Fatalf("Aggregates returned error: %v", err)
+	}
+	if len(daily) != 2 || daily[0].Period != "2025-03-31" || daily[1].Period != "2025-04-01" {
+		t.Fatalf("Unexpected daily user usage: %+v", daily)
+	}
+	if daily[1].InputTokens != 200 || daily[1].OutputTokens != 20 || daily[1].TotalTokens != 220 || daily[1].Requests != 1 {
+		t.Errorf("Unexpected daily totals: %+v", daily[1])
+	}
+
+	monthly, err := store.Aggregates(ctx, Query{Subject: SubjectTeam, ID: "search results", Period: PeriodMonth, From: "2025-01", To: "2025-12"})
+	if err != nil {
+		t.Fatalf("Aggregates returned error: %v", err)
+	}
+	if len(monthly) != 2 {
+		t.Fatalf("Expected March and April team totals, got %+v", monthly)
+	}
+	if april := monthly[1]; april.Period != "2025-04" || april.InputTokens != 250 || april.Requests != 2 {
+		t.Errorf("Unexpected April team totals: %+v", april)
+	}
+
+	narrowed, _ := store.Aggregates(ctx, Query{Subject: SubjectUser, ID: "user-1", Period: PeriodDay, From: "2025-04-01", To: "2025-04-01"})
+	if len(narrowed) != 1 {
+		t.Errorf("Expected the range to be inclusive and exclusive of other days, got %+v", narrowed)
+	}
+
+	none, err := store.Aggregates(ctx, Query{Subject: SubjectUser, ID: "nobody", Period: PeriodDay, From: "2025-01-01", To: "2025-12-31"})
+	if err != nil || none == nil || len(none) != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %v, %v", none, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestPeriodKey(t *testing.T) {
+	at := time.Date(2025, 4, 1, 0, 30, 0, 0, time.FixedZone("BST", 3600))
+
+	if got := PeriodKey(PeriodDay, at); got != "2025-03-31" {
+		t.Errorf("Expected UTC day 2025-03-31, got %s", got)
+	}
+	if got := PeriodKey(PeriodMonth, at); got != "2025-03" {
+		t.Errorf("Expected UTC month 2025-03, got %s", got)
+	}
+}
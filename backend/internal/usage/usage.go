@@ -0,0 +1,125 @@
+// Package usage records model token usage per Cognito user and team, and
+// aggregates it by day and month so AI spend can be reported per team.
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"tuitui-backend/internal/config"
+)
+
+// ErrNotConfigured is returned when no usage table is configured
+var ErrNotConfigured = errors.New("usage storage not configured")
+
+// Subjects usage is attributed to
+const (
+	SubjectUser = "user"
+	SubjectTeam = "team"
+)
+
+// Periods usage is aggregated over
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// Date layouts of the period keys
+const (
+	DayLayout   = "2006-01-02"
+	MonthLayout = "2006-01"
+)
+
+// Event is the usage of a single chat request
+type Event struct {
+	UserID       string
+	Team         string
+	InputTokens  int
+	OutputTokens int
+	Time         time.Time
+}
+
+// Aggregate is the total usage of a subject over one day or month
+type Aggregate struct {
+	Period       string `json:"period" dynamodbav:"Period"`
+	InputTokens  int    `json:"input_tokens" dynamodbav:"InputTokens"`
+	OutputTokens int    `json:"output_tokens" dynamodbav:"OutputTokens"`
+	TotalTokens  int    `json:"total_tokens" dynamodbav:"-"`
+	Requests     int    `json:"requests" dynamodbav:"Requests"`
+}
+
+// Query selects the aggregates of one subject between two period keys, inclusive
+type Query struct {
+	Subject string
+	ID      string
+	Period  string
+	From    string
+	To      string
+}
+
+// Store records usage events and answers aggregate queries
+type Store interface {
+	// Record adds an event to the daily and monthly totals of its user and team
+	Record(ctx context.Context, event Event) error
+
+	// Aggregates returns the totals matching the query, oldest first
+	Aggregates(ctx context.Context, query Query) ([]Aggregate, error)
+}
+
+// NewStore creates a DynamoDB-backed store for the configured usage table
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.UsageTable == "" {
+		return nil, ErrNotConfigured
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.AWSRegion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+
+	return NewDynamoStore(dynamodb.New(sess), cfg.UsageTable), nil
+}
+
+// PeriodKey returns the key of the day or month containing t, in UTC
+func PeriodKey(period string, t time.Time) string {
+	if period == PeriodMonth {
+		return t.UTC().Format(MonthLayout)
+	}
+	return t.UTC().Format(DayLayout)
+}
+
+// subject identifies who usage is attributed to
+type subject struct {
+	kind string
+	id   string
+}
+
+// key is the partition for the subject, e.g. "USER#<sub>" or "TEAM#<team>"
+func (s subject) key() string {
+	return strings.ToUpper(s.kind) + "#" + s.id
+}
+
+// subjects returns the subjects an event is attributed to
+func (e Event) subjects() []subject {
+	var subjects []subject
+	if e.UserID != "" {
+		subjects = append(subjects, subject{SubjectUser, e.UserID})
+	}
+	if team := NormalizeTeam(e.Team); team != "" {
+		subjects = append(subjects, subject{SubjectTeam, team})
+	}
+	return subjects
+}
+
+// NormalizeTeam trims and lowercases a team name so totals are not split by spelling
+func NormalizeTeam(team string) string {
+	return strings.ToLower(strings.TrimSpace(team))
+}
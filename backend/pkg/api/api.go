@@ -0,0 +1,52 @@
+// Package api holds the response helpers shared by the API Gateway Lambda handlers.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ErrorResponse represents an error response structure
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// CORSHeaders returns the CORS headers for all responses from an endpoint that
+// accepts the given methods, e.g. "GET,POST,OPTIONS"
+func CORSHeaders(methods string) map[string]string {
+	return map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+		"Access-Control-Allow-Methods": methods,
+	}
+}
+
+// JSON marshals body and returns it with the given status code
+func JSON(statusCode int, body interface{}, headers map[string]string) events.APIGatewayProxyResponse {
+	responseBody, err := json.Marshal(body)
+	if err != nil {
+		return Error(500, fmt.Sprintf("Failed to marshal response: %v", err), headers)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Body:       string(responseBody),
+		Headers:    headers,
+	}
+}
+
+// Error returns an ErrorResponse with the given status code
+func Error(statusCode int, message string, headers map[string]string) events.APIGatewayProxyResponse {
+	errorBody, _ := json.Marshal(ErrorResponse{
+		Error: message,
+	})
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Body:       string(errorBody),
+		Headers:    headers,
+	}
+}
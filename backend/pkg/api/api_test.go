@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestCORSHeaders(t *testing.T) {
+	headers := CORSHeaders("GET,OPTIONS")
+
+	if headers["Access-Control-Allow-Origin"] != "*" {
+		t.Error("Expected CORS origin header")
+	}
+
+	if headers["Access-Control-Allow-Methods"] != "GET,OPTIONS" {
+		t.Errorf("Expected methods 'GET,OPTIONS', got '%s'", headers["Access-Control-Allow-Methods"])
+	}
+}
+
+func TestJSON(t *testing.T) {
+	response := JSON(201, map[string]string{"id": "abc"}, CORSHeaders("POST,OPTIONS"))
+
+	if response.StatusCode != 201 {
+		t.Errorf("Expected status 201, got %d", response.StatusCode)
+	}
+
+	if response.Body != `{"id":"abc"}` {
+		t.Errorf("Unexpected body: %s", response.Body)
+	}
+}
+
+func TestJSON_MarshalFailure(t *testing.T) {
+	response := JSON(200, math.Inf(1), nil)
+
+	if response.StatusCode != 500 {
+		t.Errorf("Expected status 500, got %d", response.StatusCode)
+	}
+}
+
+func TestError(t *testing.T) {
+	response := Error(404, "Conversation not found", nil)
+
+	if response.StatusCode != 404 {
+		t.Errorf("Expected status 404, got %d", response.StatusCode)
+	}
+
+	var errorResp ErrorResponse
+	if err := json.Unmarshal([]byte(response.Body), &errorResp); err != nil {
+		t.Fatalf("Failed to parse error response: %v", err)
+	}
+
+	if errorResp.Error != "Conversation not found" {
+		t.Errorf("Expected 'Conversation not found', got '%s'", errorResp.Error)
+	}
+}
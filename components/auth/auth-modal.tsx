@@ -11,8 +11,10 @@ import {
 import { LoginForm } from './login-form'
 import { RegisterForm } from './register-form'
 import { VerifyEmailForm } from './verify-email-form'
+import { ChallengeForm } from './challenge-form'
 import { useAuth } from '@/lib/auth-context'
 import { useToast } from '@/hooks/use-toast'
+import type { ChallengeResponse } from '@/lib/api'
 
 interface AuthModalProps {
   isOpen: boolean
@@ -20,8 +22,10 @@ interface AuthModalProps {
 }
 
 export function AuthModal({ isOpen, onClose }: AuthModalProps) {
-  const [mode, setMode] = useState<'login' | 'register' | 'verify'>('login')
+  const [mode, setMode] = useState<'login' | 'register' | 'verify' | 'challenge'>('login')
   const [emailToVerify, setEmailToVerify] = useState<string>('')
+  const [challengeEmail, setChallengeEmail] = useState<string>('')
+  const [challenge, setChallenge] = useState<ChallengeResponse | null>(null)
   const { login } = useAuth()
   const { toast } = useToast()
 
@@ -31,9 +35,17 @@ export function AuthModal({ isOpen, onClose }: AuthModalProps) {
       title: 'Welcome back!',
       description: 'You have been successfully signed in.',
     })
+    setChallenge(null)
+    setMode('login')
     onClose()
   }
 
+  const handleChallenge = (email: string, next: ChallengeResponse) => {
+    setChallengeEmail(email)
+    setChallenge(next)
+    setMode('challenge')
+  }
+
   const handleRegisterSuccess = (email: string) => {
     setEmailToVerify(email)
     toast({
@@ -81,13 +93,21 @@ export function AuthModal({ isOpen, onClose }: AuthModalProps) {
       <DialogContent className="sm:max-w-md">
         <DialogHeader>
           <DialogTitle>
-            {mode === 'login' ? 'Sign In' : mode === 'register' ? 'Create Account' : 'Verify Email'}
+            {mode === 'login'
+              ? 'Sign In'
+              : mode === 'register'
+              ? 'Create Account'
+              : mode === 'challenge'
+              ? 'Verify Sign In'
+              : 'Verify Email'}
           </DialogTitle>
           <DialogDescription>
             {mode === 'login'
               ? 'Enter your credentials to access your account.'
               : mode === 'register'
               ? 'Create a new account to get started.'
+              : mode === 'challenge'
+              ? 'One more step to finish signing in.'
               : 'Enter the verification code sent to your email.'
             }
           </DialogDescription>
@@ -98,8 +118,18 @@ export function AuthModal({ isOpen, onClose }: AuthModalProps) {
             onSuccess={handleLoginSuccess}
             onError={handleError}
             onNeedsVerification={handleNeedsVerification}
+            onChallenge={handleChallenge}
             onSwitchToRegister={() => setMode('register')}
           />
+        ) : mode === 'challenge' && challenge ? (
+          <ChallengeForm
+            key={challenge.session}
+            email={challengeEmail}
+            challenge={challenge}
+            onSuccess={handleLoginSuccess}
+            onChallenge={(next) => handleChallenge(challengeEmail, next)}
+            onError={handleError}
+          />
         ) : mode === 'register' ? (
           <RegisterForm
             onSuccess={handleRegisterSuccess}
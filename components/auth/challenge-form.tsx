@@ -0,0 +1,126 @@
+'use client'
+
+import { useState } from 'react'
+import { useForm } from 'react-hook-form'
+import { Button } from '@/components/ui/button'
+import { Input } from '@/components/ui/input'
+import {
+  Form,
+  FormControl,
+  FormField,
+  FormItem,
+  FormLabel,
+  FormMessage,
+} from '@/components/ui/form'
+import { apiClient, isChallenge, type ChallengeResponse } from '@/lib/api'
+
+interface ChallengeFormProps {
+  email: string
+  challenge: ChallengeResponse
+  onSuccess: (tokens: { access_token: string; refresh_token: string; id_token: string }) => void
+  onChallenge: (challenge: ChallengeResponse) => void
+  onError: (error: string) => void
+}
+
+interface ChallengeFormValues {
+  answer: string
+}
+
+export function ChallengeForm({ email, challenge, onSuccess, onChallenge, onError }: ChallengeFormProps) {
+  const [isLoading, setIsLoading] = useState(false)
+  const newPassword = challenge.challenge_name === 'NEW_PASSWORD_REQUIRED'
+
+  const form = useForm<ChallengeFormValues>({
+    defaultValues: {
+      answer: '',
+    },
+  })
+
+  const onSubmit = async (data: ChallengeFormValues) => {
+    setIsLoading(true)
+    try {
+      const response = await apiClient.respondToChallenge({
+        email,
+        challengeName: challenge.challenge_name,
+        session: challenge.session,
+        ...(newPassword ? { newPassword: data.answer } : { code: data.answer }),
+      })
+      if (isChallenge(response)) {
+        form.reset()
+        onChallenge(response)
+        return
+      }
+      onSuccess({
+        access_token: response.access_token,
+        refresh_token: response.refresh_token,
+        id_token: response.id_token,
+      })
+    } catch (error) {
+      const errorMessage = error instanceof Error && error.message
+        ? error.message
+        : 'Verification failed. Please try again.'
+      onError(errorMessage)
+    } finally {
+      setIsLoading(false)
+    }
+  }
+
+  return (
+    <Form {...form}>
+      <form onSubmit={form.handleSubmit(onSubmit)} className="space-y-4">
+        <div className="text-sm text-muted-foreground mb-4">
+          {newPassword
+            ? 'Your account has a temporary password. Please choose a new one.'
+            : 'Enter the 6-digit code from your authenticator app.'}
+        </div>
+
+        <FormField
+          control={form.control}
+          name="answer"
+          rules={newPassword
+            ? {
+                required: 'New password is required',
+                minLength: {
+                  value: 8,
+                  message: 'Password must be at least 8 characters',
+                },
+              }
+            : {
+                required: 'Code is required',
+                pattern: {
+                  value: /^\d{6}$/,
+                  message: 'Code must be 6 digits',
+                },
+              }}
+          render={({ field }) => (
+            <FormItem>
+              <FormLabel>{newPassword ? 'New Password' : 'Authentication Code'}</FormLabel>
+              <FormControl>
+                {newPassword ? (
+                  <Input
+                    type="password"
+                    placeholder="Enter a new password"
+                    {...field}
+                  />
+                ) : (
+                  <Input
+                    type="text"
+                    placeholder="Enter 6-digit code"
+                    maxLength={6}
+                    autoComplete="one-time-code"
+                    {...field}
+                  />
+                )}
+              </FormControl>
+              <FormMessage />
+            </FormItem>
+          )}
+        />
+
+        <Button type="submit" className="w-full" disabled={isLoading}>
+          {isLoading ? 'Verifying...' : newPassword ? 'Set Password' : 'Verify'}
+        </Button>
+      </form>
+    </Form>
+  )
+}
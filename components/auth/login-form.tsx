@@ -12,16 +12,17 @@ import {
   FormLabel,
   FormMessage,
 } from '@/components/ui/form'
-import { apiClient, type LoginRequest } from '@/lib/api'
+import { apiClient, isChallenge, type ChallengeResponse, type LoginRequest } from '@/lib/api'
 
 interface LoginFormProps {
   onSuccess: (tokens: { access_token: string; refresh_token: string; id_token: string }) => void
   onError: (error: string) => void
   onNeedsVerification: (email: string) => void
+  onChallenge: (email: string, challenge: ChallengeResponse) => void
   onSwitchToRegister: () => void
 }
 
-export function LoginForm({ onSuccess, onError, onNeedsVerification, onSwitchToRegister }: LoginFormProps) {
+export function LoginForm({ onSuccess, onError, onNeedsVerification, onChallenge, onSwitchToRegister }: LoginFormProps) {
   const [isLoading, setIsLoading] = useState(false)
 
   const form = useForm<LoginRequest>({
@@ -35,6 +36,10 @@ export function LoginForm({ onSuccess, onError, onNeedsVerification, onSwitchToR
     setIsLoading(true)
     try {
       const response = await apiClient.login(data)
+      if (isChallenge(response)) {
+        onChallenge(data.email, response)
+        return
+      }
       onSuccess({
         access_token: response.access_token,
         refresh_token: response.refresh_token,
@@ -240,12 +240,13 @@ resource "aws_api_gateway_integration_response" "auth_login_options" {
   }
 }
 
-# POST method on /chat (no auth for now - will add back later)
+# POST method on /chat (Cognito identifies the owner of stored conversations)
 resource "aws_api_gateway_method" "chat_post" {
   rest_api_id   = aws_api_gateway_rest_api.main.id
   resource_id   = aws_api_gateway_resource.chat.id
   http_method   = "POST"
-  authorization = "NONE"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
 }
 
 # OPTIONS method for /chat (CORS preflight)
@@ -503,6 +504,2400 @@ resource "aws_lambda_permission" "api_gateway_auth_resend_code" {
   source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
 }
 
+# /conversations resource
+resource "aws_api_gateway_resource" "conversations" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_rest_api.main.root_resource_id
+  path_part   = "conversations"
+}
+
+# /conversations/{id} resource
+resource "aws_api_gateway_resource" "conversation" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.conversations.id
+  path_part   = "{id}"
+}
+
+# /conversations/{id}/messages resource
+resource "aws_api_gateway_resource" "conversation_messages" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.conversation.id
+  path_part   = "messages"
+}
+
+# GET method on /conversations
+resource "aws_api_gateway_method" "conversations_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversations.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "conversations_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversations.id
+  http_method = aws_api_gateway_method.conversations_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.conversations.invoke_arn
+}
+
+# POST method on /conversations
+resource "aws_api_gateway_method" "conversations_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversations.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "conversations_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversations.id
+  http_method = aws_api_gateway_method.conversations_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.conversations.invoke_arn
+}
+
+# OPTIONS method for /conversations (CORS preflight)
+resource "aws_api_gateway_method" "conversations_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversations.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "conversations_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversations.id
+  http_method = aws_api_gateway_method.conversations_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "conversations_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversations.id
+  http_method = aws_api_gateway_method.conversations_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "conversations_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversations.id
+  http_method = aws_api_gateway_method.conversations_options.http_method
+  status_code = aws_api_gateway_method_response.conversations_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /conversations/{id}
+resource "aws_api_gateway_method" "conversation_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversation.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "conversation_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation.id
+  http_method = aws_api_gateway_method.conversation_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.conversations.invoke_arn
+}
+
+# DELETE method on /conversations/{id}
+resource "aws_api_gateway_method" "conversation_delete" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversation.id
+  http_method   = "DELETE"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "conversation_delete_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation.id
+  http_method = aws_api_gateway_method.conversation_delete.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.conversations.invoke_arn
+}
+
+# OPTIONS method for /conversations/{id} (CORS preflight)
+resource "aws_api_gateway_method" "conversation_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversation.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "conversation_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation.id
+  http_method = aws_api_gateway_method.conversation_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "conversation_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation.id
+  http_method = aws_api_gateway_method.conversation_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "conversation_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation.id
+  http_method = aws_api_gateway_method.conversation_options.http_method
+  status_code = aws_api_gateway_method_response.conversation_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,DELETE,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /conversations/{id}/messages
+resource "aws_api_gateway_method" "conversation_messages_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversation_messages.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "conversation_messages_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation_messages.id
+  http_method = aws_api_gateway_method.conversation_messages_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.conversation_messages.invoke_arn
+}
+
+# OPTIONS method for /conversations/{id}/messages (CORS preflight)
+resource "aws_api_gateway_method" "conversation_messages_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.conversation_messages.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "conversation_messages_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation_messages.id
+  http_method = aws_api_gateway_method.conversation_messages_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "conversation_messages_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation_messages.id
+  http_method = aws_api_gateway_method.conversation_messages_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "conversation_messages_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.conversation_messages.id
+  http_method = aws_api_gateway_method.conversation_messages_options.http_method
+  status_code = aws_api_gateway_method_response.conversation_messages_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for conversations
+resource "aws_lambda_permission" "api_gateway_conversations" {
+  statement_id  = "AllowAPIGatewayInvokeConversations"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.conversations.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for conversation messages
+resource "aws_lambda_permission" "api_gateway_conversation_messages" {
+  statement_id  = "AllowAPIGatewayInvokeConversationMessages"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.conversation_messages.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /knowledge resource
+resource "aws_api_gateway_resource" "knowledge" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_rest_api.main.root_resource_id
+  path_part   = "knowledge"
+}
+
+# /knowledge/{id} resource
+resource "aws_api_gateway_resource" "knowledge_id" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.knowledge.id
+  path_part   = "{id}"
+}
+
+# GET method on /knowledge
+resource "aws_api_gateway_method" "knowledge_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.knowledge.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "knowledge_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge.id
+  http_method = aws_api_gateway_method.knowledge_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.knowledge.invoke_arn
+}
+
+# POST method on /knowledge
+resource "aws_api_gateway_method" "knowledge_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.knowledge.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "knowledge_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge.id
+  http_method = aws_api_gateway_method.knowledge_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.knowledge.invoke_arn
+}
+
+# OPTIONS method for /knowledge (CORS preflight)
+resource "aws_api_gateway_method" "knowledge_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.knowledge.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "knowledge_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge.id
+  http_method = aws_api_gateway_method.knowledge_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "knowledge_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge.id
+  http_method = aws_api_gateway_method.knowledge_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "knowledge_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge.id
+  http_method = aws_api_gateway_method.knowledge_options.http_method
+  status_code = aws_api_gateway_method_response.knowledge_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /knowledge/{id}
+resource "aws_api_gateway_method" "knowledge_id_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.knowledge_id.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "knowledge_id_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge_id.id
+  http_method = aws_api_gateway_method.knowledge_id_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.knowledge.invoke_arn
+}
+
+# PUT method on /knowledge/{id}
+resource "aws_api_gateway_method" "knowledge_id_put" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.knowledge_id.id
+  http_method   = "PUT"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "knowledge_id_put_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge_id.id
+  http_method = aws_api_gateway_method.knowledge_id_put.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.knowledge.invoke_arn
+}
+
+# OPTIONS method for /knowledge/{id} (CORS preflight)
+resource "aws_api_gateway_method" "knowledge_id_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.knowledge_id.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "knowledge_id_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge_id.id
+  http_method = aws_api_gateway_method.knowledge_id_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "knowledge_id_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge_id.id
+  http_method = aws_api_gateway_method.knowledge_id_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "knowledge_id_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.knowledge_id.id
+  http_method = aws_api_gateway_method.knowledge_id_options.http_method
+  status_code = aws_api_gateway_method_response.knowledge_id_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,PUT,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for knowledge
+resource "aws_lambda_permission" "api_gateway_knowledge" {
+  statement_id  = "AllowAPIGatewayInvokeKnowledge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.knowledge.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /usage resource
+resource "aws_api_gateway_resource" "usage" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_rest_api.main.root_resource_id
+  path_part   = "usage"
+}
+
+# GET method on /usage
+resource "aws_api_gateway_method" "usage_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.usage.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "usage_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.usage.id
+  http_method = aws_api_gateway_method.usage_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.usage.invoke_arn
+}
+
+# OPTIONS method for /usage (CORS preflight)
+resource "aws_api_gateway_method" "usage_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.usage.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "usage_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.usage.id
+  http_method = aws_api_gateway_method.usage_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "usage_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.usage.id
+  http_method = aws_api_gateway_method.usage_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "usage_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.usage.id
+  http_method = aws_api_gateway_method.usage_options.http_method
+  status_code = aws_api_gateway_method_response.usage_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for Usage
+resource "aws_lambda_permission" "api_gateway_usage" {
+  statement_id  = "AllowAPIGatewayInvokeUsage"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.usage.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /teams resource
+resource "aws_api_gateway_resource" "teams" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_rest_api.main.root_resource_id
+  path_part   = "teams"
+}
+
+# /teams/{id} resource
+resource "aws_api_gateway_resource" "team" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.teams.id
+  path_part   = "{id}"
+}
+
+# /teams/{id}/members resource
+resource "aws_api_gateway_resource" "team_members" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.team.id
+  path_part   = "members"
+}
+
+# /teams/{id}/members/{userId} resource
+resource "aws_api_gateway_resource" "team_member" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.team_members.id
+  path_part   = "{userId}"
+}
+
+# GET method on /teams
+resource "aws_api_gateway_method" "teams_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.teams.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "teams_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.teams.id
+  http_method = aws_api_gateway_method.teams_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.teams.invoke_arn
+}
+
+# POST method on /teams
+resource "aws_api_gateway_method" "teams_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.teams.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "teams_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.teams.id
+  http_method = aws_api_gateway_method.teams_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.teams.invoke_arn
+}
+
+# OPTIONS method for /teams (CORS preflight)
+resource "aws_api_gateway_method" "teams_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.teams.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "teams_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.teams.id
+  http_method = aws_api_gateway_method.teams_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "teams_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.teams.id
+  http_method = aws_api_gateway_method.teams_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "teams_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.teams.id
+  http_method = aws_api_gateway_method.teams_options.http_method
+  status_code = aws_api_gateway_method_response.teams_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /teams/{id}
+resource "aws_api_gateway_method" "team_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "team_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team.id
+  http_method = aws_api_gateway_method.team_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.teams.invoke_arn
+}
+
+# PUT method on /teams/{id}
+resource "aws_api_gateway_method" "team_put" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team.id
+  http_method   = "PUT"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "team_put_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team.id
+  http_method = aws_api_gateway_method.team_put.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.teams.invoke_arn
+}
+
+# DELETE method on /teams/{id}
+resource "aws_api_gateway_method" "team_delete" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team.id
+  http_method   = "DELETE"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "team_delete_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team.id
+  http_method = aws_api_gateway_method.team_delete.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.teams.invoke_arn
+}
+
+# OPTIONS method for /teams/{id} (CORS preflight)
+resource "aws_api_gateway_method" "team_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "team_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team.id
+  http_method = aws_api_gateway_method.team_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "team_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team.id
+  http_method = aws_api_gateway_method.team_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "team_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team.id
+  http_method = aws_api_gateway_method.team_options.http_method
+  status_code = aws_api_gateway_method_response.team_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,PUT,DELETE,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /teams/{id}/members
+resource "aws_api_gateway_method" "team_members_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team_members.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "team_members_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_members.id
+  http_method = aws_api_gateway_method.team_members_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.team_members.invoke_arn
+}
+
+# POST method on /teams/{id}/members
+resource "aws_api_gateway_method" "team_members_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team_members.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "team_members_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_members.id
+  http_method = aws_api_gateway_method.team_members_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.team_members.invoke_arn
+}
+
+# OPTIONS method for /teams/{id}/members (CORS preflight)
+resource "aws_api_gateway_method" "team_members_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team_members.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "team_members_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_members.id
+  http_method = aws_api_gateway_method.team_members_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "team_members_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_members.id
+  http_method = aws_api_gateway_method.team_members_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "team_members_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_members.id
+  http_method = aws_api_gateway_method.team_members_options.http_method
+  status_code = aws_api_gateway_method_response.team_members_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# PUT method on /teams/{id}/members/{userId}
+resource "aws_api_gateway_method" "team_member_put" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team_member.id
+  http_method   = "PUT"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "team_member_put_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_member.id
+  http_method = aws_api_gateway_method.team_member_put.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.team_members.invoke_arn
+}
+
+# DELETE method on /teams/{id}/members/{userId}
+resource "aws_api_gateway_method" "team_member_delete" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team_member.id
+  http_method   = "DELETE"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "team_member_delete_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_member.id
+  http_method = aws_api_gateway_method.team_member_delete.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.team_members.invoke_arn
+}
+
+# OPTIONS method for /teams/{id}/members/{userId} (CORS preflight)
+resource "aws_api_gateway_method" "team_member_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.team_member.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "team_member_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_member.id
+  http_method = aws_api_gateway_method.team_member_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "team_member_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_member.id
+  http_method = aws_api_gateway_method.team_member_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "team_member_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.team_member.id
+  http_method = aws_api_gateway_method.team_member_options.http_method
+  status_code = aws_api_gateway_method_response.team_member_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'PUT,DELETE,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for Teams
+resource "aws_lambda_permission" "api_gateway_teams" {
+  statement_id  = "AllowAPIGatewayInvokeTeams"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.teams.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for Team members
+resource "aws_lambda_permission" "api_gateway_team_members" {
+  statement_id  = "AllowAPIGatewayInvokeTeamMembers"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.team_members.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /documents resource
+resource "aws_api_gateway_resource" "documents" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_rest_api.main.root_resource_id
+  path_part   = "documents"
+}
+
+# /documents/{id} resource
+resource "aws_api_gateway_resource" "document" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.documents.id
+  path_part   = "{id}"
+}
+
+# /documents/{id}/versions resource
+resource "aws_api_gateway_resource" "document_versions" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.document.id
+  path_part   = "versions"
+}
+
+# GET method on /documents
+resource "aws_api_gateway_method" "documents_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.documents.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "documents_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.documents.id
+  http_method = aws_api_gateway_method.documents_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.documents.invoke_arn
+}
+
+# POST method on /documents
+resource "aws_api_gateway_method" "documents_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.documents.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "documents_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.documents.id
+  http_method = aws_api_gateway_method.documents_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.documents.invoke_arn
+}
+
+# OPTIONS method for /documents (CORS preflight)
+resource "aws_api_gateway_method" "documents_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.documents.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "documents_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.documents.id
+  http_method = aws_api_gateway_method.documents_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "documents_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.documents.id
+  http_method = aws_api_gateway_method.documents_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "documents_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.documents.id
+  http_method = aws_api_gateway_method.documents_options.http_method
+  status_code = aws_api_gateway_method_response.documents_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /documents/{id}
+resource "aws_api_gateway_method" "document_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.document.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "document_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document.id
+  http_method = aws_api_gateway_method.document_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.documents.invoke_arn
+}
+
+# PUT method on /documents/{id}
+resource "aws_api_gateway_method" "document_put" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.document.id
+  http_method   = "PUT"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "document_put_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document.id
+  http_method = aws_api_gateway_method.document_put.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.documents.invoke_arn
+}
+
+# DELETE method on /documents/{id}
+resource "aws_api_gateway_method" "document_delete" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.document.id
+  http_method   = "DELETE"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "document_delete_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document.id
+  http_method = aws_api_gateway_method.document_delete.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.documents.invoke_arn
+}
+
+# OPTIONS method for /documents/{id} (CORS preflight)
+resource "aws_api_gateway_method" "document_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.document.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "document_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document.id
+  http_method = aws_api_gateway_method.document_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "document_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document.id
+  http_method = aws_api_gateway_method.document_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "document_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document.id
+  http_method = aws_api_gateway_method.document_options.http_method
+  status_code = aws_api_gateway_method_response.document_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,PUT,DELETE,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /documents/{id}/versions
+resource "aws_api_gateway_method" "document_versions_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.document_versions.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "document_versions_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document_versions.id
+  http_method = aws_api_gateway_method.document_versions_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.document_versions.invoke_arn
+}
+
+# OPTIONS method for /documents/{id}/versions (CORS preflight)
+resource "aws_api_gateway_method" "document_versions_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.document_versions.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "document_versions_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document_versions.id
+  http_method = aws_api_gateway_method.document_versions_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "document_versions_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document_versions.id
+  http_method = aws_api_gateway_method.document_versions_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "document_versions_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.document_versions.id
+  http_method = aws_api_gateway_method.document_versions_options.http_method
+  status_code = aws_api_gateway_method_response.document_versions_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for Documents
+resource "aws_lambda_permission" "api_gateway_documents" {
+  statement_id  = "AllowAPIGatewayInvokeDocuments"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.documents.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for Document versions
+resource "aws_lambda_permission" "api_gateway_document_versions" {
+  statement_id  = "AllowAPIGatewayInvokeDocumentVersions"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.document_versions.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /prompts resource
+resource "aws_api_gateway_resource" "prompts" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_rest_api.main.root_resource_id
+  path_part   = "prompts"
+}
+
+# /prompts/{name} resource
+resource "aws_api_gateway_resource" "prompt" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.prompts.id
+  path_part   = "{name}"
+}
+
+# /prompts/{name}/versions resource
+resource "aws_api_gateway_resource" "prompt_versions" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.prompt.id
+  path_part   = "versions"
+}
+
+# /prompts/{name}/preview resource
+resource "aws_api_gateway_resource" "prompt_preview" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.prompt.id
+  path_part   = "preview"
+}
+
+# GET method on /prompts
+resource "aws_api_gateway_method" "prompts_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompts.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "prompts_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompts.id
+  http_method = aws_api_gateway_method.prompts_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.prompts.invoke_arn
+}
+
+# POST method on /prompts
+resource "aws_api_gateway_method" "prompts_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompts.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "prompts_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompts.id
+  http_method = aws_api_gateway_method.prompts_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.prompts.invoke_arn
+}
+
+# OPTIONS method for /prompts (CORS preflight)
+resource "aws_api_gateway_method" "prompts_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompts.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "prompts_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompts.id
+  http_method = aws_api_gateway_method.prompts_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "prompts_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompts.id
+  http_method = aws_api_gateway_method.prompts_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "prompts_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompts.id
+  http_method = aws_api_gateway_method.prompts_options.http_method
+  status_code = aws_api_gateway_method_response.prompts_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /prompts/{name}
+resource "aws_api_gateway_method" "prompt_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "prompt_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt.id
+  http_method = aws_api_gateway_method.prompt_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.prompts.invoke_arn
+}
+
+# PUT method on /prompts/{name}
+resource "aws_api_gateway_method" "prompt_put" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt.id
+  http_method   = "PUT"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "prompt_put_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt.id
+  http_method = aws_api_gateway_method.prompt_put.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.prompts.invoke_arn
+}
+
+# OPTIONS method for /prompts/{name} (CORS preflight)
+resource "aws_api_gateway_method" "prompt_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "prompt_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt.id
+  http_method = aws_api_gateway_method.prompt_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "prompt_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt.id
+  http_method = aws_api_gateway_method.prompt_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "prompt_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt.id
+  http_method = aws_api_gateway_method.prompt_options.http_method
+  status_code = aws_api_gateway_method_response.prompt_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,PUT,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# GET method on /prompts/{name}/versions
+resource "aws_api_gateway_method" "prompt_versions_get" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt_versions.id
+  http_method   = "GET"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "prompt_versions_get_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_versions.id
+  http_method = aws_api_gateway_method.prompt_versions_get.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.prompt_versions.invoke_arn
+}
+
+# POST method on /prompts/{name}/versions
+resource "aws_api_gateway_method" "prompt_versions_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt_versions.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "prompt_versions_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_versions.id
+  http_method = aws_api_gateway_method.prompt_versions_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.prompt_versions.invoke_arn
+}
+
+# OPTIONS method for /prompts/{name}/versions (CORS preflight)
+resource "aws_api_gateway_method" "prompt_versions_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt_versions.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "prompt_versions_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_versions.id
+  http_method = aws_api_gateway_method.prompt_versions_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "prompt_versions_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_versions.id
+  http_method = aws_api_gateway_method.prompt_versions_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "prompt_versions_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_versions.id
+  http_method = aws_api_gateway_method.prompt_versions_options.http_method
+  status_code = aws_api_gateway_method_response.prompt_versions_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# POST method on /prompts/{name}/preview
+resource "aws_api_gateway_method" "prompt_preview_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt_preview.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "prompt_preview_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_preview.id
+  http_method = aws_api_gateway_method.prompt_preview_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.prompt_preview.invoke_arn
+}
+
+# OPTIONS method for /prompts/{name}/preview (CORS preflight)
+resource "aws_api_gateway_method" "prompt_preview_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.prompt_preview.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "prompt_preview_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_preview.id
+  http_method = aws_api_gateway_method.prompt_preview_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "prompt_preview_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_preview.id
+  http_method = aws_api_gateway_method.prompt_preview_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "prompt_preview_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.prompt_preview.id
+  http_method = aws_api_gateway_method.prompt_preview_options.http_method
+  status_code = aws_api_gateway_method_response.prompt_preview_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for Prompts
+resource "aws_lambda_permission" "api_gateway_prompts" {
+  statement_id  = "AllowAPIGatewayInvokePrompts"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.prompts.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for Prompt versions
+resource "aws_lambda_permission" "api_gateway_prompt_versions" {
+  statement_id  = "AllowAPIGatewayInvokePromptVersions"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.prompt_versions.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for Prompt preview
+resource "aws_lambda_permission" "api_gateway_prompt_preview" {
+  statement_id  = "AllowAPIGatewayInvokePromptPreview"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.prompt_preview.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /auth/forgot-password resource
+resource "aws_api_gateway_resource" "auth_forgot_password" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth.id
+  path_part   = "forgot-password"
+}
+
+# /auth/reset-password resource
+resource "aws_api_gateway_resource" "auth_reset_password" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth.id
+  path_part   = "reset-password"
+}
+
+# POST method on /auth/forgot-password
+resource "aws_api_gateway_method" "auth_forgot_password_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_forgot_password.id
+  http_method   = "POST"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_forgot_password_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_forgot_password.id
+  http_method = aws_api_gateway_method.auth_forgot_password_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_forgot_password.invoke_arn
+}
+
+# OPTIONS method for /auth/forgot-password (CORS preflight)
+resource "aws_api_gateway_method" "auth_forgot_password_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_forgot_password.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_forgot_password_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_forgot_password.id
+  http_method = aws_api_gateway_method.auth_forgot_password_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_forgot_password_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_forgot_password.id
+  http_method = aws_api_gateway_method.auth_forgot_password_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_forgot_password_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_forgot_password.id
+  http_method = aws_api_gateway_method.auth_forgot_password_options.http_method
+  status_code = aws_api_gateway_method_response.auth_forgot_password_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# POST method on /auth/reset-password
+resource "aws_api_gateway_method" "auth_reset_password_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_reset_password.id
+  http_method   = "POST"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_reset_password_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_reset_password.id
+  http_method = aws_api_gateway_method.auth_reset_password_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_reset_password.invoke_arn
+}
+
+# OPTIONS method for /auth/reset-password (CORS preflight)
+resource "aws_api_gateway_method" "auth_reset_password_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_reset_password.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_reset_password_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_reset_password.id
+  http_method = aws_api_gateway_method.auth_reset_password_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_reset_password_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_reset_password.id
+  http_method = aws_api_gateway_method.auth_reset_password_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_reset_password_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_reset_password.id
+  http_method = aws_api_gateway_method.auth_reset_password_options.http_method
+  status_code = aws_api_gateway_method_response.auth_reset_password_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for auth forgot password
+resource "aws_lambda_permission" "api_gateway_auth_forgot_password" {
+  statement_id  = "AllowAPIGatewayInvokeAuthForgotPassword"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_forgot_password.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for auth reset password
+resource "aws_lambda_permission" "api_gateway_auth_reset_password" {
+  statement_id  = "AllowAPIGatewayInvokeAuthResetPassword"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_reset_password.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /auth/refresh resource
+resource "aws_api_gateway_resource" "auth_refresh" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth.id
+  path_part   = "refresh"
+}
+
+# POST method on /auth/refresh
+resource "aws_api_gateway_method" "auth_refresh_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_refresh.id
+  http_method   = "POST"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_refresh_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_refresh.id
+  http_method = aws_api_gateway_method.auth_refresh_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_refresh.invoke_arn
+}
+
+# OPTIONS method for /auth/refresh (CORS preflight)
+resource "aws_api_gateway_method" "auth_refresh_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_refresh.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_refresh_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_refresh.id
+  http_method = aws_api_gateway_method.auth_refresh_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_refresh_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_refresh.id
+  http_method = aws_api_gateway_method.auth_refresh_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_refresh_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_refresh.id
+  http_method = aws_api_gateway_method.auth_refresh_options.http_method
+  status_code = aws_api_gateway_method_response.auth_refresh_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for auth refresh
+resource "aws_lambda_permission" "api_gateway_auth_refresh" {
+  statement_id  = "AllowAPIGatewayInvokeAuthRefresh"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_refresh.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /auth/logout resource
+resource "aws_api_gateway_resource" "auth_logout" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth.id
+  path_part   = "logout"
+}
+
+# /auth/logout-all resource
+resource "aws_api_gateway_resource" "auth_logout_all" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth.id
+  path_part   = "logout-all"
+}
+
+# POST method on /auth/logout
+resource "aws_api_gateway_method" "auth_logout_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_logout.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "auth_logout_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout.id
+  http_method = aws_api_gateway_method.auth_logout_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_logout.invoke_arn
+}
+
+# OPTIONS method for /auth/logout (CORS preflight)
+resource "aws_api_gateway_method" "auth_logout_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_logout.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_logout_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout.id
+  http_method = aws_api_gateway_method.auth_logout_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_logout_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout.id
+  http_method = aws_api_gateway_method.auth_logout_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_logout_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout.id
+  http_method = aws_api_gateway_method.auth_logout_options.http_method
+  status_code = aws_api_gateway_method_response.auth_logout_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# POST method on /auth/logout-all
+resource "aws_api_gateway_method" "auth_logout_all_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_logout_all.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "auth_logout_all_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout_all.id
+  http_method = aws_api_gateway_method.auth_logout_all_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_logout_all.invoke_arn
+}
+
+# OPTIONS method for /auth/logout-all (CORS preflight)
+resource "aws_api_gateway_method" "auth_logout_all_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_logout_all.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_logout_all_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout_all.id
+  http_method = aws_api_gateway_method.auth_logout_all_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_logout_all_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout_all.id
+  http_method = aws_api_gateway_method.auth_logout_all_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_logout_all_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_logout_all.id
+  http_method = aws_api_gateway_method.auth_logout_all_options.http_method
+  status_code = aws_api_gateway_method_response.auth_logout_all_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for auth logout
+resource "aws_lambda_permission" "api_gateway_auth_logout" {
+  statement_id  = "AllowAPIGatewayInvokeAuthLogout"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_logout.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for auth logout all
+resource "aws_lambda_permission" "api_gateway_auth_logout_all" {
+  statement_id  = "AllowAPIGatewayInvokeAuthLogoutAll"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_logout_all.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /auth/challenge resource
+resource "aws_api_gateway_resource" "auth_challenge" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth.id
+  path_part   = "challenge"
+}
+
+# POST method on /auth/challenge
+resource "aws_api_gateway_method" "auth_challenge_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_challenge.id
+  http_method   = "POST"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_challenge_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_challenge.id
+  http_method = aws_api_gateway_method.auth_challenge_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_challenge.invoke_arn
+}
+
+# OPTIONS method for /auth/challenge (CORS preflight)
+resource "aws_api_gateway_method" "auth_challenge_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_challenge.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_challenge_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_challenge.id
+  http_method = aws_api_gateway_method.auth_challenge_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_challenge_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_challenge.id
+  http_method = aws_api_gateway_method.auth_challenge_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_challenge_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_challenge.id
+  http_method = aws_api_gateway_method.auth_challenge_options.http_method
+  status_code = aws_api_gateway_method_response.auth_challenge_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for auth challenge
+resource "aws_lambda_permission" "api_gateway_auth_challenge" {
+  statement_id  = "AllowAPIGatewayInvokeAuthChallenge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_challenge.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# /auth/mfa resource
+resource "aws_api_gateway_resource" "auth_mfa" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth.id
+  path_part   = "mfa"
+}
+
+# /auth/mfa/setup resource
+resource "aws_api_gateway_resource" "auth_mfa_setup" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth_mfa.id
+  path_part   = "setup"
+}
+
+# /auth/mfa/verify resource
+resource "aws_api_gateway_resource" "auth_mfa_verify" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth_mfa.id
+  path_part   = "verify"
+}
+
+# /auth/mfa/preference resource
+resource "aws_api_gateway_resource" "auth_mfa_preference" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth_mfa.id
+  path_part   = "preference"
+}
+
+# /auth/mfa/disable resource
+resource "aws_api_gateway_resource" "auth_mfa_disable" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  parent_id   = aws_api_gateway_resource.auth_mfa.id
+  path_part   = "disable"
+}
+
+# POST method on /auth/mfa/setup
+resource "aws_api_gateway_method" "auth_mfa_setup_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_setup.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_setup_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_setup.id
+  http_method = aws_api_gateway_method.auth_mfa_setup_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_mfa_setup.invoke_arn
+}
+
+# OPTIONS method for /auth/mfa/setup (CORS preflight)
+resource "aws_api_gateway_method" "auth_mfa_setup_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_setup.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_setup_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_setup.id
+  http_method = aws_api_gateway_method.auth_mfa_setup_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_mfa_setup_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_setup.id
+  http_method = aws_api_gateway_method.auth_mfa_setup_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_mfa_setup_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_setup.id
+  http_method = aws_api_gateway_method.auth_mfa_setup_options.http_method
+  status_code = aws_api_gateway_method_response.auth_mfa_setup_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# POST method on /auth/mfa/verify
+resource "aws_api_gateway_method" "auth_mfa_verify_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_verify.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_verify_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_verify.id
+  http_method = aws_api_gateway_method.auth_mfa_verify_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_mfa_verify.invoke_arn
+}
+
+# OPTIONS method for /auth/mfa/verify (CORS preflight)
+resource "aws_api_gateway_method" "auth_mfa_verify_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_verify.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_verify_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_verify.id
+  http_method = aws_api_gateway_method.auth_mfa_verify_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_mfa_verify_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_verify.id
+  http_method = aws_api_gateway_method.auth_mfa_verify_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_mfa_verify_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_verify.id
+  http_method = aws_api_gateway_method.auth_mfa_verify_options.http_method
+  status_code = aws_api_gateway_method_response.auth_mfa_verify_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# POST method on /auth/mfa/preference
+resource "aws_api_gateway_method" "auth_mfa_preference_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_preference.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_preference_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_preference.id
+  http_method = aws_api_gateway_method.auth_mfa_preference_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_mfa_preference.invoke_arn
+}
+
+# OPTIONS method for /auth/mfa/preference (CORS preflight)
+resource "aws_api_gateway_method" "auth_mfa_preference_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_preference.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_preference_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_preference.id
+  http_method = aws_api_gateway_method.auth_mfa_preference_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_mfa_preference_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_preference.id
+  http_method = aws_api_gateway_method.auth_mfa_preference_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_mfa_preference_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_preference.id
+  http_method = aws_api_gateway_method.auth_mfa_preference_options.http_method
+  status_code = aws_api_gateway_method_response.auth_mfa_preference_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# POST method on /auth/mfa/disable
+resource "aws_api_gateway_method" "auth_mfa_disable_post" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_disable.id
+  http_method   = "POST"
+  authorization = "COGNITO_USER_POOLS"
+  authorizer_id = aws_api_gateway_authorizer.cognito.id
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_disable_post_lambda" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_disable.id
+  http_method = aws_api_gateway_method.auth_mfa_disable_post.http_method
+
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.auth_mfa_disable.invoke_arn
+}
+
+# OPTIONS method for /auth/mfa/disable (CORS preflight)
+resource "aws_api_gateway_method" "auth_mfa_disable_options" {
+  rest_api_id   = aws_api_gateway_rest_api.main.id
+  resource_id   = aws_api_gateway_resource.auth_mfa_disable.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+resource "aws_api_gateway_integration" "auth_mfa_disable_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_disable.id
+  http_method = aws_api_gateway_method.auth_mfa_disable_options.http_method
+  type        = "MOCK"
+
+  request_templates = {
+    "application/json" = "{\"statusCode\": 200}"
+  }
+}
+
+resource "aws_api_gateway_method_response" "auth_mfa_disable_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_disable.id
+  http_method = aws_api_gateway_method.auth_mfa_disable_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Origin"  = true
+  }
+}
+
+resource "aws_api_gateway_integration_response" "auth_mfa_disable_options" {
+  rest_api_id = aws_api_gateway_rest_api.main.id
+  resource_id = aws_api_gateway_resource.auth_mfa_disable.id
+  http_method = aws_api_gateway_method.auth_mfa_disable_options.http_method
+  status_code = aws_api_gateway_method_response.auth_mfa_disable_options.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,Authorization'"
+    "method.response.header.Access-Control-Allow-Methods" = "'POST,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+  }
+}
+
+# Lambda permission for auth mfa setup
+resource "aws_lambda_permission" "api_gateway_auth_mfa_setup" {
+  statement_id  = "AllowAPIGatewayInvokeAuthMfaSetup"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_mfa_setup.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for auth mfa verify
+resource "aws_lambda_permission" "api_gateway_auth_mfa_verify" {
+  statement_id  = "AllowAPIGatewayInvokeAuthMfaVerify"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_mfa_verify.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for auth mfa preference
+resource "aws_lambda_permission" "api_gateway_auth_mfa_preference" {
+  statement_id  = "AllowAPIGatewayInvokeAuthMfaPreference"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_mfa_preference.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
+# Lambda permission for auth mfa disable
+resource "aws_lambda_permission" "api_gateway_auth_mfa_disable" {
+  statement_id  = "AllowAPIGatewayInvokeAuthMfaDisable"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_mfa_disable.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_api_gateway_rest_api.main.execution_arn}/*/*"
+}
+
 # API Gateway deployment
 resource "aws_api_gateway_deployment" "main" {
   depends_on = [
@@ -518,6 +2913,75 @@ resource "aws_api_gateway_deployment" "main" {
     aws_api_gateway_integration_response.auth_verify_options,
     aws_api_gateway_integration_response.auth_resend_code_options,
     aws_api_gateway_integration_response.chat_options,
+    aws_api_gateway_integration.conversations_get_lambda,
+    aws_api_gateway_integration.conversations_post_lambda,
+    aws_api_gateway_integration_response.conversations_options,
+    aws_api_gateway_integration.conversation_get_lambda,
+    aws_api_gateway_integration.conversation_delete_lambda,
+    aws_api_gateway_integration_response.conversation_options,
+    aws_api_gateway_integration.conversation_messages_get_lambda,
+    aws_api_gateway_integration_response.conversation_messages_options,
+    aws_api_gateway_integration.knowledge_get_lambda,
+    aws_api_gateway_integration.knowledge_post_lambda,
+    aws_api_gateway_integration_response.knowledge_options,
+    aws_api_gateway_integration.knowledge_id_get_lambda,
+    aws_api_gateway_integration.knowledge_id_put_lambda,
+    aws_api_gateway_integration_response.knowledge_id_options,
+    aws_api_gateway_integration.usage_get_lambda,
+    aws_api_gateway_integration_response.usage_options,
+    aws_api_gateway_integration.teams_get_lambda,
+    aws_api_gateway_integration.teams_post_lambda,
+    aws_api_gateway_integration_response.teams_options,
+    aws_api_gateway_integration.team_get_lambda,
+    aws_api_gateway_integration.team_put_lambda,
+    aws_api_gateway_integration.team_delete_lambda,
+    aws_api_gateway_integration_response.team_options,
+    aws_api_gateway_integration.team_members_get_lambda,
+    aws_api_gateway_integration.team_members_post_lambda,
+    aws_api_gateway_integration_response.team_members_options,
+    aws_api_gateway_integration.team_member_put_lambda,
+    aws_api_gateway_integration.team_member_delete_lambda,
+    aws_api_gateway_integration_response.team_member_options,
+    aws_api_gateway_integration.documents_get_lambda,
+    aws_api_gateway_integration.documents_post_lambda,
+    aws_api_gateway_integration_response.documents_options,
+    aws_api_gateway_integration.document_get_lambda,
+    aws_api_gateway_integration.document_put_lambda,
+    aws_api_gateway_integration.document_delete_lambda,
+    aws_api_gateway_integration_response.document_options,
+    aws_api_gateway_integration.document_versions_get_lambda,
+    aws_api_gateway_integration_response.document_versions_options,
+    aws_api_gateway_integration.prompts_get_lambda,
+    aws_api_gateway_integration.prompts_post_lambda,
+    aws_api_gateway_integration_response.prompts_options,
+    aws_api_gateway_integration.prompt_get_lambda,
+    aws_api_gateway_integration.prompt_put_lambda,
+    aws_api_gateway_integration_response.prompt_options,
+    aws_api_gateway_integration.prompt_versions_get_lambda,
+    aws_api_gateway_integration.prompt_versions_post_lambda,
+    aws_api_gateway_integration_response.prompt_versions_options,
+    aws_api_gateway_integration.prompt_preview_post_lambda,
+    aws_api_gateway_integration_response.prompt_preview_options,
+    aws_api_gateway_integration.auth_forgot_password_post_lambda,
+    aws_api_gateway_integration_response.auth_forgot_password_options,
+    aws_api_gateway_integration.auth_reset_password_post_lambda,
+    aws_api_gateway_integration_response.auth_reset_password_options,
+    aws_api_gateway_integration.auth_refresh_post_lambda,
+    aws_api_gateway_integration_response.auth_refresh_options,
+    aws_api_gateway_integration.auth_logout_post_lambda,
+    aws_api_gateway_integration_response.auth_logout_options,
+    aws_api_gateway_integration.auth_logout_all_post_lambda,
+    aws_api_gateway_integration_response.auth_logout_all_options,
+    aws_api_gateway_integration.auth_challenge_post_lambda,
+    aws_api_gateway_integration_response.auth_challenge_options,
+    aws_api_gateway_integration.auth_mfa_setup_post_lambda,
+    aws_api_gateway_integration_response.auth_mfa_setup_options,
+    aws_api_gateway_integration.auth_mfa_verify_post_lambda,
+    aws_api_gateway_integration_response.auth_mfa_verify_options,
+    aws_api_gateway_integration.auth_mfa_preference_post_lambda,
+    aws_api_gateway_integration_response.auth_mfa_preference_options,
+    aws_api_gateway_integration.auth_mfa_disable_post_lambda,
+    aws_api_gateway_integration_response.auth_mfa_disable_options,
   ]
 
   rest_api_id = aws_api_gateway_rest_api.main.id
@@ -556,6 +3020,172 @@ resource "aws_api_gateway_deployment" "main" {
       aws_api_gateway_method.chat_options.id,
       aws_api_gateway_integration.chat_lambda.id,
       aws_api_gateway_integration_response.chat_options.id,
+      aws_api_gateway_resource.conversations.id,
+      aws_api_gateway_resource.conversation.id,
+      aws_api_gateway_resource.conversation_messages.id,
+      aws_api_gateway_method.conversations_get.id,
+      aws_api_gateway_integration.conversations_get_lambda.id,
+      aws_api_gateway_method.conversations_post.id,
+      aws_api_gateway_integration.conversations_post_lambda.id,
+      aws_api_gateway_method.conversations_options.id,
+      aws_api_gateway_integration_response.conversations_options.id,
+      aws_api_gateway_method.conversation_get.id,
+      aws_api_gateway_integration.conversation_get_lambda.id,
+      aws_api_gateway_method.conversation_delete.id,
+      aws_api_gateway_integration.conversation_delete_lambda.id,
+      aws_api_gateway_method.conversation_options.id,
+      aws_api_gateway_integration_response.conversation_options.id,
+      aws_api_gateway_method.conversation_messages_get.id,
+      aws_api_gateway_integration.conversation_messages_get_lambda.id,
+      aws_api_gateway_method.conversation_messages_options.id,
+      aws_api_gateway_integration_response.conversation_messages_options.id,
+      aws_api_gateway_resource.knowledge.id,
+      aws_api_gateway_resource.knowledge_id.id,
+      aws_api_gateway_method.knowledge_get.id,
+      aws_api_gateway_integration.knowledge_get_lambda.id,
+      aws_api_gateway_method.knowledge_post.id,
+      aws_api_gateway_integration.knowledge_post_lambda.id,
+      aws_api_gateway_method.knowledge_options.id,
+      aws_api_gateway_integration_response.knowledge_options.id,
+      aws_api_gateway_method.knowledge_id_get.id,
+      aws_api_gateway_integration.knowledge_id_get_lambda.id,
+      aws_api_gateway_method.knowledge_id_put.id,
+      aws_api_gateway_integration.knowledge_id_put_lambda.id,
+      aws_api_gateway_method.knowledge_id_options.id,
+      aws_api_gateway_integration_response.knowledge_id_options.id,
+      aws_api_gateway_resource.usage.id,
+      aws_api_gateway_method.usage_get.id,
+      aws_api_gateway_integration.usage_get_lambda.id,
+      aws_api_gateway_method.usage_options.id,
+      aws_api_gateway_integration_response.usage_options.id,
+      aws_api_gateway_resource.teams.id,
+      aws_api_gateway_resource.team.id,
+      aws_api_gateway_resource.team_members.id,
+      aws_api_gateway_resource.team_member.id,
+      aws_api_gateway_method.teams_get.id,
+      aws_api_gateway_integration.teams_get_lambda.id,
+      aws_api_gateway_method.teams_post.id,
+      aws_api_gateway_integration.teams_post_lambda.id,
+      aws_api_gateway_method.teams_options.id,
+      aws_api_gateway_integration_response.teams_options.id,
+      aws_api_gateway_method.team_get.id,
+      aws_api_gateway_integration.team_get_lambda.id,
+      aws_api_gateway_method.team_put.id,
+      aws_api_gateway_integration.team_put_lambda.id,
+      aws_api_gateway_method.team_delete.id,
+      aws_api_gateway_integration.team_delete_lambda.id,
+      aws_api_gateway_method.team_options.id,
+      aws_api_gateway_integration_response.team_options.id,
+      aws_api_gateway_method.team_members_get.id,
+      aws_api_gateway_integration.team_members_get_lambda.id,
+      aws_api_gateway_method.team_members_post.id,
+      aws_api_gateway_integration.team_members_post_lambda.id,
+      aws_api_gateway_method.team_members_options.id,
+      aws_api_gateway_integration_response.team_members_options.id,
+      aws_api_gateway_method.team_member_put.id,
+      aws_api_gateway_integration.team_member_put_lambda.id,
+      aws_api_gateway_method.team_member_delete.id,
+      aws_api_gateway_integration.team_member_delete_lambda.id,
+      aws_api_gateway_method.team_member_options.id,
+      aws_api_gateway_integration_response.team_member_options.id,
+      aws_api_gateway_resource.documents.id,
+      aws_api_gateway_resource.document.id,
+      aws_api_gateway_resource.document_versions.id,
+      aws_api_gateway_method.documents_get.id,
+      aws_api_gateway_integration.documents_get_lambda.id,
+      aws_api_gateway_method.documents_post.id,
+      aws_api_gateway_integration.documents_post_lambda.id,
+      aws_api_gateway_method.documents_options.id,
+      aws_api_gateway_integration_response.documents_options.id,
+      aws_api_gateway_method.document_get.id,
+      aws_api_gateway_integration.document_get_lambda.id,
+      aws_api_gateway_method.document_put.id,
+      aws_api_gateway_integration.document_put_lambda.id,
+      aws_api_gateway_method.document_delete.id,
+      aws_api_gateway_integration.document_delete_lambda.id,
+      aws_api_gateway_method.document_options.id,
+      aws_api_gateway_integration_response.document_options.id,
+      aws_api_gateway_method.document_versions_get.id,
+      aws_api_gateway_integration.document_versions_get_lambda.id,
+      aws_api_gateway_method.document_versions_options.id,
+      aws_api_gateway_integration_response.document_versions_options.id,
+      aws_api_gateway_resource.prompts.id,
+      aws_api_gateway_resource.prompt.id,
+      aws_api_gateway_resource.prompt_versions.id,
+      aws_api_gateway_resource.prompt_preview.id,
+      aws_api_gateway_method.prompts_get.id,
+      aws_api_gateway_integration.prompts_get_lambda.id,
+      aws_api_gateway_method.prompts_post.id,
+      aws_api_gateway_integration.prompts_post_lambda.id,
+      aws_api_gateway_method.prompts_options.id,
+      aws_api_gateway_integration_response.prompts_options.id,
+      aws_api_gateway_method.prompt_get.id,
+      aws_api_gateway_integration.prompt_get_lambda.id,
+      aws_api_gateway_method.prompt_put.id,
+      aws_api_gateway_integration.prompt_put_lambda.id,
+      aws_api_gateway_method.prompt_options.id,
+      aws_api_gateway_integration_response.prompt_options.id,
+      aws_api_gateway_method.prompt_versions_get.id,
+      aws_api_gateway_integration.prompt_versions_get_lambda.id,
+      aws_api_gateway_method.prompt_versions_post.id,
+      aws_api_gateway_integration.prompt_versions_post_lambda.id,
+      aws_api_gateway_method.prompt_versions_options.id,
+      aws_api_gateway_integration_response.prompt_versions_options.id,
+      aws_api_gateway_method.prompt_preview_post.id,
+      aws_api_gateway_integration.prompt_preview_post_lambda.id,
+      aws_api_gateway_method.prompt_preview_options.id,
+      aws_api_gateway_integration_response.prompt_preview_options.id,
+      aws_api_gateway_resource.auth_forgot_password.id,
+      aws_api_gateway_resource.auth_reset_password.id,
+      aws_api_gateway_method.auth_forgot_password_post.id,
+      aws_api_gateway_integration.auth_forgot_password_post_lambda.id,
+      aws_api_gateway_method.auth_forgot_password_options.id,
+      aws_api_gateway_integration_response.auth_forgot_password_options.id,
+      aws_api_gateway_method.auth_reset_password_post.id,
+      aws_api_gateway_integration.auth_reset_password_post_lambda.id,
+      aws_api_gateway_method.auth_reset_password_options.id,
+      aws_api_gateway_integration_response.auth_reset_password_options.id,
+      aws_api_gateway_resource.auth_refresh.id,
+      aws_api_gateway_method.auth_refresh_post.id,
+      aws_api_gateway_integration.auth_refresh_post_lambda.id,
+      aws_api_gateway_method.auth_refresh_options.id,
+      aws_api_gateway_integration_response.auth_refresh_options.id,
+      aws_api_gateway_resource.auth_logout.id,
+      aws_api_gateway_resource.auth_logout_all.id,
+      aws_api_gateway_method.auth_logout_post.id,
+      aws_api_gateway_integration.auth_logout_post_lambda.id,
+      aws_api_gateway_method.auth_logout_options.id,
+      aws_api_gateway_integration_response.auth_logout_options.id,
+      aws_api_gateway_method.auth_logout_all_post.id,
+      aws_api_gateway_integration.auth_logout_all_post_lambda.id,
+      aws_api_gateway_method.auth_logout_all_options.id,
+      aws_api_gateway_integration_response.auth_logout_all_options.id,
+      aws_api_gateway_resource.auth_challenge.id,
+      aws_api_gateway_method.auth_challenge_post.id,
+      aws_api_gateway_integration.auth_challenge_post_lambda.id,
+      aws_api_gateway_method.auth_challenge_options.id,
+      aws_api_gateway_integration_response.auth_challenge_options.id,
+      aws_api_gateway_resource.auth_mfa.id,
+      aws_api_gateway_resource.auth_mfa_setup.id,
+      aws_api_gateway_resource.auth_mfa_verify.id,
+      aws_api_gateway_resource.auth_mfa_preference.id,
+      aws_api_gateway_resource.auth_mfa_disable.id,
+      aws_api_gateway_method.auth_mfa_setup_post.id,
+      aws_api_gateway_integration.auth_mfa_setup_post_lambda.id,
+      aws_api_gateway_method.auth_mfa_setup_options.id,
+      aws_api_gateway_integration_response.auth_mfa_setup_options.id,
+      aws_api_gateway_method.auth_mfa_verify_post.id,
+      aws_api_gateway_integration.auth_mfa_verify_post_lambda.id,
+      aws_api_gateway_method.auth_mfa_verify_options.id,
+      aws_api_gateway_integration_response.auth_mfa_verify_options.id,
+      aws_api_gateway_method.auth_mfa_preference_post.id,
+      aws_api_gateway_integration.auth_mfa_preference_post_lambda.id,
+      aws_api_gateway_method.auth_mfa_preference_options.id,
+      aws_api_gateway_integration_response.auth_mfa_preference_options.id,
+      aws_api_gateway_method.auth_mfa_disable_post.id,
+      aws_api_gateway_integration.auth_mfa_disable_post_lambda.id,
+      aws_api_gateway_method.auth_mfa_disable_options.id,
+      aws_api_gateway_integration_response.auth_mfa_disable_options.id,
       timestamp(),
     ]))
   }
@@ -55,3 +55,222 @@ resource "aws_cloudwatch_log_group" "lambda_auth_resend_code" {
     Name = "${var.project_name}-${var.environment}-auth-resend-code-logs"
   }
 }
+
+resource "aws_cloudwatch_log_group" "lambda_chat_stream" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-chat-stream"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-chat-stream-logs"
+  }
+}
+
+# CloudWatch Log Group for Conversations Lambda
+resource "aws_cloudwatch_log_group" "lambda_conversations" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-conversations"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-conversations-logs"
+  }
+}
+
+# CloudWatch Log Group for Conversation Messages Lambda
+resource "aws_cloudwatch_log_group" "lambda_conversation_messages" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-conversation-messages"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-conversation-messages-logs"
+  }
+}
+
+# CloudWatch Log Group for Knowledge Lambda
+resource "aws_cloudwatch_log_group" "lambda_knowledge" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-knowledge"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-knowledge-logs"
+  }
+}
+
+# CloudWatch Log Group for Usage Lambda
+resource "aws_cloudwatch_log_group" "lambda_usage" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-usage"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-usage-logs"
+  }
+}
+
+# CloudWatch Log Group for Teams Lambda
+resource "aws_cloudwatch_log_group" "lambda_teams" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-teams"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-teams-logs"
+  }
+}
+
+# CloudWatch Log Group for Team members Lambda
+resource "aws_cloudwatch_log_group" "lambda_team_members" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-team-members"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-team-members-logs"
+  }
+}
+
+# CloudWatch Log Group for Documents Lambda
+resource "aws_cloudwatch_log_group" "lambda_documents" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-documents"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-documents-logs"
+  }
+}
+
+# CloudWatch Log Group for Document versions Lambda
+resource "aws_cloudwatch_log_group" "lambda_document_versions" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-document-versions"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-document-versions-logs"
+  }
+}
+
+# CloudWatch Log Group for Prompts Lambda
+resource "aws_cloudwatch_log_group" "lambda_prompts" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-prompts"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-prompts-logs"
+  }
+}
+
+# CloudWatch Log Group for Prompt versions Lambda
+resource "aws_cloudwatch_log_group" "lambda_prompt_versions" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-prompt-versions"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-prompt-versions-logs"
+  }
+}
+
+# CloudWatch Log Group for Prompt preview Lambda
+resource "aws_cloudwatch_log_group" "lambda_prompt_preview" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-prompt-preview"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-prompt-preview-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth Forgot Password Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_forgot_password" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-forgot-password"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-forgot-password-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth Reset Password Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_reset_password" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-reset-password"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-reset-password-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth Refresh Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_refresh" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-refresh"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-refresh-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth Logout Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_logout" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-logout"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-logout-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth Logout All Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_logout_all" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-logout-all"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-logout-all-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth Challenge Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_challenge" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-challenge"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-challenge-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth MFA Setup Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_mfa_setup" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-mfa-setup"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-mfa-setup-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth MFA Verify Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_mfa_verify" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-mfa-verify"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-mfa-verify-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth MFA Preference Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_mfa_preference" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-mfa-preference"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-mfa-preference-logs"
+  }
+}
+
+# CloudWatch Log Group for Auth MFA Disable Lambda
+resource "aws_cloudwatch_log_group" "lambda_auth_mfa_disable" {
+  name              = "/aws/lambda/${var.project_name}-${var.environment}-auth-mfa-disable"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-auth-mfa-disable-logs"
+  }
+}
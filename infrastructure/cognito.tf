@@ -48,7 +48,13 @@ resource "aws_cognito_user_pool" "main" {
     email_sending_account = "COGNITO_DEFAULT"
   }
 
-  mfa_configuration = "OFF"
+  # Users opt in to authenticator app MFA at /auth/mfa/setup; sign in then
+  # asks for a SOFTWARE_TOKEN_MFA code, answered at /auth/challenge
+  mfa_configuration = "OPTIONAL"
+
+  software_token_mfa_configuration {
+    enabled = true
+  }
 
   verification_message_template {
     default_email_option = "CONFIRM_WITH_CODE"
@@ -83,6 +89,9 @@ resource "aws_cognito_user_pool_client" "main" {
 
   prevent_user_existence_errors = "ENABLED"
 
+  # Adds origin_jti to tokens and enables RevokeToken, used by /auth/logout
+  enable_token_revocation = true
+
   generate_secret = false
 
   read_attributes = [
@@ -97,6 +106,13 @@ resource "aws_cognito_user_pool_client" "main" {
   ]
 }
 
+# Members of the admin group can manage shared data such as the knowledge base
+resource "aws_cognito_user_group" "admin" {
+  name         = "admin"
+  user_pool_id = aws_cognito_user_pool.main.id
+  description  = "TuiTui administrators"
+}
+
 resource "aws_cognito_user_pool_domain" "main" {
   domain       = "${var.project_name}-${var.environment}-${random_string.cognito_domain_suffix.result}"
   user_pool_id = aws_cognito_user_pool.main.id
@@ -0,0 +1,224 @@
+# DynamoDB table for conversations and messages
+# Items are partitioned by user ("USER#<sub>") with sort keys "CONV#<id>" for
+# conversations and "MSG#<id>#<timestamp>#<seq>" for messages.
+resource "aws_dynamodb_table" "conversations" {
+  name         = "${var.project_name}-${var.environment}-conversations"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "PK"
+  range_key    = "SK"
+
+  attribute {
+    name = "PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "SK"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-conversations"
+  }
+}
+
+# DynamoDB table for knowledge base entries, keyed by entry ID
+resource "aws_dynamodb_table" "knowledge" {
+  name         = "${var.project_name}-${var.environment}-knowledge"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "ID"
+
+  attribute {
+    name = "ID"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-knowledge"
+  }
+}
+
+# DynamoDB table for token usage counters
+# Items are partitioned by subject ("USER#<sub>" or "TEAM#<team>") with sort keys
+# "DAY#<yyyy-mm-dd>" and "MONTH#<yyyy-mm>".
+resource "aws_dynamodb_table" "usage" {
+  name         = "${var.project_name}-${var.environment}-usage"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "PK"
+  range_key    = "SK"
+
+  attribute {
+    name = "PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "SK"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-usage"
+  }
+}
+
+# DynamoDB table for chat rate limit counters
+# Keys name the user and window ("REQUESTS#<sub>#<minute>", "TOKENS#<sub>#<day>");
+# old windows are removed by TTL on ExpiresAt.
+resource "aws_dynamodb_table" "rate_limits" {
+  name         = "${var.project_name}-${var.environment}-rate-limits"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "Key"
+
+  attribute {
+    name = "Key"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-rate-limits"
+  }
+}
+
+# DynamoDB table for signed out sessions, checked by the guarded Lambdas
+# Keys name a revoked refresh token ("SESSION#<origin_jti>") or a global sign
+# out ("USER#<sub>"); entries are removed by TTL on ExpiresAt once every token
+# they refuse has expired.
+resource "aws_dynamodb_table" "revoked_sessions" {
+  name         = "${var.project_name}-${var.environment}-revoked-sessions"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "Key"
+
+  attribute {
+    name = "Key"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-revoked-sessions"
+  }
+}
+
+# DynamoDB table for teams and their members
+# Each team has the partition "TEAM#<id>" holding the team under the sort key
+# "TEAM" and one "MEMBER#<sub>" item per member. The UserTeams index finds the
+# teams a user belongs to.
+resource "aws_dynamodb_table" "teams" {
+  name         = "${var.project_name}-${var.environment}-teams"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "PK"
+  range_key    = "SK"
+
+  attribute {
+    name = "PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "SK"
+    type = "S"
+  }
+
+  attribute {
+    name = "UserID"
+    type = "S"
+  }
+
+  attribute {
+    name = "TeamID"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "UserTeams"
+    hash_key        = "UserID"
+    range_key       = "TeamID"
+    projection_type = "ALL"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-teams"
+  }
+}
+
+# DynamoDB table for uploaded document metadata
+# Items are partitioned by user ("USER#<sub>"); documents use the sort key
+# "DOC#<id>" and their versions "VER#<id>#<version>". Content lives in S3.
+resource "aws_dynamodb_table" "documents" {
+  name         = "${var.project_name}-${var.environment}-documents"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "PK"
+  range_key    = "SK"
+
+  attribute {
+    name = "PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "SK"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-documents"
+  }
+}
+
+# DynamoDB table for system prompt templates
+# Templates are shared, so every item is in the partition "PROMPTS"; templates
+# use the sort key "TEMPLATE#<name>" and their versions "VERSION#<name>#<version>".
+resource "aws_dynamodb_table" "prompts" {
+  name         = "${var.project_name}-${var.environment}-prompts"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "PK"
+  range_key    = "SK"
+
+  attribute {
+    name = "PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "SK"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-prompts"
+  }
+}
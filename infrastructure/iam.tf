@@ -57,7 +57,8 @@ resource "aws_iam_role_policy" "lambda_cognito" {
           "cognito-idp:SignUp",
           "cognito-idp:InitiateAuth",
           "cognito-idp:ConfirmSignUp",
-          "cognito-idp:ResendConfirmationCode"
+          "cognito-idp:ResendConfirmationCode",
+          "cognito-idp:ListUsers"
         ]
         Resource = "*"
       }
@@ -65,6 +66,62 @@ resource "aws_iam_role_policy" "lambda_cognito" {
   })
 }
 
+# Custom policy for Bedrock model access (used when ai_provider is "bedrock")
+resource "aws_iam_role_policy" "lambda_bedrock" {
+  name = "${var.project_name}-${var.environment}-lambda-bedrock"
+  role = aws_iam_role.lambda_execution.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "bedrock:InvokeModel",
+          "bedrock:InvokeModelWithResponseStream"
+        ]
+        Resource = "*"
+      }
+    ]
+  })
+}
+
+# Custom policy for DynamoDB access
+resource "aws_iam_role_policy" "lambda_dynamodb" {
+  name = "${var.project_name}-${var.environment}-lambda-dynamodb"
+  role = aws_iam_role.lambda_execution.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:PutItem",
+          "dynamodb:UpdateItem",
+          "dynamodb:DeleteItem",
+          "dynamodb:Query",
+          "dynamodb:BatchWriteItem",
+          "dynamodb:Scan"
+        ]
+        Resource = [
+          aws_dynamodb_table.conversations.arn,
+          aws_dynamodb_table.knowledge.arn,
+          aws_dynamodb_table.usage.arn,
+          aws_dynamodb_table.rate_limits.arn,
+          aws_dynamodb_table.revoked_sessions.arn,
+          aws_dynamodb_table.teams.arn,
+          "${aws_dynamodb_table.teams.arn}/index/*",
+          aws_dynamodb_table.documents.arn,
+          aws_dynamodb_table.prompts.arn
+        ]
+      }
+    ]
+  })
+}
+
+
 # IAM role for API Gateway CloudWatch logging
 resource "aws_iam_role" "api_gateway_cloudwatch" {
   name = "${var.project_name}-${var.environment}-api-gateway-cloudwatch"
@@ -93,3 +150,30 @@ resource "aws_iam_role_policy_attachment" "api_gateway_cloudwatch" {
 resource "aws_api_gateway_account" "main" {
   cloudwatch_role_arn = aws_iam_role.api_gateway_cloudwatch.arn
 }
+
+# Custom policy for uploaded document content in S3. ListBucket lets a missing
+# object be reported as not found rather than access denied.
+resource "aws_iam_role_policy" "lambda_s3_documents" {
+  name = "${var.project_name}-${var.environment}-lambda-s3-documents"
+  role = aws_iam_role.lambda_execution.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:PutObject",
+          "s3:DeleteObject"
+        ]
+        Resource = "${aws_s3_bucket.documents.arn}/*"
+      },
+      {
+        Effect   = "Allow"
+        Action   = "s3:ListBucket"
+        Resource = aws_s3_bucket.documents.arn
+      }
+    ]
+  })
+}
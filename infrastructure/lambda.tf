@@ -35,6 +35,132 @@ data "archive_file" "lambda_auth_resend_code" {
   output_path = "${path.module}/.terraform/lambda_auth_resend_code.zip"
 }
 
+data "archive_file" "lambda_conversations" {
+  type        = "zip"
+  source_dir  = "../backend/bin/conversations"
+  output_path = "${path.module}/.terraform/lambda_conversations.zip"
+}
+
+data "archive_file" "lambda_conversation_messages" {
+  type        = "zip"
+  source_dir  = "../backend/bin/conversation-messages"
+  output_path = "${path.module}/.terraform/lambda_conversation_messages.zip"
+}
+
+data "archive_file" "lambda_knowledge" {
+  type        = "zip"
+  source_dir  = "../backend/bin/knowledge"
+  output_path = "${path.module}/.terraform/lambda_knowledge.zip"
+}
+
+data "archive_file" "lambda_usage" {
+  type        = "zip"
+  source_dir  = "../backend/bin/usage"
+  output_path = "${path.module}/.terraform/lambda_usage.zip"
+}
+
+data "archive_file" "lambda_teams" {
+  type        = "zip"
+  source_dir  = "../backend/bin/teams"
+  output_path = "${path.module}/.terraform/lambda_teams.zip"
+}
+
+data "archive_file" "lambda_team_members" {
+  type        = "zip"
+  source_dir  = "../backend/bin/team-members"
+  output_path = "${path.module}/.terraform/lambda_team_members.zip"
+}
+
+data "archive_file" "lambda_documents" {
+  type        = "zip"
+  source_dir  = "../backend/bin/documents"
+  output_path = "${path.module}/.terraform/lambda_documents.zip"
+}
+
+data "archive_file" "lambda_document_versions" {
+  type        = "zip"
+  source_dir  = "../backend/bin/document-versions"
+  output_path = "${path.module}/.terraform/lambda_document_versions.zip"
+}
+
+data "archive_file" "lambda_prompts" {
+  type        = "zip"
+  source_dir  = "../backend/bin/prompts"
+  output_path = "${path.module}/.terraform/lambda_prompts.zip"
+}
+
+data "archive_file" "lambda_prompt_versions" {
+  type        = "zip"
+  source_dir  = "../backend/bin/prompt-versions"
+  output_path = "${path.module}/.terraform/lambda_prompt_versions.zip"
+}
+
+data "archive_file" "lambda_prompt_preview" {
+  type        = "zip"
+  source_dir  = "../backend/bin/prompt-preview"
+  output_path = "${path.module}/.terraform/lambda_prompt_preview.zip"
+}
+
+data "archive_file" "lambda_auth_forgot_password" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-forgot-password"
+  output_path = "${path.module}/.terraform/lambda_auth_forgot_password.zip"
+}
+
+data "archive_file" "lambda_auth_reset_password" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-reset-password"
+  output_path = "${path.module}/.terraform/lambda_auth_reset_password.zip"
+}
+
+data "archive_file" "lambda_auth_refresh" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-refresh"
+  output_path = "${path.module}/.terraform/lambda_auth_refresh.zip"
+}
+
+data "archive_file" "lambda_auth_logout" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-logout"
+  output_path = "${path.module}/.terraform/lambda_auth_logout.zip"
+}
+
+data "archive_file" "lambda_auth_logout_all" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-logout-all"
+  output_path = "${path.module}/.terraform/lambda_auth_logout_all.zip"
+}
+
+data "archive_file" "lambda_auth_challenge" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-challenge"
+  output_path = "${path.module}/.terraform/lambda_auth_challenge.zip"
+}
+
+data "archive_file" "lambda_auth_mfa_setup" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-mfa-setup"
+  output_path = "${path.module}/.terraform/lambda_auth_mfa_setup.zip"
+}
+
+data "archive_file" "lambda_auth_mfa_verify" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-mfa-verify"
+  output_path = "${path.module}/.terraform/lambda_auth_mfa_verify.zip"
+}
+
+data "archive_file" "lambda_auth_mfa_preference" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-mfa-preference"
+  output_path = "${path.module}/.terraform/lambda_auth_mfa_preference.zip"
+}
+
+data "archive_file" "lambda_auth_mfa_disable" {
+  type        = "zip"
+  source_dir  = "../backend/bin/auth-mfa-disable"
+  output_path = "${path.module}/.terraform/lambda_auth_mfa_disable.zip"
+}
+
 # Lambda function
 resource "aws_lambda_function" "health" {
   filename         = data.archive_file.lambda_health.output_path
@@ -133,8 +259,18 @@ resource "aws_lambda_function" "chat" {
       COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
       COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
       AMAZON_AI_API_KEY            = var.amazon_ai_api_key
+      AI_PROVIDER                  = var.ai_provider
       AI_MODEL_NAME                = var.ai_model_name
       AI_API_ENDPOINT              = var.ai_api_endpoint
+      CONVERSATIONS_TABLE          = aws_dynamodb_table.conversations.name
+      KNOWLEDGE_TABLE              = aws_dynamodb_table.knowledge.name
+      USAGE_TABLE                  = aws_dynamodb_table.usage.name
+      RATE_LIMIT_TABLE             = aws_dynamodb_table.rate_limits.name
+      REVOKED_SESSIONS_TABLE       = aws_dynamodb_table.revoked_sessions.name
+      TEAMS_TABLE                  = aws_dynamodb_table.teams.name
+      DOCUMENTS_TABLE              = aws_dynamodb_table.documents.name
+      DOCUMENTS_BUCKET             = aws_s3_bucket.documents.id
+      PROMPTS_TABLE                = aws_dynamodb_table.prompts.name
     }
   }
 
@@ -197,3 +333,645 @@ resource "aws_lambda_function" "auth_resend_code" {
     aws_cloudwatch_log_group.lambda_auth_resend_code
   ]
 }
+
+# Chat Stream Lambda function (same binary as chat, served through a streaming Function URL)
+resource "aws_lambda_function" "chat_stream" {
+  filename         = data.archive_file.lambda_chat.output_path
+  function_name    = "${var.project_name}-${var.environment}-chat-stream"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_chat.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      AMAZON_AI_API_KEY            = var.amazon_ai_api_key
+      AI_PROVIDER                  = var.ai_provider
+      AI_MODEL_NAME                = var.ai_model_name
+      AI_API_ENDPOINT              = var.ai_api_endpoint
+      CHAT_RESPONSE_STREAMING      = "true"
+      CONVERSATIONS_TABLE          = aws_dynamodb_table.conversations.name
+      KNOWLEDGE_TABLE              = aws_dynamodb_table.knowledge.name
+      USAGE_TABLE                  = aws_dynamodb_table.usage.name
+      RATE_LIMIT_TABLE             = aws_dynamodb_table.rate_limits.name
+      REVOKED_SESSIONS_TABLE       = aws_dynamodb_table.revoked_sessions.name
+      TEAMS_TABLE                  = aws_dynamodb_table.teams.name
+      DOCUMENTS_TABLE              = aws_dynamodb_table.documents.name
+      DOCUMENTS_BUCKET             = aws_s3_bucket.documents.id
+      PROMPTS_TABLE                = aws_dynamodb_table.prompts.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_chat_stream
+  ]
+}
+
+# Function URL for streaming chat responses (API Gateway REST APIs buffer responses).
+# It has no Cognito authorizer, so the handler verifies the caller's ID token itself.
+resource "aws_lambda_function_url" "chat_stream" {
+  function_name      = aws_lambda_function.chat_stream.function_name
+  authorization_type = "NONE"
+  invoke_mode        = "RESPONSE_STREAM"
+
+  cors {
+    allow_origins = ["*"]
+    allow_methods = ["POST"]
+    allow_headers = ["content-type", "authorization", "accept"]
+  }
+}
+
+# Conversations Lambda function
+resource "aws_lambda_function" "conversations" {
+  filename         = data.archive_file.lambda_conversations.output_path
+  function_name    = "${var.project_name}-${var.environment}-conversations"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_conversations.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      CONVERSATIONS_TABLE          = aws_dynamodb_table.conversations.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_conversations
+  ]
+}
+
+# Conversation Messages Lambda function
+resource "aws_lambda_function" "conversation_messages" {
+  filename         = data.archive_file.lambda_conversation_messages.output_path
+  function_name    = "${var.project_name}-${var.environment}-conversation-messages"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_conversation_messages.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      CONVERSATIONS_TABLE          = aws_dynamodb_table.conversations.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_conversation_messages
+  ]
+}
+
+# Knowledge Lambda function
+resource "aws_lambda_function" "knowledge" {
+  filename         = data.archive_file.lambda_knowledge.output_path
+  function_name    = "${var.project_name}-${var.environment}-knowledge"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_knowledge.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      KNOWLEDGE_TABLE              = aws_dynamodb_table.knowledge.name
+      ADMIN_GROUP                  = aws_cognito_user_group.admin.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_knowledge
+  ]
+}
+
+# Usage Lambda function
+resource "aws_lambda_function" "usage" {
+  filename         = data.archive_file.lambda_usage.output_path
+  function_name    = "${var.project_name}-${var.environment}-usage"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_usage.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      USAGE_TABLE                  = aws_dynamodb_table.usage.name
+      TEAMS_TABLE                  = aws_dynamodb_table.teams.name
+      ADMIN_GROUP                  = aws_cognito_user_group.admin.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_usage
+  ]
+}
+
+# Teams Lambda function
+resource "aws_lambda_function" "teams" {
+  filename         = data.archive_file.lambda_teams.output_path
+  function_name    = "${var.project_name}-${var.environment}-teams"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_teams.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      TEAMS_TABLE                  = aws_dynamodb_table.teams.name
+      ADMIN_GROUP                  = aws_cognito_user_group.admin.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_teams
+  ]
+}
+
+# Team members Lambda function
+resource "aws_lambda_function" "team_members" {
+  filename         = data.archive_file.lambda_team_members.output_path
+  function_name    = "${var.project_name}-${var.environment}-team-members"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_team_members.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      TEAMS_TABLE                  = aws_dynamodb_table.teams.name
+      ADMIN_GROUP                  = aws_cognito_user_group.admin.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_team_members
+  ]
+}
+
+# Documents Lambda function
+resource "aws_lambda_function" "documents" {
+  filename         = data.archive_file.lambda_documents.output_path
+  function_name    = "${var.project_name}-${var.environment}-documents"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_documents.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      DOCUMENTS_TABLE              = aws_dynamodb_table.documents.name
+      DOCUMENTS_BUCKET             = aws_s3_bucket.documents.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_documents
+  ]
+}
+
+# Document versions Lambda function
+resource "aws_lambda_function" "document_versions" {
+  filename         = data.archive_file.lambda_document_versions.output_path
+  function_name    = "${var.project_name}-${var.environment}-document-versions"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_document_versions.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      DOCUMENTS_TABLE              = aws_dynamodb_table.documents.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_document_versions
+  ]
+}
+
+# Prompts Lambda function
+resource "aws_lambda_function" "prompts" {
+  filename         = data.archive_file.lambda_prompts.output_path
+  function_name    = "${var.project_name}-${var.environment}-prompts"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_prompts.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      PROMPTS_TABLE                = aws_dynamodb_table.prompts.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_prompts
+  ]
+}
+
+# Prompt versions Lambda function
+resource "aws_lambda_function" "prompt_versions" {
+  filename         = data.archive_file.lambda_prompt_versions.output_path
+  function_name    = "${var.project_name}-${var.environment}-prompt-versions"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_prompt_versions.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      PROMPTS_TABLE                = aws_dynamodb_table.prompts.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_prompt_versions
+  ]
+}
+
+# Prompt preview Lambda function
+resource "aws_lambda_function" "prompt_preview" {
+  filename         = data.archive_file.lambda_prompt_preview.output_path
+  function_name    = "${var.project_name}-${var.environment}-prompt-preview"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_prompt_preview.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      PROMPTS_TABLE                = aws_dynamodb_table.prompts.name
+      TEAMS_TABLE                  = aws_dynamodb_table.teams.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_prompt_preview
+  ]
+}
+
+# Auth Forgot Password Lambda function
+resource "aws_lambda_function" "auth_forgot_password" {
+  filename         = data.archive_file.lambda_auth_forgot_password.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-forgot-password"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_forgot_password.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_forgot_password
+  ]
+}
+
+# Auth Reset Password Lambda function
+resource "aws_lambda_function" "auth_reset_password" {
+  filename         = data.archive_file.lambda_auth_reset_password.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-reset-password"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_reset_password.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_reset_password
+  ]
+}
+
+# Auth Refresh Lambda function
+resource "aws_lambda_function" "auth_refresh" {
+  filename         = data.archive_file.lambda_auth_refresh.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-refresh"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_refresh.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_refresh
+  ]
+}
+
+# Auth Logout Lambda function
+resource "aws_lambda_function" "auth_logout" {
+  filename         = data.archive_file.lambda_auth_logout.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-logout"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_logout.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      REVOKED_SESSIONS_TABLE       = aws_dynamodb_table.revoked_sessions.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_logout
+  ]
+}
+
+# Auth Logout All Lambda function
+resource "aws_lambda_function" "auth_logout_all" {
+  filename         = data.archive_file.lambda_auth_logout_all.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-logout-all"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_logout_all.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+      REVOKED_SESSIONS_TABLE       = aws_dynamodb_table.revoked_sessions.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_logout_all
+  ]
+}
+
+# Auth Challenge Lambda function
+resource "aws_lambda_function" "auth_challenge" {
+  filename         = data.archive_file.lambda_auth_challenge.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-challenge"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_challenge.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_challenge
+  ]
+}
+
+# Auth MFA Setup Lambda function
+resource "aws_lambda_function" "auth_mfa_setup" {
+  filename         = data.archive_file.lambda_auth_mfa_setup.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-mfa-setup"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_mfa_setup.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_mfa_setup
+  ]
+}
+
+# Auth MFA Verify Lambda function
+resource "aws_lambda_function" "auth_mfa_verify" {
+  filename         = data.archive_file.lambda_auth_mfa_verify.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-mfa-verify"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_mfa_verify.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_mfa_verify
+  ]
+}
+
+# Auth MFA Preference Lambda function
+resource "aws_lambda_function" "auth_mfa_preference" {
+  filename         = data.archive_file.lambda_auth_mfa_preference.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-mfa-preference"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_mfa_preference.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_mfa_preference
+  ]
+}
+
+# Auth MFA Disable Lambda function
+resource "aws_lambda_function" "auth_mfa_disable" {
+  filename         = data.archive_file.lambda_auth_mfa_disable.output_path
+  function_name    = "${var.project_name}-${var.environment}-auth-mfa-disable"
+  role            = aws_iam_role.lambda_execution.arn
+  handler         = "bootstrap"
+  source_code_hash = data.archive_file.lambda_auth_mfa_disable.output_base64sha256
+  runtime         = var.lambda_runtime
+  memory_size     = var.lambda_memory_size
+  timeout         = var.lambda_timeout
+
+  environment {
+    variables = {
+      ENVIRONMENT                  = var.environment
+      API_VERSION                  = "v1"
+      LOG_LEVEL                    = "info"
+      COGNITO_USER_POOL_ID         = aws_cognito_user_pool.main.id
+      COGNITO_USER_POOL_CLIENT_ID  = aws_cognito_user_pool_client.main.id
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.lambda_basic_execution,
+    aws_cloudwatch_log_group.lambda_auth_mfa_disable
+  ]
+}
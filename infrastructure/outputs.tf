@@ -48,6 +48,86 @@ output "chat_endpoint_url" {
   value       = "${aws_api_gateway_stage.main.invoke_url}/chat"
 }
 
+output "conversations_endpoint_url" {
+  description = "Full URL for the conversations endpoint"
+  value       = "${aws_api_gateway_stage.main.invoke_url}/conversations"
+}
+
+output "conversations_table_name" {
+  description = "DynamoDB table holding conversations and messages"
+  value       = aws_dynamodb_table.conversations.name
+}
+
+output "knowledge_endpoint_url" {
+  description = "Full URL for the knowledge base endpoint"
+  value       = "${aws_api_gateway_stage.main.invoke_url}/knowledge"
+}
+
+output "knowledge_table_name" {
+  description = "DynamoDB table holding knowledge base entries"
+  value       = aws_dynamodb_table.knowledge.name
+}
+
+output "usage_endpoint_url" {
+  description = "Full URL for the usage reporting endpoint"
+  value       = "${aws_api_gateway_stage.main.invoke_url}/usage"
+}
+
+output "usage_table_name" {
+  description = "DynamoDB table holding token usage counters"
+  value       = aws_dynamodb_table.usage.name
+}
+
+output "rate_limits_table_name" {
+  description = "DynamoDB table holding chat rate limit counters"
+  value       = aws_dynamodb_table.rate_limits.name
+}
+
+output "revoked_sessions_table_name" {
+  description = "DynamoDB table holding signed out sessions"
+  value       = aws_dynamodb_table.revoked_sessions.name
+}
+
+output "teams_endpoint_url" {
+  description = "Full URL for the teams endpoint"
+  value       = "${aws_api_gateway_stage.main.invoke_url}/teams"
+}
+
+output "teams_table_name" {
+  description = "DynamoDB table holding teams and their members"
+  value       = aws_dynamodb_table.teams.name
+}
+
+output "documents_endpoint_url" {
+  description = "Full URL for the documents endpoint"
+  value       = "${aws_api_gateway_stage.main.invoke_url}/documents"
+}
+
+output "documents_table_name" {
+  description = "DynamoDB table holding uploaded document metadata"
+  value       = aws_dynamodb_table.documents.name
+}
+
+output "documents_bucket_name" {
+  description = "S3 bucket holding uploaded document content"
+  value       = aws_s3_bucket.documents.id
+}
+
+output "prompts_endpoint_url" {
+  description = "Full URL for the prompt templates endpoint"
+  value       = "${aws_api_gateway_stage.main.invoke_url}/prompts"
+}
+
+output "prompts_table_name" {
+  description = "DynamoDB table holding system prompt templates and their versions"
+  value       = aws_dynamodb_table.prompts.name
+}
+
+output "chat_stream_url" {
+  description = "Function URL for streaming chat responses as Server-Sent Events"
+  value       = aws_lambda_function_url.chat_stream.function_url
+}
+
 output "cognito_user_pool_id" {
   description = "Cognito User Pool ID"
   value       = aws_cognito_user_pool.main.id
@@ -0,0 +1,29 @@
+# S3 bucket for uploaded document content
+# Objects are keyed by the SHA-256 hash of their content ("sha256/<hash>"), so
+# repeat uploads of the same document share one object.
+resource "aws_s3_bucket" "documents" {
+  bucket_prefix = "${var.project_name}-${var.environment}-documents-"
+
+  tags = {
+    Name = "${var.project_name}-${var.environment}-documents"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "documents" {
+  bucket = aws_s3_bucket.documents.id
+
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "documents" {
+  bucket = aws_s3_bucket.documents.id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
@@ -41,6 +41,12 @@ variable "amazon_ai_api_key" {
   sensitive   = true
 }
 
+variable "ai_provider" {
+  description = "AI provider for the chat Lambda (anthropic, bedrock or openai)"
+  type        = string
+  default     = "anthropic"
+}
+
 variable "ai_model_name" {
   description = "AI model name (e.g., claude-3-haiku-20240307 or Amazon Q model)"
   type        = string
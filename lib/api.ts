@@ -21,6 +21,63 @@ export interface ResendCodeRequest {
   email: string
 }
 
+export interface ForgotPasswordRequest {
+  email: string
+}
+
+export interface RefreshRequest {
+  refreshToken: string
+}
+
+export interface LogoutRequest {
+  refreshToken: string
+}
+
+export interface LogoutAllRequest {
+  accessToken: string
+}
+
+// ChallengeRequest answers a sign in challenge: newPassword for
+// NEW_PASSWORD_REQUIRED, code for SOFTWARE_TOKEN_MFA
+export interface ChallengeRequest {
+  email: string
+  challengeName: string
+  session: string
+  newPassword?: string
+  code?: string
+}
+
+export interface MfaSetupResponse {
+  message: string
+  secret_code: string
+  // otpauth:// URI for a QR code that authenticator apps scan
+  otpauth_uri: string
+}
+
+export interface MfaVerifyRequest {
+  accessToken: string
+  code: string
+  deviceName?: string
+}
+
+export interface MfaDisableRequest {
+  accessToken: string
+  password: string
+  code: string
+}
+
+export interface MfaPreferenceRequest {
+  accessToken: string
+  enabled: boolean
+  preferred: boolean
+}
+
+export interface ResetPasswordRequest {
+  email: string
+  code: string
+  newPassword: string
+}
+
 export interface ChatMessage {
   role: 'user' | 'assistant'
   content: string
@@ -32,6 +89,9 @@ export interface ChatRequest {
   team?: string
   teamInfo?: string[]
   markdownContent?: string
+  conversationId?: string
+  // Uploaded documents to read; preferred over sending markdownContent on every request
+  documentIds?: string[]
 }
 
 export interface LoginResponse {
@@ -43,12 +103,116 @@ export interface LoginResponse {
   expires_in: number
 }
 
+// ChallengeResponse is returned instead of tokens when sign in needs another step
+export interface ChallengeResponse {
+  message: string
+  challenge_name: string
+  session: string
+  challenge_parameters?: Record<string, string>
+}
+
+export function isChallenge(response: LoginResponse | ChallengeResponse): response is ChallengeResponse {
+  return 'challenge_name' in response
+}
+
 export interface ChatResponse {
   message: string
   environment: string
   aws_region: string
   api_version: string
   status: string
+  conversation_id?: string
+  tool_calls?: ToolCall[]
+  usage?: TokenUsage
+  stop_reason?: string
+  // Set when the answer was cut off at the token limit; send "continue" to get the rest
+  truncated?: boolean
+}
+
+export interface TokenUsage {
+  input_tokens: number
+  output_tokens: number
+}
+
+export interface ToolCall {
+  id: string
+  name: string
+  input: Record<string, unknown>
+  output: string
+  is_error?: boolean
+  duration_ms: number
+}
+
+export interface Team {
+  id: string
+  name: string
+  description: string
+  system_prompt: string
+  // Name of the prompt template the team's chats use; empty uses the default
+  prompt_template: string
+  links: string[]
+  documents: { name: string; content: string }[]
+  model: { model?: string; max_tokens?: number }
+  created_at: string
+  updated_at: string
+  updated_by?: string
+}
+
+export interface TeamMember {
+  team_id: string
+  user_id: string
+  email?: string
+  role: 'member' | 'admin'
+  added_at: string
+}
+
+export interface TeamsResponse {
+  teams: Team[]
+  // The caller's own teams; chat runs as the first unless a team is requested
+  memberships: TeamMember[]
+}
+
+export interface StoredDocument {
+  id: string
+  name: string
+  hash: string
+  size: number
+  version: number
+  created_at: string
+  updated_at: string
+}
+
+export interface UploadDocumentResponse extends StoredDocument {
+  // Set when the same content was already uploaded and that document was returned
+  deduplicated: boolean
+}
+
+export interface DocumentContent {
+  id: string
+  version: number
+  name: string
+  hash: string
+  size: number
+  created_at: string
+  content: string
+}
+
+export interface PromptTemplate {
+  name: string
+  description: string
+  // text/template body, e.g. "You help {{.UserName}} of {{.Team}}"
+  body: string
+  // 0 for the built-in default until it is first saved
+  version: number
+  created_at: string
+  updated_at: string
+  updated_by?: string
+}
+
+export interface PromptPreview {
+  name: string
+  version: number
+  prompt: string
 }
 
 export interface HealthResponse {
@@ -119,8 +283,15 @@ class ApiClient {
     return this.request<HealthResponse>('/health')
   }
 
-  async login(data: LoginRequest): Promise<LoginResponse> {
-    return this.request<LoginResponse>('/auth/login', {
+  async login(data: LoginRequest): Promise<LoginResponse | ChallengeResponse> {
+    return this.request<LoginResponse | ChallengeResponse>('/auth/login', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    })
+  }
+
+  async respondToChallenge(data: ChallengeRequest): Promise<LoginResponse | ChallengeResponse> {
+    return this.request<LoginResponse | ChallengeResponse>('/auth/challenge', {
       method: 'POST',
       body: JSON.stringify(data),
     })
@@ -147,15 +318,169 @@ class ApiClient {
     })
   }
 
-  async chat(data: ChatRequest, accessToken: string): Promise<ChatResponse> {
+  async refreshTokens(data: RefreshRequest): Promise<LoginResponse> {
+    return this.request<LoginResponse>('/auth/refresh', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    })
+  }
+
+  async logout(data: LogoutRequest, idToken: string): Promise<{ message: string }> {
+    return this.request<{ message: string }>('/auth/logout', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify(data),
+    })
+  }
+
+  async logoutAll(data: LogoutAllRequest, idToken: string): Promise<{ message: string }> {
+    return this.request<{ message: string }>('/auth/logout-all', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify(data),
+    })
+  }
+
+  async setupMfa(accessToken: string, idToken: string): Promise<MfaSetupResponse> {
+    return this.request<MfaSetupResponse>('/auth/mfa/setup', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify({ accessToken }),
+    })
+  }
+
+  async verifyMfa(data: MfaVerifyRequest, idToken: string): Promise<{ message: string }> {
+    return this.request<{ message: string }>('/auth/mfa/verify', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify(data),
+    })
+  }
+
+  async setMfaPreference(
+    data: MfaPreferenceRequest,
+    idToken: string
+  ): Promise<{ message: string; enabled: boolean; preferred: boolean }> {
+    return this.request<{ message: string; enabled: boolean; preferred: boolean }>('/auth/mfa/preference', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify(data),
+    })
+  }
+
+  async disableMfa(data: MfaDisableRequest, idToken: string): Promise<{ message: string }> {
+    return this.request<{ message: string }>('/auth/mfa/disable', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify(data),
+    })
+  }
+
+  async forgotPassword(data: ForgotPasswordRequest): Promise<{ message: string }> {
+    return this.request<{ message: string }>('/auth/forgot-password', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    })
+  }
+
+  async resetPassword(data: ResetPasswordRequest): Promise<{ message: string }> {
+    return this.request<{ message: string }>('/auth/reset-password', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    })
+  }
+
+  async chat(data: ChatRequest, idToken: string): Promise<ChatResponse> {
     return this.request<ChatResponse>('/chat', {
       method: 'POST',
       headers: {
-        'Authorization': `Bearer ${accessToken}`,
+        'Authorization': `Bearer ${idToken}`,
       },
       body: JSON.stringify(data),
     })
   }
+
+  async uploadDocument(name: string, content: string, idToken: string): Promise<UploadDocumentResponse> {
+    return this.request<UploadDocumentResponse>('/documents', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify({ name, content }),
+    })
+  }
+
+  // Uploads a PDF, DOCX or HTML file, which is stored as markdown.
+  // file is the file's content, base64 encoded.
+  async uploadFile(name: string, file: string, idToken: string): Promise<UploadDocumentResponse> {
+    return this.request<UploadDocumentResponse>('/documents', {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify({ name, file }),
+    })
+  }
+
+  async document(id: string, idToken: string): Promise<DocumentContent> {
+    return this.request<DocumentContent>(`/documents/${encodeURIComponent(id)}`, {
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+    })
+  }
+
+  async promptTemplates(idToken: string): Promise<{ templates: PromptTemplate[] }> {
+    return this.request<{ templates: PromptTemplate[] }>('/prompts', {
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+    })
+  }
+
+  // Renders a template with the caller's details and sample knowledge. Pass body
+  // to preview a draft, version for an earlier version, or teamId for a team.
+  async previewPrompt(
+    name: string,
+    options: { body?: string; version?: number; teamId?: string },
+    idToken: string
+  ): Promise<PromptPreview> {
+    return this.request<PromptPreview>(`/prompts/${encodeURIComponent(name)}/preview`, {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+      body: JSON.stringify(options),
+    })
+  }
+
+  async documents(idToken: string): Promise<{ documents: StoredDocument[] }> {
+    return this.request<{ documents: StoredDocument[] }>('/documents', {
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+    })
+  }
+
+  async teams(idToken: string): Promise<TeamsResponse> {
+    return this.request<TeamsResponse>('/teams', {
+      headers: {
+        'Authorization': `Bearer ${idToken}`,
+      },
+    })
+  }
 }
 
 export const apiClient = new ApiClient(API_BASE_URL)
\ No newline at end of file
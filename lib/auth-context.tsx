@@ -1,6 +1,7 @@
 'use client'
 
 import { createContext, useContext, useState, useEffect, ReactNode } from 'react'
+import { apiClient } from './api'
 
 interface User {
   email: string
@@ -20,7 +21,9 @@ interface AuthContextType {
   isAuthenticated: boolean
   isLoading: boolean
   login: (tokens: AuthTokens) => void
-  logout: () => void
+  logout: () => Promise<void>
+  logoutAll: () => Promise<void>
+  refresh: () => Promise<AuthTokens | null>
   updateUser: (user: User) => void
 }
 
@@ -66,11 +69,58 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     })
   }
 
-  const logout = () => {
+  const clearSession = () => {
     setTokens(null)
     setUser(null)
   }
 
+  // Revoke the session on the backend before forgetting it locally, so its
+  // tokens stop working everywhere. A failed request still signs out here.
+  const logout = async () => {
+    if (tokens) {
+      try {
+        await apiClient.logout({ refreshToken: tokens.refresh_token }, tokens.id_token)
+      } catch (error) {
+        console.error('Failed to revoke session:', error)
+      }
+    }
+    clearSession()
+  }
+
+  // Sign out of every device the user is signed in on
+  const logoutAll = async () => {
+    if (tokens) {
+      try {
+        await apiClient.logoutAll({ accessToken: tokens.access_token }, tokens.id_token)
+      } catch (error) {
+        console.error('Failed to sign out everywhere:', error)
+      }
+    }
+    clearSession()
+  }
+
+  // Exchange the refresh token for new tokens, signing out if the session has
+  // expired or been revoked
+  const refresh = async (): Promise<AuthTokens | null> => {
+    if (!tokens) {
+      return null
+    }
+    try {
+      const response = await apiClient.refreshTokens({ refreshToken: tokens.refresh_token })
+      const newTokens = {
+        access_token: response.access_token,
+        refresh_token: response.refresh_token,
+        id_token: response.id_token,
+      }
+      setTokens(newTokens)
+      return newTokens
+    } catch (error) {
+      console.error('Failed to refresh session:', error)
+      clearSession()
+      return null
+    }
+  }
+
   const updateUser = (newUser: User) => {
     setUser(newUser)
   }
@@ -82,6 +132,8 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     isLoading,
     login,
     logout,
+    logoutAll,
+    refresh,
     updateUser,
   }
 